@@ -6,6 +6,7 @@ package la
 
 import (
 	"math"
+	"math/cmplx"
 
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/la/oblas"
@@ -26,6 +27,21 @@ func DenSolve(x Vector, A *Matrix, b Vector, preserveA bool) {
 	oblas.Dgesv(A.M, 1, a.Data, A.M, ipiv, x, A.M)
 }
 
+// DenSolveC solves dense linear system using LAPACK (OpenBLAS) (complex version)
+//
+//   Given:  A ⋅ x = b    find x   such that   x = A⁻¹ ⋅ b
+//
+func DenSolveC(x VectorC, A *MatrixC, b VectorC, preserveA bool) {
+	a := A
+	if preserveA {
+		a = NewMatrixC(A.M, A.N)
+		copy(a.Data, A.Data)
+	}
+	copy(x, b)
+	ipiv := make([]int32, A.M)
+	oblas.Zgesv(A.M, 1, a.Data, A.M, ipiv, x, A.M)
+}
+
 // Cholesky returns the Cholesky decomposition of a symmetric positive-definite matrix
 //
 //   a = L * trans(L)
@@ -49,6 +65,34 @@ func Cholesky(L, a *Matrix) {
 	}
 }
 
+// CholeskyC returns the Cholesky decomposition of a Hermitian positive-definite matrix
+//
+//   a = L * conj(trans(L))
+//
+//   NOTE: only the lower triangle of a is used; L is lower triangular with real diagonal
+//
+func CholeskyC(L, a *MatrixC) {
+	for j := 0; j < a.M; j++ { // loop over columns
+		for i := j; i < a.M; i++ { // loop over lower diagonal rows (including diagonal)
+			amsum := a.Get(i, j)
+			for k := 0; k < j; k++ {
+				amsum -= L.Get(i, k) * cmplx.Conj(L.Get(j, k))
+			}
+			if i == j {
+				if real(amsum) <= 0.0 {
+					chk.Panic("Cholesky factorization failed due to non positive-definite matrix")
+				}
+				L.Set(i, j, complex(math.Sqrt(real(amsum)), 0))
+			} else {
+				L.Set(i, j, amsum/L.Get(j, j))
+			}
+		}
+		for i := 0; i < j; i++ { // clear upper triangle
+			L.Set(i, j, 0)
+		}
+	}
+}
+
 // SolveRealLinSysSPD solves a linear system with real numbres and a Symmetric-Positive-Definite (SPD) matrix
 //
 //        x := inv(a) * b
@@ -5,6 +5,7 @@
 package la
 
 import (
+	"math/cmplx"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
@@ -107,6 +108,59 @@ func EigenVecLR(u, v *MatrixC, w VectorC, A *Matrix, preserveA bool) {
 	oblas.EigenvecsBuildBoth(u.Data, v.Data, wr, wi, uu, vv)
 }
 
+// HermitianEigen computes the (real) eigenvalues and, optionally, the eigenvectors of a complex
+// Hermitian matrix
+//
+//   A ⋅ v[j] = λ[j] ⋅ v[j]    with   A = Aᴴ
+//
+//   INPUT:
+//     A -- Hermitian matrix; only the upper triangle is used
+//
+//   OUTPUT:
+//     w -- eigenvalues in ascending order [pre-allocated]
+//     v -- matrix with the orthonormal eigenvectors; each column contains one eigenvector
+//          [pre-allocated]. v may be nil if the eigenvectors are not needed
+//
+func HermitianEigen(w Vector, v *MatrixC, A *MatrixC, preserveA bool) {
+	a := A
+	if v != nil {
+		copy(v.Data, A.Data)
+		a = v
+	} else if preserveA {
+		a = A.GetCopy()
+	}
+	if a.M == 0 {
+		return
+	}
+	oblas.Zheev(v != nil, true, a.M, a.Data, a.M, w)
+}
+
+// CheckEigenVecHermitian checks the eigenvectors of a Hermitian matrix:
+//
+//   A ⋅ v[j] = λ[j] ⋅ v[j]    and    vᴴ[i] ⋅ v[j] = δᵢⱼ
+//
+func CheckEigenVecHermitian(tst *testing.T, A *MatrixC, λ Vector, v *MatrixC, tol float64) {
+	Av := NewVectorC(A.M)
+	λv := NewVectorC(A.M)
+	for j := 0; j < len(λ); j++ {
+		MatVecMulC(Av, 1, A, v.Col(j))
+		λv.Apply(complex(λ[j], 0), v.Col(j))
+		chk.ArrayC(tst, io.Sf("Av[%d] = λv[%d]", j, j), tol, Av, λv)
+		for i := 0; i < len(λ); i++ {
+			var vv complex128
+			for k := 0; k < A.M; k++ {
+				vv += cmplx.Conj(v.Get(k, i)) * v.Get(k, j)
+			}
+			if i == j {
+				vv -= 1
+			}
+			if cmplx.Abs(vv) > tol {
+				tst.Errorf("eigenvectors are not orthonormal: |vᴴ[%d]⋅v[%d] - δ| = %g\n", i, j, cmplx.Abs(vv))
+			}
+		}
+	}
+}
+
 // CheckEigenVecL checks left eigenvector:
 //
 //    H                  H
@@ -0,0 +1,600 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la/oblas"
+	"github.com/cpmech/gosl/utl"
+)
+
+// MatExp computes the matrix exponential
+//
+//   res := exp(a) = I + a + a²/2! + a³/3! + ...
+//
+//   The scaling and squaring algorithm with Padé approximants of degree 3, 5, 7, 9 or 13 is
+//   employed; see [1]. Scaling is only used (with degree 13) when ‖a‖₁ > 5.37
+//
+//   Reference:
+//     [1] Higham NJ (2005) The scaling and squaring method for the matrix exponential revisited,
+//         SIAM Journal on Matrix Analysis and Applications, 26(4):1179-1193
+//
+func MatExp(res, a *Matrix) {
+
+	// check
+	n := a.M
+	if a.N != n || res.M != n || res.N != n {
+		chk.Panic("matrices must be square with the same dimensions. a:(%d,%d), res:(%d,%d)\n", a.M, a.N, res.M, res.N)
+	}
+	if n == 0 {
+		return
+	}
+
+	// select degree of Padé approximant
+	nrm := matNorm1(a)
+	for k, θ := range expPadeTheta {
+		if nrm <= θ {
+			expPade(res, a, expPadeCoef[k])
+			return
+		}
+	}
+
+	// scale, apply Padé approximant of degree 13 and square
+	s := utl.Imax(0, int(math.Ceil(math.Log2(nrm/expPadeTheta13))))
+	as := NewMatrix(n, n)
+	as.Apply(math.Pow(2, -float64(s)), a)
+	expPade(res, as, expPadeCoef13)
+	tmp := NewMatrix(n, n)
+	for k := 0; k < s; k++ {
+		MatMatMul(tmp, 1, res, res)
+		copy(res.Data, tmp.Data)
+	}
+}
+
+// MatPhi computes the φ-functions of a matrix a; i.e.
+//
+//   φ₀(a) = exp(a)   and   φₖ(a) = ∫₀¹ exp((1-θ)⋅a) θᵏ⁻¹/(k-1)! dθ   for k ≥ 1
+//
+//   such that φₖ₊₁(a)⋅a = φₖ(a) - I/k!; e.g. φ₁(a) = (exp(a) - I)⋅a⁻¹ for non-singular a.
+//
+//   Output:
+//     phi -- [p+1] pre-allocated matrices (n x n) with φ₀ ... φₚ
+//
+//   NOTE: the functions are computed as the first block row of the exponential of the augmented
+//         matrix of size n⋅(p+1) below, thus a may be singular
+//
+//           ┌             ┐
+//           │ a  I  0 … 0 │
+//           │ 0  0  I … 0 │
+//           │ ⋮        ⋱  │
+//           │ 0  0  0 … I │
+//           │ 0  0  0 … 0 │
+//           └             ┘
+//
+func MatPhi(phi []*Matrix, a *Matrix) {
+	n, p := a.M, len(phi)-1
+	if p < 0 {
+		return
+	}
+	if a.N != n {
+		chk.Panic("matrix must be square. a:(%d,%d)\n", a.M, a.N)
+	}
+	N := n * (p + 1)
+	w := NewMatrix(N, N)
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			w.Data[i+j*N] = a.Data[i+j*n]
+		}
+	}
+	for k := 1; k <= p; k++ {
+		for i := 0; i < n; i++ {
+			w.Set((k-1)*n+i, k*n+i, 1)
+		}
+	}
+	e := NewMatrix(N, N)
+	MatExp(e, w)
+	for k := 0; k <= p; k++ {
+		if phi[k].M != n || phi[k].N != n {
+			chk.Panic("phi[%d] must be (%d,%d). phi[%d]:(%d,%d)\n", k, n, n, k, phi[k].M, phi[k].N)
+		}
+		for j := 0; j < n; j++ {
+			for i := 0; i < n; i++ {
+				phi[k].Data[i+j*n] = e.Data[i+(k*n+j)*N]
+			}
+		}
+	}
+}
+
+// MatSqrt computes the principal square root of a matrix; i.e. res such that
+//
+//   res ⋅ res = a   with all eigenvalues of res in the open right half-plane
+//
+//   The Schur method is used: a = U⋅T⋅Uᴴ with complex upper triangular T and the square root of
+//   T is found by a recurrence on its superdiagonals; see [1]
+//
+//   NOTE: a must not have eigenvalues on the closed negative real axis (including zero), otherwise
+//         the real principal square root does not exist (or is not unique)
+//
+//   Reference:
+//     [1] Björck Å and Hammarling S (1983) A Schur method for the square root of a matrix,
+//         Linear Algebra and its Applications, 52/53:127-140
+//
+func MatSqrt(res, a *Matrix) {
+	u, t := matSchurC(a, "sqrt", false)
+	r := NewMatrixC(a.M, a.M)
+	triSqrt(r, t)
+	matSchurBack(res, u, r)
+}
+
+// MatLog computes the principal logarithm of a matrix; i.e. res such that
+//
+//   exp(res) = a   with all eigenvalues of res having imaginary parts in (-π, π)
+//
+//   The inverse scaling and squaring method is used: a = U⋅T⋅Uᴴ with complex upper triangular T;
+//   then, square roots of T are taken k times until T^(1/2ᵏ) is close to the identity, such that
+//   log(T) = 2ᵏ⋅log(T^(1/2ᵏ)) is computed by the [8/8] Padé approximant of log(I + X), written as
+//   the 8-point Gauss-Legendre quadrature of log(I + X) = ∫₀¹ X⋅(I + s⋅X)⁻¹ ds; see [1,2]
+//
+//   NOTE: a must not have eigenvalues on the closed negative real axis (including zero)
+//
+//   References:
+//     [1] Kenney C and Laub AJ (1989) Condition estimates for matrix functions, SIAM Journal on
+//         Matrix Analysis and Applications, 10(2):191-209
+//     [2] Higham NJ (2008) Functions of Matrices: Theory and Computation, SIAM
+//
+func MatLog(res, a *Matrix) {
+
+	// Schur decomposition
+	n := a.M
+	u, t := matSchurC(a, "log", true)
+
+	// take square roots until ‖T - I‖₁ ≤ 1/4
+	r := NewMatrixC(n, n)
+	k := 0
+	for ; ; k++ {
+		nrm := 0.0
+		for j := 0; j < n; j++ {
+			sum := 0.0
+			for i := 0; i <= j; i++ {
+				tij := t.Data[i+j*n]
+				if i == j {
+					tij -= 1
+				}
+				sum += cmplx.Abs(tij)
+			}
+			nrm = math.Max(nrm, sum)
+		}
+		if nrm <= 0.25 {
+			break
+		}
+		if k == logMaxSqrt {
+			chk.Panic("logarithm of matrix failed: ‖T - I‖ = %g after %d square roots\n", nrm, k)
+		}
+		triSqrt(r, t)
+		t, r = r, t
+	}
+
+	// X := T - I
+	for i := 0; i < n; i++ {
+		t.Data[i+i*n] -= 1
+	}
+
+	// L := Σ wᵢ⋅X⋅(I + sᵢ⋅X)⁻¹ ⋅ 2ᵏ
+	l := NewMatrixC(n, n)
+	m := NewMatrixC(n, n)
+	y := NewVectorC(n)
+	scale := math.Pow(2, float64(k))
+	for q, s := range logGaussNodes {
+		for j := 0; j < n; j++ {
+			for i := 0; i <= j; i++ {
+				m.Data[i+j*n] = complex(s, 0) * t.Data[i+j*n]
+			}
+			m.Data[j+j*n] += 1
+		}
+		for j := 0; j < n; j++ { // solve (I + s⋅X)⋅y = X[:,j]; note that X commutes with (I + s⋅X)⁻¹
+			for i := j; i >= 0; i-- {
+				sum := t.Data[i+j*n]
+				for p := i + 1; p <= j; p++ {
+					sum -= m.Data[i+p*n] * y[p]
+				}
+				y[i] = sum / m.Data[i+i*n]
+			}
+			for i := 0; i <= j; i++ {
+				l.Data[i+j*n] += complex(scale*logGaussWeights[q], 0) * y[i]
+			}
+		}
+	}
+	matSchurBack(res, u, l)
+}
+
+// SpExpv computes the action of the exponential of a sparse matrix on a vector
+//
+//   w := exp(t⋅a) ⋅ v
+//
+//   The Krylov subspace projection method with time stepping and local error control by Sidje
+//   (Expokit's expv) is used; see [1]. At each step, an Arnoldi basis Vₘ of dimension m is built and
+//   exp(τ⋅a)⋅w ≈ β⋅Vₘ⋅exp(τ⋅Hₘ)⋅e₁, where the small exponential is computed by MatExp
+//
+//   Input:
+//     t   -- time (may be negative)
+//     a   -- sparse (square) matrix
+//     v   -- vector
+//     m   -- dimension of the Krylov subspace [use 0 for default = 30]
+//     tol -- requested accuracy tolerance [use 0 for default = 1e-7]
+//   Output:
+//     w      -- result (must be different from v)
+//     nsteps -- number of time steps
+//     errEst -- estimate of the accumulated error
+//
+//   Reference:
+//     [1] Sidje RB (1998) Expokit: a software package for computing matrix exponentials, ACM
+//         Transactions on Mathematical Software, 24(1):130-156
+//
+func SpExpv(w Vector, t float64, a *CCMatrix, v Vector, m int, tol float64) (nsteps int, errEst float64) {
+
+	// check
+	n := a.m
+	if a.n != n || len(v) != n || len(w) != n {
+		chk.Panic("matrix must be square and vectors must have compatible dimensions. a:(%d,%d), len(v)=%d, len(w)=%d\n", a.m, a.n, len(v), len(w))
+	}
+	if m <= 0 {
+		m = 30
+	}
+	if tol <= 0 {
+		tol = 1e-7
+	}
+	m = utl.Imin(m, n)
+	copy(w, v)
+	β := v.Norm()
+	if n == 0 || β == 0 || t == 0 {
+		return
+	}
+
+	// constants
+	const mxrej, btol, γ, δ = 10, 1e-7, 0.9, 1.2
+
+	// norm of a
+	rsum := make([]float64, n)
+	for k := 0; k < a.p[n]; k++ {
+		rsum[a.i[k]] += math.Abs(a.x[k])
+	}
+	anorm := 0.0
+	for _, s := range rsum {
+		anorm = math.Max(anorm, s)
+	}
+	if anorm == 0 {
+		return
+	}
+	rndoff := anorm * (math.Nextafter(1, 2) - 1)
+
+	// round to 2 significant digits (upwards)
+	round := func(τ float64) float64 {
+		s := math.Pow(10, math.Floor(math.Log10(τ))-1)
+		return math.Ceil(τ/s) * s
+	}
+
+	// initial step size
+	sgn, tOut := 1.0, math.Abs(t)
+	if t < 0 {
+		sgn = -1
+	}
+	xm := 1.0 / float64(m)
+	fact := math.Pow(float64(m+1)/math.E, float64(m+1)) * math.Sqrt(2*math.Pi*float64(m+1))
+	tNew := round((1 / anorm) * math.Pow((fact*tol)/(4*β*anorm), xm))
+
+	// Krylov basis and Hessenberg matrix
+	V := make([]Vector, m+1)
+	for j := 0; j <= m; j++ {
+		V[j] = NewVector(n)
+	}
+	H := NewMatrix(m+2, m+2)
+	p := NewVector(n)
+
+	// time stepping
+	tNow := 0.0
+	for tNow < tOut {
+		nsteps++
+		τ := math.Min(tOut-tNow, tNew)
+
+		// Arnoldi process
+		k1, mb := 2, m
+		H.Fill(0)
+		V[0].Apply(1/β, w)
+		for j := 0; j < m; j++ {
+			SpMatVecMul(p, 1, a, V[j])
+			for i := 0; i <= j; i++ {
+				hij := VecDot(V[i], p)
+				H.Set(i, j, hij)
+				VecAdd(p, -hij, V[i], 1, p)
+			}
+			s := p.Norm()
+			if s < btol { // happy breakdown
+				k1, mb = 0, j+1
+				τ = tOut - tNow
+				break
+			}
+			H.Set(j+1, j, s)
+			V[j+1].Apply(1/s, p)
+		}
+		avnorm := 0.0
+		if k1 != 0 {
+			H.Set(m+1, m, 1)
+			SpMatVecMul(p, 1, a, V[m])
+			avnorm = p.Norm()
+		}
+
+		// exponential of the Hessenberg matrix and local error estimate
+		var F *Matrix
+		var errLoc float64
+		for ireject := 0; ; ireject++ {
+			mx := mb + k1
+			Hs := NewMatrix(mx, mx)
+			for j := 0; j < mx; j++ {
+				for i := 0; i < mx; i++ {
+					Hs.Data[i+j*mx] = sgn * τ * H.Get(i, j)
+				}
+			}
+			F = NewMatrix(mx, mx)
+			MatExp(F, Hs)
+			if k1 == 0 {
+				errLoc = btol
+				break
+			}
+			φ1 := math.Abs(β * F.Get(m, 0))
+			φ2 := math.Abs(β * F.Get(m+1, 0) * avnorm)
+			switch {
+			case φ1 > 10*φ2:
+				errLoc, xm = φ2, 1.0/float64(m)
+			case φ1 > φ2:
+				errLoc, xm = (φ1*φ2)/(φ1-φ2), 1.0/float64(m)
+			default:
+				errLoc, xm = φ1, 1.0/float64(utl.Imax(m-1, 1))
+			}
+			if errLoc <= δ*τ*tol {
+				break
+			}
+			if ireject == mxrej {
+				chk.Panic("SpExpv failed: the requested tolerance (%g) is too high\n", tol)
+			}
+			τ = round(γ * τ * math.Pow(τ*tol/errLoc, xm))
+		}
+
+		// update solution: w := β⋅V⋅F[:,0]
+		mx := mb + utl.Imax(0, k1-1)
+		w.Fill(0)
+		for j := 0; j < mx; j++ {
+			VecAdd(w, β*F.Get(j, 0), V[j], 1, w)
+		}
+		β = w.Norm()
+
+		// next step
+		tNow += τ
+		tNew = round(γ * τ * math.Pow(τ*tol/errLoc, xm))
+		errEst += math.Max(errLoc, rndoff)
+		if β == 0 {
+			break
+		}
+	}
+	return
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// expPadeTheta holds the maximum ‖a‖₁ for the Padé approximants of degree 3, 5, 7 and 9
+var expPadeTheta = []float64{1.495585217958292e-2, 2.539398330063230e-1, 9.504178996162932e-1, 2.097847961257068}
+
+// expPadeTheta13 holds the maximum ‖a‖₁ for the Padé approximant of degree 13
+const expPadeTheta13 = 5.371920351148152
+
+// expPadeCoef holds the coefficients of the Padé approximants of degree 3, 5, 7 and 9
+var expPadeCoef = [][]float64{
+	{120, 60, 12, 1},
+	{30240, 15120, 3360, 420, 30, 1},
+	{17297280, 8648640, 1995840, 277200, 25200, 1512, 56, 1},
+	{17643225600, 8821612800, 2075673600, 302702400, 30270240, 2162160, 110880, 3960, 90, 1},
+}
+
+// expPadeCoef13 holds the coefficients of the Padé approximant of degree 13
+var expPadeCoef13 = []float64{64764752532480000, 32382376266240000, 7771770303897600,
+	1187353796428800, 129060195264000, 10559470521600, 670442572800, 33522128640, 1323241920,
+	40840800, 960960, 16380, 182, 1}
+
+// logMaxSqrt is the maximum number of square roots in MatLog
+const logMaxSqrt = 64
+
+// logGaussNodes and logGaussWeights hold the 8-point Gauss-Legendre rule on [0,1]
+var (
+	logGaussNodes = []float64{
+		(1 - 0.9602898564975363) / 2, (1 - 0.7966664774136267) / 2,
+		(1 - 0.5255324099163290) / 2, (1 - 0.1834346424956498) / 2,
+		(1 + 0.1834346424956498) / 2, (1 + 0.5255324099163290) / 2,
+		(1 + 0.7966664774136267) / 2, (1 + 0.9602898564975363) / 2,
+	}
+	logGaussWeights = []float64{
+		0.1012285362903763 / 2, 0.2223810344533745 / 2, 0.3137066458778873 / 2, 0.3626837833783620 / 2,
+		0.3626837833783620 / 2, 0.3137066458778873 / 2, 0.2223810344533745 / 2, 0.1012285362903763 / 2,
+	}
+)
+
+// matNorm1 returns the 1-norm (maximum absolute column sum) of a
+func matNorm1(a *Matrix) (nrm float64) {
+	for j := 0; j < a.N; j++ {
+		sum := 0.0
+		for i := 0; i < a.M; i++ {
+			sum += math.Abs(a.Data[i+j*a.M])
+		}
+		nrm = math.Max(nrm, sum)
+	}
+	return
+}
+
+// expPade computes the Padé approximant r(a) = [q(a)]⁻¹⋅p(a) of exp(a) with coefficients b, where
+//
+//   p(a) = V + U  and  q(a) = V - U   with   U = a⋅Σ b[2k+1]⋅a²ᵏ   and   V = Σ b[2k]⋅a²ᵏ
+//
+func expPade(res, a *Matrix, b []float64) {
+	n := a.M
+	a2 := NewMatrix(n, n)
+	MatMatMul(a2, 1, a, a)
+	tu := NewMatrix(n, n) // U before the multiplication by a
+	v := NewMatrix(n, n)
+	if len(b) == 14 { // degree 13: evaluate with a², a⁴ and a⁶ only
+		a4 := NewMatrix(n, n)
+		a6 := NewMatrix(n, n)
+		MatMatMul(a4, 1, a2, a2)
+		MatMatMul(a6, 1, a4, a2)
+		tmp := NewMatrix(n, n)
+		for k := range tmp.Data {
+			tmp.Data[k] = b[13]*a6.Data[k] + b[11]*a4.Data[k] + b[9]*a2.Data[k]
+		}
+		MatMatMul(tu, 1, a6, tmp)
+		for k := range tmp.Data {
+			tmp.Data[k] = b[12]*a6.Data[k] + b[10]*a4.Data[k] + b[8]*a2.Data[k]
+		}
+		MatMatMul(v, 1, a6, tmp)
+		for k := range tu.Data {
+			tu.Data[k] += b[7]*a6.Data[k] + b[5]*a4.Data[k] + b[3]*a2.Data[k]
+			v.Data[k] += b[6]*a6.Data[k] + b[4]*a4.Data[k] + b[2]*a2.Data[k]
+		}
+		for i := 0; i < n; i++ {
+			tu.Data[i+i*n] += b[1]
+			v.Data[i+i*n] += b[0]
+		}
+	} else { // low degrees: I, a², a⁴, …
+		pw := NewMatrix(n, n)
+		pw.SetDiag(1)
+		tmp := NewMatrix(n, n)
+		for k := 0; 2*k+1 < len(b); k++ {
+			if k > 0 {
+				MatMatMul(tmp, 1, pw, a2)
+				pw, tmp = tmp, pw
+			}
+			for l := range pw.Data {
+				tu.Data[l] += b[2*k+1] * pw.Data[l]
+				v.Data[l] += b[2*k] * pw.Data[l]
+			}
+		}
+	}
+	u := NewMatrix(n, n)
+	MatMatMul(u, 1, a, tu)
+
+	// solve (V - U)⋅r = (V + U)
+	q := NewMatrix(n, n)
+	for k := range q.Data {
+		q.Data[k] = v.Data[k] - u.Data[k]
+		res.Data[k] = v.Data[k] + u.Data[k]
+	}
+	ipiv := make([]int32, n)
+	oblas.Dgesv(n, n, q.Data, n, ipiv, res.Data, n)
+}
+
+// matSchurC computes the complex Schur decomposition a = U⋅T⋅Uᴴ with upper triangular T
+//
+//   NOTE: panics if a has eigenvalues on the negative real axis (or zero, if noZero)
+//
+func matSchurC(a *Matrix, fname string, noZero bool) (u, t *MatrixC) {
+
+	// real Schur decomposition
+	n := a.M
+	if a.N != n {
+		chk.Panic("matrix must be square. a:(%d,%d)\n", a.M, a.N)
+	}
+	tr := a.GetCopy()
+	wr, wi := make([]float64, n), make([]float64, n)
+	vs := NewMatrix(n, n)
+	if n > 0 {
+		oblas.Dgees(true, n, tr.Data, n, wr, wi, vs.Data, n)
+	}
+	for k := 0; k < n; k++ {
+		if wi[k] == 0 && (wr[k] < 0 || (noZero && wr[k] == 0)) {
+			chk.Panic("cannot compute the principal %s of matrix: eigenvalue λ=%g is on the negative real axis or zero\n", fname, wr[k])
+		}
+	}
+
+	// convert to complex form (Givens rotations on the 2x2 blocks)
+	u, t = vs.GetComplex(), tr.GetComplex()
+	for m := n - 1; m > 0; m-- {
+		s := t.Get(m, m-1)
+		if s == 0 {
+			continue
+		}
+		a11, a12, a21, a22 := t.Get(m-1, m-1), t.Get(m-1, m), s, t.Get(m, m)
+		tra, det := a11+a22, a11*a22-a12*a21
+		λ := tra/2 + cmplx.Sqrt(tra*tra/4-det)
+		μ := λ - a22
+		r := math.Hypot(cmplx.Abs(μ), cmplx.Abs(s))
+		c, sn := μ/complex(r, 0), s/complex(r, 0)
+
+		// rows m-1 and m: G⋅T with G = [cᴴ sᴴ; -s c]
+		for j := m - 1; j < n; j++ {
+			x, y := t.Get(m-1, j), t.Get(m, j)
+			t.Set(m-1, j, cmplx.Conj(c)*x+cmplx.Conj(sn)*y)
+			t.Set(m, j, -sn*x+c*y)
+		}
+
+		// columns m-1 and m: T⋅Gᴴ and U⋅Gᴴ
+		for i := 0; i < n; i++ {
+			if i <= m {
+				x, y := t.Get(i, m-1), t.Get(i, m)
+				t.Set(i, m-1, c*x+sn*y)
+				t.Set(i, m, -cmplx.Conj(sn)*x+cmplx.Conj(c)*y)
+			}
+			x, y := u.Get(i, m-1), u.Get(i, m)
+			u.Set(i, m-1, c*x+sn*y)
+			u.Set(i, m, -cmplx.Conj(sn)*x+cmplx.Conj(c)*y)
+		}
+		t.Set(m, m-1, 0)
+	}
+	return
+}
+
+// matSchurBack computes res := real(U⋅F⋅Uᴴ)
+func matSchurBack(res *Matrix, u, f *MatrixC) {
+	n := u.M
+	if res.M != n || res.N != n {
+		chk.Panic("result matrix must be (%d,%d). res:(%d,%d)\n", n, n, res.M, res.N)
+	}
+	uf := NewMatrixC(n, n)
+	for j := 0; j < n; j++ {
+		for k := 0; k <= j; k++ { // F is upper triangular
+			fkj := f.Data[k+j*n]
+			for i := 0; i < n; i++ {
+				uf.Data[i+j*n] += u.Data[i+k*n] * fkj
+			}
+		}
+	}
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			var sum complex128
+			for k := 0; k < n; k++ {
+				sum += uf.Data[i+k*n] * cmplx.Conj(u.Data[j+k*n])
+			}
+			res.Data[i+j*n] = real(sum)
+		}
+	}
+}
+
+// triSqrt computes the principal square root r of the upper triangular matrix t
+func triSqrt(r, t *MatrixC) {
+	n := t.M
+	r.Fill(0)
+	for j := 0; j < n; j++ {
+		r.Data[j+j*n] = cmplx.Sqrt(t.Data[j+j*n])
+		for i := j - 1; i >= 0; i-- {
+			sum := t.Data[i+j*n]
+			for k := i + 1; k < j; k++ {
+				sum -= r.Data[i+k*n] * r.Data[k+j*n]
+			}
+			den := r.Data[i+i*n] + r.Data[j+j*n]
+			if den == 0 {
+				chk.Panic("square root of matrix failed: the matrix is singular\n")
+			}
+			r.Data[i+j*n] = sum / den
+		}
+	}
+}
@@ -6,6 +6,7 @@ package la
 
 import (
 	"math"
+	"math/cmplx"
 
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/la/oblas"
@@ -130,6 +131,73 @@ func MatInv(ai, a *Matrix, calcDet bool) (det float64) {
 	return
 }
 
+// MatSvdC performs the SVD decomposition of a complex matrix
+//   Input:
+//     a     -- matrix a
+//     copyA -- creates a copy of a; otherwise 'a' will be modified
+//   Output:
+//     s  -- diagonal terms [must be pre-allocated] len(s) = imin(a.M, a.N)
+//     u  -- left matrix [must be pre-allocated] u is (a.M x a.M)
+//     vt -- transposed (conjugate) right matrix [must be pre-allocated] vt is (a.N x a.N)
+func MatSvdC(s []float64, u, vt, a *MatrixC, copyA bool) {
+	superb := make([]float64, utl.Imin(a.M, a.N))
+	acpy := a
+	if copyA {
+		acpy = a.GetCopy()
+	}
+	oblas.Zgesvd('A', 'A', a.M, a.N, acpy.Data, a.M, s, u.Data, a.M, vt.Data, a.N, superb)
+}
+
+// MatInvC computes the inverse of a general complex matrix (square or not). It also computes the
+// pseudo-inverse if the matrix is not square.
+//   Input:
+//     a -- input matrix (M x N)
+//   Output:
+//     ai -- inverse matrix (N x M)
+//     det -- determinant of matrix (ONLY if calcDet == true and the matrix is square)
+//   NOTE: the dimension of the ai matrix must be N x M for the pseudo-inverse
+func MatInvC(ai, a *MatrixC, calcDet bool) (det complex128) {
+
+	// square inverse
+	if a.M == a.N {
+		copy(ai.Data, a.Data)
+		ipiv := make([]int32, utl.Imin(a.M, a.N))
+		oblas.Zgetrf(a.M, a.N, ai.Data, a.M, ipiv) // NOTE: ipiv are 1-based indices
+		if calcDet {
+			det = 1.0
+			for i := 0; i < a.M; i++ {
+				if ipiv[i]-1 == int32(i) { // NOTE: ipiv are 1-based indices
+					det = +det * ai.Get(i, i)
+				} else {
+					det = -det * ai.Get(i, i)
+				}
+			}
+		}
+		oblas.Zgetri(a.N, ai.Data, a.M, ipiv)
+		return
+	}
+
+	// singular value decomposition
+	s := make([]float64, utl.Imin(a.M, a.N))
+	u := NewMatrixC(a.M, a.M)
+	vt := NewMatrixC(a.N, a.N)
+	MatSvdC(s, u, vt, a, true)
+
+	// pseudo inverse: V ⋅ S⁺ ⋅ Uᴴ
+	tolS := 1e-8 // TODO: improve this tolerance with a better estimate
+	for i := 0; i < a.N; i++ {
+		for j := 0; j < a.M; j++ {
+			ai.Set(i, j, 0)
+			for k := 0; k < len(s); k++ {
+				if s[k] > tolS {
+					ai.Add(i, j, cmplx.Conj(vt.Get(k, i))*cmplx.Conj(u.Get(j, k))/complex(s[k], 0))
+				}
+			}
+		}
+	}
+	return
+}
+
 // MatCondNum returns the condition number of a square matrix using the inverse of this matrix;
 // thus it is not as efficient as it could be, e.g. by using the SV decomposition.
 //  normtype -- Type of norm to use:
@@ -0,0 +1,2950 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !cgo purego
+
+package oblas
+
+import (
+	"math"
+	"math/big"
+)
+
+// This file contains pure-Go ports of the (unblocked) reference LAPACK routines for real
+// matrices. All matrices are stored in column-major order: A(i,j) = a[i+j*lda]; all indices
+// are 0-based, except the ones in ipiv and in the balancing permutation (as in Fortran).
+
+// machine constants (as in dlamch)
+const (
+	dlamchE = 0x1p-53   // relative machine precision (eps)
+	dlamchP = 0x1p-52   // eps * base
+	dlamchS = 0x1p-1022 // safe minimum such that 1/sfmin does not overflow
+)
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+func imin(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func imax(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// idamax finds the (0-based) index of the first element having maximum absolute value
+func idamax(n int, x []float64, incx int) (idx int) {
+	if n < 1 || incx <= 0 {
+		return -1
+	}
+	dmax := math.Abs(x[0])
+	for i, ix := 1, incx; i < n; i, ix = i+1, ix+incx {
+		if math.Abs(x[ix]) > dmax {
+			idx = i
+			dmax = math.Abs(x[ix])
+		}
+	}
+	return
+}
+
+// dnrm2 returns the euclidean norm of a vector.
+//
+//  NOTE: the sum of squares is accumulated in 64-bit mantissa (x87 extended) arithmetic using
+//        four partial sums, as in the x86-64 OpenBLAS kernel (nrm2.S). This reproduces the
+//        rounding of the cgo version, which matters when singular values/eigenvalues repeat.
+func dnrm2(n int, x []float64, incx int) float64 {
+	if n < 1 || incx < 1 {
+		return 0
+	}
+	res := 0.0
+	for i, ix := 0, 0; i < n; i, ix = i+1, ix+incx {
+		if math.IsNaN(x[ix]) || math.IsInf(x[ix], 0) {
+			res += math.Abs(x[ix])
+		}
+	}
+	if res != 0 {
+		return res // NaN or Inf
+	}
+	var acc [4]big.Float
+	var v, p big.Float
+	v.SetPrec(64)
+	p.SetPrec(64)
+	for k := range acc {
+		acc[k].SetPrec(64)
+	}
+	n8 := n / 8 * 8
+	for i, ix := 0, 0; i < n; i, ix = i+1, ix+incx {
+		v.SetFloat64(x[ix])
+		p.Mul(&v, &v)
+		k := 0
+		if i < n8 {
+			k = i % 4
+		}
+		acc[k].Add(&acc[k], &p)
+	}
+	acc[2].Add(&acc[2], &acc[0])
+	acc[3].Add(&acc[3], &acc[1])
+	acc[3].Add(&acc[3], &acc[2])
+	acc[3].Sqrt(&acc[3])
+	res, _ = acc[3].Float64()
+	return res
+}
+
+// dlapy2 returns sqrt(x²+y²), taking care not to cause unnecessary overflow
+func dlapy2(x, y float64) float64 {
+	xabs, yabs := math.Abs(x), math.Abs(y)
+	w, z := math.Max(xabs, yabs), math.Min(xabs, yabs)
+	if z == 0 {
+		return w
+	}
+	return w * math.Sqrt(1+(z/w)*(z/w))
+}
+
+// drot applies a plane rotation to the vectors x and y
+func drot(n int, x []float64, incx int, y []float64, incy int, c, s float64) {
+	for i, ix, iy := 0, 0, 0; i < n; i, ix, iy = i+1, ix+incx, iy+incy {
+		temp := c*x[ix] + s*y[iy]
+		y[iy] = c*y[iy] - s*x[ix]
+		x[ix] = temp
+	}
+}
+
+// dswap interchanges two vectors
+func dswap(n int, x []float64, incx int, y []float64, incy int) {
+	for i, ix, iy := 0, 0, 0; i < n; i, ix, iy = i+1, ix+incx, iy+incy {
+		x[ix], y[iy] = y[iy], x[ix]
+	}
+}
+
+// dlange returns the largest absolute value of the elements of an m-by-n matrix
+func dlange(m, n int, a []float64, lda int) (res float64) {
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			v := math.Abs(a[i+j*lda])
+			if v > res || math.IsNaN(v) {
+				res = v
+			}
+		}
+	}
+	return
+}
+
+// dlascl multiplies the m-by-n matrix A by cto/cfrom without over/underflow
+func dlascl(cfrom, cto float64, m, n int, a []float64, lda int) {
+	smlnum := dlamchS
+	bignum := 1 / smlnum
+	cfromc, ctoc := cfrom, cto
+	for done := false; !done; {
+		var mul float64
+		cfrom1 := cfromc * smlnum
+		if cfrom1 == cfromc { // cfromc is inf
+			mul = ctoc / cfromc
+			done = true
+		} else {
+			cto1 := ctoc / bignum
+			if cto1 == ctoc { // ctoc is either 0 or inf
+				mul = ctoc
+				done = true
+				cfromc = 1
+			} else if math.Abs(cfrom1) > math.Abs(ctoc) && ctoc != 0 {
+				mul = smlnum
+				cfromc = cfrom1
+			} else if math.Abs(cto1) > math.Abs(cfromc) {
+				mul = bignum
+				ctoc = cto1
+			} else {
+				mul = ctoc / cfromc
+				done = true
+			}
+		}
+		for j := 0; j < n; j++ {
+			for i := 0; i < m; i++ {
+				a[i+j*lda] *= mul
+			}
+		}
+	}
+}
+
+// dlacpy copies all (uplo=0), the upper (uplo='U') or the lower (uplo='L') part of A into B
+func dlacpy(uplo byte, m, n int, a []float64, lda int, b []float64, ldb int) {
+	for j := 0; j < n; j++ {
+		i0, i1 := 0, m
+		switch uplo {
+		case 'U':
+			i1 = imin(j+1, m)
+		case 'L':
+			i0 = imin(j, m)
+		}
+		for i := i0; i < i1; i++ {
+			b[i+j*ldb] = a[i+j*lda]
+		}
+	}
+}
+
+// LU factorisation ////////////////////////////////////////////////////////////////////////////////
+
+// dgetf2 computes the LU factorisation of an m-by-n matrix using partial pivoting
+func dgetf2(m, n int, a []float64, lda int, ipiv []int32) (info int) {
+	mn := imin(m, n)
+	for j := 0; j < mn; j++ {
+		jp := j + idamax(m-j, a[j+j*lda:], 1)
+		ipiv[j] = int32(jp + 1)
+		if a[jp+j*lda] != 0 {
+			if jp != j {
+				dswap(n, a[j:], lda, a[jp:], lda)
+			}
+			if j < m-1 {
+				if math.Abs(a[j+j*lda]) >= dlamchS {
+					Dscal(m-j-1, 1/a[j+j*lda], a[j+1+j*lda:], 1)
+				} else {
+					for i := 0; i < m-j-1; i++ {
+						a[j+1+i+j*lda] /= a[j+j*lda]
+					}
+				}
+			}
+		} else if info == 0 {
+			info = j + 1
+		}
+		if j < mn-1 {
+			Dger(m-j-1, n-j-1, -1, a[j+1+j*lda:], 1, a[j+(j+1)*lda:], lda, a[j+1+(j+1)*lda:], lda)
+		}
+	}
+	return
+}
+
+// dgetrs solves A⋅X = B using the LU factorisation computed by dgetf2
+func dgetrs(n, nrhs int, a []float64, lda int, ipiv []int32, b []float64, ldb int) {
+	for k := 0; k < nrhs; k++ {
+		x := b[k*ldb:]
+		for i := 0; i < n; i++ {
+			if ip := int(ipiv[i]) - 1; ip != i {
+				x[i], x[ip] = x[ip], x[i]
+			}
+		}
+		for j := 0; j < n; j++ {
+			if x[j] != 0 {
+				for i := j + 1; i < n; i++ {
+					x[i] -= x[j] * a[i+j*lda]
+				}
+			}
+		}
+		for j := n - 1; j >= 0; j-- {
+			if x[j] != 0 {
+				x[j] /= a[j+j*lda]
+				for i := 0; i < j; i++ {
+					x[i] -= x[j] * a[i+j*lda]
+				}
+			}
+		}
+	}
+}
+
+// dgetri computes the inverse of a matrix using the LU factorisation computed by dgetf2
+func dgetri(n int, a []float64, lda int, ipiv []int32) (info int) {
+
+	// check for singularity
+	for j := 0; j < n; j++ {
+		if a[j+j*lda] == 0 {
+			return j + 1
+		}
+	}
+
+	// compute inv(U) (dtrti2)
+	for j := 0; j < n; j++ {
+		a[j+j*lda] = 1 / a[j+j*lda]
+		ajj := -a[j+j*lda]
+		x := a[j*lda:]
+		for k := 0; k < j; k++ {
+			if x[k] != 0 {
+				temp := x[k]
+				for i := 0; i < k; i++ {
+					x[i] += temp * a[i+k*lda]
+				}
+				x[k] *= a[k+k*lda]
+			}
+		}
+		Dscal(j, ajj, x, 1)
+	}
+
+	// solve inv(A)⋅L = inv(U) for inv(A)
+	work := make([]float64, n)
+	for j := n - 1; j >= 0; j-- {
+		for i := j + 1; i < n; i++ {
+			work[i] = a[i+j*lda]
+			a[i+j*lda] = 0
+		}
+		if j < n-1 {
+			Dgemv(false, n, n-j-1, -1, a[(j+1)*lda:], lda, work[j+1:], 1, 1, a[j*lda:], 1)
+		}
+	}
+
+	// apply column interchanges
+	for j := n - 2; j >= 0; j-- {
+		if jp := int(ipiv[j]) - 1; jp != j {
+			dswap(n, a[j*lda:], 1, a[jp*lda:], 1)
+		}
+	}
+	return
+}
+
+// Cholesky factorisation //////////////////////////////////////////////////////////////////////////
+
+// dpotf2 computes the Cholesky factorisation of a symmetric positive definite matrix
+func dpotf2(up bool, n int, a []float64, lda int) (info int) {
+	for j := 0; j < n; j++ {
+		var ajj float64
+		if up {
+			ajj = a[j+j*lda] - Ddot(j, a[j*lda:], 1, a[j*lda:], 1)
+		} else {
+			ajj = a[j+j*lda] - Ddot(j, a[j:], lda, a[j:], lda)
+		}
+		if ajj <= 0 || math.IsNaN(ajj) {
+			a[j+j*lda] = ajj
+			return j + 1
+		}
+		ajj = math.Sqrt(ajj)
+		a[j+j*lda] = ajj
+		if j < n-1 {
+			if up {
+				Dgemv(true, j, n-j-1, -1, a[(j+1)*lda:], lda, a[j*lda:], 1, 1, a[j+(j+1)*lda:], lda)
+				Dscal(n-j-1, 1/ajj, a[j+(j+1)*lda:], lda)
+			} else {
+				Dgemv(false, n-j-1, j, -1, a[j+1:], lda, a[j:], lda, 1, a[j+1+j*lda:], 1)
+				Dscal(n-j-1, 1/ajj, a[j+1+j*lda:], 1)
+			}
+		}
+	}
+	return
+}
+
+// Householder reflectors //////////////////////////////////////////////////////////////////////////
+
+// dlarfg generates an elementary reflector H such that H⋅(alpha,x) = (beta,0).
+// x is overwritten with v (v[0] = 1 is not stored)
+func dlarfg(n int, alpha float64, x []float64, incx int) (beta, tau float64) {
+	if n <= 1 {
+		return alpha, 0
+	}
+	xnorm := dnrm2(n-1, x, incx)
+	if xnorm == 0 {
+		return alpha, 0
+	}
+	beta = -math.Copysign(dlapy2(alpha, xnorm), alpha)
+	safmin := dlamchS / dlamchE
+	knt := 0
+	if math.Abs(beta) < safmin {
+		rsafmn := 1 / safmin
+		for {
+			knt++
+			Dscal(n-1, rsafmn, x, incx)
+			beta *= rsafmn
+			alpha *= rsafmn
+			if math.Abs(beta) >= safmin || knt >= 20 {
+				break
+			}
+		}
+		xnorm = dnrm2(n-1, x, incx)
+		beta = -math.Copysign(dlapy2(alpha, xnorm), alpha)
+	}
+	tau = (beta - alpha) / beta
+	Dscal(n-1, 1/(alpha-beta), x, incx)
+	for j := 0; j < knt; j++ {
+		beta *= safmin
+	}
+	return
+}
+
+// dlarf applies H = I - tau⋅v⋅vᵀ to C from the left (H⋅C) or from the right (C⋅H)
+func dlarf(left bool, m, n int, v []float64, incv int, tau float64, c []float64, ldc int, work []float64) {
+	if tau == 0 {
+		return
+	}
+	if left {
+		Dgemv(true, m, n, 1, c, ldc, v, incv, 0, work, 1)
+		Dger(m, n, -tau, v, incv, work, 1, c, ldc)
+		return
+	}
+	Dgemv(false, m, n, 1, c, ldc, v, incv, 0, work, 1)
+	Dger(m, n, -tau, work, 1, v, incv, c, ldc)
+}
+
+// dgeqr2 computes the QR factorisation of an m-by-n matrix
+func dgeqr2(m, n int, a []float64, lda int, tau, work []float64) {
+	k := imin(m, n)
+	for i := 0; i < k; i++ {
+		beta, t := dlarfg(m-i, a[i+i*lda], a[imin(i+1, m-1)+i*lda:], 1)
+		tau[i] = t
+		if i < n-1 {
+			a[i+i*lda] = 1
+			dlarf(true, m-i, n-i-1, a[i+i*lda:], 1, t, a[i+(i+1)*lda:], lda, work)
+		}
+		a[i+i*lda] = beta
+	}
+}
+
+// dgelq2 computes the LQ factorisation of an m-by-n matrix
+func dgelq2(m, n int, a []float64, lda int, tau, work []float64) {
+	k := imin(m, n)
+	for i := 0; i < k; i++ {
+		beta, t := dlarfg(n-i, a[i+i*lda], a[i+imin(i+1, n-1)*lda:], lda)
+		tau[i] = t
+		if i < m-1 {
+			a[i+i*lda] = 1
+			dlarf(false, m-i-1, n-i, a[i+i*lda:], lda, t, a[i+1+i*lda:], lda, work)
+		}
+		a[i+i*lda] = beta
+	}
+}
+
+// dorg2r generates the m-by-n matrix Q with orthonormal columns defined by k reflectors (from dgeqr2)
+func dorg2r(m, n, k int, a []float64, lda int, tau, work []float64) {
+	if n <= 0 {
+		return
+	}
+	for j := k; j < n; j++ {
+		for l := 0; l < m; l++ {
+			a[l+j*lda] = 0
+		}
+		a[j+j*lda] = 1
+	}
+	for i := k - 1; i >= 0; i-- {
+		if i < n-1 {
+			a[i+i*lda] = 1
+			dlarf(true, m-i, n-i-1, a[i+i*lda:], 1, tau[i], a[i+(i+1)*lda:], lda, work)
+		}
+		if i < m-1 {
+			Dscal(m-i-1, -tau[i], a[i+1+i*lda:], 1)
+		}
+		a[i+i*lda] = 1 - tau[i]
+		for l := 0; l < i; l++ {
+			a[l+i*lda] = 0
+		}
+	}
+}
+
+// dorm2r multiplies C by Q or Qᵀ (from the left or from the right), where Q is defined by k
+// reflectors (from dgeqr2 or dgeqp3)
+func dorm2r(left, trans bool, m, n, k int, a []float64, lda int, tau, c []float64, ldc int, work []float64) {
+	if m <= 0 || n <= 0 || k <= 0 {
+		return
+	}
+	forward := (left && trans) || (!left && !trans)
+	for l := 0; l < k; l++ {
+		i := l
+		if !forward {
+			i = k - 1 - l
+		}
+		mi, ni, ic, jc := m, n, 0, 0
+		if left {
+			mi, ic = m-i, i
+		} else {
+			ni, jc = n-i, i
+		}
+		aii := a[i+i*lda]
+		a[i+i*lda] = 1
+		dlarf(left, mi, ni, a[i+i*lda:], 1, tau[i], c[ic+jc*ldc:], ldc, work)
+		a[i+i*lda] = aii
+	}
+}
+
+// dgeqp3 computes the QR factorisation with column pivoting of an m-by-n matrix. The leading
+// (fixed) columns are factorised first; the remaining ones are factorised by dlaqp2
+func dgeqp3(m, n int, a []float64, lda int, jpvt []int32, tau []float64) {
+
+	// move initial columns up front
+	nfxd := 0
+	for j := 0; j < n; j++ {
+		if jpvt[j] != 0 {
+			if j != nfxd {
+				dswap(m, a[j*lda:], 1, a[nfxd*lda:], 1)
+				jpvt[j] = jpvt[nfxd]
+				jpvt[nfxd] = int32(j + 1)
+			} else {
+				jpvt[j] = int32(j + 1)
+			}
+			nfxd++
+		} else {
+			jpvt[j] = int32(j + 1)
+		}
+	}
+
+	// factorise fixed columns and update the remaining ones
+	na := imin(m, nfxd)
+	if na > 0 {
+		dgeqr2(m, na, a, lda, tau, make([]float64, na))
+		if na < n {
+			dorm2r(true, true, m, n-na, na, a, lda, tau, a[na*lda:], lda, make([]float64, n-na))
+		}
+	}
+
+	// factorise free columns
+	if na < imin(m, n) {
+		sn := n - nfxd
+		vn1 := make([]float64, sn)
+		vn2 := make([]float64, sn)
+		for j := 0; j < sn; j++ {
+			vn1[j] = dnrm2(m-nfxd, a[nfxd+(nfxd+j)*lda:], 1)
+			vn2[j] = vn1[j]
+		}
+		dlaqp2(m, sn, nfxd, a[nfxd*lda:], lda, jpvt[nfxd:], tau[nfxd:], vn1, vn2, make([]float64, sn))
+	}
+}
+
+// dlaqp2 computes the QR factorisation with column pivoting of the block A(offset:m,0:n); the block
+// A(0:offset,0:n) is accordingly pivoted, but not factorised. vn1 and vn2 hold the partial and
+// exact column norms (the partial norms are downdated as in LAPACK Working Note 176)
+func dlaqp2(m, n, offset int, a []float64, lda int, jpvt []int32, tau, vn1, vn2, work []float64) {
+	mn := imin(m-offset, n)
+	tol3z := math.Sqrt(dlamchE)
+	for i := 0; i < mn; i++ {
+		offpi := offset + i
+
+		// determine i-th pivot column and swap if necessary
+		pvt := i + idamax(n-i, vn1[i:], 1)
+		if pvt != i {
+			dswap(m, a[pvt*lda:], 1, a[i*lda:], 1)
+			jpvt[pvt], jpvt[i] = jpvt[i], jpvt[pvt]
+			vn1[pvt] = vn1[i]
+			vn2[pvt] = vn2[i]
+		}
+
+		// generate elementary reflector H(i)
+		var beta float64
+		if offpi < m-1 {
+			beta, tau[i] = dlarfg(m-offpi, a[offpi+i*lda], a[offpi+1+i*lda:], 1)
+		} else {
+			beta, tau[i] = dlarfg(1, a[m-1+i*lda], a[m-1+i*lda:], 1)
+		}
+		a[offpi+i*lda] = beta
+
+		// apply H(i)ᵀ to A(offset+i:m,i+1:n) from the left
+		if i < n-1 {
+			aii := a[offpi+i*lda]
+			a[offpi+i*lda] = 1
+			dlarf(true, m-offpi, n-i-1, a[offpi+i*lda:], 1, tau[i], a[offpi+(i+1)*lda:], lda, work)
+			a[offpi+i*lda] = aii
+		}
+
+		// update partial column norms
+		for j := i + 1; j < n; j++ {
+			if vn1[j] != 0 {
+				temp := math.Abs(a[offpi+j*lda]) / vn1[j]
+				temp = math.Max(1-temp*temp, 0)
+				temp2 := temp * (vn1[j] / vn2[j]) * (vn1[j] / vn2[j])
+				if temp2 <= tol3z {
+					if offpi < m-1 {
+						vn1[j] = dnrm2(m-offpi-1, a[offpi+1+j*lda:], 1)
+						vn2[j] = vn1[j]
+					} else {
+						vn1[j] = 0
+						vn2[j] = 0
+					}
+				} else {
+					vn1[j] *= math.Sqrt(temp)
+				}
+			}
+		}
+	}
+}
+
+// dorgl2 generates the m-by-n matrix Q with orthonormal rows defined by k reflectors (from dgelq2)
+func dorgl2(m, n, k int, a []float64, lda int, tau, work []float64) {
+	if m <= 0 {
+		return
+	}
+	if k < m {
+		for j := 0; j < n; j++ {
+			for l := k; l < m; l++ {
+				a[l+j*lda] = 0
+			}
+			if j >= k && j < m {
+				a[j+j*lda] = 1
+			}
+		}
+	}
+	for i := k - 1; i >= 0; i-- {
+		if i < n-1 {
+			if i < m-1 {
+				a[i+i*lda] = 1
+				dlarf(false, m-i-1, n-i, a[i+i*lda:], lda, tau[i], a[i+1+i*lda:], lda, work)
+			}
+			Dscal(n-i-1, -tau[i], a[i+(i+1)*lda:], lda)
+		}
+		a[i+i*lda] = 1 - tau[i]
+		for l := 0; l < i; l++ {
+			a[i+l*lda] = 0
+		}
+	}
+}
+
+// bidiagonalisation and SVD ///////////////////////////////////////////////////////////////////////
+
+// dgebd2 reduces an m-by-n matrix to upper (m ≥ n) or lower (m < n) bidiagonal form
+func dgebd2(m, n int, a []float64, lda int, d, e, tauq, taup, work []float64) {
+	if m >= n {
+		for i := 0; i < n; i++ {
+			beta, tq := dlarfg(m-i, a[i+i*lda], a[imin(i+1, m-1)+i*lda:], 1)
+			d[i], tauq[i] = beta, tq
+			if i < n-1 {
+				a[i+i*lda] = 1
+				dlarf(true, m-i, n-i-1, a[i+i*lda:], 1, tq, a[i+(i+1)*lda:], lda, work)
+			}
+			a[i+i*lda] = d[i]
+			if i < n-1 {
+				beta, tp := dlarfg(n-i-1, a[i+(i+1)*lda], a[i+imin(i+2, n-1)*lda:], lda)
+				e[i], taup[i] = beta, tp
+				a[i+(i+1)*lda] = 1
+				dlarf(false, m-i-1, n-i-1, a[i+(i+1)*lda:], lda, tp, a[i+1+(i+1)*lda:], lda, work)
+				a[i+(i+1)*lda] = e[i]
+			} else {
+				taup[i] = 0
+			}
+		}
+		return
+	}
+	for i := 0; i < m; i++ {
+		beta, tp := dlarfg(n-i, a[i+i*lda], a[i+imin(i+1, n-1)*lda:], lda)
+		d[i], taup[i] = beta, tp
+		if i < m-1 {
+			a[i+i*lda] = 1
+			dlarf(false, m-i-1, n-i, a[i+i*lda:], lda, tp, a[i+1+i*lda:], lda, work)
+		}
+		a[i+i*lda] = d[i]
+		if i < m-1 {
+			beta, tq := dlarfg(m-i-1, a[i+1+i*lda], a[imin(i+2, m-1)+i*lda:], 1)
+			e[i], tauq[i] = beta, tq
+			a[i+1+i*lda] = 1
+			dlarf(true, m-i-1, n-i-1, a[i+1+i*lda:], 1, tq, a[i+1+(i+1)*lda:], lda, work)
+			a[i+1+i*lda] = e[i]
+		} else {
+			tauq[i] = 0
+		}
+	}
+}
+
+// dorgbr generates Q (vect='Q') or Pᵀ (vect='P') as determined by dgebd2
+func dorgbr(vect byte, m, n, k int, a []float64, lda int, tau, work []float64) {
+	if m == 0 || n == 0 {
+		return
+	}
+	if vect == 'Q' {
+		if m >= k {
+			dorg2r(m, n, k, a, lda, tau, work)
+			return
+		}
+		for j := m - 1; j >= 1; j-- {
+			a[j*lda] = 0
+			for i := j + 1; i < m; i++ {
+				a[i+j*lda] = a[i+(j-1)*lda]
+			}
+		}
+		a[0] = 1
+		for i := 1; i < m; i++ {
+			a[i] = 0
+		}
+		if m > 1 {
+			dorg2r(m-1, m-1, m-1, a[1+lda:], lda, tau, work)
+		}
+		return
+	}
+	if k < n {
+		dorgl2(m, n, k, a, lda, tau, work)
+		return
+	}
+	a[0] = 1
+	for i := 1; i < n; i++ {
+		a[i] = 0
+	}
+	for j := 1; j < n; j++ {
+		for i := j - 1; i >= 1; i-- {
+			a[i+j*lda] = a[i-1+j*lda]
+		}
+		a[j*lda] = 0
+	}
+	if n > 1 {
+		dorgl2(n-1, n-1, n-1, a[1+lda:], lda, tau, work)
+	}
+}
+
+// dgesvd computes the singular value decomposition of a real m-by-n matrix
+func dgesvd(jobu, jobvt byte, m, n int, a []float64, lda int, s, u []float64, ldu int, vt []float64, ldvt int, superb []float64) (info int) {
+
+	// quick return
+	if m == 0 || n == 0 {
+		return
+	}
+	wntua, wntus, wntuo := jobu == 'A', jobu == 'S', jobu == 'O'
+	wntva, wntvs, wntvo := jobvt == 'A', jobvt == 'S', jobvt == 'O'
+	wntuas, wntvas := wntua || wntus, wntva || wntvs
+	minmn := imin(m, n)
+	mnthr := int(float64(minmn) * 1.6)
+
+	// scale A if max element outside range [smlnum,bignum]
+	smlnum := math.Sqrt(dlamchS) / dlamchP
+	bignum := 1 / smlnum
+	anrm := dlange(m, n, a, lda)
+	iscl := false
+	if anrm > 0 && anrm < smlnum {
+		iscl = true
+		dlascl(anrm, smlnum, m, n, a, lda)
+	} else if anrm > bignum {
+		iscl = true
+		dlascl(anrm, bignum, m, n, a, lda)
+	}
+
+	// workspace
+	e := make([]float64, minmn)
+	tauq := make([]float64, minmn)
+	taup := make([]float64, minmn)
+	work := make([]float64, imax(m, n))
+
+	// singular vectors
+	var vecs *bdsqrReal
+	lower := m < n
+	if m >= n {
+
+		// path with QR decomposition first
+		if m >= mnthr && wntuas && wntvas {
+			ncu := n
+			if wntua {
+				ncu = m
+			}
+			tau := make([]float64, n)
+			dgeqr2(m, n, a, lda, tau, work)
+			dlacpy('L', m, n, a, lda, u, ldu)
+			dorg2r(m, ncu, n, u, ldu, tau, work)
+			r := make([]float64, n*n)
+			dlacpy('U', n, n, a, lda, r, n)
+			dgebd2(n, n, r, n, s, e, tauq, taup, work)
+			dlacpy('U', n, n, r, n, vt, ldvt)
+			dorgbr('Q', n, n, n, r, n, tauq, work)
+			dorgbr('P', n, n, n, vt, ldvt, taup, work)
+			vecs = &bdsqrReal{n, n, vt, ldvt, r, n}
+			info = bdsqr(false, n, s, e, vecs)
+			Dgemm(false, false, m, n, n, 1, u, ldu, r, n, 0, a, lda)
+			dlacpy(0, m, n, a, lda, u, ldu)
+
+		} else {
+
+			// reduce to bidiagonal form without QR decomposition
+			dgebd2(m, n, a, lda, s, e, tauq, taup, work)
+			vecs = &bdsqrReal{vt: vt, ldvt: ldvt, u: u, ldu: ldu}
+			if wntuas {
+				ncu := n
+				if wntua {
+					ncu = m
+				}
+				dlacpy('L', m, n, a, lda, u, ldu)
+				dorgbr('Q', m, ncu, n, u, ldu, tauq, work)
+			}
+			if wntvas {
+				dlacpy('U', n, n, a, lda, vt, ldvt)
+				dorgbr('P', n, n, n, vt, ldvt, taup, work)
+			}
+			if wntuo {
+				dorgbr('Q', m, n, n, a, lda, tauq, work)
+				vecs.u, vecs.ldu = a, lda
+			}
+			if wntvo {
+				dorgbr('P', n, n, n, a, lda, taup, work)
+				vecs.vt, vecs.ldvt = a, lda
+			}
+			if wntuas || wntuo {
+				vecs.nru = m
+			}
+			if wntvas || wntvo {
+				vecs.ncvt = n
+			}
+			info = bdsqr(false, n, s, e, vecs)
+		}
+
+	} else {
+
+		// path with LQ decomposition first
+		if n >= mnthr && wntvas && wntuas {
+			nrvt := m
+			if wntva {
+				nrvt = n
+			}
+			tau := make([]float64, m)
+			dgelq2(m, n, a, lda, tau, work)
+			dlacpy('U', m, n, a, lda, vt, ldvt)
+			dorgl2(nrvt, n, m, vt, ldvt, tau, work)
+			l := make([]float64, m*m)
+			dlacpy('L', m, m, a, lda, l, m)
+			dgebd2(m, m, l, m, s, e, tauq, taup, work)
+			dlacpy('L', m, m, l, m, u, ldu)
+			dorgbr('P', m, m, m, l, m, taup, work)
+			dorgbr('Q', m, m, m, u, ldu, tauq, work)
+			vecs = &bdsqrReal{m, m, l, m, u, ldu}
+			info = bdsqr(false, m, s, e, vecs)
+			Dgemm(false, false, m, n, m, 1, l, m, vt, ldvt, 0, a, lda)
+			dlacpy(0, m, n, a, lda, vt, ldvt)
+
+		} else {
+
+			// reduce to bidiagonal form without LQ decomposition
+			dgebd2(m, n, a, lda, s, e, tauq, taup, work)
+			vecs = &bdsqrReal{vt: vt, ldvt: ldvt, u: u, ldu: ldu}
+			if wntuas {
+				dlacpy('L', m, m, a, lda, u, ldu)
+				dorgbr('Q', m, m, n, u, ldu, tauq, work)
+			}
+			if wntvas {
+				nrvt := m
+				if wntva {
+					nrvt = n
+				}
+				dlacpy('U', m, n, a, lda, vt, ldvt)
+				dorgbr('P', nrvt, n, m, vt, ldvt, taup, work)
+			}
+			if wntuo {
+				dorgbr('Q', m, m, n, a, lda, tauq, work)
+				vecs.u, vecs.ldu = a, lda
+			}
+			if wntvo {
+				dorgbr('P', m, n, m, a, lda, taup, work)
+				vecs.vt, vecs.ldvt = a, lda
+			}
+			if wntuas || wntuo {
+				vecs.nru = m
+			}
+			if wntvas || wntvo {
+				vecs.ncvt = n
+			}
+			info = bdsqr(lower, m, s, e, vecs)
+		}
+	}
+
+	// undo scaling if necessary
+	if iscl {
+		if anrm > bignum {
+			dlascl(bignum, anrm, minmn, 1, s, minmn)
+		}
+		if anrm < smlnum {
+			dlascl(smlnum, anrm, minmn, 1, s, minmn)
+		}
+	}
+	copy(superb, e[:minmn-1])
+	return
+}
+
+// bdsqrVectors holds the singular vectors updated by bdsqr
+type bdsqrVectors interface {
+	rotVt(i, j int, c, s float64) // rotate rows i and j of VT
+	rotU(i, j int, c, s float64)  // rotate columns i and j of U
+	negVt(i int)                  // negate row i of VT
+	swap(i, j int)                // swap rows i and j of VT and columns i and j of U
+}
+
+// bdsqrReal implements bdsqrVectors for real matrices
+type bdsqrReal struct {
+	ncvt, nru int       // number of columns in VT and number of rows in U
+	vt        []float64 // right singular vectors
+	ldvt      int       // leading dimension of vt
+	u         []float64 // left singular vectors
+	ldu       int       // leading dimension of u
+}
+
+func (o *bdsqrReal) rotVt(i, j int, c, s float64) {
+	if o.ncvt == 0 {
+		return
+	}
+	drot(o.ncvt, o.vt[i:], o.ldvt, o.vt[j:], o.ldvt, c, s)
+}
+
+func (o *bdsqrReal) rotU(i, j int, c, s float64) {
+	if o.nru == 0 {
+		return
+	}
+	drot(o.nru, o.u[i*o.ldu:], 1, o.u[j*o.ldu:], 1, c, s)
+}
+
+func (o *bdsqrReal) negVt(i int) {
+	if o.ncvt == 0 {
+		return
+	}
+	Dscal(o.ncvt, -1, o.vt[i:], o.ldvt)
+}
+
+func (o *bdsqrReal) swap(i, j int) {
+	if o.ncvt > 0 {
+		dswap(o.ncvt, o.vt[i:], o.ldvt, o.vt[j:], o.ldvt)
+	}
+	if o.nru > 0 {
+		dswap(o.nru, o.u[i*o.ldu:], 1, o.u[j*o.ldu:], 1)
+	}
+}
+
+// bdsqr computes the singular values and, optionally, the singular vectors of a real n-by-n
+// (upper or lower) bidiagonal matrix B with diagonal d and off-diagonal e, using the implicit
+// zero-shift QR algorithm (dbdsqr). The rotations are applied to VT and U via vecs.
+func bdsqr(lower bool, n int, d, e []float64, vecs bdsqrVectors) (info int) {
+	if n == 0 {
+		return
+	}
+	if n > 1 {
+
+		// constants
+		const maxitr = 6
+		eps := dlamchE
+		unfl := dlamchS
+
+		// if matrix lower bidiagonal, rotate to be upper bidiagonal
+		if lower {
+			for i := 0; i < n-1; i++ {
+				cs, sn, r := dlartg(d[i], e[i])
+				d[i] = r
+				e[i] = sn * d[i+1]
+				d[i+1] = cs * d[i+1]
+				vecs.rotU(i, i+1, cs, sn)
+			}
+		}
+
+		// tolerance for convergence
+		tolmul := math.Max(10, math.Min(100, math.Pow(eps, -0.125)))
+		tol := tolmul * eps
+
+		// compute approximate maximum, minimum singular values
+		smax := 0.0
+		for i := 0; i < n; i++ {
+			smax = math.Max(smax, math.Abs(d[i]))
+		}
+		for i := 0; i < n-1; i++ {
+			smax = math.Max(smax, math.Abs(e[i]))
+		}
+		sminoa := math.Abs(d[0])
+		if sminoa != 0 {
+			mu := sminoa
+			for i := 1; i < n; i++ {
+				mu = math.Abs(d[i]) * (mu / (mu + math.Abs(e[i-1])))
+				sminoa = math.Min(sminoa, mu)
+				if sminoa == 0 {
+					break
+				}
+			}
+		}
+		sminoa = sminoa / math.Sqrt(float64(n))
+		thresh := math.Max(tol*sminoa, float64(maxitr*n*n)*unfl)
+
+		// main iteration loop; m points to the last element of the unconverged part
+		maxit := maxitr * n * n
+		iter := 0
+		oldll, oldm := -1, -1
+		idir := 0
+		m := n - 1
+		for m > 0 {
+			if iter > maxit {
+				for i := 0; i < n-1; i++ {
+					if e[i] != 0 {
+						info++
+					}
+				}
+				return
+			}
+
+			// find diagonal block of matrix to work on
+			smax = math.Abs(d[m])
+			smin := smax
+			ll := -1
+			split := false
+			for lll := 1; lll <= m; lll++ {
+				ll = m - lll
+				abss := math.Abs(d[ll])
+				abse := math.Abs(e[ll])
+				if abse <= thresh {
+					split = true
+					break
+				}
+				smin = math.Min(smin, abss)
+				smax = math.Max(smax, math.Max(abss, abse))
+			}
+			if split {
+				e[ll] = 0
+				if ll == m-1 { // convergence of bottom singular value
+					m--
+					continue
+				}
+			} else {
+				ll = -1
+			}
+			ll++
+
+			// 2 by 2 block, handle separately
+			if ll == m-1 {
+				sigmn, sigmx, sinr, cosr, sinl, cosl := dlasv2(d[m-1], e[m-1], d[m])
+				d[m-1] = sigmx
+				e[m-1] = 0
+				d[m] = sigmn
+				vecs.rotVt(m-1, m, cosr, sinr)
+				vecs.rotU(m-1, m, cosl, sinl)
+				m -= 2
+				continue
+			}
+
+			// if working on new submatrix, choose shift direction
+			if ll > oldm || m < oldll {
+				if math.Abs(d[ll]) >= math.Abs(d[m]) {
+					idir = 1 // chase bulge from top (big end) to bottom (small end)
+				} else {
+					idir = 2 // chase bulge from bottom (big end) to top (small end)
+				}
+			}
+
+			// apply convergence tests
+			var sminl float64
+			converged := false
+			if idir == 1 {
+				if math.Abs(e[m-1]) <= math.Abs(tol)*math.Abs(d[m]) {
+					e[m-1] = 0
+					continue
+				}
+				mu := math.Abs(d[ll])
+				sminl = mu
+				for lll := ll; lll < m; lll++ {
+					if math.Abs(e[lll]) <= tol*mu {
+						e[lll] = 0
+						converged = true
+						break
+					}
+					mu = math.Abs(d[lll+1]) * (mu / (mu + math.Abs(e[lll])))
+					sminl = math.Min(sminl, mu)
+				}
+			} else {
+				if math.Abs(e[ll]) <= math.Abs(tol)*math.Abs(d[ll]) {
+					e[ll] = 0
+					continue
+				}
+				mu := math.Abs(d[m])
+				sminl = mu
+				for lll := m - 1; lll >= ll; lll-- {
+					if math.Abs(e[lll]) <= tol*mu {
+						e[lll] = 0
+						converged = true
+						break
+					}
+					mu = math.Abs(d[lll]) * (mu / (mu + math.Abs(e[lll])))
+					sminl = math.Min(sminl, mu)
+				}
+			}
+			if converged {
+				continue
+			}
+			oldll, oldm = ll, m
+
+			// compute shift. First, test if shifting would ruin relative accuracy
+			var shift float64
+			if float64(n)*tol*(sminl/smax) > math.Max(eps, 0.01*tol) {
+				var sll float64
+				if idir == 1 {
+					sll = math.Abs(d[ll])
+					shift, _ = dlas2(d[m-1], e[m-1], d[m])
+				} else {
+					sll = math.Abs(d[m])
+					shift, _ = dlas2(d[ll], e[ll], d[ll+1])
+				}
+				if sll > 0 && (shift/sll)*(shift/sll) < eps {
+					shift = 0
+				}
+			}
+			iter += m - ll
+
+			// simplified QR iteration (zero shift)
+			if shift == 0 {
+				if idir == 1 {
+					cs, oldcs := 1.0, 1.0
+					var sn, oldsn, r float64
+					for i := ll; i < m; i++ {
+						cs, sn, r = dlartg(d[i]*cs, e[i])
+						if i > ll {
+							e[i-1] = oldsn * r
+						}
+						oldcs, oldsn, d[i] = dlartg(oldcs*r, d[i+1]*sn)
+						vecs.rotVt(i, i+1, cs, sn)
+						vecs.rotU(i, i+1, oldcs, oldsn)
+					}
+					h := d[m] * cs
+					d[m] = h * oldcs
+					e[m-1] = h * oldsn
+					if math.Abs(e[m-1]) <= thresh {
+						e[m-1] = 0
+					}
+				} else {
+					cs, oldcs := 1.0, 1.0
+					var sn, oldsn, r float64
+					for i := m; i >= ll+1; i-- {
+						cs, sn, r = dlartg(d[i]*cs, e[i-1])
+						if i < m {
+							e[i] = oldsn * r
+						}
+						oldcs, oldsn, d[i] = dlartg(oldcs*r, d[i-1]*sn)
+						vecs.rotVt(i-1, i, oldcs, -oldsn)
+						vecs.rotU(i-1, i, cs, -sn)
+					}
+					h := d[ll] * cs
+					d[ll] = h * oldcs
+					e[ll] = h * oldsn
+					if math.Abs(e[ll]) <= thresh {
+						e[ll] = 0
+					}
+				}
+				continue
+			}
+
+			// use nonzero shift
+			if idir == 1 {
+				f := (math.Abs(d[ll]) - shift) * (math.Copysign(1, d[ll]) + shift/d[ll])
+				g := e[ll]
+				for i := ll; i < m; i++ {
+					cosr, sinr, r := dlartg(f, g)
+					if i > ll {
+						e[i-1] = r
+					}
+					f = cosr*d[i] + sinr*e[i]
+					e[i] = cosr*e[i] - sinr*d[i]
+					g = sinr * d[i+1]
+					d[i+1] = cosr * d[i+1]
+					cosl, sinl, r := dlartg(f, g)
+					d[i] = r
+					f = cosl*e[i] + sinl*d[i+1]
+					d[i+1] = cosl*d[i+1] - sinl*e[i]
+					if i < m-1 {
+						g = sinl * e[i+1]
+						e[i+1] = cosl * e[i+1]
+					}
+					vecs.rotVt(i, i+1, cosr, sinr)
+					vecs.rotU(i, i+1, cosl, sinl)
+				}
+				e[m-1] = f
+				if math.Abs(e[m-1]) <= thresh {
+					e[m-1] = 0
+				}
+			} else {
+				f := (math.Abs(d[m]) - shift) * (math.Copysign(1, d[m]) + shift/d[m])
+				g := e[m-1]
+				for i := m; i >= ll+1; i-- {
+					cosr, sinr, r := dlartg(f, g)
+					if i < m {
+						e[i] = r
+					}
+					f = cosr*d[i] + sinr*e[i-1]
+					e[i-1] = cosr*e[i-1] - sinr*d[i]
+					g = sinr * d[i-1]
+					d[i-1] = cosr * d[i-1]
+					cosl, sinl, r := dlartg(f, g)
+					d[i] = r
+					f = cosl*e[i-1] + sinl*d[i-1]
+					d[i-1] = cosl*d[i-1] - sinl*e[i-1]
+					if i > ll+1 {
+						g = sinl * e[i-2]
+						e[i-2] = cosl * e[i-2]
+					}
+					vecs.rotVt(i-1, i, cosl, -sinl)
+					vecs.rotU(i-1, i, cosr, -sinr)
+				}
+				e[ll] = f
+				if math.Abs(e[ll]) <= thresh {
+					e[ll] = 0
+				}
+			}
+		}
+	}
+
+	// all singular values converged, so make them positive
+	for i := 0; i < n; i++ {
+		if d[i] < 0 {
+			d[i] = -d[i]
+			vecs.negVt(i)
+		}
+	}
+
+	// sort the singular values into decreasing order
+	for i := 0; i < n-1; i++ {
+		isub := 0
+		smin := d[0]
+		for j := 1; j < n-i; j++ {
+			if d[j] <= smin {
+				isub = j
+				smin = d[j]
+			}
+		}
+		if isub != n-1-i {
+			d[isub] = d[n-1-i]
+			d[n-1-i] = smin
+			vecs.swap(isub, n-1-i)
+		}
+	}
+	return
+}
+
+// dlartg generates a plane rotation so that [cs sn; -sn cs]⋅[f; g] = [r; 0]
+func dlartg(f, g float64) (cs, sn, r float64) {
+	const safmn2 = 0x1p-484
+	const safmx2 = 1 / safmn2
+	if g == 0 {
+		return 1, 0, f
+	}
+	if f == 0 {
+		return 0, 1, g
+	}
+	f1, g1 := f, g
+	scale := math.Max(math.Abs(f1), math.Abs(g1))
+	count := 0
+	if scale >= safmx2 {
+		for scale >= safmx2 && count < 20 {
+			count++
+			f1 *= safmn2
+			g1 *= safmn2
+			scale = math.Max(math.Abs(f1), math.Abs(g1))
+		}
+		r = math.Sqrt(f1*f1 + g1*g1)
+		cs, sn = f1/r, g1/r
+		for i := 0; i < count; i++ {
+			r *= safmx2
+		}
+	} else if scale <= safmn2 {
+		for scale <= safmn2 && count < 20 {
+			count++
+			f1 *= safmx2
+			g1 *= safmx2
+			scale = math.Max(math.Abs(f1), math.Abs(g1))
+		}
+		r = math.Sqrt(f1*f1 + g1*g1)
+		cs, sn = f1/r, g1/r
+		for i := 0; i < count; i++ {
+			r *= safmn2
+		}
+	} else {
+		r = math.Sqrt(f1*f1 + g1*g1)
+		cs, sn = f1/r, g1/r
+	}
+	if math.Abs(f) > math.Abs(g) && cs < 0 {
+		cs, sn, r = -cs, -sn, -r
+	}
+	return
+}
+
+// dlas2 computes the singular values of the 2-by-2 matrix [f g; 0 h]
+func dlas2(f, g, h float64) (ssmin, ssmax float64) {
+	fa, ga, ha := math.Abs(f), math.Abs(g), math.Abs(h)
+	fhmn, fhmx := math.Min(fa, ha), math.Max(fa, ha)
+	if fhmn == 0 {
+		if fhmx == 0 {
+			return 0, ga
+		}
+		mx, mn := math.Max(fhmx, ga), math.Min(fhmx, ga)
+		return 0, mx * math.Sqrt(1+(mn/mx)*(mn/mx))
+	}
+	if ga < fhmx {
+		as := 1 + fhmn/fhmx
+		at := (fhmx - fhmn) / fhmx
+		au := (ga / fhmx) * (ga / fhmx)
+		c := 2 / (math.Sqrt(as*as+au) + math.Sqrt(at*at+au))
+		return fhmn * c, fhmx / c
+	}
+	au := fhmx / ga
+	if au == 0 {
+		return (fhmn * fhmx) / ga, ga
+	}
+	as := 1 + fhmn/fhmx
+	at := (fhmx - fhmn) / fhmx
+	c := 1 / (math.Sqrt(1+(as*au)*(as*au)) + math.Sqrt(1+(at*au)*(at*au)))
+	ssmin = (fhmn * c) * au
+	ssmin += ssmin
+	ssmax = ga / (c + c)
+	return
+}
+
+// dlasv2 computes the singular value decomposition of the 2-by-2 triangular matrix [f g; 0 h]
+func dlasv2(f, g, h float64) (ssmin, ssmax, snr, csr, snl, csl float64) {
+	ft, fa := f, math.Abs(f)
+	ht, ha := h, math.Abs(h)
+	pmax := 1
+	swap := ha > fa
+	if swap {
+		pmax = 3
+		ft, ht = ht, ft
+		fa, ha = ha, fa
+	}
+	gt, ga := g, math.Abs(g)
+	var clt, crt, slt, srt float64
+	if ga == 0 {
+		ssmin, ssmax = ha, fa
+		clt, crt, slt, srt = 1, 1, 0, 0
+	} else {
+		gasmal := true
+		if ga > fa {
+			pmax = 2
+			if fa/ga < dlamchE {
+				gasmal = false
+				ssmax = ga
+				if ha > 1 {
+					ssmin = fa / (ga / ha)
+				} else {
+					ssmin = (fa / ga) * ha
+				}
+				clt = 1
+				slt = ht / gt
+				srt = 1
+				crt = ft / gt
+			}
+		}
+		if gasmal {
+			d := fa - ha
+			var l float64
+			if d == fa {
+				l = 1
+			} else {
+				l = d / fa
+			}
+			m := gt / ft
+			t := 2 - l
+			mm := m * m
+			tt := t * t
+			s := math.Sqrt(tt + mm)
+			var r float64
+			if l == 0 {
+				r = math.Abs(m)
+			} else {
+				r = math.Sqrt(l*l + mm)
+			}
+			a := 0.5 * (s + r)
+			ssmin = ha / a
+			ssmax = fa * a
+			if mm == 0 {
+				if l == 0 {
+					t = math.Copysign(2, ft) * math.Copysign(1, gt)
+				} else {
+					t = gt/math.Copysign(d, ft) + m/t
+				}
+			} else {
+				t = (m/(s+t) + m/(r+l)) * (1 + a)
+			}
+			l = math.Sqrt(t*t + 4)
+			crt = 2 / l
+			srt = t / l
+			clt = (crt + srt*m) / a
+			slt = (ht / ft) * srt / a
+		}
+	}
+	if swap {
+		csl, snl, csr, snr = srt, crt, slt, clt
+	} else {
+		csl, snl, csr, snr = clt, slt, crt, srt
+	}
+
+	// correct signs of ssmax and ssmin
+	var tsign float64
+	switch pmax {
+	case 1:
+		tsign = math.Copysign(1, csr) * math.Copysign(1, csl) * math.Copysign(1, f)
+	case 2:
+		tsign = math.Copysign(1, snr) * math.Copysign(1, csl) * math.Copysign(1, g)
+	default:
+		tsign = math.Copysign(1, snr) * math.Copysign(1, snl) * math.Copysign(1, h)
+	}
+	ssmax = math.Copysign(ssmax, tsign)
+	ssmin = math.Copysign(ssmin, tsign*math.Copysign(1, f)*math.Copysign(1, h))
+	return
+}
+
+// symmetric eigenproblem //////////////////////////////////////////////////////////////////////////
+
+// dsyev computes all eigenvalues (ascending) and, optionally, the eigenvectors of a symmetric
+// matrix using Householder tridiagonalisation followed by the implicit QL method (EISPACK
+// routines tred2 and tql2)
+func dsyev(calcV, up bool, n int, a []float64, lda int, w []float64) (info int) {
+	if n == 0 {
+		return
+	}
+
+	// fill the other triangle
+	for j := 0; j < n; j++ {
+		for i := j + 1; i < n; i++ {
+			if up {
+				a[i+j*lda] = a[j+i*lda]
+			} else {
+				a[j+i*lda] = a[i+j*lda]
+			}
+		}
+	}
+
+	// Householder reduction to tridiagonal form
+	q := func(i, j int) *float64 { return &a[i+j*lda] }
+	e := make([]float64, n)
+	for i := n - 1; i > 0; i-- {
+		l := i - 1
+		var h, scale float64
+		if l > 0 {
+			for k := 0; k <= l; k++ {
+				scale += math.Abs(*q(i, k))
+			}
+			if scale == 0 {
+				e[i] = *q(i, l)
+			} else {
+				for k := 0; k <= l; k++ {
+					*q(i, k) /= scale
+					h += *q(i, k) * *q(i, k)
+				}
+				f := *q(i, l)
+				g := math.Sqrt(h)
+				if f >= 0 {
+					g = -g
+				}
+				e[i] = scale * g
+				h -= f * g
+				*q(i, l) = f - g
+				f = 0
+				for j := 0; j <= l; j++ {
+					*q(j, i) = *q(i, j) / h
+					g = 0
+					for k := 0; k <= j; k++ {
+						g += *q(j, k) * *q(i, k)
+					}
+					for k := j + 1; k <= l; k++ {
+						g += *q(k, j) * *q(i, k)
+					}
+					e[j] = g / h
+					f += e[j] * *q(i, j)
+				}
+				hh := f / (h + h)
+				for j := 0; j <= l; j++ {
+					f = *q(i, j)
+					g = e[j] - hh*f
+					e[j] = g
+					for k := 0; k <= j; k++ {
+						*q(j, k) -= f*e[k] + g**q(i, k)
+					}
+				}
+			}
+		} else {
+			e[i] = *q(i, l)
+		}
+		w[i] = h
+	}
+	w[0] = 0
+	e[0] = 0
+	for i := 0; i < n; i++ {
+		if w[i] != 0 {
+			for j := 0; j < i; j++ {
+				g := 0.0
+				for k := 0; k < i; k++ {
+					g += *q(i, k) * *q(k, j)
+				}
+				for k := 0; k < i; k++ {
+					*q(k, j) -= g * *q(k, i)
+				}
+			}
+		}
+		w[i] = *q(i, i)
+		*q(i, i) = 1
+		for j := 0; j < i; j++ {
+			*q(j, i) = 0
+			*q(i, j) = 0
+		}
+	}
+
+	// implicit QL iterations
+	for i := 1; i < n; i++ {
+		e[i-1] = e[i]
+	}
+	e[n-1] = 0
+	rot := func(i int, c, s float64) { drot(n, a[(i+1)*lda:], 1, a[i*lda:], 1, c, s) }
+	swap := func(i, k int) { dswap(n, a[i*lda:], 1, a[k*lda:], 1) }
+	return dsteql(n, w, e, rot, swap)
+}
+
+// dsteql computes all eigenvalues (ascending) of the symmetric tridiagonal matrix with diagonal d
+// and off-diagonal e (e[i] couples i and i+1; e[n-1] is not used) by the implicit QL method. The
+// plane rotations of columns i and i+1 of the eigenvectors matrix and the swaps of columns i and k
+// performed while sorting are delegated to rot and swap
+func dsteql(n int, d, e []float64, rot func(i int, c, s float64), swap func(i, k int)) (info int) {
+	eps := dlamchP
+	for l := 0; l < n; l++ {
+		it := 0
+		for {
+			var m int
+			for m = l; m < n-1; m++ {
+				dd := math.Abs(d[m]) + math.Abs(d[m+1])
+				if math.Abs(e[m]) <= dlamchS+eps*dd {
+					break
+				}
+			}
+			if m == l {
+				break
+			}
+			if it == 30*n {
+				return l + 1
+			}
+			it++
+			g := (d[l+1] - d[l]) / (2.0 * e[l])
+			r := dlapy2(g, 1.0)
+			if g >= 0 {
+				g = d[m] - d[l] + e[l]/(g+r)
+			} else {
+				g = d[m] - d[l] + e[l]/(g-r)
+			}
+			s, c, p := 1.0, 1.0, 0.0
+			i := m - 1
+			for ; i >= l; i-- {
+				f := s * e[i]
+				b := c * e[i]
+				r = dlapy2(f, g)
+				e[i+1] = r
+				if r == 0 {
+					d[i+1] -= p
+					e[m] = 0
+					break
+				}
+				s = f / r
+				c = g / r
+				g = d[i+1] - p
+				r = (d[i]-g)*s + 2.0*c*b
+				p = s * r
+				d[i+1] = g + p
+				g = c*r - b
+				rot(i, c, s)
+			}
+			if r == 0 && i >= l {
+				continue
+			}
+			d[l] -= p
+			e[l] = g
+			e[m] = 0
+		}
+	}
+
+	// sort eigenvalues (and eigenvectors) in ascending order
+	for i := 0; i < n-1; i++ {
+		k := i
+		for j := i + 1; j < n; j++ {
+			if d[j] < d[k] {
+				k = j
+			}
+		}
+		if k != i {
+			d[i], d[k] = d[k], d[i]
+			swap(i, k)
+		}
+	}
+	return
+}
+
+// nonsymmetric eigenproblem ///////////////////////////////////////////////////////////////////////
+
+// dgeev computes the eigenvalues and, optionally, the left and/or right eigenvectors of a real
+// nonsymmetric matrix (balancing → Hessenberg → Schur (dlahqr) → dtrevc → back-transformation)
+func dgeev(calcVl, calcVr bool, n int, a []float64, lda int, wr, wi, vl []float64, ldvl int, vr []float64, ldvr int) (info int) {
+	if n == 0 {
+		return
+	}
+
+	// scale A if max element outside range [smlnum,bignum]
+	smlnum := math.Sqrt(dlamchS) / dlamchP
+	bignum := 1 / smlnum
+	anrm := dlange(n, n, a, lda)
+	scalea := false
+	var cscale float64
+	if anrm > 0 && anrm < smlnum {
+		scalea, cscale = true, smlnum
+	} else if anrm > bignum {
+		scalea, cscale = true, bignum
+	}
+	if scalea {
+		dlascl(anrm, cscale, n, n, a, lda)
+	}
+
+	// balance the matrix
+	scale := make([]float64, n)
+	ilo, ihi := dgebal(n, a, lda, scale)
+
+	// reduce to upper Hessenberg form
+	tau := make([]float64, n)
+	work := make([]float64, n)
+	dgehd2(n, ilo, ihi, a, lda, tau, work)
+
+	// compute Schur form and Schur vectors
+	if calcVl {
+		dlacpy('L', n, n, a, lda, vl, ldvl)
+		dorghr(n, ilo, ihi, vl, ldvl, tau, work)
+		info = dhseqr(true, true, n, ilo, ihi, a, lda, wr, wi, vl, ldvl)
+		if calcVr {
+			dlacpy(0, n, n, vl, ldvl, vr, ldvr)
+		}
+	} else if calcVr {
+		dlacpy('L', n, n, a, lda, vr, ldvr)
+		dorghr(n, ilo, ihi, vr, ldvr, tau, work)
+		info = dhseqr(true, true, n, ilo, ihi, a, lda, wr, wi, vr, ldvr)
+	} else {
+		info = dhseqr(false, false, n, ilo, ihi, a, lda, wr, wi, nil, 1)
+	}
+
+	// compute eigenvectors, undo balancing and normalise
+	if info == 0 && (calcVl || calcVr) {
+		dtrevc(calcVl, calcVr, n, a, lda, vl, ldvl, vr, ldvr)
+		if calcVl {
+			dgebak(false, n, ilo, ihi, scale, n, vl, ldvl)
+			normaliseEigenvecs(n, wi, vl, ldvl)
+		}
+		if calcVr {
+			dgebak(true, n, ilo, ihi, scale, n, vr, ldvr)
+			normaliseEigenvecs(n, wi, vr, ldvr)
+		}
+	}
+
+	// undo scaling if necessary
+	if scalea {
+		dlascl(cscale, anrm, n-info, 1, wr[info:], imax(n-info, 1))
+		dlascl(cscale, anrm, n-info, 1, wi[info:], imax(n-info, 1))
+	}
+	return
+}
+
+// dgees computes the real Schur form T = Zᵀ⋅A⋅Z of a general matrix and, optionally, the Schur
+// vectors Z. Balancing is not applied such that Z is orthogonal
+func dgees(calcVs bool, n int, a []float64, lda int, wr, wi, vs []float64, ldvs int) (info int) {
+	if n == 0 {
+		return
+	}
+	tau := make([]float64, n)
+	work := make([]float64, n)
+	dgehd2(n, 0, n-1, a, lda, tau, work)
+	if calcVs {
+		dlacpy('L', n, n, a, lda, vs, ldvs)
+		dorghr(n, 0, n-1, vs, ldvs, tau, work)
+		return dhseqr(true, true, n, 0, n-1, a, lda, wr, wi, vs, ldvs)
+	}
+	return dhseqr(true, false, n, 0, n-1, a, lda, wr, wi, nil, 1)
+}
+
+// normaliseEigenvecs normalises the eigenvectors to have unit norm and largest component real
+func normaliseEigenvecs(n int, wi, v []float64, ldv int) {
+	work := make([]float64, n)
+	for i := 0; i < n; i++ {
+		if wi[i] == 0 {
+			scl := 1 / dnrm2(n, v[i*ldv:], 1)
+			Dscal(n, scl, v[i*ldv:], 1)
+		} else if wi[i] > 0 {
+			scl := 1 / dlapy2(dnrm2(n, v[i*ldv:], 1), dnrm2(n, v[(i+1)*ldv:], 1))
+			Dscal(n, scl, v[i*ldv:], 1)
+			Dscal(n, scl, v[(i+1)*ldv:], 1)
+			for k := 0; k < n; k++ {
+				work[k] = v[k+i*ldv]*v[k+i*ldv] + v[k+(i+1)*ldv]*v[k+(i+1)*ldv]
+			}
+			k := idamax(n, work, 1)
+			cs, sn, _ := dlartg(v[k+i*ldv], v[k+(i+1)*ldv])
+			drot(n, v[i*ldv:], 1, v[(i+1)*ldv:], 1, cs, sn)
+			v[k+(i+1)*ldv] = 0
+		}
+	}
+}
+
+// dgebal balances a general matrix (permutation and scaling). Returns the 0-based ilo and ihi
+// indices such that A(i,j) = 0 if i > j and j = 0...ilo-1 or i = ihi+1...n-1. scale holds the
+// (1-based) permutation indices outside ilo...ihi and the scaling factors within ilo...ihi
+func dgebal(n int, a []float64, lda int, scale []float64) (ilo, ihi int) {
+	const radix = 2.0
+	const factor = 0.95
+
+	// permutation to isolate eigenvalues if possible
+	k, l := 0, n-1
+	exchange := func(j, m int) {
+		scale[m] = float64(j + 1)
+		if j != m {
+			dswap(l+1, a[j*lda:], 1, a[m*lda:], 1)
+			dswap(n-k, a[j+k*lda:], lda, a[m+k*lda:], lda)
+		}
+	}
+
+	// search for rows isolating an eigenvalue and push them down
+	for found := true; found; {
+		found = false
+		for j := l; j >= 0; j-- {
+			isolated := true
+			for i := 0; i <= l; i++ {
+				if i != j && a[j+i*lda] != 0 {
+					isolated = false
+					break
+				}
+			}
+			if isolated {
+				exchange(j, l)
+				if l == 0 {
+					return 0, 0
+				}
+				l--
+				found = true
+				break
+			}
+		}
+	}
+
+	// search for columns isolating an eigenvalue and push them left
+	for found := true; found; {
+		found = false
+		for j := k; j <= l; j++ {
+			isolated := true
+			for i := k; i <= l; i++ {
+				if i != j && a[i+j*lda] != 0 {
+					isolated = false
+					break
+				}
+			}
+			if isolated {
+				exchange(j, k)
+				k++
+				found = true
+				break
+			}
+		}
+	}
+	for i := k; i <= l; i++ {
+		scale[i] = 1
+	}
+
+	// balance the submatrix in rows k to l; iterative loop for norm reduction
+	sfmin1 := dlamchS / dlamchP
+	sfmax1 := 1 / sfmin1
+	sfmin2 := sfmin1 * radix
+	sfmax2 := 1 / sfmin2
+	for noconv := true; noconv; {
+		noconv = false
+		for i := k; i <= l; i++ {
+			c := dnrm2(l-k+1, a[k+i*lda:], 1)
+			r := dnrm2(l-k+1, a[i+k*lda:], lda)
+			ica := idamax(l+1, a[i*lda:], 1)
+			ca := math.Abs(a[ica+i*lda])
+			ira := idamax(n-k, a[i+k*lda:], lda)
+			ra := math.Abs(a[i+(ira+k)*lda])
+
+			// guard against zero c or r due to underflow
+			if c == 0 || r == 0 {
+				continue
+			}
+			g := r / radix
+			f := 1.0
+			s := c + r
+			for c < g && math.Max(f, math.Max(c, ca)) < sfmax2 && math.Min(r, math.Min(g, ra)) > sfmin2 {
+				f *= radix
+				c *= radix
+				ca *= radix
+				r /= radix
+				g /= radix
+				ra /= radix
+			}
+			g = c / radix
+			for g >= r && math.Max(r, ra) < sfmax2 && math.Min(math.Min(f, c), math.Min(g, ca)) > sfmin2 {
+				f /= radix
+				c /= radix
+				g /= radix
+				ca /= radix
+				r *= radix
+				ra *= radix
+			}
+
+			// now balance
+			if c+r >= factor*s {
+				continue
+			}
+			if f < 1 && scale[i] < 1 {
+				if f*scale[i] <= sfmin1 {
+					continue
+				}
+			}
+			if f > 1 && scale[i] > 1 {
+				if scale[i] >= sfmax1/f {
+					continue
+				}
+			}
+			g = 1 / f
+			scale[i] *= f
+			noconv = true
+			Dscal(n-k, g, a[i+k*lda:], lda)
+			Dscal(l+1, f, a[i*lda:], 1)
+		}
+	}
+	return k, l
+}
+
+// dgebak transforms the eigenvectors of a balanced matrix back to the ones of the original matrix
+func dgebak(right bool, n, ilo, ihi int, scale []float64, m int, v []float64, ldv int) {
+	if n == 0 || m == 0 {
+		return
+	}
+	if ilo != ihi {
+		for i := ilo; i <= ihi; i++ {
+			s := scale[i]
+			if !right {
+				s = 1 / s
+			}
+			Dscal(m, s, v[i:], ldv)
+		}
+	}
+	for ii := 0; ii < n; ii++ {
+		i := ii
+		if i >= ilo && i <= ihi {
+			continue
+		}
+		if i < ilo {
+			i = ilo - 1 - ii
+		}
+		k := int(scale[i]) - 1
+		if k != i {
+			dswap(m, v[i:], ldv, v[k:], ldv)
+		}
+	}
+}
+
+// dgehd2 reduces a general matrix to upper Hessenberg form by an orthogonal similarity transformation
+func dgehd2(n, ilo, ihi int, a []float64, lda int, tau, work []float64) {
+	for i := 0; i < ilo; i++ {
+		tau[i] = 0
+	}
+	for i := imax(0, ihi); i < n-1; i++ {
+		tau[i] = 0
+	}
+	for i := ilo; i < ihi; i++ {
+		beta, t := dlarfg(ihi-i, a[i+1+i*lda], a[imin(i+2, n-1)+i*lda:], 1)
+		tau[i] = t
+		a[i+1+i*lda] = 1
+		dlarf(false, ihi+1, ihi-i, a[i+1+i*lda:], 1, t, a[(i+1)*lda:], lda, work)
+		dlarf(true, ihi-i, n-i-1, a[i+1+i*lda:], 1, t, a[i+1+(i+1)*lda:], lda, work)
+		a[i+1+i*lda] = beta
+	}
+}
+
+// dorghr generates the orthogonal matrix Q determined by dgehd2
+func dorghr(n, ilo, ihi int, a []float64, lda int, tau, work []float64) {
+	nh := ihi - ilo
+	for j := ihi; j >= ilo+1; j-- {
+		for i := 0; i < j; i++ {
+			a[i+j*lda] = 0
+		}
+		for i := j + 1; i <= ihi; i++ {
+			a[i+j*lda] = a[i+(j-1)*lda]
+		}
+		for i := ihi + 1; i < n; i++ {
+			a[i+j*lda] = 0
+		}
+	}
+	for j := 0; j <= ilo; j++ {
+		for i := 0; i < n; i++ {
+			a[i+j*lda] = 0
+		}
+		a[j+j*lda] = 1
+	}
+	for j := ihi + 1; j < n; j++ {
+		for i := 0; i < n; i++ {
+			a[i+j*lda] = 0
+		}
+		a[j+j*lda] = 1
+	}
+	if nh > 0 {
+		dorg2r(nh, nh, nh, a[(ilo+1)+(ilo+1)*lda:], lda, tau[ilo:], work)
+	}
+}
+
+// dhseqr computes the eigenvalues of a Hessenberg matrix H and, optionally, the Schur form
+// (wantt) and the Schur vectors (wantz; Z must hold Q on input)
+func dhseqr(wantt, wantz bool, n, ilo, ihi int, h []float64, ldh int, wr, wi, z []float64, ldz int) (info int) {
+	if n == 0 {
+		return
+	}
+
+	// copy eigenvalues isolated by dgebal
+	for i := 0; i < ilo; i++ {
+		wr[i], wi[i] = h[i+i*ldh], 0
+	}
+	for i := ihi + 1; i < n; i++ {
+		wr[i], wi[i] = h[i+i*ldh], 0
+	}
+	if ilo == ihi {
+		wr[ilo], wi[ilo] = h[ilo+ilo*ldh], 0
+		return
+	}
+	info = dlahqr(wantt, wantz, n, ilo, ihi, h, ldh, wr, wi, ilo, ihi, z, ldz)
+
+	// clear out the trash
+	if (wantt || info != 0) && n > 2 {
+		for j := 0; j < n-2; j++ {
+			for i := j + 2; i < n; i++ {
+				h[i+j*ldh] = 0
+			}
+		}
+	}
+	return
+}
+
+// dlahqr computes the eigenvalues and Schur factorisation of an upper Hessenberg matrix using
+// the double-shift/single-shift QR algorithm
+func dlahqr(wantt, wantz bool, n, ilo, ihi int, h []float64, ldh int, wr, wi []float64, iloz, ihiz int, z []float64, ldz int) (info int) {
+	const dat1, dat2 = 3.0 / 4.0, -0.4375
+	const kexsh = 10
+	if n == 0 {
+		return
+	}
+	H := func(i, j int) *float64 { return &h[i+j*ldh] }
+	if ilo == ihi {
+		wr[ilo], wi[ilo] = *H(ilo, ilo), 0
+		return
+	}
+
+	// clear out the trash
+	for j := ilo; j <= ihi-3; j++ {
+		*H(j+2, j) = 0
+		*H(j+3, j) = 0
+	}
+	if ilo <= ihi-2 {
+		*H(ihi, ihi-2) = 0
+	}
+	nh := ihi - ilo + 1
+	nz := ihiz - iloz + 1
+
+	// machine-dependent constants for the stopping criterion
+	safmin := dlamchS
+	ulp := dlamchP
+	smlnum := safmin * (float64(nh) / ulp)
+
+	// i1 and i2 are the indices of the first row and last column of H to which
+	// transformations must be applied
+	var i1, i2 int
+	if wantt {
+		i1, i2 = 0, n-1
+	}
+	itmax := 30 * imax(10, nh)
+	kdefl := 0
+
+	// main loop: i decreases from ihi to ilo in steps of 1 or 2
+	var v [3]float64
+	i := ihi
+	for i >= ilo {
+		l := ilo
+		converged := false
+		for its := 0; its <= itmax; its++ {
+
+			// look for a single small subdiagonal element
+			var k int
+			for k = i; k > l; k-- {
+				if math.Abs(*H(k, k-1)) <= smlnum {
+					break
+				}
+				tst := math.Abs(*H(k-1, k-1)) + math.Abs(*H(k, k))
+				if tst == 0 {
+					if k-2 >= ilo {
+						tst += math.Abs(*H(k-1, k-2))
+					}
+					if k+1 <= ihi {
+						tst += math.Abs(*H(k+1, k))
+					}
+				}
+				// conservative small subdiagonal deflation criterion due to Ahues & Kressner (2004)
+				if math.Abs(*H(k, k-1)) <= ulp*tst {
+					ab := math.Max(math.Abs(*H(k, k-1)), math.Abs(*H(k-1, k)))
+					ba := math.Min(math.Abs(*H(k, k-1)), math.Abs(*H(k-1, k)))
+					aa := math.Max(math.Abs(*H(k, k)), math.Abs(*H(k-1, k-1)-*H(k, k)))
+					bb := math.Min(math.Abs(*H(k, k)), math.Abs(*H(k-1, k-1)-*H(k, k)))
+					s := aa + ab
+					if ba*(ab/s) <= math.Max(smlnum, ulp*(bb*(aa/s))) {
+						break
+					}
+				}
+			}
+			l = k
+			if l > ilo {
+				*H(l, l-1) = 0 // H(l,l-1) is negligible
+			}
+
+			// exit from loop if a submatrix of order 1 or 2 has split off
+			if l >= i-1 {
+				converged = true
+				break
+			}
+			kdefl++
+
+			// now the active submatrix is in rows and columns l to i
+			if !wantt {
+				i1, i2 = l, i
+			}
+
+			// shifts
+			var h11, h12, h21, h22 float64
+			if kdefl%(2*kexsh) == 0 { // exceptional shift
+				s := math.Abs(*H(i, i-1)) + math.Abs(*H(i-1, i-2))
+				h11 = dat1*s + *H(i, i)
+				h12 = dat2 * s
+				h21 = s
+				h22 = h11
+			} else if kdefl%kexsh == 0 { // exceptional shift
+				s := math.Abs(*H(l+1, l)) + math.Abs(*H(l+2, l+1))
+				h11 = dat1*s + *H(l, l)
+				h12 = dat2 * s
+				h21 = s
+				h22 = h11
+			} else { // Francis' double shift
+				h11 = *H(i-1, i-1)
+				h21 = *H(i, i-1)
+				h12 = *H(i-1, i)
+				h22 = *H(i, i)
+			}
+			var rt1r, rt1i, rt2r, rt2i float64
+			s := math.Abs(h11) + math.Abs(h12) + math.Abs(h21) + math.Abs(h22)
+			if s != 0 {
+				h11 /= s
+				h21 /= s
+				h12 /= s
+				h22 /= s
+				tr := (h11 + h22) / 2.0
+				det := (h11-tr)*(h22-tr) - h12*h21
+				rtdisc := math.Sqrt(math.Abs(det))
+				if det >= 0 { // complex conjugate shifts
+					rt1r = tr * s
+					rt2r = rt1r
+					rt1i = rtdisc * s
+					rt2i = -rt1i
+				} else { // real shifts (use only one of them)
+					rt1r = tr + rtdisc
+					rt2r = tr - rtdisc
+					if math.Abs(rt1r-h22) <= math.Abs(rt2r-h22) {
+						rt1r *= s
+						rt2r = rt1r
+					} else {
+						rt2r *= s
+						rt1r = rt2r
+					}
+				}
+			}
+
+			// look for two consecutive small subdiagonal elements
+			var m int
+			for m = i - 2; m >= l; m-- {
+				h21s := *H(m+1, m)
+				s = math.Abs(*H(m, m)-rt2r) + math.Abs(rt2i) + math.Abs(h21s)
+				h21s = *H(m+1, m) / s
+				v[0] = h21s**H(m, m+1) + (*H(m, m)-rt1r)*((*H(m, m)-rt2r)/s) - rt1i*(rt2i/s)
+				v[1] = h21s * (*H(m, m) + *H(m+1, m+1) - rt1r - rt2r)
+				v[2] = h21s * *H(m+2, m+1)
+				s = math.Abs(v[0]) + math.Abs(v[1]) + math.Abs(v[2])
+				v[0] /= s
+				v[1] /= s
+				v[2] /= s
+				if m == l {
+					break
+				}
+				h00 := math.Abs(*H(m, m-1)) * (math.Abs(v[1]) + math.Abs(v[2]))
+				h01 := ulp * math.Abs(v[0]) * (math.Abs(*H(m-1, m-1)) + math.Abs(*H(m, m)) + math.Abs(*H(m+1, m+1)))
+				if h00 <= h01 {
+					break
+				}
+			}
+
+			// double-shift QR step
+			for k = m; k <= i-1; k++ {
+				nr := imin(3, i-k+1)
+				if k > m {
+					for j := 0; j < nr; j++ {
+						v[j] = *H(k+j, k-1)
+					}
+				}
+				var t1 float64
+				v[0], t1 = dlarfg(nr, v[0], v[1:], 1)
+				if k > m {
+					*H(k, k-1) = v[0]
+					*H(k+1, k-1) = 0
+					if k < i-1 {
+						*H(k+2, k-1) = 0
+					}
+				} else if m > l {
+					*H(k, k-1) *= 1 - t1
+				}
+				v2 := v[1]
+				t2 := t1 * v2
+				if nr == 3 {
+					v3 := v[2]
+					t3 := t1 * v3
+					for j := k; j <= i2; j++ {
+						sum := *H(k, j) + v2**H(k+1, j) + v3**H(k+2, j)
+						*H(k, j) -= sum * t1
+						*H(k+1, j) -= sum * t2
+						*H(k+2, j) -= sum * t3
+					}
+					for j := i1; j <= imin(k+3, i); j++ {
+						sum := *H(j, k) + v2**H(j, k+1) + v3**H(j, k+2)
+						*H(j, k) -= sum * t1
+						*H(j, k+1) -= sum * t2
+						*H(j, k+2) -= sum * t3
+					}
+					if wantz {
+						for j := iloz; j <= ihiz; j++ {
+							zk := z[j+k*ldz:]
+							sum := zk[0] + v2*z[j+(k+1)*ldz] + v3*z[j+(k+2)*ldz]
+							zk[0] -= sum * t1
+							z[j+(k+1)*ldz] -= sum * t2
+							z[j+(k+2)*ldz] -= sum * t3
+						}
+					}
+				} else if nr == 2 {
+					for j := k; j <= i2; j++ {
+						sum := *H(k, j) + v2**H(k+1, j)
+						*H(k, j) -= sum * t1
+						*H(k+1, j) -= sum * t2
+					}
+					for j := i1; j <= i; j++ {
+						sum := *H(j, k) + v2**H(j, k+1)
+						*H(j, k) -= sum * t1
+						*H(j, k+1) -= sum * t2
+					}
+					if wantz {
+						for j := iloz; j <= ihiz; j++ {
+							sum := z[j+k*ldz] + v2*z[j+(k+1)*ldz]
+							z[j+k*ldz] -= sum * t1
+							z[j+(k+1)*ldz] -= sum * t2
+						}
+					}
+				}
+			}
+		}
+
+		// failure to converge in remaining number of iterations
+		if !converged {
+			return i + 1
+		}
+
+		if l == i {
+			// H(i,i-1) is negligible: one eigenvalue has converged
+			wr[i], wi[i] = *H(i, i), 0
+		} else if l == i-1 {
+			// H(i-1,i-2) is negligible: a pair of eigenvalues have converged.
+			// Transform the 2-by-2 submatrix to standard Schur form
+			var cs, sn float64
+			*H(i-1, i-1), *H(i-1, i), *H(i, i-1), *H(i, i), wr[i-1], wi[i-1], wr[i], wi[i], cs, sn =
+				dlanv2(*H(i-1, i-1), *H(i-1, i), *H(i, i-1), *H(i, i))
+			if wantt {
+				if i2 > i {
+					drot(i2-i, h[i-1+(i+1)*ldh:], ldh, h[i+(i+1)*ldh:], ldh, cs, sn)
+				}
+				drot(i-i1-1, h[i1+(i-1)*ldh:], 1, h[i1+i*ldh:], 1, cs, sn)
+			}
+			if wantz {
+				drot(nz, z[iloz+(i-1)*ldz:], 1, z[iloz+i*ldz:], 1, cs, sn)
+			}
+		}
+
+		// reset deflation counter and return to start of the main loop with new value of i
+		kdefl = 0
+		i = l - 1
+	}
+	return
+}
+
+// dlanv2 computes the Schur factorisation of a real 2-by-2 nonsymmetric matrix in standardised form
+func dlanv2(a, b, c, d float64) (aa, bb, cc, dd, rt1r, rt1i, rt2r, rt2i, cs, sn float64) {
+	const multpl = 4.0
+	const eps = dlamchP
+	const safmn2 = 0x1p-485
+	const safmx2 = 1 / safmn2
+	if c == 0 {
+		cs, sn = 1, 0
+	} else if b == 0 {
+		// swap rows and columns
+		cs, sn = 0, 1
+		a, d = d, a
+		b = -c
+		c = 0
+	} else if (a-d) == 0 && math.Copysign(1, b) != math.Copysign(1, c) {
+		cs, sn = 1, 0
+	} else {
+		temp := a - d
+		p := 0.5 * temp
+		bcmax := math.Max(math.Abs(b), math.Abs(c))
+		bcmis := math.Min(math.Abs(b), math.Abs(c)) * math.Copysign(1, b) * math.Copysign(1, c)
+		scale := math.Max(math.Abs(p), bcmax)
+		z := (p/scale)*p + (bcmax/scale)*bcmis
+
+		// if z is of the order of the machine accuracy, postpone the
+		// decision on the nature of eigenvalues
+		if z >= multpl*eps {
+			// real eigenvalues. Compute a and d
+			z = p + math.Copysign(math.Sqrt(scale)*math.Sqrt(z), p)
+			a = d + z
+			d -= (bcmax / z) * bcmis
+			// compute b and the rotation matrix
+			tau := dlapy2(c, z)
+			cs = z / tau
+			sn = c / tau
+			b -= c
+			c = 0
+		} else {
+			// complex eigenvalues, or real (almost) equal eigenvalues; make diagonal elements equal
+			count := 0
+			sigma := b + c
+			for {
+				count++
+				scale = math.Max(math.Abs(temp), math.Abs(sigma))
+				if scale >= safmx2 {
+					sigma *= safmn2
+					temp *= safmn2
+					if count <= 20 {
+						continue
+					}
+				}
+				if scale <= safmn2 {
+					sigma *= safmx2
+					temp *= safmx2
+					if count <= 20 {
+						continue
+					}
+				}
+				break
+			}
+			p = 0.5 * temp
+			tau := dlapy2(sigma, temp)
+			cs = math.Sqrt(0.5 * (1 + math.Abs(sigma)/tau))
+			sn = -(p / (tau * cs)) * math.Copysign(1, sigma)
+
+			// compute [aa bb; cc dd] = [a b; c d]⋅[cs -sn; sn cs]
+			aa = a*cs + b*sn
+			bb = -a*sn + b*cs
+			cc = c*cs + d*sn
+			dd = -c*sn + d*cs
+
+			// compute [a b; c d] = [cs sn; -sn cs]⋅[aa bb; cc dd]
+			a = aa*cs + cc*sn
+			b = bb*cs + dd*sn
+			c = -aa*sn + cc*cs
+			d = -bb*sn + dd*cs
+			temp = 0.5 * (a + d)
+			a = temp
+			d = temp
+			if c != 0 {
+				if b != 0 {
+					if math.Copysign(1, b) == math.Copysign(1, c) {
+						// real eigenvalues: reduce to upper triangular form
+						sab := math.Sqrt(math.Abs(b))
+						sac := math.Sqrt(math.Abs(c))
+						p = math.Copysign(sab*sac, c)
+						tau = 1 / math.Sqrt(math.Abs(b+c))
+						a = temp + p
+						d = temp - p
+						b -= c
+						c = 0
+						cs1 := sab * tau
+						sn1 := sac * tau
+						temp = cs*cs1 - sn*sn1
+						sn = cs*sn1 + sn*cs1
+						cs = temp
+					}
+				} else {
+					b = -c
+					c = 0
+					cs, sn = -sn, cs
+				}
+			}
+		}
+	}
+
+	// store eigenvalues in (rt1r,rt1i) and (rt2r,rt2i)
+	rt1r, rt2r = a, d
+	if c != 0 {
+		rt1i = math.Sqrt(math.Abs(b)) * math.Sqrt(math.Abs(c))
+		rt2i = -rt1i
+	}
+	return a, b, c, d, rt1r, rt1i, rt2r, rt2i, cs, sn
+}
+
+// dtrevc computes the right and/or left eigenvectors of a real upper quasi-triangular matrix T
+// and back-transforms them with the matrices given in vl and vr (HOWMNY='B')
+func dtrevc(leftv, rightv bool, n int, t []float64, ldt int, vl []float64, ldvl int, vr []float64, ldvr int) {
+	T := func(i, j int) float64 { return t[i+j*ldt] }
+
+	// constants to control overflow
+	unfl := dlamchS
+	ulp := dlamchP
+	smlnum := unfl * (float64(n) / ulp)
+	bignum := (1 - ulp) / smlnum
+
+	// 1-norm of each column of strictly upper triangular part of T to control overflow
+	work := make([]float64, 3*n)
+	for j := 1; j < n; j++ {
+		for i := 0; i < j; i++ {
+			work[j] += math.Abs(T(i, j))
+		}
+	}
+	n2 := 2 * n
+	var x [2][2]float64
+
+	// right eigenvectors
+	if rightv {
+		ip := 0
+		for ki := n - 1; ki >= 0; ki-- {
+			if ip == 1 {
+				ip = 0
+				continue
+			}
+			if ki > 0 && T(ki, ki-1) != 0 {
+				ip = -1
+			}
+
+			// compute the ki-th eigenvalue (wr,wi)
+			wr := T(ki, ki)
+			wi := 0.0
+			if ip != 0 {
+				wi = math.Sqrt(math.Abs(T(ki, ki-1))) * math.Sqrt(math.Abs(T(ki-1, ki)))
+			}
+			smin := math.Max(ulp*(math.Abs(wr)+math.Abs(wi)), smlnum)
+
+			if ip == 0 {
+
+				// real right eigenvector
+				work[ki+n] = 1
+				for k := 0; k < ki; k++ {
+					work[k+n] = -T(k, ki)
+				}
+
+				// solve the upper quasi-triangular system (T(0:ki-1,0:ki-1) - wr)⋅X = scale⋅work
+				jnxt := ki - 1
+				for j := ki - 1; j >= 0; j-- {
+					if j > jnxt {
+						continue
+					}
+					j1, j2 := j, j
+					jnxt = j - 1
+					if j > 0 && T(j, j-1) != 0 {
+						j1 = j - 1
+						jnxt = j - 2
+					}
+					if j1 == j2 {
+						scale, xnorm := dlaln2(false, 1, 1, smin, 1, t[j+j*ldt:], ldt, 1, 1, work[j+n:], n, wr, 0, &x)
+						if xnorm > 1 && work[j] > bignum/xnorm {
+							x[0][0] /= xnorm
+							scale /= xnorm
+						}
+						if scale != 1 {
+							Dscal(ki+1, scale, work[n:], 1)
+						}
+						work[j+n] = x[0][0]
+						Daxpy(j, -x[0][0], t[j*ldt:], 1, work[n:], 1)
+					} else {
+						scale, xnorm := dlaln2(false, 2, 1, smin, 1, t[j-1+(j-1)*ldt:], ldt, 1, 1, work[j-1+n:], n, wr, 0, &x)
+						if xnorm > 1 {
+							beta := math.Max(work[j-1], work[j])
+							if beta > bignum/xnorm {
+								x[0][0] /= xnorm
+								x[1][0] /= xnorm
+								scale /= xnorm
+							}
+						}
+						if scale != 1 {
+							Dscal(ki+1, scale, work[n:], 1)
+						}
+						work[j-1+n] = x[0][0]
+						work[j+n] = x[1][0]
+						Daxpy(j-1, -x[0][0], t[(j-1)*ldt:], 1, work[n:], 1)
+						Daxpy(j-1, -x[1][0], t[j*ldt:], 1, work[n:], 1)
+					}
+				}
+
+				// copy Q⋅x to vr and normalise
+				if ki > 0 {
+					Dgemv(false, n, ki, 1, vr, ldvr, work[n:], 1, work[ki+n], vr[ki*ldvr:], 1)
+				}
+				ii := idamax(n, vr[ki*ldvr:], 1)
+				remax := 1 / math.Abs(vr[ii+ki*ldvr])
+				Dscal(n, remax, vr[ki*ldvr:], 1)
+
+			} else {
+
+				// complex right eigenvector; initial solve
+				if math.Abs(T(ki-1, ki)) >= math.Abs(T(ki, ki-1)) {
+					work[ki-1+n] = 1
+					work[ki+n2] = wi / T(ki-1, ki)
+				} else {
+					work[ki-1+n] = -wi / T(ki, ki-1)
+					work[ki+n2] = 1
+				}
+				work[ki+n] = 0
+				work[ki-1+n2] = 0
+				for k := 0; k < ki-1; k++ {
+					work[k+n] = -work[ki-1+n] * T(k, ki-1)
+					work[k+n2] = -work[ki+n2] * T(k, ki)
+				}
+
+				// solve upper quasi-triangular system (T(0:ki-2,0:ki-2) - (wr+i⋅wi))⋅X = scale⋅(work+i⋅work2)
+				jnxt := ki - 2
+				for j := ki - 2; j >= 0; j-- {
+					if j > jnxt {
+						continue
+					}
+					j1, j2 := j, j
+					jnxt = j - 1
+					if j > 0 && T(j, j-1) != 0 {
+						j1 = j - 1
+						jnxt = j - 2
+					}
+					if j1 == j2 {
+						scale, xnorm := dlaln2(false, 1, 2, smin, 1, t[j+j*ldt:], ldt, 1, 1, work[j+n:], n, wr, wi, &x)
+						if xnorm > 1 && work[j] > bignum/xnorm {
+							x[0][0] /= xnorm
+							x[0][1] /= xnorm
+							scale /= xnorm
+						}
+						if scale != 1 {
+							Dscal(ki+1, scale, work[n:], 1)
+							Dscal(ki+1, scale, work[n2:], 1)
+						}
+						work[j+n] = x[0][0]
+						work[j+n2] = x[0][1]
+						Daxpy(j, -x[0][0], t[j*ldt:], 1, work[n:], 1)
+						Daxpy(j, -x[0][1], t[j*ldt:], 1, work[n2:], 1)
+					} else {
+						scale, xnorm := dlaln2(false, 2, 2, smin, 1, t[j-1+(j-1)*ldt:], ldt, 1, 1, work[j-1+n:], n, wr, wi, &x)
+						if xnorm > 1 {
+							beta := math.Max(work[j-1], work[j])
+							if beta > bignum/xnorm {
+								rec := 1 / xnorm
+								x[0][0] *= rec
+								x[0][1] *= rec
+								x[1][0] *= rec
+								x[1][1] *= rec
+								scale *= rec
+							}
+						}
+						if scale != 1 {
+							Dscal(ki+1, scale, work[n:], 1)
+							Dscal(ki+1, scale, work[n2:], 1)
+						}
+						work[j-1+n] = x[0][0]
+						work[j+n] = x[1][0]
+						work[j-1+n2] = x[0][1]
+						work[j+n2] = x[1][1]
+						Daxpy(j-1, -x[0][0], t[(j-1)*ldt:], 1, work[n:], 1)
+						Daxpy(j-1, -x[1][0], t[j*ldt:], 1, work[n:], 1)
+						Daxpy(j-1, -x[0][1], t[(j-1)*ldt:], 1, work[n2:], 1)
+						Daxpy(j-1, -x[1][1], t[j*ldt:], 1, work[n2:], 1)
+					}
+				}
+
+				// copy Q⋅x to vr and normalise
+				if ki > 1 {
+					Dgemv(false, n, ki-1, 1, vr, ldvr, work[n:], 1, work[ki-1+n], vr[(ki-1)*ldvr:], 1)
+					Dgemv(false, n, ki-1, 1, vr, ldvr, work[n2:], 1, work[ki+n2], vr[ki*ldvr:], 1)
+				} else {
+					Dscal(n, work[ki-1+n], vr[(ki-1)*ldvr:], 1)
+					Dscal(n, work[ki+n2], vr[ki*ldvr:], 1)
+				}
+				emax := 0.0
+				for k := 0; k < n; k++ {
+					emax = math.Max(emax, math.Abs(vr[k+(ki-1)*ldvr])+math.Abs(vr[k+ki*ldvr]))
+				}
+				remax := 1 / emax
+				Dscal(n, remax, vr[(ki-1)*ldvr:], 1)
+				Dscal(n, remax, vr[ki*ldvr:], 1)
+			}
+			if ip == -1 {
+				ip = 1
+			}
+		}
+	}
+
+	// left eigenvectors
+	if leftv {
+		ip := 0
+		for ki := 0; ki < n; ki++ {
+			if ip == -1 {
+				ip = 0
+				continue
+			}
+			if ki < n-1 && T(ki+1, ki) != 0 {
+				ip = 1
+			}
+
+			// compute the ki-th eigenvalue (wr,wi)
+			wr := T(ki, ki)
+			wi := 0.0
+			if ip != 0 {
+				wi = math.Sqrt(math.Abs(T(ki, ki+1))) * math.Sqrt(math.Abs(T(ki+1, ki)))
+			}
+			smin := math.Max(ulp*(math.Abs(wr)+math.Abs(wi)), smlnum)
+
+			if ip == 0 {
+
+				// real left eigenvector
+				work[ki+n] = 1
+				for k := ki + 1; k < n; k++ {
+					work[k+n] = -T(ki, k)
+				}
+
+				// solve the quasi-triangular system (T(ki+1:n,ki+1:n) - wr)ᵀ⋅X = scale⋅work
+				vmax := 1.0
+				vcrit := bignum
+				jnxt := ki + 1
+				for j := ki + 1; j < n; j++ {
+					if j < jnxt {
+						continue
+					}
+					j1, j2 := j, j
+					jnxt = j + 1
+					if j < n-1 && T(j+1, j) != 0 {
+						j2 = j + 1
+						jnxt = j + 2
+					}
+					if j1 == j2 {
+						if work[j] > vcrit {
+							rec := 1 / vmax
+							Dscal(n-ki, rec, work[ki+n:], 1)
+							vmax = 1
+							vcrit = bignum
+						}
+						work[j+n] -= Ddot(j-ki-1, t[ki+1+j*ldt:], 1, work[ki+1+n:], 1)
+						scale, _ := dlaln2(false, 1, 1, smin, 1, t[j+j*ldt:], ldt, 1, 1, work[j+n:], n, wr, 0, &x)
+						if scale != 1 {
+							Dscal(n-ki, scale, work[ki+n:], 1)
+						}
+						work[j+n] = x[0][0]
+						vmax = math.Max(math.Abs(work[j+n]), vmax)
+						vcrit = bignum / vmax
+					} else {
+						beta := math.Max(work[j], work[j+1])
+						if beta > vcrit {
+							rec := 1 / vmax
+							Dscal(n-ki, rec, work[ki+n:], 1)
+							vmax = 1
+							vcrit = bignum
+						}
+						work[j+n] -= Ddot(j-ki-1, t[ki+1+j*ldt:], 1, work[ki+1+n:], 1)
+						work[j+1+n] -= Ddot(j-ki-1, t[ki+1+(j+1)*ldt:], 1, work[ki+1+n:], 1)
+						scale, _ := dlaln2(true, 2, 1, smin, 1, t[j+j*ldt:], ldt, 1, 1, work[j+n:], n, wr, 0, &x)
+						if scale != 1 {
+							Dscal(n-ki, scale, work[ki+n:], 1)
+						}
+						work[j+n] = x[0][0]
+						work[j+1+n] = x[1][0]
+						vmax = math.Max(math.Max(math.Abs(work[j+n]), math.Abs(work[j+1+n])), vmax)
+						vcrit = bignum / vmax
+					}
+				}
+
+				// copy Q⋅x to vl and normalise
+				if ki < n-1 {
+					Dgemv(false, n, n-ki-1, 1, vl[(ki+1)*ldvl:], ldvl, work[ki+1+n:], 1, work[ki+n], vl[ki*ldvl:], 1)
+				}
+				ii := idamax(n, vl[ki*ldvl:], 1)
+				remax := 1 / math.Abs(vl[ii+ki*ldvl])
+				Dscal(n, remax, vl[ki*ldvl:], 1)
+
+			} else {
+
+				// complex left eigenvector; initial solve
+				if math.Abs(T(ki, ki+1)) >= math.Abs(T(ki+1, ki)) {
+					work[ki+n] = wi / T(ki, ki+1)
+					work[ki+1+n2] = 1
+				} else {
+					work[ki+n] = 1
+					work[ki+1+n2] = -wi / T(ki+1, ki)
+				}
+				work[ki+1+n] = 0
+				work[ki+n2] = 0
+				for k := ki + 2; k < n; k++ {
+					work[k+n] = -work[ki+n] * T(ki, k)
+					work[k+n2] = -work[ki+1+n2] * T(ki+1, k)
+				}
+
+				// solve complex quasi-triangular system (T(ki+2:n,ki+2:n) - (wr-i⋅wi))ᵀ⋅X = work1+i⋅work2
+				vmax := 1.0
+				vcrit := bignum
+				jnxt := ki + 2
+				for j := ki + 2; j < n; j++ {
+					if j < jnxt {
+						continue
+					}
+					j1, j2 := j, j
+					jnxt = j + 1
+					if j < n-1 && T(j+1, j) != 0 {
+						j2 = j + 1
+						jnxt = j + 2
+					}
+					if j1 == j2 {
+						if work[j] > vcrit {
+							rec := 1 / vmax
+							Dscal(n-ki, rec, work[ki+n:], 1)
+							Dscal(n-ki, rec, work[ki+n2:], 1)
+							vmax = 1
+							vcrit = bignum
+						}
+						work[j+n] -= Ddot(j-ki-2, t[ki+2+j*ldt:], 1, work[ki+2+n:], 1)
+						work[j+n2] -= Ddot(j-ki-2, t[ki+2+j*ldt:], 1, work[ki+2+n2:], 1)
+						scale, _ := dlaln2(false, 1, 2, smin, 1, t[j+j*ldt:], ldt, 1, 1, work[j+n:], n, wr, -wi, &x)
+						if scale != 1 {
+							Dscal(n-ki, scale, work[ki+n:], 1)
+							Dscal(n-ki, scale, work[ki+n2:], 1)
+						}
+						work[j+n] = x[0][0]
+						work[j+n2] = x[0][1]
+						vmax = math.Max(math.Max(math.Abs(work[j+n]), math.Abs(work[j+n2])), vmax)
+						vcrit = bignum / vmax
+					} else {
+						beta := math.Max(work[j], work[j+1])
+						if beta > vcrit {
+							rec := 1 / vmax
+							Dscal(n-ki, rec, work[ki+n:], 1)
+							Dscal(n-ki, rec, work[ki+n2:], 1)
+							vmax = 1
+							vcrit = bignum
+						}
+						work[j+n] -= Ddot(j-ki-2, t[ki+2+j*ldt:], 1, work[ki+2+n:], 1)
+						work[j+n2] -= Ddot(j-ki-2, t[ki+2+j*ldt:], 1, work[ki+2+n2:], 1)
+						work[j+1+n] -= Ddot(j-ki-2, t[ki+2+(j+1)*ldt:], 1, work[ki+2+n:], 1)
+						work[j+1+n2] -= Ddot(j-ki-2, t[ki+2+(j+1)*ldt:], 1, work[ki+2+n2:], 1)
+						scale, _ := dlaln2(true, 2, 2, smin, 1, t[j+j*ldt:], ldt, 1, 1, work[j+n:], n, wr, -wi, &x)
+						if scale != 1 {
+							Dscal(n-ki, scale, work[ki+n:], 1)
+							Dscal(n-ki, scale, work[ki+n2:], 1)
+						}
+						work[j+n] = x[0][0]
+						work[j+n2] = x[0][1]
+						work[j+1+n] = x[1][0]
+						work[j+1+n2] = x[1][1]
+						vmax = math.Max(math.Max(math.Abs(x[0][0]), math.Abs(x[0][1])), math.Max(math.Max(math.Abs(x[1][0]), math.Abs(x[1][1])), vmax))
+						vcrit = bignum / vmax
+					}
+				}
+
+				// copy Q⋅x to vl and normalise
+				if ki < n-2 {
+					Dgemv(false, n, n-ki-2, 1, vl[(ki+2)*ldvl:], ldvl, work[ki+2+n:], 1, work[ki+n], vl[ki*ldvl:], 1)
+					Dgemv(false, n, n-ki-2, 1, vl[(ki+2)*ldvl:], ldvl, work[ki+2+n2:], 1, work[ki+1+n2], vl[(ki+1)*ldvl:], 1)
+				} else {
+					Dscal(n, work[ki+n], vl[ki*ldvl:], 1)
+					Dscal(n, work[ki+1+n2], vl[(ki+1)*ldvl:], 1)
+				}
+				emax := 0.0
+				for k := 0; k < n; k++ {
+					emax = math.Max(emax, math.Abs(vl[k+ki*ldvl])+math.Abs(vl[k+(ki+1)*ldvl]))
+				}
+				remax := 1 / emax
+				Dscal(n, remax, vl[ki*ldvl:], 1)
+				Dscal(n, remax, vl[(ki+1)*ldvl:], 1)
+			}
+			if ip == 1 {
+				ip = -1
+			}
+		}
+	}
+}
+
+// dlaln2 solves (ca⋅A - w⋅D)⋅X = s⋅B or (ca⋅Aᵀ - w⋅D)⋅X = s⋅B with A 1-by-1 or 2-by-2, D diagonal,
+// w = wr + i⋅wi (nw = 2) or w = wr (nw = 1), and X and B 1-by-1 or 2-by-1 (possibly complex)
+func dlaln2(ltrans bool, na, nw int, smin, ca float64, a []float64, lda int, d1, d2 float64, b []float64, ldb int, wr, wi float64, x *[2][2]float64) (scale, xnorm float64) {
+	zswap := [4]bool{false, false, true, true}
+	rswap := [4]bool{false, true, false, true}
+	ipivot := [4][4]int{{0, 1, 2, 3}, {1, 0, 3, 2}, {2, 3, 0, 1}, {3, 2, 1, 0}} // ipivot[icmax][row]
+
+	smlnum := 2 * dlamchS
+	bignum := 1 / smlnum
+	smini := math.Max(smin, smlnum)
+	scale = 1
+
+	// 1 x 1 (i.e., scalar) system C⋅X = B
+	if na == 1 {
+		if nw == 1 {
+			csr := ca*a[0] - wr*d1
+			cnorm := math.Abs(csr)
+			if cnorm < smini {
+				csr = smini
+				cnorm = smini
+			}
+			bnorm := math.Abs(b[0])
+			if cnorm < 1 && bnorm > 1 {
+				if bnorm > bignum*cnorm {
+					scale = 1 / bnorm
+				}
+			}
+			x[0][0] = (b[0] * scale) / csr
+			xnorm = math.Abs(x[0][0])
+			return
+		}
+		csr := ca*a[0] - wr*d1
+		csi := -wi * d1
+		cnorm := math.Abs(csr) + math.Abs(csi)
+		if cnorm < smini {
+			csr = smini
+			csi = 0
+			cnorm = smini
+		}
+		bnorm := math.Abs(b[0]) + math.Abs(b[ldb])
+		if cnorm < 1 && bnorm > 1 {
+			if bnorm > bignum*cnorm {
+				scale = 1 / bnorm
+			}
+		}
+		x[0][0], x[0][1] = dladiv(scale*b[0], scale*b[ldb], csr, csi)
+		xnorm = math.Abs(x[0][0]) + math.Abs(x[0][1])
+		return
+	}
+
+	// 2x2 system: compute the real part of C = ca⋅A - w⋅D (or ca⋅Aᵀ - w⋅D)
+	var crv, civ [4]float64 // column-major 2x2
+	crv[0] = ca*a[0] - wr*d1
+	crv[3] = ca*a[1+lda] - wr*d2
+	if ltrans {
+		crv[2] = ca * a[1]
+		crv[1] = ca * a[lda]
+	} else {
+		crv[1] = ca * a[1]
+		crv[2] = ca * a[lda]
+	}
+
+	// real 2x2 system (w is real)
+	if nw == 1 {
+		cmax := 0.0
+		icmax := -1
+		for j := 0; j < 4; j++ {
+			if math.Abs(crv[j]) > cmax {
+				cmax = math.Abs(crv[j])
+				icmax = j
+			}
+		}
+
+		// if norm(C) < smini, use smini⋅identity
+		if cmax < smini {
+			bnorm := math.Max(math.Abs(b[0]), math.Abs(b[1]))
+			if smini < 1 && bnorm > 1 {
+				if bnorm > bignum*smini {
+					scale = 1 / bnorm
+				}
+			}
+			temp := scale / smini
+			x[0][0] = temp * b[0]
+			x[1][0] = temp * b[1]
+			xnorm = temp * bnorm
+			return
+		}
+
+		// Gaussian elimination with complete pivoting
+		ur11 := crv[icmax]
+		cr21 := crv[ipivot[icmax][1]]
+		ur12 := crv[ipivot[icmax][2]]
+		cr22 := crv[ipivot[icmax][3]]
+		ur11r := 1 / ur11
+		lr21 := ur11r * cr21
+		ur22 := cr22 - ur12*lr21
+		if math.Abs(ur22) < smini {
+			ur22 = smini
+		}
+		var br1, br2 float64
+		if rswap[icmax] {
+			br1, br2 = b[1], b[0]
+		} else {
+			br1, br2 = b[0], b[1]
+		}
+		br2 -= lr21 * br1
+		bbnd := math.Max(math.Abs(br1*(ur22*ur11r)), math.Abs(br2))
+		if bbnd > 1 && math.Abs(ur22) < 1 {
+			if bbnd >= bignum*math.Abs(ur22) {
+				scale = 1 / bbnd
+			}
+		}
+		xr2 := (br2 * scale) / ur22
+		xr1 := (scale*br1)*ur11r - xr2*(ur11r*ur12)
+		if zswap[icmax] {
+			x[0][0], x[1][0] = xr2, xr1
+		} else {
+			x[0][0], x[1][0] = xr1, xr2
+		}
+		xnorm = math.Max(math.Abs(xr1), math.Abs(xr2))
+
+		// further scaling if norm(A)⋅norm(X) > overflow
+		if xnorm > 1 && cmax > 1 {
+			if xnorm > bignum/cmax {
+				temp := cmax / bignum
+				x[0][0] *= temp
+				x[1][0] *= temp
+				xnorm *= temp
+				scale *= temp
+			}
+		}
+		return
+	}
+
+	// complex 2x2 system (w is complex)
+	civ[0] = -wi * d1
+	civ[1] = 0
+	civ[2] = 0
+	civ[3] = -wi * d2
+	cmax := 0.0
+	icmax := -1
+	for j := 0; j < 4; j++ {
+		if math.Abs(crv[j])+math.Abs(civ[j]) > cmax {
+			cmax = math.Abs(crv[j]) + math.Abs(civ[j])
+			icmax = j
+		}
+	}
+
+	// if norm(C) < smini, use smini⋅identity
+	if cmax < smini {
+		bnorm := math.Max(math.Abs(b[0])+math.Abs(b[ldb]), math.Abs(b[1])+math.Abs(b[1+ldb]))
+		if smini < 1 && bnorm > 1 {
+			if bnorm > bignum*smini {
+				scale = 1 / bnorm
+			}
+		}
+		temp := scale / smini
+		x[0][0] = temp * b[0]
+		x[1][0] = temp * b[1]
+		x[0][1] = temp * b[ldb]
+		x[1][1] = temp * b[1+ldb]
+		xnorm = temp * bnorm
+		return
+	}
+
+	// Gaussian elimination with complete pivoting
+	ur11 := crv[icmax]
+	ui11 := civ[icmax]
+	cr21 := crv[ipivot[icmax][1]]
+	ci21 := civ[ipivot[icmax][1]]
+	ur12 := crv[ipivot[icmax][2]]
+	ui12 := civ[ipivot[icmax][2]]
+	cr22 := crv[ipivot[icmax][3]]
+	ci22 := civ[ipivot[icmax][3]]
+	var ur11r, ui11r, lr21, li21, ur12s, ui12s, ur22, ui22 float64
+	if icmax == 0 || icmax == 3 {
+		// code when off-diagonals of pivoted C are real
+		if math.Abs(ur11) > math.Abs(ui11) {
+			temp := ui11 / ur11
+			ur11r = 1 / (ur11 * (1 + temp*temp))
+			ui11r = -temp * ur11r
+		} else {
+			temp := ur11 / ui11
+			ui11r = -1 / (ui11 * (1 + temp*temp))
+			ur11r = -temp * ui11r
+		}
+		lr21 = cr21 * ur11r
+		li21 = cr21 * ui11r
+		ur12s = ur12 * ur11r
+		ui12s = ur12 * ui11r
+		ur22 = cr22 - ur12*lr21
+		ui22 = ci22 - ur12*li21
+	} else {
+		// code when diagonals of pivoted C are real
+		ur11r = 1 / ur11
+		ui11r = 0
+		lr21 = cr21 * ur11r
+		li21 = ci21 * ur11r
+		ur12s = ur12 * ur11r
+		ui12s = ui12 * ur11r
+		ur22 = cr22 - ur12*lr21 + ui12*li21
+		ui22 = -ur12*li21 - ui12*lr21
+	}
+	u22abs := math.Abs(ur22) + math.Abs(ui22)
+	if u22abs < smini {
+		ur22 = smini
+		ui22 = 0
+	}
+	var br1, br2, bi1, bi2 float64
+	if rswap[icmax] {
+		br2, br1 = b[0], b[1]
+		bi2, bi1 = b[ldb], b[1+ldb]
+	} else {
+		br1, br2 = b[0], b[1]
+		bi1, bi2 = b[ldb], b[1+ldb]
+	}
+	br2 = br2 - lr21*br1 + li21*bi1
+	bi2 = bi2 - li21*br1 - lr21*bi1
+	bbnd := math.Max((math.Abs(br1)+math.Abs(bi1))*(u22abs*(math.Abs(ur11r)+math.Abs(ui11r))), math.Abs(br2)+math.Abs(bi2))
+	if bbnd > 1 && u22abs < 1 {
+		if bbnd >= bignum*u22abs {
+			scale = 1 / bbnd
+			br1 *= scale
+			bi1 *= scale
+			br2 *= scale
+			bi2 *= scale
+		}
+	}
+	xr2, xi2 := dladiv(br2, bi2, ur22, ui22)
+	xr1 := ur11r*br1 - ui11r*bi1 - ur12s*xr2 + ui12s*xi2
+	xi1 := ui11r*br1 + ur11r*bi1 - ui12s*xr2 - ur12s*xi2
+	if zswap[icmax] {
+		x[0][0], x[1][0], x[0][1], x[1][1] = xr2, xr1, xi2, xi1
+	} else {
+		x[0][0], x[1][0], x[0][1], x[1][1] = xr1, xr2, xi1, xi2
+	}
+	xnorm = math.Max(math.Abs(xr1)+math.Abs(xi1), math.Abs(xr2)+math.Abs(xi2))
+
+	// further scaling if norm(A)⋅norm(X) > overflow
+	if xnorm > 1 && cmax > 1 {
+		if xnorm > bignum/cmax {
+			temp := cmax / bignum
+			x[0][0] *= temp
+			x[1][0] *= temp
+			x[0][1] *= temp
+			x[1][1] *= temp
+			xnorm *= temp
+			scale *= temp
+		}
+	}
+	return
+}
+
+// dladiv performs the complex division (a + i⋅b) / (c + i⋅d) = p + i⋅q robustly
+func dladiv(a, b, c, d float64) (p, q float64) {
+	const bs = 2.0
+	aa, bb, cc, dd := a, b, c, d
+	ab := math.Max(math.Abs(a), math.Abs(b))
+	cd := math.Max(math.Abs(c), math.Abs(d))
+	s := 1.0
+	ov := math.MaxFloat64
+	un := dlamchS
+	eps := dlamchE
+	be := bs / (eps * eps)
+	if ab >= 0.5*ov {
+		aa *= 0.5
+		bb *= 0.5
+		s *= 2
+	}
+	if cd >= 0.5*ov {
+		cc *= 0.5
+		dd *= 0.5
+		s *= 0.5
+	}
+	if ab <= un*bs/eps {
+		aa *= be
+		bb *= be
+		s /= be
+	}
+	if cd <= un*bs/eps {
+		cc *= be
+		dd *= be
+		s *= be
+	}
+	if math.Abs(d) <= math.Abs(c) {
+		p, q = dladiv1(aa, bb, cc, dd)
+	} else {
+		p, q = dladiv1(bb, aa, dd, cc)
+		q = -q
+	}
+	return p * s, q * s
+}
+
+func dladiv1(a, b, c, d float64) (p, q float64) {
+	r := d / c
+	t := 1 / (c + d*r)
+	p = dladiv2(a, b, c, d, r, t)
+	q = dladiv2(b, -a, c, d, r, t)
+	return
+}
+
+func dladiv2(a, b, c, d, r, t float64) float64 {
+	if r != 0 {
+		br := b * r
+		if br != 0 {
+			return (a + br) * t
+		}
+		return a*t + (b*t)*r
+	}
+	return (a + d*(b/c)) * t
+}
@@ -0,0 +1,868 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !cgo purego
+
+package oblas
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// This file contains pure-Go ports of the (unblocked) reference LAPACK routines for complex
+// matrices. See lapack_purego.go for the storage conventions.
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// cabs1 returns |Re(z)| + |Im(z)|
+func cabs1(z complex128) float64 {
+	return math.Abs(real(z)) + math.Abs(imag(z))
+}
+
+// izamax finds the (0-based) index of the first element having maximum |Re|+|Im|
+func izamax(n int, x []complex128, incx int) (idx int) {
+	if n < 1 || incx <= 0 {
+		return -1
+	}
+	dmax := cabs1(x[0])
+	for i, ix := 1, incx; i < n; i, ix = i+1, ix+incx {
+		if cabs1(x[ix]) > dmax {
+			idx = i
+			dmax = cabs1(x[ix])
+		}
+	}
+	return
+}
+
+// dznrm2 returns the euclidean norm of a complex vector (scaled to avoid overflow)
+func dznrm2(n int, x []complex128, incx int) float64 {
+	if n < 1 || incx < 1 {
+		return 0
+	}
+	scale, ssq := 0.0, 1.0
+	for i, ix := 0, 0; i < n; i, ix = i+1, ix+incx {
+		for _, v := range [2]float64{real(x[ix]), imag(x[ix])} {
+			if v != 0 {
+				temp := math.Abs(v)
+				if scale < temp {
+					ssq = 1 + ssq*(scale/temp)*(scale/temp)
+					scale = temp
+				} else {
+					ssq += (temp / scale) * (temp / scale)
+				}
+			}
+		}
+	}
+	return scale * math.Sqrt(ssq)
+}
+
+// dlapy3 returns sqrt(x²+y²+z²), taking care not to cause unnecessary overflow
+func dlapy3(x, y, z float64) float64 {
+	xabs, yabs, zabs := math.Abs(x), math.Abs(y), math.Abs(z)
+	w := math.Max(xabs, math.Max(yabs, zabs))
+	if w == 0 {
+		return xabs + yabs + zabs
+	}
+	return w * math.Sqrt((xabs/w)*(xabs/w)+(yabs/w)*(yabs/w)+(zabs/w)*(zabs/w))
+}
+
+// zscal scales a complex vector by a constant
+func zscal(n int, alpha complex128, x []complex128, incx int) {
+	for i, ix := 0, 0; i < n; i, ix = i+1, ix+incx {
+		x[ix] *= alpha
+	}
+}
+
+// zswap interchanges two complex vectors
+func zswap(n int, x []complex128, incx int, y []complex128, incy int) {
+	for i, ix, iy := 0, 0, 0; i < n; i, ix, iy = i+1, ix+incx, iy+incy {
+		x[ix], y[iy] = y[iy], x[ix]
+	}
+}
+
+// zdotc returns xᴴ⋅y
+func zdotc(n int, x []complex128, incx int, y []complex128, incy int) (res complex128) {
+	for i, ix, iy := 0, 0, 0; i < n; i, ix, iy = i+1, ix+incx, iy+incy {
+		res += conj(x[ix]) * y[iy]
+	}
+	return
+}
+
+// zdrot applies a real plane rotation to the complex vectors x and y
+func zdrot(n int, x []complex128, incx int, y []complex128, incy int, c, s float64) {
+	cc, ss := complex(c, 0), complex(s, 0)
+	for i, ix, iy := 0, 0, 0; i < n; i, ix, iy = i+1, ix+incx, iy+incy {
+		temp := cc*x[ix] + ss*y[iy]
+		y[iy] = cc*y[iy] - ss*x[ix]
+		x[ix] = temp
+	}
+}
+
+// zlacgv conjugates a complex vector
+func zlacgv(n int, x []complex128, incx int) {
+	for i, ix := 0, 0; i < n; i, ix = i+1, ix+incx {
+		x[ix] = conj(x[ix])
+	}
+}
+
+// zlange returns the largest absolute value of the elements of an m-by-n complex matrix
+func zlange(m, n int, a []complex128, lda int) (res float64) {
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			v := cmplx.Abs(a[i+j*lda])
+			if v > res || math.IsNaN(v) {
+				res = v
+			}
+		}
+	}
+	return
+}
+
+// zlascl multiplies the m-by-n complex matrix A by cto/cfrom without over/underflow
+func zlascl(cfrom, cto float64, m, n int, a []complex128, lda int) {
+	smlnum := dlamchS
+	bignum := 1 / smlnum
+	cfromc, ctoc := cfrom, cto
+	for done := false; !done; {
+		var mul float64
+		cfrom1 := cfromc * smlnum
+		if cfrom1 == cfromc {
+			mul = ctoc / cfromc
+			done = true
+		} else {
+			cto1 := ctoc / bignum
+			if cto1 == ctoc {
+				mul = ctoc
+				done = true
+				cfromc = 1
+			} else if math.Abs(cfrom1) > math.Abs(ctoc) && ctoc != 0 {
+				mul = smlnum
+				cfromc = cfrom1
+			} else if math.Abs(cto1) > math.Abs(cfromc) {
+				mul = bignum
+				ctoc = cto1
+			} else {
+				mul = ctoc / cfromc
+				done = true
+			}
+		}
+		for j := 0; j < n; j++ {
+			for i := 0; i < m; i++ {
+				a[i+j*lda] *= complex(mul, 0)
+			}
+		}
+	}
+}
+
+// zlacpy copies all (uplo=0), the upper (uplo='U') or the lower (uplo='L') part of A into B
+func zlacpy(uplo byte, m, n int, a []complex128, lda int, b []complex128, ldb int) {
+	for j := 0; j < n; j++ {
+		i0, i1 := 0, m
+		switch uplo {
+		case 'U':
+			i1 = imin(j+1, m)
+		case 'L':
+			i0 = imin(j, m)
+		}
+		for i := i0; i < i1; i++ {
+			b[i+j*ldb] = a[i+j*lda]
+		}
+	}
+}
+
+// LU factorisation ////////////////////////////////////////////////////////////////////////////////
+
+// zgetf2 computes the LU factorisation of an m-by-n complex matrix using partial pivoting
+func zgetf2(m, n int, a []complex128, lda int, ipiv []int32) (info int) {
+	mn := imin(m, n)
+	for j := 0; j < mn; j++ {
+		jp := j + izamax(m-j, a[j+j*lda:], 1)
+		ipiv[j] = int32(jp + 1)
+		if a[jp+j*lda] != 0 {
+			if jp != j {
+				zswap(n, a[j:], lda, a[jp:], lda)
+			}
+			if j < m-1 {
+				if cmplx.Abs(a[j+j*lda]) >= dlamchS {
+					zscal(m-j-1, 1/a[j+j*lda], a[j+1+j*lda:], 1)
+				} else {
+					for i := 0; i < m-j-1; i++ {
+						a[j+1+i+j*lda] /= a[j+j*lda]
+					}
+				}
+			}
+		} else if info == 0 {
+			info = j + 1
+		}
+		if j < mn-1 {
+			for l := j + 1; l < n; l++ {
+				if temp := -a[j+l*lda]; temp != 0 {
+					for i := j + 1; i < m; i++ {
+						a[i+l*lda] += a[i+j*lda] * temp
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// zgetrs solves A⋅X = B using the LU factorisation computed by zgetf2
+func zgetrs(n, nrhs int, a []complex128, lda int, ipiv []int32, b []complex128, ldb int) {
+	for k := 0; k < nrhs; k++ {
+		x := b[k*ldb:]
+		for i := 0; i < n; i++ {
+			if ip := int(ipiv[i]) - 1; ip != i {
+				x[i], x[ip] = x[ip], x[i]
+			}
+		}
+		for j := 0; j < n; j++ {
+			if x[j] != 0 {
+				for i := j + 1; i < n; i++ {
+					x[i] -= x[j] * a[i+j*lda]
+				}
+			}
+		}
+		for j := n - 1; j >= 0; j-- {
+			if x[j] != 0 {
+				x[j] /= a[j+j*lda]
+				for i := 0; i < j; i++ {
+					x[i] -= x[j] * a[i+j*lda]
+				}
+			}
+		}
+	}
+}
+
+// zgetri computes the inverse of a complex matrix using the LU factorisation computed by zgetf2
+func zgetri(n int, a []complex128, lda int, ipiv []int32) (info int) {
+
+	// check for singularity
+	for j := 0; j < n; j++ {
+		if a[j+j*lda] == 0 {
+			return j + 1
+		}
+	}
+
+	// compute inv(U) (ztrti2)
+	for j := 0; j < n; j++ {
+		a[j+j*lda] = 1 / a[j+j*lda]
+		ajj := -a[j+j*lda]
+		x := a[j*lda:]
+		for k := 0; k < j; k++ {
+			if x[k] != 0 {
+				temp := x[k]
+				for i := 0; i < k; i++ {
+					x[i] += temp * a[i+k*lda]
+				}
+				x[k] *= a[k+k*lda]
+			}
+		}
+		zscal(j, ajj, x, 1)
+	}
+
+	// solve inv(A)⋅L = inv(U) for inv(A)
+	work := make([]complex128, n)
+	for j := n - 1; j >= 0; j-- {
+		for i := j + 1; i < n; i++ {
+			work[i] = a[i+j*lda]
+			a[i+j*lda] = 0
+		}
+		if j < n-1 {
+			Zgemv(false, n, n-j-1, -1, a[(j+1)*lda:], lda, work[j+1:], 1, 1, a[j*lda:], 1)
+		}
+	}
+
+	// apply column interchanges
+	for j := n - 2; j >= 0; j-- {
+		if jp := int(ipiv[j]) - 1; jp != j {
+			zswap(n, a[j*lda:], 1, a[jp*lda:], 1)
+		}
+	}
+	return
+}
+
+// Cholesky factorisation //////////////////////////////////////////////////////////////////////////
+
+// zpotf2 computes the Cholesky factorisation of a Hermitian positive definite matrix
+func zpotf2(up bool, n int, a []complex128, lda int) (info int) {
+	for j := 0; j < n; j++ {
+		var ajj float64
+		if up {
+			ajj = real(a[j+j*lda]) - real(zdotc(j, a[j*lda:], 1, a[j*lda:], 1))
+		} else {
+			ajj = real(a[j+j*lda]) - real(zdotc(j, a[j:], lda, a[j:], lda))
+		}
+		if ajj <= 0 || math.IsNaN(ajj) {
+			a[j+j*lda] = complex(ajj, 0)
+			return j + 1
+		}
+		ajj = math.Sqrt(ajj)
+		a[j+j*lda] = complex(ajj, 0)
+		if j < n-1 {
+			if up {
+				// A(j,j+1:n) -= A(0:j,j)ᴴ⋅A(0:j,j+1:n)
+				for l := j + 1; l < n; l++ {
+					a[j+l*lda] = (a[j+l*lda] - zdotc(j, a[j*lda:], 1, a[l*lda:], 1)) / complex(ajj, 0)
+				}
+			} else {
+				// A(j+1:n,j) -= A(j+1:n,0:j)⋅A(j,0:j)ᴴ
+				for i := j + 1; i < n; i++ {
+					var sum complex128
+					for k := 0; k < j; k++ {
+						sum += a[i+k*lda] * conj(a[j+k*lda])
+					}
+					a[i+j*lda] = (a[i+j*lda] - sum) / complex(ajj, 0)
+				}
+			}
+		}
+	}
+	return
+}
+
+// Householder reflectors //////////////////////////////////////////////////////////////////////////
+
+// zlarfg generates an elementary reflector H such that Hᴴ⋅(alpha,x) = (beta,0) with beta real.
+// x is overwritten with v (v[0] = 1 is not stored)
+func zlarfg(n int, alpha complex128, x []complex128, incx int) (beta, tau complex128) {
+	if n <= 0 {
+		return alpha, 0
+	}
+	xnorm := dznrm2(n-1, x, incx)
+	alphr, alphi := real(alpha), imag(alpha)
+	if xnorm == 0 && alphi == 0 {
+		return alpha, 0
+	}
+	b := -math.Copysign(dlapy3(alphr, alphi, xnorm), alphr)
+	safmin := dlamchS / dlamchE
+	rsafmn := 1 / safmin
+	knt := 0
+	if math.Abs(b) < safmin {
+		for {
+			knt++
+			zscal(n-1, complex(rsafmn, 0), x, incx)
+			b *= rsafmn
+			alphi *= rsafmn
+			alphr *= rsafmn
+			if math.Abs(b) >= safmin || knt >= 20 {
+				break
+			}
+		}
+		xnorm = dznrm2(n-1, x, incx)
+		alpha = complex(alphr, alphi)
+		b = -math.Copysign(dlapy3(alphr, alphi, xnorm), alphr)
+	}
+	tau = complex((b-alphr)/b, -alphi/b)
+	zscal(n-1, 1/(alpha-complex(b, 0)), x, incx)
+	for j := 0; j < knt; j++ {
+		b *= safmin
+	}
+	return complex(b, 0), tau
+}
+
+// zlarf applies H = I - tau⋅v⋅vᴴ to C from the left (H⋅C) or from the right (C⋅H)
+func zlarf(left bool, m, n int, v []complex128, incv int, tau complex128, c []complex128, ldc int, work []complex128) {
+	if tau == 0 {
+		return
+	}
+	if left {
+		for j := 0; j < n; j++ {
+			var sum complex128
+			for i, iv := 0, 0; i < m; i, iv = i+1, iv+incv {
+				sum += conj(c[i+j*ldc]) * v[iv]
+			}
+			work[j] = sum
+		}
+		for j := 0; j < n; j++ {
+			temp := -tau * conj(work[j])
+			for i, iv := 0, 0; i < m; i, iv = i+1, iv+incv {
+				c[i+j*ldc] += v[iv] * temp
+			}
+		}
+		return
+	}
+	for i := 0; i < m; i++ {
+		work[i] = 0
+	}
+	for j, jv := 0, 0; j < n; j, jv = j+1, jv+incv {
+		for i := 0; i < m; i++ {
+			work[i] += c[i+j*ldc] * v[jv]
+		}
+	}
+	for j, jv := 0, 0; j < n; j, jv = j+1, jv+incv {
+		temp := -tau * conj(v[jv])
+		for i := 0; i < m; i++ {
+			c[i+j*ldc] += work[i] * temp
+		}
+	}
+}
+
+// zgeqr2 computes the QR factorisation of a complex m-by-n matrix
+func zgeqr2(m, n int, a []complex128, lda int, tau, work []complex128) {
+	k := imin(m, n)
+	for i := 0; i < k; i++ {
+		beta, t := zlarfg(m-i, a[i+i*lda], a[imin(i+1, m-1)+i*lda:], 1)
+		tau[i] = t
+		if i < n-1 {
+			a[i+i*lda] = 1
+			zlarf(true, m-i, n-i-1, a[i+i*lda:], 1, conj(t), a[i+(i+1)*lda:], lda, work)
+		}
+		a[i+i*lda] = beta
+	}
+}
+
+// zgelq2 computes the LQ factorisation of a complex m-by-n matrix
+func zgelq2(m, n int, a []complex128, lda int, tau, work []complex128) {
+	k := imin(m, n)
+	for i := 0; i < k; i++ {
+		zlacgv(n-i, a[i+i*lda:], lda)
+		beta, t := zlarfg(n-i, a[i+i*lda], a[i+imin(i+1, n-1)*lda:], lda)
+		tau[i] = t
+		if i < m-1 {
+			a[i+i*lda] = 1
+			zlarf(false, m-i-1, n-i, a[i+i*lda:], lda, t, a[i+1+i*lda:], lda, work)
+		}
+		a[i+i*lda] = beta
+		zlacgv(n-i, a[i+i*lda:], lda)
+	}
+}
+
+// zung2r generates the m-by-n matrix Q with orthonormal columns defined by k reflectors (from zgeqr2)
+func zung2r(m, n, k int, a []complex128, lda int, tau, work []complex128) {
+	if n <= 0 {
+		return
+	}
+	for j := k; j < n; j++ {
+		for l := 0; l < m; l++ {
+			a[l+j*lda] = 0
+		}
+		a[j+j*lda] = 1
+	}
+	for i := k - 1; i >= 0; i-- {
+		if i < n-1 {
+			a[i+i*lda] = 1
+			zlarf(true, m-i, n-i-1, a[i+i*lda:], 1, tau[i], a[i+(i+1)*lda:], lda, work)
+		}
+		if i < m-1 {
+			zscal(m-i-1, -tau[i], a[i+1+i*lda:], 1)
+		}
+		a[i+i*lda] = 1 - tau[i]
+		for l := 0; l < i; l++ {
+			a[l+i*lda] = 0
+		}
+	}
+}
+
+// zungl2 generates the m-by-n matrix Q with orthonormal rows defined by k reflectors (from zgelq2)
+func zungl2(m, n, k int, a []complex128, lda int, tau, work []complex128) {
+	if m <= 0 {
+		return
+	}
+	if k < m {
+		for j := 0; j < n; j++ {
+			for l := k; l < m; l++ {
+				a[l+j*lda] = 0
+			}
+			if j >= k && j < m {
+				a[j+j*lda] = 1
+			}
+		}
+	}
+	for i := k - 1; i >= 0; i-- {
+		if i < n-1 {
+			zlacgv(n-i-1, a[i+(i+1)*lda:], lda)
+			if i < m-1 {
+				a[i+i*lda] = 1
+				zlarf(false, m-i-1, n-i, a[i+i*lda:], lda, conj(tau[i]), a[i+1+i*lda:], lda, work)
+			}
+			zscal(n-i-1, -tau[i], a[i+(i+1)*lda:], lda)
+			zlacgv(n-i-1, a[i+(i+1)*lda:], lda)
+		}
+		a[i+i*lda] = 1 - conj(tau[i])
+		for l := 0; l < i; l++ {
+			a[i+l*lda] = 0
+		}
+	}
+}
+
+// Hermitian eigenproblem //////////////////////////////////////////////////////////////////////////
+
+// zheev computes all eigenvalues (ascending) and, optionally, the eigenvectors of a Hermitian
+// matrix using the Householder reduction to real tridiagonal form Qᴴ⋅A⋅Q = T followed by the
+// implicit QL method (dsteql) with the rotations accumulated into Q
+func zheev(calcV, up bool, n int, a []complex128, lda int, w []float64) (info int) {
+	if n == 0 {
+		return
+	}
+
+	// fill the other triangle
+	for j := 0; j < n; j++ {
+		a[j+j*lda] = complex(real(a[j+j*lda]), 0)
+		for i := j + 1; i < n; i++ {
+			if up {
+				a[i+j*lda] = conj(a[j+i*lda])
+			} else {
+				a[j+i*lda] = conj(a[i+j*lda])
+			}
+		}
+	}
+
+	// Householder reduction: H(k)ᴴ⋅A⋅H(k) annihilates A(k+2:n,k) and makes A(k+1,k) real
+	e := make([]float64, n)
+	tau := make([]complex128, n)
+	work := make([]complex128, n)
+	for k := 0; k < n-1; k++ {
+		m := n - k - 1
+		beta, t := zlarfg(m, a[k+1+k*lda], a[imin(k+2, n-1)+k*lda:], 1)
+		tau[k] = t
+		e[k] = real(beta)
+		if t != 0 {
+			v := a[k+1+k*lda:]
+			v[0] = 1
+			b := a[k+1+(k+1)*lda:]
+
+			// x := tau⋅B⋅v and w := x - ½⋅tau⋅(xᴴ⋅v)⋅v
+			for i := 0; i < m; i++ {
+				var sum complex128
+				for j := 0; j < m; j++ {
+					sum += b[i+j*lda] * v[j]
+				}
+				work[i] = t * sum
+			}
+			alpha := -0.5 * t * zdotc(m, work, 1, v, 1)
+			for i := 0; i < m; i++ {
+				work[i] += alpha * v[i]
+			}
+
+			// B := B - v⋅wᴴ - w⋅vᴴ
+			for j := 0; j < m; j++ {
+				for i := 0; i < m; i++ {
+					b[i+j*lda] -= v[i]*conj(work[j]) + work[i]*conj(v[j])
+				}
+			}
+		}
+		w[k] = real(a[k+k*lda])
+	}
+	w[n-1] = real(a[n-1+(n-1)*lda])
+
+	// eigenvalues only
+	if !calcV {
+		noop := func(int, float64, float64) {}
+		return dsteql(n, w, e, noop, func(int, int) {})
+	}
+
+	// generate Q = H(0)⋅H(1)⋯H(n-2) by backward accumulation
+	z := make([]complex128, n*n)
+	for i := 0; i < n; i++ {
+		z[i+i*n] = 1
+	}
+	for k := n - 2; k >= 0; k-- {
+		m := n - k - 1
+		a[k+1+k*lda] = 1
+		zlarf(true, m, m, a[k+1+k*lda:], 1, tau[k], z[k+1+(k+1)*n:], n, work)
+	}
+
+	// QL iterations and eigenvectors
+	rot := func(i int, c, s float64) { zdrot(n, z[(i+1)*n:], 1, z[i*n:], 1, c, s) }
+	swap := func(i, k int) { zswap(n, z[i*n:], 1, z[k*n:], 1) }
+	info = dsteql(n, w, e, rot, swap)
+	zlacpy(0, n, n, z, n, a, lda)
+	return
+}
+
+// bidiagonalisation and SVD ///////////////////////////////////////////////////////////////////////
+
+// zgebd2 reduces a complex m-by-n matrix to real upper (m ≥ n) or lower (m < n) bidiagonal form
+func zgebd2(m, n int, a []complex128, lda int, d, e []float64, tauq, taup, work []complex128) {
+	if m >= n {
+		for i := 0; i < n; i++ {
+			beta, tq := zlarfg(m-i, a[i+i*lda], a[imin(i+1, m-1)+i*lda:], 1)
+			d[i], tauq[i] = real(beta), tq
+			a[i+i*lda] = 1
+			if i < n-1 {
+				zlarf(true, m-i, n-i-1, a[i+i*lda:], 1, conj(tq), a[i+(i+1)*lda:], lda, work)
+			}
+			a[i+i*lda] = complex(d[i], 0)
+			if i < n-1 {
+				zlacgv(n-i-1, a[i+(i+1)*lda:], lda)
+				beta, tp := zlarfg(n-i-1, a[i+(i+1)*lda], a[i+imin(i+2, n-1)*lda:], lda)
+				e[i], taup[i] = real(beta), tp
+				a[i+(i+1)*lda] = 1
+				zlarf(false, m-i-1, n-i-1, a[i+(i+1)*lda:], lda, tp, a[i+1+(i+1)*lda:], lda, work)
+				zlacgv(n-i-1, a[i+(i+1)*lda:], lda)
+				a[i+(i+1)*lda] = complex(e[i], 0)
+			} else {
+				taup[i] = 0
+			}
+		}
+		return
+	}
+	for i := 0; i < m; i++ {
+		zlacgv(n-i, a[i+i*lda:], lda)
+		beta, tp := zlarfg(n-i, a[i+i*lda], a[i+imin(i+1, n-1)*lda:], lda)
+		d[i], taup[i] = real(beta), tp
+		a[i+i*lda] = 1
+		if i < m-1 {
+			zlarf(false, m-i-1, n-i, a[i+i*lda:], lda, tp, a[i+1+i*lda:], lda, work)
+		}
+		zlacgv(n-i, a[i+i*lda:], lda)
+		a[i+i*lda] = complex(d[i], 0)
+		if i < m-1 {
+			beta, tq := zlarfg(m-i-1, a[i+1+i*lda], a[imin(i+2, m-1)+i*lda:], 1)
+			e[i], tauq[i] = real(beta), tq
+			a[i+1+i*lda] = 1
+			zlarf(true, m-i-1, n-i-1, a[i+1+i*lda:], 1, conj(tq), a[i+1+(i+1)*lda:], lda, work)
+			a[i+1+i*lda] = complex(e[i], 0)
+		} else {
+			tauq[i] = 0
+		}
+	}
+}
+
+// zungbr generates Q (vect='Q') or Pᴴ (vect='P') as determined by zgebd2
+func zungbr(vect byte, m, n, k int, a []complex128, lda int, tau, work []complex128) {
+	if m == 0 || n == 0 {
+		return
+	}
+	if vect == 'Q' {
+		if m >= k {
+			zung2r(m, n, k, a, lda, tau, work)
+			return
+		}
+		for j := m - 1; j >= 1; j-- {
+			a[j*lda] = 0
+			for i := j + 1; i < m; i++ {
+				a[i+j*lda] = a[i+(j-1)*lda]
+			}
+		}
+		a[0] = 1
+		for i := 1; i < m; i++ {
+			a[i] = 0
+		}
+		if m > 1 {
+			zung2r(m-1, m-1, m-1, a[1+lda:], lda, tau, work)
+		}
+		return
+	}
+	if k < n {
+		zungl2(m, n, k, a, lda, tau, work)
+		return
+	}
+	a[0] = 1
+	for i := 1; i < n; i++ {
+		a[i] = 0
+	}
+	for j := 1; j < n; j++ {
+		for i := j - 1; i >= 1; i-- {
+			a[i+j*lda] = a[i-1+j*lda]
+		}
+		a[j*lda] = 0
+	}
+	if n > 1 {
+		zungl2(n-1, n-1, n-1, a[1+lda:], lda, tau, work)
+	}
+}
+
+// bdsqrComplex implements bdsqrVectors for complex matrices
+type bdsqrComplex struct {
+	ncvt, nru int          // number of columns in VT and number of rows in U
+	vt        []complex128 // right singular vectors
+	ldvt      int          // leading dimension of vt
+	u         []complex128 // left singular vectors
+	ldu       int          // leading dimension of u
+}
+
+func (o *bdsqrComplex) rotVt(i, j int, c, s float64) {
+	if o.ncvt == 0 {
+		return
+	}
+	zdrot(o.ncvt, o.vt[i:], o.ldvt, o.vt[j:], o.ldvt, c, s)
+}
+
+func (o *bdsqrComplex) rotU(i, j int, c, s float64) {
+	if o.nru == 0 {
+		return
+	}
+	zdrot(o.nru, o.u[i*o.ldu:], 1, o.u[j*o.ldu:], 1, c, s)
+}
+
+func (o *bdsqrComplex) negVt(i int) {
+	if o.ncvt == 0 {
+		return
+	}
+	zscal(o.ncvt, -1, o.vt[i:], o.ldvt)
+}
+
+func (o *bdsqrComplex) swap(i, j int) {
+	if o.ncvt > 0 {
+		zswap(o.ncvt, o.vt[i:], o.ldvt, o.vt[j:], o.ldvt)
+	}
+	if o.nru > 0 {
+		zswap(o.nru, o.u[i*o.ldu:], 1, o.u[j*o.ldu:], 1)
+	}
+}
+
+// zgesvd computes the singular value decomposition of a complex m-by-n matrix
+func zgesvd(jobu, jobvt byte, m, n int, a []complex128, lda int, s []float64, u []complex128, ldu int, vt []complex128, ldvt int, superb []float64) (info int) {
+
+	// quick return
+	if m == 0 || n == 0 {
+		return
+	}
+	wntua, wntus, wntuo := jobu == 'A', jobu == 'S', jobu == 'O'
+	wntva, wntvs, wntvo := jobvt == 'A', jobvt == 'S', jobvt == 'O'
+	wntuas, wntvas := wntua || wntus, wntva || wntvs
+	minmn := imin(m, n)
+	mnthr := int(float64(minmn) * 1.6)
+
+	// scale A if max element outside range [smlnum,bignum]
+	smlnum := math.Sqrt(dlamchS) / dlamchP
+	bignum := 1 / smlnum
+	anrm := zlange(m, n, a, lda)
+	iscl := false
+	if anrm > 0 && anrm < smlnum {
+		iscl = true
+		zlascl(anrm, smlnum, m, n, a, lda)
+	} else if anrm > bignum {
+		iscl = true
+		zlascl(anrm, bignum, m, n, a, lda)
+	}
+
+	// workspace
+	e := make([]float64, minmn)
+	tauq := make([]complex128, minmn)
+	taup := make([]complex128, minmn)
+	work := make([]complex128, imax(m, n))
+
+	// singular vectors
+	var vecs *bdsqrComplex
+	lower := m < n
+	if m >= n {
+
+		// path with QR decomposition first
+		if m >= mnthr && wntuas && wntvas {
+			ncu := n
+			if wntua {
+				ncu = m
+			}
+			tau := make([]complex128, n)
+			zgeqr2(m, n, a, lda, tau, work)
+			zlacpy('L', m, n, a, lda, u, ldu)
+			zung2r(m, ncu, n, u, ldu, tau, work)
+			r := make([]complex128, n*n)
+			zlacpy('U', n, n, a, lda, r, n)
+			zgebd2(n, n, r, n, s, e, tauq, taup, work)
+			zlacpy('U', n, n, r, n, vt, ldvt)
+			zungbr('Q', n, n, n, r, n, tauq, work)
+			zungbr('P', n, n, n, vt, ldvt, taup, work)
+			vecs = &bdsqrComplex{n, n, vt, ldvt, r, n}
+			info = bdsqr(false, n, s, e, vecs)
+			Zgemm(false, false, m, n, n, 1, u, ldu, r, n, 0, a, lda)
+			zlacpy(0, m, n, a, lda, u, ldu)
+
+		} else {
+
+			// reduce to bidiagonal form without QR decomposition
+			zgebd2(m, n, a, lda, s, e, tauq, taup, work)
+			vecs = &bdsqrComplex{vt: vt, ldvt: ldvt, u: u, ldu: ldu}
+			if wntuas {
+				ncu := n
+				if wntua {
+					ncu = m
+				}
+				zlacpy('L', m, n, a, lda, u, ldu)
+				zungbr('Q', m, ncu, n, u, ldu, tauq, work)
+			}
+			if wntvas {
+				zlacpy('U', n, n, a, lda, vt, ldvt)
+				zungbr('P', n, n, n, vt, ldvt, taup, work)
+			}
+			if wntuo {
+				zungbr('Q', m, n, n, a, lda, tauq, work)
+				vecs.u, vecs.ldu = a, lda
+			}
+			if wntvo {
+				zungbr('P', n, n, n, a, lda, taup, work)
+				vecs.vt, vecs.ldvt = a, lda
+			}
+			if wntuas || wntuo {
+				vecs.nru = m
+			}
+			if wntvas || wntvo {
+				vecs.ncvt = n
+			}
+			info = bdsqr(false, n, s, e, vecs)
+		}
+
+	} else {
+
+		// path with LQ decomposition first
+		if n >= mnthr && wntvas && wntuas {
+			nrvt := m
+			if wntva {
+				nrvt = n
+			}
+			tau := make([]complex128, m)
+			zgelq2(m, n, a, lda, tau, work)
+			zlacpy('U', m, n, a, lda, vt, ldvt)
+			zungl2(nrvt, n, m, vt, ldvt, tau, work)
+			l := make([]complex128, m*m)
+			zlacpy('L', m, m, a, lda, l, m)
+			zgebd2(m, m, l, m, s, e, tauq, taup, work)
+			zlacpy('L', m, m, l, m, u, ldu)
+			zungbr('P', m, m, m, l, m, taup, work)
+			zungbr('Q', m, m, m, u, ldu, tauq, work)
+			vecs = &bdsqrComplex{m, m, l, m, u, ldu}
+			info = bdsqr(false, m, s, e, vecs)
+			Zgemm(false, false, m, n, m, 1, l, m, vt, ldvt, 0, a, lda)
+			zlacpy(0, m, n, a, lda, vt, ldvt)
+
+		} else {
+
+			// reduce to bidiagonal form without LQ decomposition
+			zgebd2(m, n, a, lda, s, e, tauq, taup, work)
+			vecs = &bdsqrComplex{vt: vt, ldvt: ldvt, u: u, ldu: ldu}
+			if wntuas {
+				zlacpy('L', m, m, a, lda, u, ldu)
+				zungbr('Q', m, m, n, u, ldu, tauq, work)
+			}
+			if wntvas {
+				nrvt := m
+				if wntva {
+					nrvt = n
+				}
+				zlacpy('U', m, n, a, lda, vt, ldvt)
+				zungbr('P', nrvt, n, m, vt, ldvt, taup, work)
+			}
+			if wntuo {
+				zungbr('Q', m, m, n, a, lda, tauq, work)
+				vecs.u, vecs.ldu = a, lda
+			}
+			if wntvo {
+				zungbr('P', m, n, m, a, lda, taup, work)
+				vecs.vt, vecs.ldvt = a, lda
+			}
+			if wntuas || wntuo {
+				vecs.nru = m
+			}
+			if wntvas || wntvo {
+				vecs.ncvt = n
+			}
+			info = bdsqr(lower, m, s, e, vecs)
+		}
+	}
+
+	// undo scaling if necessary
+	if iscl {
+		if anrm > bignum {
+			dlascl(bignum, anrm, minmn, 1, s, minmn)
+		}
+		if anrm < smlnum {
+			dlascl(smlnum, anrm, minmn, 1, s, minmn)
+		}
+	}
+	copy(superb, e[:minmn-1])
+	return
+}
@@ -2,6 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// +build cgo,!purego
+
 // Package oblas implements lower-level linear algebra routines using OpenBLAS
 // for maximum efficiency. This package uses column-major representation for matrices.
 //
@@ -675,6 +677,50 @@ func Zpotrf(up bool, n int, a []complex128, lda int) {
 	}
 }
 
+// Dsyev computes all eigenvalues and, optionally, eigenvectors of a real symmetric matrix A.
+//
+//  See: http://www.netlib.org/lapack/explore-html/dd/d4c/dsyev_8f.html
+//
+//  Only the upper (up=true) or lower (up=false) triangle of A is referenced. The eigenvalues
+//  are returned in ascending order. If calcV is true, A is overwritten by the orthonormal
+//  eigenvectors (in columns); otherwise A is destroyed.
+func Dsyev(calcV, up bool, n int, a []float64, lda int, w []float64) {
+	info := C.LAPACKE_dsyev(
+		C.int(lapackColMajor),
+		jobVlr(calcV),
+		lUplo(up),
+		C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&a[0])),
+		C.lapack_int(lda),
+		(*C.double)(unsafe.Pointer(&w[0])),
+	)
+	if info != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Zheev computes all eigenvalues and, optionally, eigenvectors of a complex Hermitian matrix A.
+//
+//  See: http://www.netlib.org/lapack/lapack-3.1.1/html/zheev.f.html
+//
+//  Only the upper (up=true) or lower (up=false) triangle of A is referenced. The (real)
+//  eigenvalues are returned in ascending order. If calcV is true, A is overwritten by the
+//  orthonormal eigenvectors (in columns); otherwise A is destroyed.
+func Zheev(calcV, up bool, n int, a []complex128, lda int, w []float64) {
+	info := C.LAPACKE_zheev(
+		C.int(lapackColMajor),
+		jobVlr(calcV),
+		lUplo(up),
+		C.lapack_int(n),
+		(*C.lapack_complex_double)(unsafe.Pointer(&a[0])),
+		C.lapack_int(lda),
+		(*C.double)(unsafe.Pointer(&w[0])),
+	)
+	if info != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
 // Dgeev computes for an N-by-N real nonsymmetric matrix A, the
 // eigenvalues and, optionally, the left and/or right eigenvectors.
 //
@@ -729,6 +775,190 @@ func Dgeev(calcVl, calcVr bool, n int, a []float64, lda int, wr []float64, wi, v
 	}
 }
 
+// Dgees computes for an N-by-N real nonsymmetric matrix A, the eigenvalues, the real Schur form T,
+// and, optionally, the matrix of Schur vectors Z.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d8/d7e/dgees_8f.html
+//
+//  The Schur factorization is
+//
+//                   A = Z * T * Z**T
+//
+//  where T is upper quasi-triangular with 1-by-1 and 2-by-2 diagonal blocks; the 2-by-2 blocks
+//  are standardized in the form
+//
+//                   [  a  b  ]
+//                   [  c  a  ]
+//
+//  where b*c < 0 and correspond to pairs of complex conjugate eigenvalues a ± sqrt(b*c).
+//  The eigenvalues are not ordered (no sorting is performed).
+//
+//  NOTE: matrix 'a' will be modified (it will contain T on exit)
+func Dgees(calcVs bool, n int, a []float64, lda int, wr, wi, vs []float64, ldvs int) {
+	var vvs *C.double
+	if calcVs {
+		vvs = (*C.double)(unsafe.Pointer(&vs[0]))
+	} else {
+		ldvs = 1
+	}
+	var sdim C.lapack_int
+	info := C.LAPACKE_dgees(
+		C.int(lapackColMajor),
+		jobVlr(calcVs),
+		'N',
+		nil,
+		C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&a[0])),
+		C.lapack_int(lda),
+		&sdim,
+		(*C.double)(unsafe.Pointer(&wr[0])),
+		(*C.double)(unsafe.Pointer(&wi[0])),
+		vvs,
+		C.lapack_int(ldvs),
+	)
+	if info != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dgeqrf computes a QR factorization of a real M-by-N matrix A.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d3/d69/dgeqrf_8f.html
+//
+//  See: https://software.intel.com/en-us/mkl-developer-reference-c-geqrf
+//
+//  The factorization has the form
+//
+//     A = Q * R
+//
+//  On exit, the elements on and above the diagonal of the array contain the min(M,N)-by-N upper
+//  trapezoidal matrix R; the elements below the diagonal, with the array tau, represent the
+//  orthogonal matrix Q as a product of min(m,n) elementary reflectors
+//
+//     Q = H(1) H(2) . . . H(k), where k = min(m,n)
+//
+//  Each H(i) has the form
+//
+//     H(i) = I - tau * v * v**T
+//
+//  where tau is a real scalar, and v is a real vector with v(1:i-1) = 0 and v(i) = 1;
+//  v(i+1:m) is stored on exit in A(i+1:m,i), and tau in tau(i).
+//
+//  NOTE: matrix 'a' will be modified
+func Dgeqrf(m, n int, a []float64, lda int, tau []float64) {
+	info := C.LAPACKE_dgeqrf(
+		C.int(lapackColMajor),
+		C.lapack_int(m),
+		C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&a[0])),
+		C.lapack_int(lda),
+		(*C.double)(unsafe.Pointer(&tau[0])),
+	)
+	if info != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dgeqp3 computes a QR factorization with column pivoting of a matrix A.
+//
+//  See: http://www.netlib.org/lapack/explore-html/db/de5/dgeqp3_8f.html
+//
+//  See: https://software.intel.com/en-us/mkl-developer-reference-c-geqp3
+//
+//  The factorization has the form
+//
+//     A * P = Q * R
+//
+//  On entry, if jpvt(j) ≠ 0, the j-th column of A is permuted to the front of A*P (a leading
+//  column); if jpvt(j) = 0, the j-th column of A is a free column. On exit, if jpvt(j) = k, then
+//  the j-th column of A*P was the k-th column of A. The matrix Q is represented as a product of
+//  elementary reflectors as in Dgeqrf.
+//
+//  NOTE: (1) matrix 'a' will be modified
+//        (2) jpvt indices are 1-based (i.e. Fortran)
+func Dgeqp3(m, n int, a []float64, lda int, jpvt []int32, tau []float64) {
+	info := C.LAPACKE_dgeqp3(
+		C.int(lapackColMajor),
+		C.lapack_int(m),
+		C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&a[0])),
+		C.lapack_int(lda),
+		(*C.lapack_int)(unsafe.Pointer(&jpvt[0])),
+		(*C.double)(unsafe.Pointer(&tau[0])),
+	)
+	if info != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dorgqr generates an M-by-N real matrix Q with orthonormal columns.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d9/d1d/dorgqr_8f.html
+//
+//  See: https://software.intel.com/en-us/mkl-developer-reference-c-orgqr
+//
+//  Q is defined as the first N columns of a product of K elementary
+//  reflectors of order M
+//
+//        Q  =  H(1) H(2) . . . H(k)
+//
+//  as returned by Dgeqrf or Dgeqp3 (m ≥ n ≥ k).
+//
+//  NOTE: matrix 'a' will be modified
+func Dorgqr(m, n, k int, a []float64, lda int, tau []float64) {
+	info := C.LAPACKE_dorgqr(
+		C.int(lapackColMajor),
+		C.lapack_int(m),
+		C.lapack_int(n),
+		C.lapack_int(k),
+		(*C.double)(unsafe.Pointer(&a[0])),
+		C.lapack_int(lda),
+		(*C.double)(unsafe.Pointer(&tau[0])),
+	)
+	if info != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dormqr multiplies a general real M-by-N matrix C by the orthogonal matrix Q of a QR factorization.
+//
+//  See: http://www.netlib.org/lapack/explore-html/da/dba/dormqr_8f.html
+//
+//  See: https://software.intel.com/en-us/mkl-developer-reference-c-ormqr
+//
+//  C is overwritten by
+//
+//                  left=true  left=false
+//     trans=false: Q * C      C * Q
+//     trans=true:  Q**T * C   C * Q**T
+//
+//  where Q is a real orthogonal matrix defined as the product of k
+//  elementary reflectors
+//
+//        Q = H(1) H(2) . . . H(k)
+//
+//  as returned by Dgeqrf or Dgeqp3. Q is of order M if left=true and of order N if left=false.
+//
+//  NOTE: matrix 'c' will be modified
+func Dormqr(left, trans bool, m, n, k int, a []float64, lda int, tau, c []float64, ldc int) {
+	info := C.LAPACKE_dormqr(
+		C.int(lapackColMajor),
+		lSide(left),
+		lTrans(trans),
+		C.lapack_int(m),
+		C.lapack_int(n),
+		C.lapack_int(k),
+		(*C.double)(unsafe.Pointer(&a[0])),
+		C.lapack_int(lda),
+		(*C.double)(unsafe.Pointer(&tau[0])),
+		(*C.double)(unsafe.Pointer(&c[0])),
+		C.lapack_int(ldc),
+	)
+	if info != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
 // auxiliary //////////////////////////////////////////////////////////////////////////////////////
 
 // constants
@@ -781,6 +1011,20 @@ func lUplo(up bool) C.char {
 	return 'L'
 }
 
+func lSide(left bool) C.char {
+	if left {
+		return 'L'
+	}
+	return 'R'
+}
+
+func lTrans(trans bool) C.char {
+	if trans {
+		return 'T'
+	}
+	return 'N'
+}
+
 func jobVlr(doCalc bool) C.char {
 	if doCalc {
 		return 'V'
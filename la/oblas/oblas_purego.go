@@ -0,0 +1,914 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !cgo purego
+
+package oblas
+
+import "github.com/cpmech/gosl/chk"
+
+// This file implements the routines of oblas.go in pure Go. It is selected when cgo is disabled
+// (CGO_ENABLED=0) or when the "purego" build tag is given. The LAPACK routines are ports of the
+// unblocked reference implementations; thus, for small matrices, the results (including the
+// ordering of eigenvalues and the signs of singular vectors) coincide with the ones of OpenBLAS.
+
+// SetNumThreads sets the number of threads in OpenBLAS
+//  NOTE: this is a no-op in pure-Go builds
+func SetNumThreads(n int) {
+}
+
+// Ddot forms the dot product of two vectors. Uses unrolled loops for increments equal to one.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d5/df6/ddot_8f.html
+func Ddot(n int, x []float64, incx int, y []float64, incy int) (res float64) {
+	if n <= 0 {
+		return
+	}
+	ix, iy := start(n, incx), start(n, incy)
+	for i := 0; i < n; i++ {
+		res += x[ix] * y[iy]
+		ix += incx
+		iy += incy
+	}
+	return
+}
+
+// Dscal scales a vector by a constant. Uses unrolled loops for increment equal to 1.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d4/dd0/dscal_8f.html
+func Dscal(n int, alpha float64, x []float64, incx int) {
+	if n <= 0 || incx <= 0 {
+		return
+	}
+	for i, ix := 0, 0; i < n; i, ix = i+1, ix+incx {
+		x[ix] *= alpha
+	}
+}
+
+// Daxpy computes constant times a vector plus a vector.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d9/dcd/daxpy_8f.html
+//
+//  y += alpha*x + y
+//
+func Daxpy(n int, alpha float64, x []float64, incx int, y []float64, incy int) {
+	if n <= 0 || alpha == 0 {
+		return
+	}
+	ix, iy := start(n, incx), start(n, incy)
+	for i := 0; i < n; i++ {
+		y[iy] += alpha * x[ix]
+		ix += incx
+		iy += incy
+	}
+}
+
+// Zaxpy computes constant times a vector plus a vector.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d7/db2/zaxpy_8f.html
+//
+//  y += alpha*x + y
+//
+func Zaxpy(n int, alpha complex128, x []complex128, incx int, y []complex128, incy int) {
+	if n <= 0 || alpha == 0 {
+		return
+	}
+	ix, iy := start(n, incx), start(n, incy)
+	for i := 0; i < n; i++ {
+		y[iy] += alpha * x[ix]
+		ix += incx
+		iy += incy
+	}
+}
+
+// Dgemv performs one of the matrix-vector operations
+//
+//  See: http://www.netlib.org/lapack/explore-html/dc/da8/dgemv_8f.html
+//
+//     y := alpha*A*x + beta*y,   or   y := alpha*A**T*x + beta*y,
+//
+//  where alpha and beta are scalars, x and y are vectors and A is an
+//  m by n matrix.
+//     trans=false     y := alpha*A*x + beta*y.
+//
+//     trans=true      y := alpha*A**T*x + beta*y.
+func Dgemv(trans bool, m, n int, alpha float64, a []float64, lda int, x []float64, incx int, beta float64, y []float64, incy int) {
+	if m <= 0 || n <= 0 || (alpha == 0 && beta == 1) {
+		return
+	}
+	lenx, leny := n, m
+	if trans {
+		lenx, leny = m, n
+	}
+	kx, ky := start(lenx, incx), start(leny, incy)
+	if beta != 1 {
+		for i, iy := 0, ky; i < leny; i, iy = i+1, iy+incy {
+			if beta == 0 {
+				y[iy] = 0
+			} else {
+				y[iy] *= beta
+			}
+		}
+	}
+	if alpha == 0 {
+		return
+	}
+	if !trans {
+		for j, jx := 0, kx; j < n; j, jx = j+1, jx+incx {
+			temp := alpha * x[jx]
+			for i, iy := 0, ky; i < m; i, iy = i+1, iy+incy {
+				y[iy] += temp * a[i+j*lda]
+			}
+		}
+		return
+	}
+	for j, jy := 0, ky; j < n; j, jy = j+1, jy+incy {
+		temp := 0.0
+		for i, ix := 0, kx; i < m; i, ix = i+1, ix+incx {
+			temp += a[i+j*lda] * x[ix]
+		}
+		y[jy] += alpha * temp
+	}
+}
+
+// Zgemv performs one of the matrix-vector operations.
+//
+//  See: http://www.netlib.org/lapack/explore-html/db/d40/zgemv_8f.html
+//
+//     y := alpha*A*x + beta*y,   or   y := alpha*A**T*x + beta*y,   or
+//
+//  where alpha and beta are scalars, x and y are vectors and A is an
+//  m by n matrix.
+//     trans=false     y := alpha*A*x + beta*y.
+//
+//     trans=true      y := alpha*A**T*x + beta*y.
+func Zgemv(trans bool, m, n int, alpha complex128, a []complex128, lda int, x []complex128, incx int, beta complex128, y []complex128, incy int) {
+	if m <= 0 || n <= 0 || (alpha == 0 && beta == 1) {
+		return
+	}
+	lenx, leny := n, m
+	if trans {
+		lenx, leny = m, n
+	}
+	kx, ky := start(lenx, incx), start(leny, incy)
+	if beta != 1 {
+		for i, iy := 0, ky; i < leny; i, iy = i+1, iy+incy {
+			if beta == 0 {
+				y[iy] = 0
+			} else {
+				y[iy] *= beta
+			}
+		}
+	}
+	if alpha == 0 {
+		return
+	}
+	if !trans {
+		for j, jx := 0, kx; j < n; j, jx = j+1, jx+incx {
+			temp := alpha * x[jx]
+			for i, iy := 0, ky; i < m; i, iy = i+1, iy+incy {
+				y[iy] += temp * a[i+j*lda]
+			}
+		}
+		return
+	}
+	for j, jy := 0, ky; j < n; j, jy = j+1, jy+incy {
+		var temp complex128
+		for i, ix := 0, kx; i < m; i, ix = i+1, ix+incx {
+			temp += a[i+j*lda] * x[ix]
+		}
+		y[jy] += alpha * temp
+	}
+}
+
+// Dger performs the rank 1 operation
+//
+//  See: http://www.netlib.org/lapack/explore-html/dc/da8/dger_8f.html
+//
+//    A := alpha*x*y**T + A,
+//
+//  where alpha is a scalar, x is an m element vector, y is an n element
+//  vector and A is an m by n matrix.
+func Dger(m, n int, alpha float64, x []float64, incx int, y []float64, incy int, a []float64, lda int) {
+	if m <= 0 || n <= 0 || alpha == 0 {
+		return
+	}
+	kx, jy := start(m, incx), start(n, incy)
+	for j := 0; j < n; j, jy = j+1, jy+incy {
+		if y[jy] != 0 {
+			temp := alpha * y[jy]
+			for i, ix := 0, kx; i < m; i, ix = i+1, ix+incx {
+				a[i+j*lda] += x[ix] * temp
+			}
+		}
+	}
+}
+
+// Dgemm performs one of the matrix-matrix operations
+//
+//  See: http://www.netlib.org/lapack/explore-html/d7/d2b/dgemm_8f.html
+//
+//     C := alpha*op( A )*op( B ) + beta*C,
+//
+//  where  op( X ) is one of
+//
+//     op( X ) = X   or   op( X ) = X**T,
+//
+//  alpha and beta are scalars, and A, B and C are matrices, with op( A )
+//  an m by k matrix,  op( B )  a  k by n matrix and  C an m by n matrix.
+func Dgemm(transA, transB bool, m, n, k int, alpha float64, a []float64, lda int, b []float64, ldb int, beta float64, c []float64, ldc int) {
+	if m <= 0 || n <= 0 || ((alpha == 0 || k == 0) && beta == 1) {
+		return
+	}
+	for j := 0; j < n; j++ {
+		if beta == 0 {
+			for i := 0; i < m; i++ {
+				c[i+j*ldc] = 0
+			}
+		} else if beta != 1 {
+			for i := 0; i < m; i++ {
+				c[i+j*ldc] *= beta
+			}
+		}
+		if alpha == 0 {
+			continue
+		}
+		switch {
+		case !transA && !transB:
+			for l := 0; l < k; l++ {
+				temp := alpha * b[l+j*ldb]
+				for i := 0; i < m; i++ {
+					c[i+j*ldc] += temp * a[i+l*lda]
+				}
+			}
+		case !transA && transB:
+			for l := 0; l < k; l++ {
+				temp := alpha * b[j+l*ldb]
+				for i := 0; i < m; i++ {
+					c[i+j*ldc] += temp * a[i+l*lda]
+				}
+			}
+		case transA && !transB:
+			for i := 0; i < m; i++ {
+				temp := 0.0
+				for l := 0; l < k; l++ {
+					temp += a[l+i*lda] * b[l+j*ldb]
+				}
+				c[i+j*ldc] += alpha * temp
+			}
+		default:
+			for i := 0; i < m; i++ {
+				temp := 0.0
+				for l := 0; l < k; l++ {
+					temp += a[l+i*lda] * b[j+l*ldb]
+				}
+				c[i+j*ldc] += alpha * temp
+			}
+		}
+	}
+}
+
+// Zgemm performs one of the matrix-matrix operations
+//
+//  See: http://www.netlib.org/lapack/explore-html/d7/d76/zgemm_8f.html
+//
+//     C := alpha*op( A )*op( B ) + beta*C,
+//
+//  where  op( X ) is one of
+//
+//     op( X ) = X   or   op( X ) = X**T
+//
+//  alpha and beta are scalars, and A, B and C are matrices, with op( A )
+//  an m by k matrix,  op( B )  a  k by n matrix and  C an m by n matrix.
+func Zgemm(transA, transB bool, m, n, k int, alpha complex128, a []complex128, lda int, b []complex128, ldb int, beta complex128, c []complex128, ldc int) {
+	if m <= 0 || n <= 0 || ((alpha == 0 || k == 0) && beta == 1) {
+		return
+	}
+	for j := 0; j < n; j++ {
+		if beta == 0 {
+			for i := 0; i < m; i++ {
+				c[i+j*ldc] = 0
+			}
+		} else if beta != 1 {
+			for i := 0; i < m; i++ {
+				c[i+j*ldc] *= beta
+			}
+		}
+		if alpha == 0 {
+			continue
+		}
+		switch {
+		case !transA && !transB:
+			for l := 0; l < k; l++ {
+				temp := alpha * b[l+j*ldb]
+				for i := 0; i < m; i++ {
+					c[i+j*ldc] += temp * a[i+l*lda]
+				}
+			}
+		case !transA && transB:
+			for l := 0; l < k; l++ {
+				temp := alpha * b[j+l*ldb]
+				for i := 0; i < m; i++ {
+					c[i+j*ldc] += temp * a[i+l*lda]
+				}
+			}
+		case transA && !transB:
+			for i := 0; i < m; i++ {
+				var temp complex128
+				for l := 0; l < k; l++ {
+					temp += a[l+i*lda] * b[l+j*ldb]
+				}
+				c[i+j*ldc] += alpha * temp
+			}
+		default:
+			for i := 0; i < m; i++ {
+				var temp complex128
+				for l := 0; l < k; l++ {
+					temp += a[l+i*lda] * b[j+l*ldb]
+				}
+				c[i+j*ldc] += alpha * temp
+			}
+		}
+	}
+}
+
+// Dgesv computes the solution to a real system of linear equations.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d8/d72/dgesv_8f.html
+//
+//  The system is:
+//
+//     A * X = B,
+//
+//  where A is an N-by-N matrix and X and B are N-by-NRHS matrices.
+//
+//  The LU decomposition with partial pivoting and row interchanges is
+//  used to factor A as
+//
+//     A = P * L * U,
+//
+//  where P is a permutation matrix, L is unit lower triangular, and U is
+//  upper triangular.  The factored form of A is then used to solve the
+//  system of equations A * X = B.
+//
+//  NOTE: matrix 'a' will be modified
+func Dgesv(n, nrhs int, a []float64, lda int, ipiv []int32, b []float64, ldb int) {
+	if len(ipiv) != n {
+		chk.Panic("len(ipiv) must be equal to n. %d != %d\n", len(ipiv), n)
+	}
+	if dgetf2(n, n, a, lda, ipiv) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+	dgetrs(n, nrhs, a, lda, ipiv, b, ldb)
+}
+
+// Zgesv computes the solution to a complex system of linear equations.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d1/ddc/zgesv_8f.html
+//
+//  The system is:
+//
+//     A * X = B,
+//
+//  where A is an N-by-N matrix and X and B are N-by-NRHS matrices.
+//
+//  The LU decomposition with partial pivoting and row interchanges is
+//  used to factor A as
+//
+//     A = P * L * U,
+//
+//  where P is a permutation matrix, L is unit lower triangular, and U is
+//  upper triangular.  The factored form of A is then used to solve the
+//  system of equations A * X = B.
+//
+//  NOTE: matrix 'a' will be modified
+func Zgesv(n, nrhs int, a []complex128, lda int, ipiv []int32, b []complex128, ldb int) {
+	if len(ipiv) != n {
+		chk.Panic("len(ipiv) must be equal to n. %d != %d\n", len(ipiv), n)
+	}
+	if zgetf2(n, n, a, lda, ipiv) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+	zgetrs(n, nrhs, a, lda, ipiv, b, ldb)
+}
+
+// Dgesvd computes the singular value decomposition (SVD) of a real M-by-N matrix A, optionally computing the left and/or right singular vectors.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d8/d2d/dgesvd_8f.html
+//
+//  The SVD is written
+//
+//       A = U * SIGMA * transpose(V)
+//
+//  where SIGMA is an M-by-N matrix which is zero except for its
+//  min(m,n) diagonal elements, U is an M-by-M orthogonal matrix, and
+//  V is an N-by-N orthogonal matrix.  The diagonal elements of SIGMA
+//  are the singular values of A; they are real and non-negative, and
+//  are returned in descending order.  The first min(m,n) columns of
+//  U and V are the left and right singular vectors of A.
+//
+//  Note that the routine returns V**T, not V.
+//
+//  NOTE: matrix 'a' will be modified
+func Dgesvd(jobu, jobvt rune, m, n int, a []float64, lda int, s []float64, u []float64, ldu int, vt []float64, ldvt int, superb []float64) {
+	if dgesvd(byte(jobu), byte(jobvt), m, n, a, lda, s, u, ldu, vt, ldvt, superb) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Zgesvd computes the singular value decomposition (SVD) of a complex M-by-N matrix A, optionally computing the left and/or right singular vectors.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d6/d42/zgesvd_8f.html
+//
+//  The SVD is written
+//
+//       A = U * SIGMA * conjugate-transpose(V)
+//
+//  where SIGMA is an M-by-N matrix which is zero except for its
+//  min(m,n) diagonal elements, U is an M-by-M unitary matrix, and
+//  V is an N-by-N unitary matrix.  The diagonal elements of SIGMA
+//  are the singular values of A; they are real and non-negative, and
+//  are returned in descending order.  The first min(m,n) columns of
+//  U and V are the left and right singular vectors of A.
+//
+//  Note that the routine returns V**H, not V.
+//
+//  NOTE: matrix 'a' will be modified
+func Zgesvd(jobu, jobvt rune, m, n int, a []complex128, lda int, s []float64, u []complex128, ldu int, vt []complex128, ldvt int, superb []float64) {
+	if zgesvd(byte(jobu), byte(jobvt), m, n, a, lda, s, u, ldu, vt, ldvt, superb) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dgetrf computes an LU factorization of a general M-by-N matrix A using partial pivoting with row interchanges.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d3/d6a/dgetrf_8f.html
+//
+//  The factorization has the form
+//     A = P * L * U
+//  where P is a permutation matrix, L is lower triangular with unit
+//  diagonal elements (lower trapezoidal if m > n), and U is upper
+//  triangular (upper trapezoidal if m < n).
+//
+//  This is the unblocked (Level 2 BLAS) version of the algorithm.
+//
+//  NOTE: (1) matrix 'a' will be modified
+//        (2) ipiv indices are 1-based (i.e. Fortran)
+func Dgetrf(m, n int, a []float64, lda int, ipiv []int32) {
+	if dgetf2(m, n, a, lda, ipiv) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Zgetrf computes an LU factorization of a general M-by-N matrix A using partial pivoting with row interchanges.
+//
+//  See: http://www.netlib.org/lapack/explore-html/dd/dd1/zgetrf_8f.html
+//
+//  The factorization has the form
+//     A = P * L * U
+//  where P is a permutation matrix, L is lower triangular with unit
+//  diagonal elements (lower trapezoidal if m > n), and U is upper
+//  triangular (upper trapezoidal if m < n).
+//
+//  This is the unblocked (Level 2 BLAS) version of the algorithm.
+//
+//  NOTE: (1) matrix 'a' will be modified
+//        (2) ipiv indices are 1-based (i.e. Fortran)
+func Zgetrf(m, n int, a []complex128, lda int, ipiv []int32) {
+	if zgetf2(m, n, a, lda, ipiv) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dgetri computes the inverse of a matrix using the LU factorization computed by DGETRF.
+//
+//  See: http://www.netlib.org/lapack/explore-html/df/da4/dgetri_8f.html
+//
+//  This method inverts U and then computes inv(A) by solving the system
+//  inv(A)*L = inv(U) for inv(A).
+func Dgetri(n int, a []float64, lda int, ipiv []int32) {
+	if dgetri(n, a, lda, ipiv) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Zgetri computes the inverse of a matrix using the LU factorization computed by Zgetrf.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d0/db3/zgetri_8f.html
+//
+//  This method inverts U and then computes inv(A) by solving the system
+//  inv(A)*L = inv(U) for inv(A).
+func Zgetri(n int, a []complex128, lda int, ipiv []int32) {
+	if zgetri(n, a, lda, ipiv) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dsyrk performs one of the symmetric rank k operations
+//
+//  See: http://www.netlib.org/lapack/explore-html/dc/d05/dsyrk_8f.html
+//
+//     C := alpha*A*A**T + beta*C,
+//
+//  or
+//
+//     C := alpha*A**T*A + beta*C,
+//
+//  where  alpha and beta  are scalars, C is an  n by n  symmetric matrix
+//  and  A  is an  n by k  matrix in the first case and a  k by n  matrix
+//  in the second case.
+func Dsyrk(up, trans bool, n, k int, alpha float64, a []float64, lda int, beta float64, c []float64, ldc int) {
+	if n <= 0 || ((alpha == 0 || k == 0) && beta == 1) {
+		return
+	}
+	for j := 0; j < n; j++ {
+		i0, i1 := j, n
+		if up {
+			i0, i1 = 0, j+1
+		}
+		if !trans {
+			for i := i0; i < i1; i++ {
+				if beta == 0 {
+					c[i+j*ldc] = 0
+				} else if beta != 1 {
+					c[i+j*ldc] *= beta
+				}
+			}
+			for l := 0; l < k; l++ {
+				if a[j+l*lda] != 0 {
+					temp := alpha * a[j+l*lda]
+					for i := i0; i < i1; i++ {
+						c[i+j*ldc] += temp * a[i+l*lda]
+					}
+				}
+			}
+			continue
+		}
+		for i := i0; i < i1; i++ {
+			temp := 0.0
+			for l := 0; l < k; l++ {
+				temp += a[l+i*lda] * a[l+j*lda]
+			}
+			if beta == 0 {
+				c[i+j*ldc] = alpha * temp
+			} else {
+				c[i+j*ldc] = alpha*temp + beta*c[i+j*ldc]
+			}
+		}
+	}
+}
+
+// Zsyrk performs one of the symmetric rank k operations
+//
+//  See: http://www.netlib.org/lapack/explore-html/de/d54/zsyrk_8f.html
+//
+//     C := alpha*A*A**T + beta*C,
+//
+//  or
+//
+//     C := alpha*A**T*A + beta*C,
+//
+//  where  alpha and beta  are scalars,  C is an  n by n symmetric matrix
+//  and  A  is an  n by k  matrix in the first case and a  k by n  matrix
+//  in the second case.
+func Zsyrk(up, trans bool, n, k int, alpha complex128, a []complex128, lda int, beta complex128, c []complex128, ldc int) {
+	if n <= 0 || ((alpha == 0 || k == 0) && beta == 1) {
+		return
+	}
+	for j := 0; j < n; j++ {
+		i0, i1 := j, n
+		if up {
+			i0, i1 = 0, j+1
+		}
+		if !trans {
+			for i := i0; i < i1; i++ {
+				if beta == 0 {
+					c[i+j*ldc] = 0
+				} else if beta != 1 {
+					c[i+j*ldc] *= beta
+				}
+			}
+			for l := 0; l < k; l++ {
+				if a[j+l*lda] != 0 {
+					temp := alpha * a[j+l*lda]
+					for i := i0; i < i1; i++ {
+						c[i+j*ldc] += temp * a[i+l*lda]
+					}
+				}
+			}
+			continue
+		}
+		for i := i0; i < i1; i++ {
+			var temp complex128
+			for l := 0; l < k; l++ {
+				temp += a[l+i*lda] * a[l+j*lda]
+			}
+			if beta == 0 {
+				c[i+j*ldc] = alpha * temp
+			} else {
+				c[i+j*ldc] = alpha*temp + beta*c[i+j*ldc]
+			}
+		}
+	}
+}
+
+// Zherk performs one of the hermitian rank k operations
+//
+//  See: http://www.netlib.org/lapack/explore-html/d1/db1/zherk_8f.html
+//
+//     C := alpha*A*A**H + beta*C,
+//
+//  or
+//
+//     C := alpha*A**H*A + beta*C,
+//
+//  where  alpha and beta  are  real scalars,  C is an  n by n  hermitian
+//  matrix and  A  is an  n by k  matrix in the  first case and a  k by n
+//  matrix in the second case.
+func Zherk(up, trans bool, n, k int, alpha float64, a []complex128, lda int, beta float64, c []complex128, ldc int) {
+	if n <= 0 || ((alpha == 0 || k == 0) && beta == 1) {
+		return
+	}
+	for j := 0; j < n; j++ {
+		i0, i1 := j+1, n // strictly off-diagonal part of column j
+		if up {
+			i0, i1 = 0, j
+		}
+		if !trans {
+			for i := i0; i < i1; i++ {
+				if beta == 0 {
+					c[i+j*ldc] = 0
+				} else if beta != 1 {
+					c[i+j*ldc] *= complex(beta, 0)
+				}
+			}
+			cjj := beta * real(c[j+j*ldc])
+			if beta == 0 {
+				cjj = 0
+			}
+			for l := 0; l < k; l++ {
+				if a[j+l*lda] != 0 {
+					temp := complex(alpha, 0) * conj(a[j+l*lda])
+					for i := i0; i < i1; i++ {
+						c[i+j*ldc] += temp * a[i+l*lda]
+					}
+					cjj += real(temp * a[j+l*lda])
+				}
+			}
+			c[j+j*ldc] = complex(cjj, 0)
+			continue
+		}
+		for i := i0; i < i1; i++ {
+			var temp complex128
+			for l := 0; l < k; l++ {
+				temp += conj(a[l+i*lda]) * a[l+j*lda]
+			}
+			if beta == 0 {
+				c[i+j*ldc] = complex(alpha, 0) * temp
+			} else {
+				c[i+j*ldc] = complex(alpha, 0)*temp + complex(beta, 0)*c[i+j*ldc]
+			}
+		}
+		rtemp := 0.0
+		for l := 0; l < k; l++ {
+			rtemp += real(conj(a[l+j*lda]) * a[l+j*lda])
+		}
+		if beta == 0 {
+			c[j+j*ldc] = complex(alpha*rtemp, 0)
+		} else {
+			c[j+j*ldc] = complex(alpha*rtemp+beta*real(c[j+j*ldc]), 0)
+		}
+	}
+}
+
+// Dpotrf computes the Cholesky factorization of a real symmetric positive definite matrix A.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d0/d8a/dpotrf_8f.html
+//
+//  The factorization has the form
+//
+//     A = U**T * U,  if UPLO = 'U'
+//
+//  or
+//
+//     A = L  * L**T,  if UPLO = 'L'
+//
+//  where U is an upper triangular matrix and L is lower triangular.
+//
+//  This is the unblocked version of the algorithm, calling Level 2 BLAS.
+func Dpotrf(up bool, n int, a []float64, lda int) {
+	if dpotf2(up, n, a, lda) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Zpotrf computes the Cholesky factorization of a complex Hermitian positive definite matrix A.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d1/db9/zpotrf_8f.html
+//
+//  The factorization has the form
+//
+//     A = U**H * U,  if UPLO = 'U'
+//
+//  or
+//
+//     A = L  * L**H,  if UPLO = 'L'
+//
+//  where U is an upper triangular matrix and L is lower triangular.
+//
+//  This is the unblocked version of the algorithm, calling Level 2 BLAS.
+func Zpotrf(up bool, n int, a []complex128, lda int) {
+	if zpotf2(up, n, a, lda) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dsyev computes all eigenvalues and, optionally, eigenvectors of a real symmetric matrix A.
+//
+//  See: http://www.netlib.org/lapack/explore-html/dd/d4c/dsyev_8f.html
+//
+//  Only the upper (up=true) or lower (up=false) triangle of A is referenced. The eigenvalues
+//  are returned in ascending order. If calcV is true, A is overwritten by the orthonormal
+//  eigenvectors (in columns); otherwise A is destroyed.
+//
+//  The matrix is reduced to tridiagonal form by Householder transformations and the
+//  eigenvalues and eigenvectors are computed by the implicit QL method.
+func Dsyev(calcV, up bool, n int, a []float64, lda int, w []float64) {
+	if dsyev(calcV, up, n, a, lda, w) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Zheev computes all eigenvalues and, optionally, eigenvectors of a complex Hermitian matrix A.
+//
+//  See: http://www.netlib.org/lapack/lapack-3.1.1/html/zheev.f.html
+//
+//  Only the upper (up=true) or lower (up=false) triangle of A is referenced. The (real)
+//  eigenvalues are returned in ascending order. If calcV is true, A is overwritten by the
+//  orthonormal eigenvectors (in columns); otherwise A is destroyed.
+//
+//  The matrix is reduced to real tridiagonal form by Householder transformations and the
+//  eigenvalues and eigenvectors are computed by the implicit QL method.
+func Zheev(calcV, up bool, n int, a []complex128, lda int, w []float64) {
+	if zheev(calcV, up, n, a, lda, w) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dgeev computes for an N-by-N real nonsymmetric matrix A, the
+// eigenvalues and, optionally, the left and/or right eigenvectors.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d9/d28/dgeev_8f.html
+//
+//  See: https://www.nag.co.uk/numeric/fl/nagdoc_fl26/html/f08/f08naf.html
+//
+//  The right eigenvector v(j) of A satisfies
+//
+//                   A * v(j) = lambda(j) * v(j)
+//
+//  where lambda(j) is its eigenvalue.
+//
+//  The left eigenvector u(j) of A satisfies
+//
+//                u(j)**H * A = lambda(j) * u(j)**H
+//
+//  where u(j)**H denotes the conjugate-transpose of u(j).
+//
+//  The computed eigenvectors are normalized to have Euclidean norm
+//  equal to 1 and largest component real.
+func Dgeev(calcVl, calcVr bool, n int, a []float64, lda int, wr []float64, wi, vl []float64, ldvl int, vr []float64, ldvr int) {
+	if dgeev(calcVl, calcVr, n, a, lda, wr, wi, vl, ldvl, vr, ldvr) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dgees computes for an N-by-N real nonsymmetric matrix A, the eigenvalues, the real Schur form T,
+// and, optionally, the matrix of Schur vectors Z.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d8/d7e/dgees_8f.html
+//
+//  The Schur factorization is
+//
+//                   A = Z * T * Z**T
+//
+//  where T is upper quasi-triangular with 1-by-1 and 2-by-2 diagonal blocks; the 2-by-2 blocks
+//  are standardized in the form
+//
+//                   [  a  b  ]
+//                   [  c  a  ]
+//
+//  where b*c < 0 and correspond to pairs of complex conjugate eigenvalues a ± sqrt(b*c).
+//  The eigenvalues are not ordered (no sorting is performed).
+//
+//  NOTE: matrix 'a' will be modified (it will contain T on exit)
+func Dgees(calcVs bool, n int, a []float64, lda int, wr, wi, vs []float64, ldvs int) {
+	if dgees(calcVs, n, a, lda, wr, wi, vs, ldvs) != 0 {
+		chk.Panic("lapack failed\n")
+	}
+}
+
+// Dgeqrf computes a QR factorization of a real M-by-N matrix A.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d3/d69/dgeqrf_8f.html
+//
+//  The factorization has the form
+//
+//     A = Q * R
+//
+//  On exit, the elements on and above the diagonal of the array contain the min(M,N)-by-N upper
+//  trapezoidal matrix R; the elements below the diagonal, with the array tau, represent the
+//  orthogonal matrix Q as a product of min(m,n) elementary reflectors
+//
+//     Q = H(1) H(2) . . . H(k), where k = min(m,n)
+//
+//  Each H(i) has the form
+//
+//     H(i) = I - tau * v * v**T
+//
+//  where tau is a real scalar, and v is a real vector with v(1:i-1) = 0 and v(i) = 1;
+//  v(i+1:m) is stored on exit in A(i+1:m,i), and tau in tau(i).
+//
+//  NOTE: matrix 'a' will be modified
+func Dgeqrf(m, n int, a []float64, lda int, tau []float64) {
+	work := make([]float64, n)
+	dgeqr2(m, n, a, lda, tau, work)
+}
+
+// Dgeqp3 computes a QR factorization with column pivoting of a matrix A.
+//
+//  See: http://www.netlib.org/lapack/explore-html/db/de5/dgeqp3_8f.html
+//
+//  The factorization has the form
+//
+//     A * P = Q * R
+//
+//  On entry, if jpvt(j) ≠ 0, the j-th column of A is permuted to the front of A*P (a leading
+//  column); if jpvt(j) = 0, the j-th column of A is a free column. On exit, if jpvt(j) = k, then
+//  the j-th column of A*P was the k-th column of A. The matrix Q is represented as a product of
+//  elementary reflectors as in Dgeqrf.
+//
+//  NOTE: (1) matrix 'a' will be modified
+//        (2) jpvt indices are 1-based (i.e. Fortran)
+func Dgeqp3(m, n int, a []float64, lda int, jpvt []int32, tau []float64) {
+	dgeqp3(m, n, a, lda, jpvt, tau)
+}
+
+// Dorgqr generates an M-by-N real matrix Q with orthonormal columns.
+//
+//  See: http://www.netlib.org/lapack/explore-html/d9/d1d/dorgqr_8f.html
+//
+//  Q is defined as the first N columns of a product of K elementary
+//  reflectors of order M
+//
+//        Q  =  H(1) H(2) . . . H(k)
+//
+//  as returned by Dgeqrf or Dgeqp3 (m ≥ n ≥ k).
+//
+//  NOTE: matrix 'a' will be modified
+func Dorgqr(m, n, k int, a []float64, lda int, tau []float64) {
+	work := make([]float64, n)
+	dorg2r(m, n, k, a, lda, tau, work)
+}
+
+// Dormqr multiplies a general real M-by-N matrix C by the orthogonal matrix Q of a QR factorization.
+//
+//  See: http://www.netlib.org/lapack/explore-html/da/dba/dormqr_8f.html
+//
+//  C is overwritten by
+//
+//                  left=true  left=false
+//     trans=false: Q * C      C * Q
+//     trans=true:  Q**T * C   C * Q**T
+//
+//  where Q is a real orthogonal matrix defined as the product of k
+//  elementary reflectors
+//
+//        Q = H(1) H(2) . . . H(k)
+//
+//  as returned by Dgeqrf or Dgeqp3. Q is of order M if left=true and of order N if left=false.
+//
+//  NOTE: matrix 'c' will be modified
+func Dormqr(left, trans bool, m, n, k int, a []float64, lda int, tau, c []float64, ldc int) {
+	nw := n
+	if !left {
+		nw = m
+	}
+	work := make([]float64, nw)
+	dorm2r(left, trans, m, n, k, a, lda, tau, c, ldc, work)
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// start returns the index of the first element of a BLAS vector with n elements and increment inc
+func start(n, inc int) int {
+	if inc < 0 {
+		return (1 - n) * inc
+	}
+	return 0
+}
+
+// conj returns the complex conjugate of z
+func conj(z complex128) complex128 {
+	return complex(real(z), -imag(z))
+}
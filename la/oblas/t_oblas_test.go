@@ -6,9 +6,11 @@ package oblas
 
 import (
 	"math"
+	"math/cmplx"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/utl"
 )
 
@@ -1355,6 +1357,71 @@ func TestZpotrf01(tst *testing.T) {
 	})
 }
 
+func TestDsyev01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dsyev01")
+
+	// a matrix
+	a := [][]float64{
+		{+2, -1, +0, +0},
+		{-1, +2, -1, +0},
+		{+0, -1, +2, -1},
+		{+0, +0, -1, +2},
+	}
+	n := len(a)
+
+	// reference eigenvalues: 2 - 2⋅cos(k⋅π/5), k = 1...4
+	wRef := make([]float64, n)
+	for k := 0; k < n; k++ {
+		wRef[k] = 2 - 2*math.Cos(float64(k+1)*math.Pi/5)
+	}
+
+	// run with upper and lower triangles (the other triangle is zeroed to check it is not used)
+	for _, up := range []bool{true, false} {
+		tri := make([][]float64, n)
+		for i := 0; i < n; i++ {
+			tri[i] = make([]float64, n)
+			for j := 0; j < n; j++ {
+				if (up && j >= i) || (!up && j <= i) {
+					tri[i][j] = a[i][j]
+				}
+			}
+		}
+
+		// eigenvalues only
+		b := SliceToColMajor(tri)
+		w := make([]float64, n)
+		Dsyev(false, up, n, b, n, w)
+		chk.Array(tst, io.Sf("w (up=%v)", up), 1e-15, w, wRef)
+
+		// eigenvalues and eigenvectors
+		v := SliceToColMajor(tri)
+		Dsyev(true, up, n, v, n, w)
+		chk.Array(tst, io.Sf("w (up=%v, calcV)", up), 1e-15, w, wRef)
+
+		// check a⋅v = λ⋅v and vᵀ⋅v = I
+		for k := 0; k < n; k++ {
+			av := make([]float64, n)
+			lv := make([]float64, n)
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					av[i] += a[i][j] * v[j+k*n]
+				}
+				lv[i] = w[k] * v[i+k*n]
+			}
+			chk.Array(tst, io.Sf("a⋅v%d (up=%v)", k, up), 1e-15, av, lv)
+			for l := 0; l < n; l++ {
+				δ := 0.0
+				if k == l {
+					δ = 1
+				}
+				chk.Float64(tst, io.Sf("v%d⋅v%d", k, l), 1e-14, Ddot(n, v[k*n:], 1, v[l*n:], 1), δ)
+			}
+		}
+	}
+}
+
 func TestDgeev01(tst *testing.T) {
 
 	//verbose()
@@ -1508,3 +1575,241 @@ func TestDgeev01(tst *testing.T) {
 	ww4 := GetJoinComplex(wr4, wi4)
 	chk.ArrayC(tst, "4: w", 1e-16, ww4, wRef)
 }
+
+func TestDgeqp301(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dgeqp301")
+
+	// a matrix with rank 3 (last column = 2 ⋅ first column + second column)
+	a := [][]float64{
+		{1, 2, 0, 4},
+		{4, 5, 1, 13},
+		{7, 8, 0, 22},
+		{1, 0, 2, 2},
+		{2, 1, 1, 5},
+	}
+	m, n := len(a), len(a[0])
+	k := utl.Imin(m, n)
+
+	// factorisation
+	qr := SliceToColMajor(a)
+	jpvt := make([]int32, n)
+	tau := make([]float64, k)
+	Dgeqp3(m, n, qr, m, jpvt, tau)
+	io.Pforan("jpvt = %v\n", jpvt)
+	chk.Int32s(tst, "jpvt", jpvt, []int32{4, 3, 2, 1})
+
+	// diagonal of R is non-increasing (in absolute value) and reveals the rank
+	for i := 1; i < k; i++ {
+		if math.Abs(qr[i+i*m]) > math.Abs(qr[i-1+(i-1)*m]) {
+			tst.Errorf("|R[%d,%d]| must not be greater than |R[%d,%d]|\n", i, i, i-1, i-1)
+		}
+	}
+	chk.Float64(tst, "R[3,3]", 1e-14, qr[3+3*m], 0)
+
+	// Q with orthonormal columns
+	q := make([]float64, m*k)
+	copy(q, qr[:m*k])
+	Dorgqr(m, k, k, q, m, tau)
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			δ := 0.0
+			if i == j {
+				δ = 1
+			}
+			chk.Float64(tst, io.Sf("q%d⋅q%d", i, j), 1e-15, Ddot(m, q[i*m:], 1, q[j*m:], 1), δ)
+		}
+	}
+
+	// check Q⋅R = A⋅P
+	for j := 0; j < n; j++ {
+		qrj := make([]float64, m)
+		for l := 0; l <= utl.Imin(j, k-1); l++ {
+			Daxpy(m, qr[l+j*m], q[l*m:], 1, qrj, 1)
+		}
+		apj := make([]float64, m)
+		for i := 0; i < m; i++ {
+			apj[i] = a[i][jpvt[j]-1]
+		}
+		chk.Array(tst, io.Sf("(Q⋅R)[:,%d]", j), 1e-14, qrj, apj)
+	}
+
+	// check Qᵀ⋅(A⋅P) = R and Q⋅R = A⋅P using Dormqr
+	ap := make([]float64, m*n)
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			ap[i+j*m] = a[i][jpvt[j]-1]
+		}
+	}
+	Dormqr(true, true, m, n, k, qr, m, tau, ap, m)
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			rij := 0.0
+			if i <= j {
+				rij = qr[i+j*m]
+			}
+			chk.Float64(tst, io.Sf("(Qᵀ⋅A⋅P)[%d,%d]", i, j), 1e-14, ap[i+j*m], rij)
+		}
+	}
+	Dormqr(true, false, m, n, k, qr, m, tau, ap, m)
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			chk.Float64(tst, io.Sf("(Q⋅R)[%d,%d]", i, j), 1e-14, ap[i+j*m], a[i][jpvt[j]-1])
+		}
+	}
+
+	// fixed column: the third column must come first
+	qr = SliceToColMajor(a)
+	jpvt = []int32{0, 0, 1, 0}
+	Dgeqp3(m, n, qr, m, jpvt, tau)
+	io.Pforan("jpvt = %v (fixed)\n", jpvt)
+	if jpvt[0] != 3 {
+		tst.Errorf("the fixed column must be the first one. jpvt = %v\n", jpvt)
+	}
+	chk.Float64(tst, "|R[0,0]|", 1e-15, math.Abs(qr[0]), math.Sqrt(6))
+}
+
+func TestDgees01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dgees01")
+
+	adeep2 := [][]float64{
+		{+0.35, +0.45, -0.14, -0.17},
+		{+0.09, +0.07, -0.54, +0.35},
+		{-0.44, -0.33, -0.03, +0.17},
+		{+0.25, -0.32, -0.13, +0.11},
+	}
+	a := SliceToColMajor(adeep2)
+	t := SliceToColMajor(adeep2)
+
+	n := 4
+	wr := make([]float64, n)
+	wi := make([]float64, n)
+	vs := make([]float64, n*n)
+	Dgees(true, n, t, n, wr, wi, vs, n)
+
+	// check eigenvalues (same as in Dgeev01, but possibly in a different order)
+	wRef := []complex128{
+		+7.994821225862098e-01,
+		-9.941245329507467e-02 + 4.007924719897546e-01i,
+		-9.941245329507467e-02 - 4.007924719897546e-01i,
+		-1.006572159960587e-01,
+	}
+	ww := GetJoinComplex(wr, wi)
+	io.Pforan("w = %v\n", ww)
+	for _, wref := range wRef {
+		found := false
+		for _, w := range ww {
+			if cmplx.Abs(w-wref) < 1e-14 {
+				found = true
+			}
+		}
+		if !found {
+			tst.Errorf("eigenvalue %v is missing\n", wref)
+		}
+	}
+
+	// check quasi-triangular form: at most one non-zero sub-diagonal per 2x2 block
+	for j := 0; j < n; j++ {
+		for i := j + 2; i < n; i++ {
+			chk.Float64(tst, io.Sf("T[%d,%d]", i, j), 1e-17, t[i+j*n], 0)
+		}
+		if j < n-1 && t[j+1+j*n] != 0 {
+			chk.Float64(tst, "T[j,j] - T[j+1,j+1]", 1e-15, t[j+j*n], t[j+1+(j+1)*n])
+			if j < n-2 {
+				chk.Float64(tst, "T[j+2,j+1]", 1e-17, t[j+2+(j+1)*n], 0)
+			}
+		}
+	}
+
+	// check orthogonality: Zᵀ⋅Z = I
+	ztz := make([]float64, n*n)
+	Dgemm(true, false, n, n, n, 1, vs, n, vs, n, 0, ztz, n)
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			δij := 0.0
+			if i == j {
+				δij = 1
+			}
+			chk.Float64(tst, io.Sf("(Zᵀ⋅Z)[%d,%d]", i, j), 1e-15, ztz[i+j*n], δij)
+		}
+	}
+
+	// check factorisation: Z⋅T⋅Zᵀ = A
+	zt := make([]float64, n*n)
+	ztzt := make([]float64, n*n)
+	Dgemm(false, false, n, n, n, 1, vs, n, t, n, 0, zt, n)
+	Dgemm(false, true, n, n, n, 1, zt, n, vs, n, 0, ztzt, n)
+	chk.Array(tst, "Z⋅T⋅Zᵀ", 1e-14, ztzt, a)
+}
+
+func TestZheev01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Zheev01")
+
+	// 2x2: λ = (5 ± √(25 - 16))/2
+	a := SliceToColMajorC([][]complex128{
+		{2, 1 - 1i},
+		{0, 3},
+	})
+	w := make([]float64, 2)
+	Zheev(false, true, 2, a, 2, w)
+	chk.Array(tst, "w(2x2)", 1e-15, w, []float64{1, 4})
+
+	// 4x4
+	adeep2 := [][]complex128{
+		{+4 + 0i, 0 + 1i, -3 + 1i, 0 + 2i},
+		{+0 - 1i, 3 + 0i, +1 + 0i, 2 + 0i},
+		{-3 - 1i, 1 + 0i, +4 + 0i, 1 - 1i},
+		{+0 - 2i, 2 + 0i, +1 + 1i, 4 + 0i},
+	}
+	n := 4
+	for _, up := range []bool{true, false} {
+		v := make([]complex128, n*n)
+		for j := 0; j < n; j++ {
+			for i := 0; i < n; i++ {
+				if (up && i <= j) || (!up && i >= j) {
+					v[i+j*n] = adeep2[i][j]
+				}
+			}
+		}
+		w = make([]float64, n)
+		Zheev(true, up, n, v, n, w)
+		io.Pforan("w = %v\n", w)
+
+		// trace and ordering
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			sum += w[i]
+			if i > 0 && w[i] < w[i-1] {
+				tst.Errorf("eigenvalues must be in ascending order: %v\n", w)
+			}
+		}
+		chk.Float64(tst, "Σλ = tr(A)", 1e-14, sum, 15)
+
+		// A⋅v = λ⋅v and Vᴴ⋅V = I
+		for k := 0; k < n; k++ {
+			for i := 0; i < n; i++ {
+				var av complex128
+				for j := 0; j < n; j++ {
+					av += adeep2[i][j] * v[j+k*n]
+				}
+				chk.Complex128(tst, io.Sf("(A⋅v%d)[%d]", k, i), 1e-14, av, complex(w[k], 0)*v[i+k*n])
+			}
+			for l := 0; l < n; l++ {
+				var vv complex128
+				for i := 0; i < n; i++ {
+					vv += cmplx.Conj(v[i+k*n]) * v[i+l*n]
+				}
+				δkl := 0.0
+				if k == l {
+					δkl = 1
+				}
+				chk.Complex128(tst, io.Sf("(Vᴴ⋅V)[%d,%d]", k, l), 1e-15, vv, complex(δkl, 0))
+			}
+		}
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"github.com/cpmech/gosl/chk"
+)
+
+// LinearOperator defines a (possibly matrix-free) linear operator A with dimensions m × n
+//
+//   y := A ⋅ x    and    y := Aᵀ ⋅ x
+//
+//   NOTE: x and y must not share memory
+//
+type LinearOperator interface {
+	Size() (m, n int)           // returns the number of rows (m) and columns (n)
+	Apply(y, x Vector)          // computes y := A ⋅ x   with len(y) = m and len(x) = n
+	ApplyTranspose(y, x Vector) // computes y := Aᵀ ⋅ x  with len(y) = n and len(x) = m
+}
+
+// OpMatrix implements LinearOperator for a dense Matrix
+type OpMatrix struct {
+	A *Matrix
+}
+
+// NewOpMatrix returns a LinearOperator wrapping a dense matrix
+func NewOpMatrix(a *Matrix) *OpMatrix {
+	return &OpMatrix{a}
+}
+
+// Size returns the dimensions of the operator
+func (o *OpMatrix) Size() (m, n int) { return o.A.M, o.A.N }
+
+// Apply computes y := A ⋅ x
+func (o *OpMatrix) Apply(y, x Vector) { MatVecMul(y, 1, o.A, x) }
+
+// ApplyTranspose computes y := Aᵀ ⋅ x
+func (o *OpMatrix) ApplyTranspose(y, x Vector) { MatTrVecMul(y, 1, o.A, x) }
+
+// OpCCMatrix implements LinearOperator for a column-compressed sparse matrix
+type OpCCMatrix struct {
+	A *CCMatrix
+}
+
+// NewOpCCMatrix returns a LinearOperator wrapping a column-compressed sparse matrix
+func NewOpCCMatrix(a *CCMatrix) *OpCCMatrix {
+	return &OpCCMatrix{a}
+}
+
+// Size returns the dimensions of the operator
+func (o *OpCCMatrix) Size() (m, n int) { return o.A.m, o.A.n }
+
+// Apply computes y := A ⋅ x
+func (o *OpCCMatrix) Apply(y, x Vector) { SpMatVecMul(y, 1, o.A, x) }
+
+// ApplyTranspose computes y := Aᵀ ⋅ x
+func (o *OpCCMatrix) ApplyTranspose(y, x Vector) { SpMatTrVecMul(y, 1, o.A, x) }
+
+// OpCSRMatrix implements LinearOperator for a compressed sparse row matrix (with parallel products)
+type OpCSRMatrix struct {
+	A *CSRMatrix
+}
+
+// NewOpCSRMatrix returns a LinearOperator wrapping a compressed sparse row matrix
+func NewOpCSRMatrix(a *CSRMatrix) *OpCSRMatrix {
+	return &OpCSRMatrix{a}
+}
+
+// Size returns the dimensions of the operator
+func (o *OpCSRMatrix) Size() (m, n int) { return o.A.m, o.A.n }
+
+// Apply computes y := A ⋅ x
+func (o *OpCSRMatrix) Apply(y, x Vector) { SpCsrMatVecMul(y, 1, o.A, x) }
+
+// ApplyTranspose computes y := Aᵀ ⋅ x
+func (o *OpCSRMatrix) ApplyTranspose(y, x Vector) { SpCsrMatTrVecMul(y, 1, o.A, x) }
+
+// OpFunc implements LinearOperator with user-defined functions (matrix-free operator)
+type OpFunc struct {
+	M, N  int               // dimensions
+	Fcn   func(y, x Vector) // computes y := A ⋅ x
+	FcnTr func(y, x Vector) // computes y := Aᵀ ⋅ x. may be nil if not needed
+}
+
+// NewOpFunc returns a matrix-free LinearOperator defined by functions
+//   fcnTr -- may be nil if the transpose is never needed (e.g. with CG or GMRES)
+func NewOpFunc(m, n int, fcn, fcnTr func(y, x Vector)) *OpFunc {
+	if fcn == nil {
+		chk.Panic("function computing y := A ⋅ x must be given\n")
+	}
+	return &OpFunc{m, n, fcn, fcnTr}
+}
+
+// Size returns the dimensions of the operator
+func (o *OpFunc) Size() (m, n int) { return o.M, o.N }
+
+// Apply computes y := A ⋅ x
+func (o *OpFunc) Apply(y, x Vector) { o.Fcn(y, x) }
+
+// ApplyTranspose computes y := Aᵀ ⋅ x
+func (o *OpFunc) ApplyTranspose(y, x Vector) {
+	if o.FcnTr == nil {
+		chk.Panic("transpose of operator is not available\n")
+	}
+	o.FcnTr(y, x)
+}
+
+// OpTranspose implements the transpose of another LinearOperator
+type OpTranspose struct {
+	Op LinearOperator
+}
+
+// NewOpTranspose returns a LinearOperator representing Aᵀ
+func NewOpTranspose(op LinearOperator) *OpTranspose {
+	return &OpTranspose{op}
+}
+
+// Size returns the dimensions of the operator
+func (o *OpTranspose) Size() (m, n int) {
+	n, m = o.Op.Size()
+	return
+}
+
+// Apply computes y := Aᵀ ⋅ x
+func (o *OpTranspose) Apply(y, x Vector) { o.Op.ApplyTranspose(y, x) }
+
+// ApplyTranspose computes y := A ⋅ x
+func (o *OpTranspose) ApplyTranspose(y, x Vector) { o.Op.Apply(y, x) }
+
+// OpBlock implements a block-structured LinearOperator composed of sub-operators
+//
+//         ┌                      ┐
+//         │ A₀₀  A₀₁  …  A₀,ₙ₋₁  │
+//   A  =  │ A₁₀  A₁₁  …  A₁,ₙ₋₁  │      where nil blocks are zero
+//         │  ⋮              ⋮    │
+//         └                      ┘
+//
+//   NOTE: every block row and every block column must have at least one non-nil block; the
+//         vectors x and y are partitioned according to the sizes of the blocks
+//
+type OpBlock struct {
+	Blocks [][]LinearOperator // [nbr][nbc] sub-operators; nil means zero
+	Rows   []int              // [nbr+1] offsets of block rows; i.e. rows of block I are Rows[I] ≤ i < Rows[I+1]
+	Cols   []int              // [nbc+1] offsets of block columns
+	work   Vector             // workspace
+}
+
+// NewOpBlock returns a new block operator (the blocks are not copied)
+func NewOpBlock(blocks [][]LinearOperator) (o *OpBlock) {
+	nbr := len(blocks)
+	if nbr == 0 {
+		chk.Panic("there must be at least one block row\n")
+	}
+	nbc := len(blocks[0])
+	sizR, sizC := make([]int, nbr), make([]int, nbc)
+	for I := 0; I < nbr; I++ {
+		sizR[I] = -1
+	}
+	for J := 0; J < nbc; J++ {
+		sizC[J] = -1
+	}
+	for I, row := range blocks {
+		if len(row) != nbc {
+			chk.Panic("all block rows must have the same number of blocks. %d != %d\n", len(row), nbc)
+		}
+		for J, b := range row {
+			if b == nil {
+				continue
+			}
+			m, n := b.Size()
+			if sizR[I] >= 0 && sizR[I] != m {
+				chk.Panic("block (%d,%d) has %d rows but block row %d has %d rows\n", I, J, m, I, sizR[I])
+			}
+			if sizC[J] >= 0 && sizC[J] != n {
+				chk.Panic("block (%d,%d) has %d columns but block column %d has %d columns\n", I, J, n, J, sizC[J])
+			}
+			sizR[I], sizC[J] = m, n
+		}
+	}
+	o = &OpBlock{Blocks: blocks, Rows: make([]int, nbr+1), Cols: make([]int, nbc+1)}
+	for I, m := range sizR {
+		if m < 0 {
+			chk.Panic("block row %d has no blocks\n", I)
+		}
+		o.Rows[I+1] = o.Rows[I] + m
+	}
+	for J, n := range sizC {
+		if n < 0 {
+			chk.Panic("block column %d has no blocks\n", J)
+		}
+		o.Cols[J+1] = o.Cols[J] + n
+	}
+	return
+}
+
+// NewOpSaddlePoint returns the block operator of a saddle-point problem
+//
+//         ┌        ┐
+//   K  =  │ A   Bᵀ │      C may be nil (zero block)
+//         │ B   C  │
+//         └        ┘
+//
+func NewOpSaddlePoint(A, B, C LinearOperator) *OpBlock {
+	return NewOpBlock([][]LinearOperator{
+		{A, NewOpTranspose(B)},
+		{B, C},
+	})
+}
+
+// Size returns the dimensions of the operator
+func (o *OpBlock) Size() (m, n int) { return o.Rows[len(o.Rows)-1], o.Cols[len(o.Cols)-1] }
+
+// Apply computes y := A ⋅ x
+func (o *OpBlock) Apply(y, x Vector) {
+	o.apply(y, x, o.Rows, o.Cols, false)
+}
+
+// ApplyTranspose computes y := Aᵀ ⋅ x
+func (o *OpBlock) ApplyTranspose(y, x Vector) {
+	o.apply(y, x, o.Cols, o.Rows, true)
+}
+
+// apply computes y := A ⋅ x or y := Aᵀ ⋅ x; the offsets of y and x are given in ry and rx
+func (o *OpBlock) apply(y, x Vector, ry, rx []int, transp bool) {
+	m, n := o.Size()
+	if transp {
+		m, n = n, m
+	}
+	if len(y) != m || len(x) != n {
+		chk.Panic("vectors have incompatible dimensions. len(y) = %d != %d or len(x) = %d != %d\n", len(y), m, len(x), n)
+	}
+	if len(o.work) < m {
+		o.work = NewVector(m)
+	}
+	y.Fill(0)
+	for I := 0; I < len(ry)-1; I++ {
+		yI := y[ry[I]:ry[I+1]]
+		tmp := o.work[:len(yI)]
+		for J := 0; J < len(rx)-1; J++ {
+			var b LinearOperator
+			if transp {
+				b = o.Blocks[J][I]
+			} else {
+				b = o.Blocks[I][J]
+			}
+			if b == nil {
+				continue
+			}
+			xJ := x[rx[J]:rx[J+1]]
+			if transp {
+				b.ApplyTranspose(tmp, xJ)
+			} else {
+				b.Apply(tmp, xJ)
+			}
+			for i, v := range tmp {
+				yI[i] += v
+			}
+		}
+	}
+}
+
+// OpToMatrix computes the dense matrix corresponding to a LinearOperator by applying it to the
+// columns of the identity matrix (useful for debugging and small problems)
+func OpToMatrix(op LinearOperator) (a *Matrix) {
+	m, n := op.Size()
+	a = NewMatrix(m, n)
+	e := NewVector(n)
+	for j := 0; j < n; j++ {
+		e[j] = 1
+		op.Apply(a.Col(j), e)
+		e[j] = 0
+	}
+	return
+}
@@ -0,0 +1,181 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/la/oblas"
+	"github.com/cpmech/gosl/utl"
+)
+
+// QR holds the QR decomposition with column pivoting of a general (m x n) matrix A
+//
+//   A ⋅ P = Q ⋅ R
+//
+//   where P is a permutation matrix, Q has orthonormal columns and R is upper triangular
+//   (trapezoidal if m < n). The columns are pivoted such that |R[0,0]| ≥ |R[1,1]| ≥ ... ;
+//   thus the diagonal of R reveals the numerical rank of A.
+//
+//   NOTE: the factors are stored in compact (LAPACK) form; use GetQ and GetR to extract them
+//
+type QR struct {
+	M, N int       // dimensions of A
+	K    int       // number of elementary reflectors = min(M, N)
+	Perm []int     // permutation: column j of A⋅P is column Perm[j] of A
+	qr   *Matrix   // R on and above the diagonal; Householder vectors below the diagonal
+	tau  []float64 // scalar factors of the elementary reflectors
+}
+
+// NewQR computes the QR decomposition with column pivoting of A (Householder method)
+//
+//   NOTE: A is not modified
+//
+func NewQR(A *Matrix) (o *QR) {
+	o = new(QR)
+	o.M, o.N, o.K = A.M, A.N, utl.Imin(A.M, A.N)
+	o.qr = A.GetCopy()
+	o.tau = make([]float64, o.K)
+	jpvt := make([]int32, o.N)
+	oblas.Dgeqp3(o.M, o.N, o.qr.Data, o.M, jpvt, o.tau)
+	o.Perm = make([]int, o.N)
+	for j := 0; j < o.N; j++ {
+		o.Perm[j] = int(jpvt[j]) - 1 // NOTE: jpvt are 1-based indices
+	}
+	return
+}
+
+// Rank returns the numerical rank of A; i.e. the number of diagonal entries of R such that
+//
+//   |R[i,i]| > tol ⋅ |R[0,0]|
+//
+//   NOTE: if tol ≤ 0, the default tolerance max(M,N) ⋅ ϵ is used (ϵ = machine epsilon)
+//
+func (o *QR) Rank(tol float64) (rank int) {
+	if o.K == 0 {
+		return
+	}
+	if tol <= 0 {
+		tol = float64(utl.Imax(o.M, o.N)) * (math.Nextafter(1, 2) - 1)
+	}
+	lim := tol * math.Abs(o.qr.Get(0, 0))
+	for rank < o.K && math.Abs(o.qr.Get(rank, rank)) > lim {
+		rank++
+	}
+	return
+}
+
+// GetQ computes the economy-size Q matrix with orthonormal columns
+//
+//   Q -- [pre-allocated] (M x K) matrix, where K = min(M,N)
+//
+func (o *QR) GetQ(Q *Matrix) {
+	if o.K == 0 {
+		return
+	}
+	copy(Q.Data, o.qr.Data[:o.M*o.K])
+	oblas.Dorgqr(o.M, o.K, o.K, Q.Data, o.M, o.tau)
+}
+
+// GetR computes the economy-size upper triangular (trapezoidal) R matrix
+//
+//   R -- [pre-allocated] (K x N) matrix, where K = min(M,N)
+//
+func (o *QR) GetR(R *Matrix) {
+	for j := 0; j < o.N; j++ {
+		for i := 0; i < o.K; i++ {
+			if i <= j {
+				R.Set(i, j, o.qr.Get(i, j))
+			} else {
+				R.Set(i, j, 0)
+			}
+		}
+	}
+}
+
+// Solve computes the minimum-norm solution of the least-squares problem
+//
+//   min ‖ A ⋅ x - b ‖₂    (with the smallest ‖x‖₂ if A is rank deficient or M < N)
+//
+//   x   -- [pre-allocated] solution vector (len(x) = N)
+//   b   -- right-hand side (len(b) = M)
+//   tol -- tolerance to determine the numerical rank of A (see Rank); use 0 for the default
+//
+//   NOTE: if A has full column rank, x is obtained by back substitution with R; otherwise,
+//         the rank-deficient part of R is annihilated by an orthogonal transformation from the
+//         right (complete orthogonal factorisation), as in LAPACK's dgelsy.
+//
+func (o *QR) Solve(x, b Vector, tol float64) (rank int) {
+
+	// numerical rank
+	x.Fill(0)
+	rank = o.Rank(tol)
+	if rank == 0 {
+		return
+	}
+
+	// c := Qᵀ ⋅ b
+	c := b.GetCopy()
+	oblas.Dormqr(true, true, o.M, 1, o.K, o.qr.Data, o.M, o.tau, c, o.M)
+
+	// full column rank: solve R ⋅ w = c
+	w := NewVector(o.N)
+	if rank == o.N {
+		for i := o.N - 1; i >= 0; i-- {
+			sum := c[i]
+			for j := i + 1; j < o.N; j++ {
+				sum -= o.qr.Get(i, j) * w[j]
+			}
+			w[i] = sum / o.qr.Get(i, i)
+		}
+		for j := 0; j < o.N; j++ {
+			x[o.Perm[j]] = w[j]
+		}
+		return
+	}
+
+	// rank deficient: [R11 R12]ᵀ = Z ⋅ [T; 0] with T (rank x rank) upper triangular
+	t := NewMatrix(o.N, rank)
+	for i := 0; i < rank; i++ {
+		for j := i; j < o.N; j++ {
+			t.Set(j, i, o.qr.Get(i, j))
+		}
+	}
+	tauz := make([]float64, rank)
+	oblas.Dgeqrf(o.N, rank, t.Data, o.N, tauz)
+
+	// solve Tᵀ ⋅ y = c[:rank] (storing y in w)
+	for i := 0; i < rank; i++ {
+		sum := c[i]
+		for j := 0; j < i; j++ {
+			sum -= t.Get(j, i) * w[j]
+		}
+		w[i] = sum / t.Get(i, i)
+	}
+
+	// w := Z ⋅ [y; 0]
+	oblas.Dormqr(true, false, o.N, 1, rank, t.Data, o.N, tauz, w, o.N)
+	for j := 0; j < o.N; j++ {
+		x[o.Perm[j]] = w[j]
+	}
+	return
+}
+
+// LeastSquares computes the minimum-norm solution of the (overdetermined, underdetermined or
+// rank-deficient) least-squares problem using the QR decomposition with column pivoting
+//
+//   min ‖ A ⋅ x - b ‖₂    (with the smallest ‖x‖₂ if A is rank deficient or M < N)
+//
+//   x -- [pre-allocated] solution vector (len(x) = A.N)
+//   b -- right-hand side (len(b) = A.M)
+//
+//   Output:
+//     rank -- the numerical rank of A (computed with the default tolerance; see QR.Rank)
+//
+//   NOTE: A is not modified
+//
+func LeastSquares(x Vector, A *Matrix, b Vector) (rank int) {
+	return NewQR(A).Solve(x, b, 0)
+}
@@ -0,0 +1,763 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"bytes"
+	"container/heap"
+	"math"
+	"math/rand"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/mpi"
+)
+
+// Amg implements algebraic multigrid (AMG) methods for sparse linear systems; e.g. from the
+// discretisation of Poisson-like (elliptic) equations. Amg is a SparseSolver (kind "amg";
+// multigrid cycles are repeated until convergence) and PrecAmg is the corresponding
+// Preconditioner (kind "amg"; one cycle with zero initial guess is applied)
+//
+//   Method:
+//     "sa" -- smoothed aggregation: the strongly connected nodes are grouped into aggregates and
+//             the piecewise-constant (tentative) prolongation is smoothed by one damped Jacobi step
+//     "rs" -- classical Ruge-Stüben: the nodes are split into coarse (C) and fine (F) points and
+//             the F points are interpolated directly from their strongly connected C points
+//
+//   The coarse matrices are computed with the Galerkin product Aₗ₊₁ = Pᵀ ⋅ Aₗ ⋅ P and the
+//   system at the coarsest level is solved directly (dense inverse)
+//
+//   Smoother:
+//     "jacobi"    -- damped Jacobi with factor JacobiOmega
+//     "gs"        -- Gauss-Seidel: forward sweeps before and backward sweeps after the coarse
+//                    correction; thus, the cycle is symmetric if NumPre == NumPost
+//     "chebyshev" -- Chebyshev polynomial of degree ChebDegree in D⁻¹⋅A targeting the upper part
+//                    of the spectrum [λmax/30, 1.1⋅λmax], with λmax estimated by power iterations
+//
+//   NOTE: (1) A should be (nearly) symmetric positive-definite with non-zero diagonal
+//         (2) the settings may be changed after NewAmg (or NewSparseSolver("amg").(*Amg)) and
+//             before Fact or Init
+//
+//   References:
+//    [1] Vaněk P, Mandel J and Brezina M (1996) Algebraic multigrid by smoothed aggregation for
+//        second and fourth order elliptic problems. Computing 56:179-196
+//    [2] Stüben K (2001) A review of algebraic multigrid. J. Comput. Appl. Math. 128:281-309
+//    [3] Briggs WL, Henson VE and McCormick SF (2000) A Multigrid Tutorial, 2nd Edition. SIAM
+type Amg struct {
+
+	// settings
+	Method      string  // "sa" (smoothed aggregation) or "rs" (Ruge-Stüben)
+	Smoother    string  // "jacobi", "gs" or "chebyshev"
+	Cycle       string  // "V" or "W"
+	NumPre      int     // number of pre-smoothing sweeps
+	NumPost     int     // number of post-smoothing sweeps
+	Theta       float64 // strength threshold; 0 means 0.08 for "sa" and 0.25 for "rs"
+	MaxLevels   int     // maximum number of levels
+	MaxCoarse   int     // maximum dimension of the coarsest level
+	JacobiOmega float64 // damping factor of the Jacobi smoother
+	ChebDegree  int     // degree of the Chebyshev smoother
+	Tol         float64 // tolerance on the relative residual ‖b - A⋅x‖ / ‖b‖ (solver only)
+	MaxIt       int     // maximum number of cycles (solver only)
+
+	// statistics
+	NumIt   int       // number of cycles performed by the last call to Solve
+	Resid   float64   // relative residual at the end of the last call to Solve
+	History []float64 // relative residual after each cycle (History[0] refers to x₀ = 0)
+
+	// internal
+	verbose bool        // show messages
+	t       *Triplet    // triplet with A (solver only)
+	levels  []*amgLevel // multigrid hierarchy; level 0 is the finest
+}
+
+// amgLevel holds the data of one level of the multigrid hierarchy
+type amgLevel struct {
+	n      int       // dimension
+	rp, rj []int     // A in row-compressed form: pointers and column indices
+	rx     []float64 // A in row-compressed form: values
+	diag   []float64 // diagonal of A
+	pp, pj []int     // prolongation P (n × ncoarse) in row-compressed form; nil at the coarsest level
+	px     []float64 // values of P
+	λmax   float64   // estimate of the largest eigenvalue of D⁻¹⋅A (Chebyshev smoother)
+	ainv   *Matrix   // inverse of A at the coarsest level
+	x, b   Vector    // solution and right-hand side (levels > 0)
+	r, d   Vector    // workspace
+}
+
+// NewAmg returns a new AMG solver/preconditioner with default settings
+func NewAmg() (o *Amg) {
+	o = new(Amg)
+	o.Method = "sa"
+	o.Smoother = "gs"
+	o.Cycle = "V"
+	o.NumPre = 1
+	o.NumPost = 1
+	o.MaxLevels = 10
+	o.MaxCoarse = 50
+	o.JacobiOmega = 2.0 / 3.0
+	o.ChebDegree = 3
+	o.Tol = 1e-8
+	o.MaxIt = 100
+	return
+}
+
+// solver and preconditioner interfaces ////////////////////////////////////////////////////////////
+
+// Init initialises the AMG solver (SparseSolver interface)
+//  NOTE: symmetric, ordering, scaling and comm are not used
+func (o *Amg) Init(t *Triplet, symmetric, verbose bool, ordering, scaling string, comm *mpi.Communicator) {
+	if t.m != t.n {
+		chk.Panic("AMG requires a square matrix. %d × %d is invalid\n", t.m, t.n)
+	}
+	o.t = t
+	o.verbose = verbose
+}
+
+// Free frees memory
+func (o *Amg) Free() {
+}
+
+// Fact converts the triplet to column-compressed format and builds the multigrid hierarchy
+func (o *Amg) Fact() {
+	if o.t == nil {
+		chk.Panic("linear solver must be initialised first\n")
+	}
+	o.Setup(o.t.ToMatrix(nil))
+	if o.verbose {
+		io.Pf("%s", o.Summary())
+	}
+}
+
+// Solve solves the linear system by repeating multigrid cycles (SparseSolver interface)
+//
+//   Given:  A ⋅ x = b    find x   such that   ‖b - A ⋅ x‖ ≤ Tol ⋅ ‖b‖
+//
+//   NOTE: bIsDistr is not used
+func (o *Amg) Solve(x, b Vector, bIsDistr bool) {
+	if len(o.levels) == 0 {
+		chk.Panic("multigrid hierarchy must be built first (Fact or Setup)\n")
+	}
+	lev := o.levels[0]
+	x.Fill(0)
+	o.NumIt = 0
+	o.History = append(o.History[:0], 1)
+	nb := b.Norm()
+	if nb == 0 {
+		o.Resid, o.History[0] = 0, 0
+		return
+	}
+	o.Resid = 1
+	for o.NumIt < o.MaxIt && o.Resid > o.Tol {
+		o.NumIt++
+		o.cycle(0, x, b)
+		lev.residual(lev.r, x, b)
+		o.Resid = lev.r.Norm() / nb
+		o.History = append(o.History, o.Resid)
+		if o.verbose {
+			io.Pf("amg: it = %4d  ‖r‖/‖b‖ = %23.15e\n", o.NumIt, o.Resid)
+		}
+	}
+	if o.Resid > o.Tol {
+		chk.Panic("amg: cannot converge after %d cycles. relative residual = %g\n", o.NumIt, o.Resid)
+	}
+}
+
+// Apply applies one multigrid cycle with zero initial guess: z := M⁻¹ ⋅ r
+func (o *Amg) Apply(z, r Vector) {
+	z.Fill(0)
+	o.cycle(0, z, r)
+}
+
+// PrecAmg implements the algebraic multigrid preconditioner: one cycle with zero initial guess
+//
+//   NOTE: see Amg for the settings. Use NewPrecAmg to get the default settings
+type PrecAmg struct {
+	Amg
+}
+
+// NewPrecAmg returns a new AMG preconditioner with default settings
+func NewPrecAmg() (o *PrecAmg) {
+	return &PrecAmg{*NewAmg()}
+}
+
+// Init initialises the preconditioner (builds the multigrid hierarchy)
+func (o *PrecAmg) Init(a *CCMatrix) {
+	o.Setup(a)
+}
+
+// Setup builds the multigrid hierarchy (also called by Fact)
+func (o *Amg) Setup(a *CCMatrix) {
+
+	// check
+	switch o.Method {
+	case "sa", "rs":
+	default:
+		chk.Panic("AMG method %q is invalid. options are \"sa\" or \"rs\"\n", o.Method)
+	}
+	switch o.Smoother {
+	case "jacobi", "gs", "chebyshev":
+	default:
+		chk.Panic("AMG smoother %q is invalid. options are \"jacobi\", \"gs\" or \"chebyshev\"\n", o.Smoother)
+	}
+	if o.Cycle != "V" && o.Cycle != "W" {
+		chk.Panic("AMG cycle %q is invalid. options are \"V\" or \"W\"\n", o.Cycle)
+	}
+	if a.m != a.n {
+		chk.Panic("AMG requires a square matrix. %d × %d is invalid\n", a.m, a.n)
+	}
+	θ := o.Theta
+	if θ == 0 {
+		θ = 0.08
+		if o.Method == "rs" {
+			θ = 0.25
+		}
+	}
+
+	// levels
+	o.levels = o.levels[:0]
+	for {
+		lev := newAmgLevel(a)
+		o.levels = append(o.levels, lev)
+		if a.n <= o.MaxCoarse || len(o.levels) == o.MaxLevels {
+			break
+		}
+		var P *CCMatrix
+		if o.Method == "sa" {
+			P = lev.saProlongation(a, θ)
+		} else {
+			P = lev.rsProlongation(θ)
+		}
+		if P.n == 0 || P.n == a.n {
+			break // coarsening has stagnated
+		}
+		lev.pp, lev.pj, lev.px = spRowCompress(P)
+		a = SpPtAP(a, P)
+	}
+
+	// coarsest level
+	last := o.levels[len(o.levels)-1]
+	if last.n > 5000 {
+		chk.Panic("AMG coarsening has stagnated with %d rows at level %d\n", last.n, len(o.levels)-1)
+	}
+	last.ainv = NewMatrix(last.n, last.n)
+	MatInv(last.ainv, a.ToDense(), false)
+
+	// smoother data
+	if o.Smoother == "chebyshev" {
+		for _, lev := range o.levels[:len(o.levels)-1] {
+			lev.λmax = lev.rhoDinvA()
+		}
+	}
+}
+
+// Summary returns a report with the multigrid hierarchy
+func (o *Amg) Summary() string {
+	var b bytes.Buffer
+	io.Ff(&b, "AMG hierarchy: method = %s, cycle = %s, smoother = %s\n", o.Method, o.Cycle, o.Smoother)
+	io.Ff(&b, "%6s%12s%12s%10s\n", "level", "rows", "nnz", "nnz/row")
+	var sumN, sumNnz int
+	for l, lev := range o.levels {
+		nnz := lev.rp[lev.n]
+		sumN += lev.n
+		sumNnz += nnz
+		io.Ff(&b, "%6d%12d%12d%10.2f\n", l, lev.n, nnz, float64(nnz)/float64(lev.n))
+	}
+	oc, gc := o.Complexity()
+	io.Ff(&b, "operator complexity = %.3f\n", oc)
+	io.Ff(&b, "grid complexity     = %.3f\n", gc)
+	return b.String()
+}
+
+// Complexity returns the operator complexity (Σ nnz(Aₗ) / nnz(A₀)) and the grid complexity
+// (Σ nₗ / n₀) of the multigrid hierarchy
+func (o *Amg) Complexity() (operator, grid float64) {
+	if len(o.levels) == 0 {
+		return
+	}
+	for _, lev := range o.levels {
+		operator += float64(lev.rp[lev.n])
+		grid += float64(lev.n)
+	}
+	return operator / float64(o.levels[0].rp[o.levels[0].n]), grid / float64(o.levels[0].n)
+}
+
+// NumLevels returns the number of levels in the multigrid hierarchy
+func (o *Amg) NumLevels() int { return len(o.levels) }
+
+// cycles and smoothers ////////////////////////////////////////////////////////////////////////////
+
+// cycle performs one V- or W-cycle starting at level l
+func (o *Amg) cycle(l int, x, b Vector) {
+	lev := o.levels[l]
+	if lev.ainv != nil {
+		MatVecMul(x, 1, lev.ainv, b)
+		return
+	}
+	o.smooth(lev, x, b, o.NumPre, true)
+
+	// restriction: bc := Pᵀ ⋅ (b - A ⋅ x)
+	next := o.levels[l+1]
+	lev.residual(lev.r, x, b)
+	next.b.Fill(0)
+	for i := 0; i < lev.n; i++ {
+		for k := lev.pp[i]; k < lev.pp[i+1]; k++ {
+			next.b[lev.pj[k]] += lev.px[k] * lev.r[i]
+		}
+	}
+
+	// coarse-grid correction: x := x + P ⋅ xc
+	next.x.Fill(0)
+	o.cycle(l+1, next.x, next.b)
+	if o.Cycle == "W" && next.ainv == nil {
+		o.cycle(l+1, next.x, next.b)
+	}
+	for i := 0; i < lev.n; i++ {
+		for k := lev.pp[i]; k < lev.pp[i+1]; k++ {
+			x[i] += lev.px[k] * next.x[lev.pj[k]]
+		}
+	}
+	o.smooth(lev, x, b, o.NumPost, false)
+}
+
+// smooth applies nu sweeps of the smoother; forward indicates pre-smoothing
+func (o *Amg) smooth(lev *amgLevel, x, b Vector, nu int, forward bool) {
+	for s := 0; s < nu; s++ {
+		switch o.Smoother {
+		case "jacobi":
+			lev.residual(lev.r, x, b)
+			for i := 0; i < lev.n; i++ {
+				x[i] += o.JacobiOmega * lev.r[i] / lev.diag[i]
+			}
+		case "gs":
+			lev.gaussSeidel(x, b, forward)
+		case "chebyshev":
+			lev.chebyshev(x, b, o.ChebDegree)
+		}
+	}
+}
+
+// amgLevel ////////////////////////////////////////////////////////////////////////////////////////
+
+// newAmgLevel allocates a new level with matrix a
+func newAmgLevel(a *CCMatrix) (o *amgLevel) {
+	o = new(amgLevel)
+	o.n = a.n
+	o.rp, o.rj, o.rx = spRowCompress(a)
+	o.diag = spRowDiag(a.n, o.rp, o.rj, o.rx)
+	o.x, o.b = NewVector(o.n), NewVector(o.n)
+	o.r, o.d = NewVector(o.n), NewVector(o.n)
+	return
+}
+
+// residual computes r := b - A ⋅ x
+func (o *amgLevel) residual(r, x, b Vector) {
+	for i := 0; i < o.n; i++ {
+		sum := b[i]
+		for k := o.rp[i]; k < o.rp[i+1]; k++ {
+			sum -= o.rx[k] * x[o.rj[k]]
+		}
+		r[i] = sum
+	}
+}
+
+// gaussSeidel performs one forward or backward Gauss-Seidel sweep
+func (o *amgLevel) gaussSeidel(x, b Vector, forward bool) {
+	for s := 0; s < o.n; s++ {
+		i := s
+		if !forward {
+			i = o.n - 1 - s
+		}
+		sum := b[i]
+		for k := o.rp[i]; k < o.rp[i+1]; k++ {
+			if o.rj[k] != i {
+				sum -= o.rx[k] * x[o.rj[k]]
+			}
+		}
+		x[i] = sum / o.diag[i]
+	}
+}
+
+// chebyshev applies the Chebyshev iteration with the given degree to D⁻¹⋅A⋅x = D⁻¹⋅b
+func (o *amgLevel) chebyshev(x, b Vector, degree int) {
+	upper := 1.1 * o.λmax
+	lower := o.λmax / 30.0
+	θ, δ := (upper+lower)/2, (upper-lower)/2
+	σ := θ / δ
+	ρ := 1.0 / σ
+	o.residual(o.r, x, b)
+	for i := 0; i < o.n; i++ {
+		o.d[i] = o.r[i] / o.diag[i] / θ
+	}
+	for k := 0; k < degree; k++ {
+		for i := 0; i < o.n; i++ {
+			x[i] += o.d[i]
+		}
+		if k == degree-1 {
+			break
+		}
+		o.residual(o.r, x, b)
+		ρnew := 1.0 / (2*σ - ρ)
+		for i := 0; i < o.n; i++ {
+			o.d[i] = ρnew*ρ*o.d[i] + 2*ρnew/δ*o.r[i]/o.diag[i]
+		}
+		ρ = ρnew
+	}
+}
+
+// rhoDinvA estimates the spectral radius of D⁻¹⋅A by power iterations
+func (o *amgLevel) rhoDinvA() (ρ float64) {
+	rng := rand.New(rand.NewSource(1234))
+	v, w := NewVector(o.n), NewVector(o.n)
+	for i := 0; i < o.n; i++ {
+		v[i] = rng.Float64()
+	}
+	v.Apply(1.0/v.Norm(), v)
+	for it := 0; it < 20; it++ {
+		for i := 0; i < o.n; i++ {
+			sum := 0.0
+			for k := o.rp[i]; k < o.rp[i+1]; k++ {
+				sum += o.rx[k] * v[o.rj[k]]
+			}
+			w[i] = sum / o.diag[i]
+		}
+		ρ = w.Norm()
+		if ρ == 0 {
+			return 1
+		}
+		v.Apply(1.0/ρ, w)
+	}
+	return
+}
+
+// strength returns the strongly connected neighbours of each node in row-compressed form
+//   sa: |aᵢⱼ| ≥ θ ⋅ sqrt(|aᵢᵢ ⋅ aⱼⱼ|)
+//   rs: -aᵢⱼ ≥ θ ⋅ maxₖ≠ᵢ(-aᵢₖ)
+func (o *amgLevel) strength(θ float64, classical bool) (sp, sj []int) {
+	sp = make([]int, o.n+1)
+	sj = make([]int, 0, o.rp[o.n])
+	for i := 0; i < o.n; i++ {
+		maxNeg := 0.0
+		if classical {
+			for k := o.rp[i]; k < o.rp[i+1]; k++ {
+				if o.rj[k] != i && -o.rx[k] > maxNeg {
+					maxNeg = -o.rx[k]
+				}
+			}
+		}
+		for k := o.rp[i]; k < o.rp[i+1]; k++ {
+			j := o.rj[k]
+			if j == i {
+				continue
+			}
+			if classical {
+				if maxNeg > 0 && -o.rx[k] >= θ*maxNeg {
+					sj = append(sj, j)
+				}
+			} else if o.rx[k]*o.rx[k] >= θ*θ*math.Abs(o.diag[i]*o.diag[j]) && o.rx[k] != 0 {
+				sj = append(sj, j)
+			}
+		}
+		sp[i+1] = len(sj)
+	}
+	return
+}
+
+// saProlongation computes the smoothed-aggregation prolongation P = (I - ω⋅D⁻¹⋅A) ⋅ T where T is
+// the tentative (piecewise-constant) prolongation and ω = 4 / (3 ⋅ ρ(D⁻¹⋅A))
+func (o *amgLevel) saProlongation(a *CCMatrix, θ float64) (P *CCMatrix) {
+
+	// aggregation
+	sp, sj := o.strength(θ, false)
+	agg := make([]int, o.n)
+	for i := 0; i < o.n; i++ {
+		agg[i] = -1
+	}
+	nagg := 0
+
+	// pass 1: nodes whose strong neighbours are all free form new aggregates
+	for i := 0; i < o.n; i++ {
+		if agg[i] >= 0 || sp[i] == sp[i+1] {
+			continue
+		}
+		free := true
+		for k := sp[i]; k < sp[i+1]; k++ {
+			if agg[sj[k]] >= 0 {
+				free = false
+				break
+			}
+		}
+		if free {
+			agg[i] = nagg
+			for k := sp[i]; k < sp[i+1]; k++ {
+				agg[sj[k]] = nagg
+			}
+			nagg++
+		}
+	}
+
+	// pass 2: remaining nodes join an aggregate of a strong neighbour
+	pass1 := make([]int, o.n)
+	copy(pass1, agg)
+	for i := 0; i < o.n; i++ {
+		if agg[i] >= 0 {
+			continue
+		}
+		for k := sp[i]; k < sp[i+1]; k++ {
+			if pass1[sj[k]] >= 0 {
+				agg[i] = pass1[sj[k]]
+				break
+			}
+		}
+	}
+
+	// pass 3: remaining nodes form aggregates with their free strong neighbours
+	for i := 0; i < o.n; i++ {
+		if agg[i] >= 0 || sp[i] == sp[i+1] {
+			continue
+		}
+		agg[i] = nagg
+		for k := sp[i]; k < sp[i+1]; k++ {
+			if agg[sj[k]] < 0 {
+				agg[sj[k]] = nagg
+			}
+		}
+		nagg++
+	}
+	if nagg == 0 {
+		return &CCMatrix{m: o.n, p: []int{0}}
+	}
+
+	// tentative prolongation with normalised columns (isolated nodes are not interpolated)
+	size := make([]int, nagg)
+	for i := 0; i < o.n; i++ {
+		if agg[i] >= 0 {
+			size[agg[i]]++
+		}
+	}
+	tt := NewTriplet(o.n, nagg, o.n)
+	for i := 0; i < o.n; i++ {
+		if agg[i] >= 0 {
+			tt.Put(i, agg[i], 1.0/math.Sqrt(float64(size[agg[i]])))
+		}
+	}
+	T := tt.ToMatrix(nil)
+
+	// smoothing
+	ω := 4.0 / (3.0 * o.rhoDinvA())
+	dinvA := new(CCMatrix)
+	SpInitSimilar(dinvA, a)
+	for k := 0; k < a.nnz; k++ {
+		dinvA.x[k] = a.x[k] / o.diag[a.i[k]]
+	}
+	AT := SpMatMatMul(1, dinvA, T)
+	P, t2p, at2p := SpAllocMatAddMat(T, AT)
+	SpMatAddMat(P, 1, T, -ω, AT, t2p, at2p)
+	return
+}
+
+// rsProlongation computes the Ruge-Stüben C/F splitting and the direct interpolation
+func (o *amgLevel) rsProlongation(θ float64) (P *CCMatrix) {
+
+	// strong connections (S) and their transpose (Sᵀ: nodes strongly depending on i)
+	sp, sj := o.strength(θ, true)
+	tp := make([]int, o.n+1)
+	tj := make([]int, len(sj))
+	for k := 0; k < len(sj); k++ {
+		tp[sj[k]+1]++
+	}
+	for i := 0; i < o.n; i++ {
+		tp[i+1] += tp[i]
+	}
+	next := make([]int, o.n)
+	copy(next, tp[:o.n])
+	for i := 0; i < o.n; i++ {
+		for k := sp[i]; k < sp[i+1]; k++ {
+			tj[next[sj[k]]] = i
+			next[sj[k]]++
+		}
+	}
+
+	// first pass: pick C points with maximum measure λᵢ = |Sᵀᵢ| + number of F points in Sᵀᵢ
+	const (
+		free   = 0
+		cPoint = 1
+		fPoint = 2
+	)
+	state := make([]int, o.n)
+	λ := make([]int, o.n)
+	h := new(amgHeap)
+	for i := 0; i < o.n; i++ {
+		if sp[i] == sp[i+1] && tp[i] == tp[i+1] {
+			state[i] = fPoint // isolated node
+			continue
+		}
+		λ[i] = tp[i+1] - tp[i]
+		heap.Push(h, amgHeapItem{λ[i], i})
+	}
+	for h.Len() > 0 {
+		item := heap.Pop(h).(amgHeapItem)
+		i := item.i
+		if state[i] != free || item.λ != λ[i] {
+			continue // stale entry
+		}
+		state[i] = cPoint
+		for k := tp[i]; k < tp[i+1]; k++ {
+			j := tj[k]
+			if state[j] != free {
+				continue
+			}
+			state[j] = fPoint
+			for q := sp[j]; q < sp[j+1]; q++ {
+				if l := sj[q]; state[l] == free {
+					λ[l]++
+					heap.Push(h, amgHeapItem{λ[l], l})
+				}
+			}
+		}
+		for k := sp[i]; k < sp[i+1]; k++ {
+			if j := sj[k]; state[j] == free && λ[j] > 0 {
+				λ[j]--
+				heap.Push(h, amgHeapItem{λ[j], j})
+			}
+		}
+	}
+
+	// second pass: strongly connected F points must share a C point
+	mark := make([]int, o.n)
+	for i := 0; i < o.n; i++ {
+		mark[i] = -1
+	}
+	for i := 0; i < o.n; i++ {
+		if state[i] != fPoint {
+			continue
+		}
+		for k := sp[i]; k < sp[i+1]; k++ {
+			if state[sj[k]] == cPoint {
+				mark[sj[k]] = i
+			}
+		}
+		for k := sp[i]; k < sp[i+1]; k++ {
+			j := sj[k]
+			if state[j] != fPoint {
+				continue
+			}
+			shared := false
+			for q := sp[j]; q < sp[j+1]; q++ {
+				if mark[sj[q]] == i && state[sj[q]] == cPoint {
+					shared = true
+					break
+				}
+			}
+			if !shared {
+				state[j] = cPoint
+				mark[j] = i
+			}
+		}
+	}
+
+	// coarse indices
+	cidx := make([]int, o.n)
+	nc := 0
+	for i := 0; i < o.n; i++ {
+		if state[i] == cPoint {
+			cidx[i] = nc
+			nc++
+		}
+	}
+	if nc == 0 {
+		return &CCMatrix{m: o.n, p: []int{0}}
+	}
+
+	// direct interpolation
+	t := NewTriplet(o.n, nc, nc+len(sj))
+	isStrongC := make([]int, o.n)
+	for i := 0; i < o.n; i++ {
+		isStrongC[i] = -1
+	}
+	for i := 0; i < o.n; i++ {
+		if state[i] == cPoint {
+			t.Put(i, cidx[i], 1)
+			continue
+		}
+		for k := sp[i]; k < sp[i+1]; k++ {
+			if state[sj[k]] == cPoint {
+				isStrongC[sj[k]] = i
+			}
+		}
+		var sumNneg, sumNpos, sumCneg, sumCpos float64
+		aii := o.diag[i]
+		for k := o.rp[i]; k < o.rp[i+1]; k++ {
+			j, aij := o.rj[k], o.rx[k]
+			if j == i {
+				continue
+			}
+			if aij < 0 {
+				sumNneg += aij
+			} else {
+				sumNpos += aij
+			}
+			if isStrongC[j] == i {
+				if aij < 0 {
+					sumCneg += aij
+				} else {
+					sumCpos += aij
+				}
+			}
+		}
+		α, β := 0.0, 0.0
+		if sumCneg != 0 {
+			α = sumNneg / sumCneg
+		}
+		if sumCpos != 0 {
+			β = sumNpos / sumCpos
+		} else {
+			aii += sumNpos
+		}
+		for k := o.rp[i]; k < o.rp[i+1]; k++ {
+			j, aij := o.rj[k], o.rx[k]
+			if j == i || isStrongC[j] != i {
+				continue
+			}
+			if aij < 0 {
+				t.Put(i, cidx[j], -α*aij/aii)
+			} else {
+				t.Put(i, cidx[j], -β*aij/aii)
+			}
+		}
+	}
+	return t.ToMatrix(nil)
+}
+
+// amgHeap implements a max-heap of nodes sorted by measure (ties: smallest index first)
+type amgHeap []amgHeapItem
+
+// amgHeapItem holds one entry of amgHeap
+type amgHeapItem struct {
+	λ, i int
+}
+
+func (h amgHeap) Len() int { return len(h) }
+func (h amgHeap) Less(a, b int) bool {
+	if h[a].λ == h[b].λ {
+		return h[a].i < h[b].i
+	}
+	return h[a].λ > h[b].λ
+}
+func (h amgHeap) Swap(a, b int)       { h[a], h[b] = h[b], h[a] }
+func (h *amgHeap) Push(x interface{}) { *h = append(*h, x.(amgHeapItem)) }
+func (h *amgHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// add solver and preconditioner to databases //////////////////////////////////////////////////////
+
+func init() {
+	spSolverDB["amg"] = func() SparseSolver { return NewAmg() }
+	precondDB["amg"] = func() Preconditioner { return NewPrecAmg() }
+}
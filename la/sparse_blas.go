@@ -4,7 +4,12 @@
 
 package la
 
-import "github.com/cpmech/gosl/chk"
+import (
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
 
 // --------------------------------------------------------------------------------------------------
 // matrix-matrix ------------------------------------------------------------------------------------
@@ -24,6 +29,187 @@ func SpMatMatTrMul(b *Matrix, α float64, a *CCMatrix) {
 	}
 }
 
+// SpMatMatMul computes the sparse matrix-matrix multiplication:
+//  c := α * a * b   c_ij = α * a_ik * b_kj
+//  NOTE: c is allocated here and the row indices in each column of c are sorted
+func SpMatMatMul(α float64, a, b *CCMatrix) (c *CCMatrix) {
+	if a.n != b.m {
+		chk.Panic("matrices 'a' (%dx%d) and 'b' (%dx%d) are incompatible", a.m, a.n, b.m, b.n)
+	}
+	c = new(CCMatrix)
+	c.m, c.n = a.m, b.n
+	c.p = make([]int, c.n+1)
+	c.i = make([]int, 0, a.nnz+b.nnz)
+	c.x = make([]float64, 0, a.nnz+b.nnz)
+	w := make([]float64, a.m) // accumulator for the current column
+	mark := make([]int, a.m)  // mark[i] = j+1 if row i is already in column j of c
+	for j := 0; j < b.n; j++ {
+		start := len(c.i)
+		for kb := b.p[j]; kb < b.p[j+1]; kb++ {
+			l, blj := b.i[kb], b.x[kb]
+			for ka := a.p[l]; ka < a.p[l+1]; ka++ {
+				i := a.i[ka]
+				if mark[i] != j+1 {
+					mark[i] = j + 1
+					w[i] = 0
+					c.i = append(c.i, i)
+				}
+				w[i] += a.x[ka] * blj
+			}
+		}
+		sort.Ints(c.i[start:])
+		for _, i := range c.i[start:] {
+			c.x = append(c.x, α*w[i])
+		}
+		c.p[j+1] = len(c.i)
+	}
+	c.nnz = len(c.i)
+	return
+}
+
+// SpTranspose returns the transpose of a sparse matrix:
+//  at := aᵀ
+//  NOTE: at is allocated here and the row indices in each column of at are sorted
+func SpTranspose(a *CCMatrix) (at *CCMatrix) {
+	at = new(CCMatrix)
+	at.m, at.n, at.nnz = a.n, a.m, a.p[a.n]
+	at.p = make([]int, at.n+1)
+	at.i = make([]int, at.nnz)
+	at.x = make([]float64, at.nnz)
+	for k := 0; k < at.nnz; k++ {
+		at.p[a.i[k]+1]++
+	}
+	for i := 0; i < at.n; i++ {
+		at.p[i+1] += at.p[i]
+	}
+	w := make([]int, at.n)
+	copy(w, at.p[:at.n])
+	for j := 0; j < a.n; j++ {
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			q := w[a.i[k]]
+			at.i[q], at.x[q] = j, a.x[k]
+			w[a.i[k]]++
+		}
+	}
+	return
+}
+
+// SpPtAP computes the Galerkin (triple) product used in multigrid methods:
+//  c := pᵀ * a * p
+//  NOTE: c is allocated here
+func SpPtAP(a, p *CCMatrix) (c *CCMatrix) {
+	return SpMatMatMul(1, SpTranspose(p), SpMatMatMul(1, a, p))
+}
+
+// SpMatDenseMul computes the multiplication of a sparse matrix by a dense matrix:
+//  c := α * a * b   c_ij = α * a_ik * b_kj
+//  NOTE: dense matrix c will be first initialised with zeros
+func SpMatDenseMul(c *Matrix, α float64, a *CCMatrix, b *Matrix) {
+	if a.n != b.M || c.M != a.m || c.N != b.N {
+		chk.Panic("matrices are incompatible: c (%dx%d) := a (%dx%d) * b (%dx%d)", c.M, c.N, a.m, a.n, b.M, b.N)
+	}
+	c.Fill(0)
+	for q := 0; q < b.N; q++ {
+		SpMatVecMulAdd(c.Col(q), α, a, b.Col(q))
+	}
+}
+
+// SpMatTrDenseMul computes the multiplication of a transposed sparse matrix by a dense matrix:
+//  c := α * aᵀ * b   c_ij = α * a_ki * b_kj
+//  NOTE: dense matrix c will be first initialised with zeros
+func SpMatTrDenseMul(c *Matrix, α float64, a *CCMatrix, b *Matrix) {
+	if a.m != b.M || c.M != a.n || c.N != b.N {
+		chk.Panic("matrices are incompatible: c (%dx%d) := aᵀ (%dx%d) * b (%dx%d)", c.M, c.N, a.n, a.m, b.M, b.N)
+	}
+	c.Fill(0)
+	for q := 0; q < b.N; q++ {
+		SpMatTrVecMulAdd(c.Col(q), α, a, b.Col(q))
+	}
+}
+
+// --------------------------------------------------------------------------------------------------
+// extraction ---------------------------------------------------------------------------------------
+// --------------------------------------------------------------------------------------------------
+
+// SpGetCol returns column j of a sparse matrix as a dense vector
+func SpGetCol(a *CCMatrix, j int) (col Vector) {
+	col = NewVector(a.m)
+	for k := a.p[j]; k < a.p[j+1]; k++ {
+		col[a.i[k]] += a.x[k]
+	}
+	return
+}
+
+// SpGetRow returns row i of a sparse matrix as a dense vector
+//  NOTE: this requires a search over all columns
+func SpGetRow(a *CCMatrix, i int) (row Vector) {
+	row = NewVector(a.n)
+	for j := 0; j < a.n; j++ {
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			if a.i[k] == i {
+				row[j] += a.x[k]
+			}
+		}
+	}
+	return
+}
+
+// SpSubMatrix extracts a sub-matrix (slice) of a sparse matrix:
+//  b_IJ := a_rows[I],cols[J]
+//  Input:
+//   rows -- indices of the rows of a to be extracted (in the order of the rows of b). nil means all
+//   cols -- indices of the columns of a to be extracted (in the order of the columns of b). nil means all
+//  NOTE: b is allocated here and the row indices in each column of b are sorted. Repeated
+//        indices in rows are not allowed
+func SpSubMatrix(a *CCMatrix, rows, cols []int) (b *CCMatrix) {
+	if rows == nil {
+		rows = utl.IntRange(a.m)
+	}
+	if cols == nil {
+		cols = utl.IntRange(a.n)
+	}
+	newRow := make([]int, a.m) // maps row of a to row of b (or -1)
+	for i := 0; i < a.m; i++ {
+		newRow[i] = -1
+	}
+	for I, i := range rows {
+		if i < 0 || i >= a.m {
+			chk.Panic("row index %d is out of range [0, %d)", i, a.m)
+		}
+		if newRow[i] >= 0 {
+			chk.Panic("row index %d is repeated", i)
+		}
+		newRow[i] = I
+	}
+	b = new(CCMatrix)
+	b.m, b.n = len(rows), len(cols)
+	b.p = make([]int, b.n+1)
+	type entry struct {
+		i int
+		x float64
+	}
+	var col []entry
+	for J, j := range cols {
+		if j < 0 || j >= a.n {
+			chk.Panic("column index %d is out of range [0, %d)", j, a.n)
+		}
+		col = col[:0]
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			if I := newRow[a.i[k]]; I >= 0 {
+				col = append(col, entry{I, a.x[k]})
+			}
+		}
+		sort.Slice(col, func(r, s int) bool { return col[r].i < col[s].i })
+		for _, e := range col {
+			b.i = append(b.i, e.i)
+			b.x = append(b.x, e.x)
+		}
+		b.p[J+1] = len(b.i)
+	}
+	b.nnz = len(b.i)
+	return
+}
+
 // --------------------------------------------------------------------------------------------------
 // matrix-vector ------------------------------------------------------------------------------------
 // --------------------------------------------------------------------------------------------------
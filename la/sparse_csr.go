@@ -0,0 +1,471 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// csrMinNnzPerThread is the minimum number of non-zeros handled by each goroutine in the
+// matrix-vector products; i.e. small matrices are processed serially
+const csrMinNnzPerThread = 2048
+
+// CSRMatrix represents a sparse matrix using the so-called "compressed sparse row format".
+//
+//   The matrix-vector products (SpCsrMatVecMul, etc.) are computed in parallel by goroutines
+//   working on blocks of rows with approximately the same number of non-zeros.
+//
+//   NOTE: the transpose products use workspace stored in the matrix; thus, they must not be
+//         called concurrently with the same matrix.
+//
+type CSRMatrix struct {
+	m, n     int         // matrix dimension (rows, columns)
+	nnz      int         // number of non-zeros
+	p, j     []int       // pointers and column indices (len(p)=m+1, len(j)=nnz)
+	x        []float64   // values (len(x)=nnz)
+	nthreads int         // maximum number of goroutines (0 => runtime.GOMAXPROCS)
+	part     []int       // partition of rows: block k = rows part[k] ≤ i < part[k+1]
+	work     [][]float64 // workspace for the transpose products: one vector (len=n) per block
+}
+
+// ToCSR converts a sparse matrix in triplet form to compressed sparse row form. Repeated entries
+// are summed up and the column indices are sorted in each row.
+//  INPUT:
+//   a -- a previous CSRMatrix to be filled in; otherwise, "nil" tells to allocate a new one
+//  OUTPUT:
+//   the previous "a" matrix or a pointer to a new one
+//  NOTE: the structure of "a" must be compatible with the triplet (e.g. from a previous call
+//        with the same sparsity pattern)
+func (t *Triplet) ToCSR(a *CSRMatrix) *CSRMatrix {
+	if t.pos < 1 {
+		chk.Panic("conversion can only be made for non-empty triplets. error: (pos = %d)", t.pos)
+	}
+	if a == nil {
+		a = new(CSRMatrix)
+		a.m, a.n = t.m, t.n
+		a.p = make([]int, a.m+1)
+		a.j = make([]int, t.pos)
+		a.x = make([]float64, t.pos)
+	}
+	tripletToCol(t.n, t.m, t.pos, t.j, t.i, t.x, a.p, a.j, a.x) // CSR of a = CC of transpose(a)
+	a.nnz = a.p[a.m]
+	a.j, a.x = a.j[:a.nnz], a.x[:a.nnz]
+	a.partition()
+	return a
+}
+
+// ToCSR converts a column-compressed matrix to compressed sparse row form
+//  INPUT:
+//   a -- a previous CSRMatrix to be filled in; otherwise, "nil" tells to allocate a new one
+//  OUTPUT:
+//   the previous "a" matrix or a pointer to a new one
+func (o *CCMatrix) ToCSR(a *CSRMatrix) *CSRMatrix {
+	if a == nil {
+		a = new(CSRMatrix)
+		a.m, a.n, a.nnz = o.m, o.n, o.nnz
+		a.p = make([]int, a.m+1)
+		a.j = make([]int, a.nnz)
+		a.x = make([]float64, a.nnz)
+	}
+
+	// count entries in each row
+	for i := 0; i <= a.m; i++ {
+		a.p[i] = 0
+	}
+	for k := 0; k < o.nnz; k++ {
+		a.p[o.i[k]+1]++
+	}
+	for i := 0; i < a.m; i++ {
+		a.p[i+1] += a.p[i]
+	}
+
+	// scatter (column indices come out sorted)
+	w := make([]int, a.m)
+	copy(w, a.p[:a.m])
+	for j := 0; j < o.n; j++ {
+		for k := o.p[j]; k < o.p[j+1]; k++ {
+			q := w[o.i[k]]
+			a.j[q], a.x[q] = j, o.x[k]
+			w[o.i[k]]++
+		}
+	}
+	a.partition()
+	return a
+}
+
+// Set sets compressed sparse row matrix directly
+func (o *CSRMatrix) Set(m, n int, Ap, Aj []int, Ax []float64) {
+	if len(Ap)-1 != m {
+		chk.Panic("len(Ap) must be equal to m+1. %d != %d", len(Ap), m+1)
+	}
+	nnz := len(Aj)
+	if len(Ax) != nnz {
+		chk.Panic("len(Ax) must be equal to len(Aj) == nnz. %d != %d", len(Ax), nnz)
+	}
+	if Ap[m] != nnz {
+		chk.Panic("last item in Ap must be equal to nnz. %d != %d", Ap[m], nnz)
+	}
+	o.m, o.n, o.nnz = m, n, nnz
+	o.p, o.j, o.x = Ap, Aj, Ax
+	o.partition()
+}
+
+// SetNumThreads sets the maximum number of goroutines used in the matrix-vector products.
+// Use n ≤ 0 to set the default value (runtime.GOMAXPROCS)
+func (o *CSRMatrix) SetNumThreads(n int) {
+	if n < 0 {
+		n = 0
+	}
+	o.nthreads = n
+	o.partition()
+}
+
+// ToDense converts a compressed sparse row matrix to dense form
+func (o *CSRMatrix) ToDense() (res *Matrix) {
+	res = NewMatrix(o.m, o.n)
+	for i := 0; i < o.m; i++ {
+		for k := o.p[i]; k < o.p[i+1]; k++ {
+			res.Set(i, o.j[k], o.x[k])
+		}
+	}
+	return
+}
+
+// ToCC converts a compressed sparse row matrix to column-compressed form
+//  INPUT:
+//   a -- a previous CCMatrix to be filled in; otherwise, "nil" tells to allocate a new one
+//  OUTPUT:
+//   the previous "a" matrix or a pointer to a new one
+func (o *CSRMatrix) ToCC(a *CCMatrix) *CCMatrix {
+	if a == nil {
+		a = new(CCMatrix)
+		a.m, a.n, a.nnz = o.m, o.n, o.nnz
+		a.p = make([]int, a.n+1)
+		a.i = make([]int, a.nnz)
+		a.x = make([]float64, a.nnz)
+	}
+	for j := 0; j <= a.n; j++ {
+		a.p[j] = 0
+	}
+	for k := 0; k < o.nnz; k++ {
+		a.p[o.j[k]+1]++
+	}
+	for j := 0; j < a.n; j++ {
+		a.p[j+1] += a.p[j]
+	}
+	w := make([]int, a.n)
+	copy(w, a.p[:a.n])
+	for i := 0; i < o.m; i++ {
+		for k := o.p[i]; k < o.p[i+1]; k++ {
+			q := w[o.j[k]]
+			a.i[q], a.x[q] = i, o.x[k]
+			w[o.j[k]]++
+		}
+	}
+	return a
+}
+
+// partition computes the blocks of rows (with approximately the same number of non-zeros)
+// processed by each goroutine
+func (o *CSRMatrix) partition() {
+	nt := o.nthreads
+	if nt == 0 {
+		nt = runtime.GOMAXPROCS(0)
+	}
+	if nt > o.nnz/csrMinNnzPerThread {
+		nt = o.nnz / csrMinNnzPerThread
+	}
+	if nt > o.m {
+		nt = o.m
+	}
+	if nt < 1 {
+		nt = 1
+	}
+	o.part = make([]int, nt+1)
+	for k := 1; k < nt; k++ {
+		o.part[k] = sort.SearchInts(o.p[:o.m+1], k*o.nnz/nt)
+		if o.part[k] < o.part[k-1] {
+			o.part[k] = o.part[k-1]
+		}
+	}
+	o.part[nt] = o.m
+	o.work = nil
+}
+
+// parallel runs fcn(k, start, end) for each block of rows k, with start ≤ i < end, concurrently
+func (o *CSRMatrix) parallel(fcn func(k, start, end int)) {
+	nt := len(o.part) - 1
+	if nt == 1 {
+		fcn(0, 0, o.m)
+		return
+	}
+	var wg sync.WaitGroup
+	wg.Add(nt)
+	for k := 0; k < nt; k++ {
+		go func(k int) {
+			fcn(k, o.part[k], o.part[k+1])
+			wg.Done()
+		}(k)
+	}
+	wg.Wait()
+}
+
+// trMul computes v += α * transp(a) * u  (each block of rows scatters into its own workspace;
+// the workspaces are then added up in parallel by blocks of columns)
+func (o *CSRMatrix) trMul(v Vector, α float64, u Vector) {
+	nt := len(o.part) - 1
+	if nt == 1 {
+		for i := 0; i < o.m; i++ {
+			for k := o.p[i]; k < o.p[i+1]; k++ {
+				v[o.j[k]] += α * o.x[k] * u[i]
+			}
+		}
+		return
+	}
+	if o.work == nil {
+		o.work = make([][]float64, nt)
+		for k := 0; k < nt; k++ {
+			o.work[k] = make([]float64, o.n)
+		}
+	}
+	o.parallel(func(t, start, end int) {
+		w := o.work[t]
+		for j := 0; j < o.n; j++ {
+			w[j] = 0
+		}
+		for i := start; i < end; i++ {
+			for k := o.p[i]; k < o.p[i+1]; k++ {
+				w[o.j[k]] += o.x[k] * u[i]
+			}
+		}
+	})
+	var wg sync.WaitGroup
+	wg.Add(nt)
+	for t := 0; t < nt; t++ {
+		go func(t int) {
+			for j := t * o.n / nt; j < (t+1)*o.n/nt; j++ {
+				sum := 0.0
+				for _, w := range o.work {
+					sum += w[j]
+				}
+				v[j] += α * sum
+			}
+			wg.Done()
+		}(t)
+	}
+	wg.Wait()
+}
+
+// --------------------------------------------------------------------------------------------------
+// matrix-vector ------------------------------------------------------------------------------------
+// --------------------------------------------------------------------------------------------------
+
+// SpCsrMatVecMul returns the (sparse/CSR) matrix-vector multiplication (scaled):
+//  v := α * a * u  =>  vi = α * aij * uj
+func SpCsrMatVecMul(v Vector, α float64, a *CSRMatrix, u Vector) {
+	a.parallel(func(_, start, end int) {
+		for i := start; i < end; i++ {
+			sum := 0.0
+			for k := a.p[i]; k < a.p[i+1]; k++ {
+				sum += a.x[k] * u[a.j[k]]
+			}
+			v[i] = α * sum
+		}
+	})
+}
+
+// SpCsrMatVecMulAdd returns the (sparse/CSR) matrix-vector multiplication with addition (scaled):
+//  v += α * a * u  =>  vi += α * aij * uj
+func SpCsrMatVecMulAdd(v Vector, α float64, a *CSRMatrix, u Vector) {
+	a.parallel(func(_, start, end int) {
+		for i := start; i < end; i++ {
+			sum := 0.0
+			for k := a.p[i]; k < a.p[i+1]; k++ {
+				sum += a.x[k] * u[a.j[k]]
+			}
+			v[i] += α * sum
+		}
+	})
+}
+
+// SpCsrMatVecMulAddX returns the (sparse/CSR) matrix-vector multiplication with addition (scaled/extended):
+//  v += a * (α*u + β*w)  =>  vi += aij * (α*uj + β*wj)
+func SpCsrMatVecMulAddX(v Vector, a *CSRMatrix, α float64, u Vector, β float64, w Vector) {
+	a.parallel(func(_, start, end int) {
+		for i := start; i < end; i++ {
+			for k := a.p[i]; k < a.p[i+1]; k++ {
+				v[i] += a.x[k] * (α*u[a.j[k]] + β*w[a.j[k]])
+			}
+		}
+	})
+}
+
+// SpCsrMatTrVecMul returns the (sparse/CSR) matrix-vector multiplication with "a" transposed (scaled):
+//  v := α * transp(a) * u  =>  vj = α * aij * ui
+//  NOTE: dense vector v will be first initialised with zeros
+func SpCsrMatTrVecMul(v Vector, α float64, a *CSRMatrix, u Vector) {
+	v.Fill(0)
+	a.trMul(v, α, u)
+}
+
+// SpCsrMatTrVecMulAdd returns the (sparse/CSR) matrix-vector multiplication with addition and "a" transposed (scaled):
+//  v += α * transp(a) * u  =>  vj += α * aij * ui
+func SpCsrMatTrVecMulAdd(v Vector, α float64, a *CSRMatrix, u Vector) {
+	a.trMul(v, α, u)
+}
+
+// --------------------------------------------------------------------------------------------------
+// auxiliary ----------------------------------------------------------------------------------------
+// --------------------------------------------------------------------------------------------------
+
+// SpCsrInitSimilar initialises another matrix "b" with the same structure (Ap, Aj) of
+// sparse matrix "a". The values Ax are not copied though.
+func SpCsrInitSimilar(b *CSRMatrix, a *CSRMatrix) {
+	b.m, b.n, b.nnz = a.m, a.n, a.nnz
+	b.p = make([]int, a.m+1)
+	b.j = make([]int, a.nnz)
+	b.x = make([]float64, a.nnz)
+	copy(b.p, a.p)
+	copy(b.j, a.j)
+	b.nthreads = a.nthreads
+	b.partition()
+}
+
+// SpCsrMatScale scales all values of "a" by α:
+//  a := α*a
+func SpCsrMatScale(α float64, a *CSRMatrix) {
+	for k := 0; k < a.nnz; k++ {
+		a.x[k] *= α
+	}
+}
+
+// SpCsrMatAddI adds an identity matrix I to "a", scaled by α and β according to:
+//  r := α*a + β*I
+//  NOTE: "r" must have the same structure as "a" (e.g. from SpCsrInitSimilar) and,
+//        if β ≠ 0, all diagonal elements must be present (see SpCsrCheckDiag)
+func SpCsrMatAddI(r *CSRMatrix, α float64, a *CSRMatrix, β float64) {
+	for i := 0; i < a.m; i++ {
+		for k := a.p[i]; k < a.p[i+1]; k++ {
+			if a.j[k] == i {
+				r.x[k] = α*a.x[k] + β
+			} else {
+				r.x[k] = α * a.x[k]
+			}
+		}
+	}
+}
+
+// SpCsrCheckDiag checks if all elements on the diagonal of "a" are present.
+//  OUTPUT:
+//   ok -- true if all diagonal elements are present;
+//         otherwise, ok == false if any diagonal element is missing.
+func SpCsrCheckDiag(a *CSRMatrix) bool {
+	for i := 0; i < a.m; i++ {
+		found := false
+		for k := a.p[i]; k < a.p[i+1]; k++ {
+			if a.j[k] == i {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// --------------------------------------------------------------------------------------------------
+// matrix-matrix ------------------------------------------------------------------------------------
+// --------------------------------------------------------------------------------------------------
+
+// SpCsrAllocMatAddMat allocates a matrix 'c' to hold the result of the addition of 'a' and 'b'.
+// It also allocates the mapping arrays a2c and b2c, where:
+//  a2c maps 'k' in 'a' to 'k' in 'c': len(a2c) = a.nnz
+//  b2c maps 'k' in 'b' to 'k' in 'c': len(b2c) = b.nnz
+func SpCsrAllocMatAddMat(a, b *CSRMatrix) (c *CSRMatrix, a2c, b2c []int) {
+	if a.m != b.m || a.n != b.n {
+		chk.Panic("matrices 'a' (%dx%d) and 'b' (%dx%d) must have the same dimensions", a.m, a.n, b.m, b.n)
+	}
+
+	// number of non-zeros in 'c'
+	c2a := make([]int, a.n) // maps a column index to the corresponding k index of 'a'
+	c2b := make([]int, a.n) // maps a column index to the corresponding k index of 'b'
+	mark := make([]int, a.n)
+	for j := 0; j < a.n; j++ {
+		mark[j] = -1
+	}
+	nnz := 0
+	for i := 0; i < a.m; i++ {
+		for k := a.p[i]; k < a.p[i+1]; k++ {
+			if mark[a.j[k]] != i {
+				mark[a.j[k]] = i
+				nnz++
+			}
+		}
+		for k := b.p[i]; k < b.p[i+1]; k++ {
+			if mark[b.j[k]] != i {
+				mark[b.j[k]] = i
+				nnz++
+			}
+		}
+	}
+
+	// allocate c, a2c, and b2c
+	c = new(CSRMatrix)
+	c.m, c.n, c.nnz = a.m, a.n, nnz
+	c.x = make([]float64, nnz)
+	c.j = make([]int, nnz)
+	c.p = make([]int, c.m+1)
+	c.nthreads = a.nthreads
+	a2c = make([]int, a.nnz)
+	b2c = make([]int, b.nnz)
+	nnz = 0 // == k of 'c'
+	for i := 0; i < a.m; i++ {
+		for j := 0; j < a.n; j++ {
+			c2a[j], c2b[j] = -1, -1
+		}
+		for k := a.p[i]; k < a.p[i+1]; k++ {
+			c2a[a.j[k]] = k
+		}
+		for k := b.p[i]; k < b.p[i+1]; k++ {
+			c2b[b.j[k]] = k
+		}
+		for j := 0; j < a.n; j++ {
+			if c2a[j] > -1 || c2b[j] > -1 {
+				if c2a[j] > -1 {
+					a2c[c2a[j]] = nnz
+				}
+				if c2b[j] > -1 {
+					b2c[c2b[j]] = nnz
+				}
+				c.j[nnz] = j
+				nnz++
+			}
+		}
+		c.p[i+1] = nnz
+	}
+	c.partition()
+	return
+}
+
+// SpCsrMatAddMat adds two sparse matrices. The 'c' matrix matrix and the 'a2c' and 'b2c' arrays
+// must be pre-allocated by SpCsrAllocMatAddMat. The result is:
+//  c := α*a + β*b
+func SpCsrMatAddMat(c *CSRMatrix, α float64, a *CSRMatrix, β float64, b *CSRMatrix, a2c, b2c []int) {
+	for k := 0; k < c.nnz; k++ {
+		c.x[k] = 0
+	}
+	for k := 0; k < a.nnz; k++ {
+		c.x[a2c[k]] += α * a.x[k]
+	}
+	for k := 0; k < b.nnz; k++ {
+		c.x[b2c[k]] += β * b.x[k]
+	}
+}
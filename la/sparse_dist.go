@@ -0,0 +1,302 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/mpi"
+	"github.com/cpmech/gosl/utl"
+)
+
+// DistMatrix implements a sparse matrix distributed by rows over the processors of a
+// communicator. Each processor holds the rows it owns in compressed sparse row format and the
+// entries of x needed from other processors ("ghosts") are exchanged in the matrix-vector products
+//
+//         ┌          ┐   ┌   ┐
+//         │  A₀ (p0) │   │x₀ │ (p0)
+//   A⋅x = │  A₁ (p1) │ ⋅ │x₁ │ (p1)      with  Aₚ ⋅ x = Aₚ,ₗₒ꜀ ⋅ xₚ + Aₚ,ₒₜₕₑᵣ ⋅ x_ghost
+//         │  ⋮       │   │ ⋮ │
+//         └          ┘   └   ┘
+//
+//   NOTE: (1) vectors are distributed in the same way: each processor holds the slice of x
+//             corresponding to its columns (ColPart) and the slice of y = A⋅x corresponding to
+//             its rows (RowPart). Thus, Size, Apply and ApplyTranspose (LinearOperator) work with
+//             local vectors
+//         (2) Apply and ApplyTranspose are collective operations: all processors must call them
+//         (3) a nil communicator (or with one processor) means serial computations
+//
+type DistMatrix struct {
+	M, N    int   // global dimensions
+	RowPart []int // [nproc+1] offsets of rows: processor p owns rows RowPart[p] ≤ i < RowPart[p+1]
+	ColPart []int // [nproc+1] offsets of columns; i.e. partition of the x vector
+
+	// internal
+	comm   *mpi.Communicator // communicator
+	rank   int               // rank of this processor
+	nproc  int               // number of processors
+	nrow   int               // number of local rows
+	ncol   int               // number of local columns (not counting ghosts)
+	local  *CSRMatrix        // local rows with columns renumbered: [0,ncol) local and [ncol,ncol+nghost) ghosts
+	diag   *CCMatrix         // diagonal block: local rows and local columns (nil if empty)
+	ghosts []int             // global indices of ghost columns (sorted)
+	gstart []int             // [nproc+1] ghost entries received from p are ghosts[gstart[p]:gstart[p+1]]
+	send   [][]int           // [nproc][...] local indices of x entries to be sent to each processor
+	xext   Vector            // x extended with ghosts
+	buf    Vector            // buffer for sending values
+}
+
+// DistPartition returns the offsets of an (almost) uniform partition of n items over nproc
+// processors; i.e. processor p owns the items part[p] ≤ i < part[p+1]
+func DistPartition(n, nproc int) (part []int) {
+	if nproc < 1 {
+		nproc = 1
+	}
+	part = make([]int, nproc+1)
+	for p := 0; p <= nproc; p++ {
+		part[p] = (p * n) / nproc
+	}
+	return
+}
+
+// NewDistMatrix creates a new distributed sparse matrix (collective operation)
+//  Input:
+//   comm    -- communicator. nil means serial
+//   t       -- triplet with the entries of the local rows using global indices; i.e. t has
+//              dimensions M × N and only entries with RowPart[rank] ≤ i < RowPart[rank+1]
+//   rowPart -- [nproc+1] offsets of rows. nil means DistPartition(M, nproc)
+//   colPart -- [nproc+1] offsets of columns. nil means rowPart if M == N or DistPartition(N, nproc)
+//  NOTE: all processors must call this function with the same rowPart and colPart
+func NewDistMatrix(comm *mpi.Communicator, t *Triplet, rowPart, colPart []int) (o *DistMatrix) {
+
+	// partitions
+	o = new(DistMatrix)
+	o.comm = comm
+	o.rank, o.nproc = distRankSize(comm)
+	o.M, o.N = t.m, t.n
+	if rowPart == nil {
+		rowPart = DistPartition(o.M, o.nproc)
+	}
+	if colPart == nil {
+		if o.M == o.N {
+			colPart = rowPart
+		} else {
+			colPart = DistPartition(o.N, o.nproc)
+		}
+	}
+	if len(rowPart) != o.nproc+1 || rowPart[0] != 0 || rowPart[o.nproc] != o.M {
+		chk.Panic("partition of rows is invalid. rowPart = %v (nproc = %d, M = %d)\n", rowPart, o.nproc, o.M)
+	}
+	if len(colPart) != o.nproc+1 || colPart[0] != 0 || colPart[o.nproc] != o.N {
+		chk.Panic("partition of columns is invalid. colPart = %v (nproc = %d, N = %d)\n", colPart, o.nproc, o.N)
+	}
+	o.RowPart, o.ColPart = rowPart, colPart
+	r0, c0 := rowPart[o.rank], colPart[o.rank]
+	o.nrow, o.ncol = rowPart[o.rank+1]-r0, colPart[o.rank+1]-c0
+
+	// find ghost columns
+	isLocal := func(j int) bool { return j >= c0 && j < c0+o.ncol }
+	needed := make(map[int]bool)
+	for k := 0; k < t.pos; k++ {
+		if t.i[k] < r0 || t.i[k] >= r0+o.nrow {
+			chk.Panic("triplet has entry in row %d which is not owned by processor %d (rows %d to %d)\n", t.i[k], o.rank, r0, r0+o.nrow-1)
+		}
+		if !isLocal(t.j[k]) {
+			needed[t.j[k]] = true
+		}
+	}
+	o.ghosts = make([]int, 0, len(needed))
+	for j := range needed {
+		o.ghosts = append(o.ghosts, j)
+	}
+	sort.Ints(o.ghosts)
+	gidx := make(map[int]int, len(o.ghosts))
+	for k, j := range o.ghosts {
+		gidx[j] = o.ncol + k
+	}
+	o.gstart = make([]int, o.nproc+1)
+	for p := 0; p < o.nproc; p++ {
+		o.gstart[p+1] = o.gstart[p] + sort.SearchInts(o.ghosts[o.gstart[p]:], colPart[p+1])
+	}
+
+	// local matrices
+	tl := NewTriplet(o.nrow, o.ncol+len(o.ghosts), t.pos)
+	td := NewTriplet(o.nrow, o.ncol, t.pos)
+	for k := 0; k < t.pos; k++ {
+		i, j := t.i[k]-r0, t.j[k]
+		if isLocal(j) {
+			tl.Put(i, j-c0, t.x[k])
+			td.Put(i, j-c0, t.x[k])
+		} else {
+			tl.Put(i, gidx[j], t.x[k])
+		}
+	}
+	if tl.pos > 0 {
+		o.local = tl.ToCSR(nil)
+	} else {
+		o.local = new(CSRMatrix)
+		o.local.Set(tl.m, tl.n, make([]int, tl.m+1), []int{}, []float64{})
+	}
+	if td.pos > 0 && o.nrow == o.ncol {
+		o.diag = td.ToMatrix(nil)
+	}
+
+	// tell each processor which of its entries are needed here
+	o.send = make([][]int, o.nproc)
+	o.exchange(func(p int) {
+		req := o.ghosts[o.gstart[p]:o.gstart[p+1]]
+		o.comm.SendOneI(len(req), p)
+		if len(req) > 0 {
+			o.comm.SendI(req, p)
+		}
+	}, func(p int) {
+		nreq := o.comm.RecvOneI(p)
+		o.send[p] = make([]int, nreq)
+		if nreq > 0 {
+			o.comm.RecvI(o.send[p], p)
+		}
+		for k, j := range o.send[p] {
+			if !isLocal(j) {
+				chk.Panic("processor %d requested column %d which is not owned by processor %d\n", p, j, o.rank)
+			}
+			o.send[p][k] = j - c0
+		}
+	})
+
+	// workspace
+	nsend := 0
+	for _, s := range o.send {
+		nsend = utl.Imax(nsend, len(s))
+	}
+	o.xext = NewVector(o.ncol + len(o.ghosts))
+	o.buf = NewVector(utl.Imax(nsend, len(o.ghosts)))
+	return
+}
+
+// Comm returns the communicator
+func (o *DistMatrix) Comm() *mpi.Communicator { return o.comm }
+
+// NumGhosts returns the number of entries of x received from other processors in Apply
+func (o *DistMatrix) NumGhosts() int { return len(o.ghosts) }
+
+// LocalDiag returns the diagonal block of the local rows (i.e. the entries coupling local rows
+// and local columns); e.g. to build block-Jacobi preconditioners. Returns nil if the block is
+// empty or not square
+func (o *DistMatrix) LocalDiag() *CCMatrix { return o.diag }
+
+// Size returns the local dimensions: number of local rows and number of local columns
+func (o *DistMatrix) Size() (m, n int) { return o.nrow, o.ncol }
+
+// Apply computes the local part of y := A ⋅ x (collective operation)
+//  Input:
+//   x -- local part of x [ncol]
+//  Output:
+//   y -- local part of y [nrow]
+func (o *DistMatrix) Apply(y, x Vector) {
+	copy(o.xext, x[:o.ncol])
+	o.exchange(func(p int) {
+		if len(o.send[p]) > 0 {
+			buf := o.buf[:len(o.send[p])]
+			for k, j := range o.send[p] {
+				buf[k] = x[j]
+			}
+			o.comm.Send(buf, p)
+		}
+	}, func(p int) {
+		if o.gstart[p+1] > o.gstart[p] {
+			o.comm.Recv(o.xext[o.ncol+o.gstart[p]:o.ncol+o.gstart[p+1]], p)
+		}
+	})
+	SpCsrMatVecMul(y, 1, o.local, o.xext)
+}
+
+// ApplyTranspose computes the local part of y := Aᵀ ⋅ x (collective operation)
+//  Input:
+//   x -- local part of x [nrow]
+//  Output:
+//   y -- local part of y [ncol]
+func (o *DistMatrix) ApplyTranspose(y, x Vector) {
+	SpCsrMatTrVecMul(o.xext, 1, o.local, x)
+	copy(y, o.xext[:o.ncol])
+	o.exchange(func(p int) {
+		if o.gstart[p+1] > o.gstart[p] {
+			o.comm.Send(o.xext[o.ncol+o.gstart[p]:o.ncol+o.gstart[p+1]], p)
+		}
+	}, func(p int) {
+		if len(o.send[p]) > 0 {
+			buf := o.buf[:len(o.send[p])]
+			o.comm.Recv(buf, p)
+			for k, j := range o.send[p] {
+				y[j] += buf[k]
+			}
+		}
+	})
+}
+
+// exchange calls send(p) and recv(p) for all other processors p. The messages are ordered by
+// pairs of processors (the lower rank sends first) to avoid deadlocks with blocking Send/Recv
+func (o *DistMatrix) exchange(send, recv func(p int)) {
+	for p := 0; p < o.nproc; p++ {
+		if p < o.rank {
+			recv(p)
+			send(p)
+		} else if p > o.rank {
+			send(p)
+			recv(p)
+		}
+	}
+}
+
+// distributed vectors /////////////////////////////////////////////////////////////////////////////
+
+// DistVecDot returns the dot product of distributed vectors: u⋅v = Σₚ uₚ⋅vₚ
+//  NOTE: collective operation; comm may be nil (serial)
+func DistVecDot(comm *mpi.Communicator, u, v Vector) float64 {
+	res := VecDot(u, v)
+	if _, nproc := distRankSize(comm); nproc > 1 {
+		dest := []float64{0}
+		comm.AllReduceSum(dest, []float64{res})
+		res = dest[0]
+	}
+	return res
+}
+
+// DistVecNorm returns the Euclidean norm of a distributed vector: sqrt(Σₚ vₚ⋅vₚ)
+//  NOTE: collective operation; comm may be nil (serial)
+func DistVecNorm(comm *mpi.Communicator, v Vector) float64 {
+	return math.Sqrt(DistVecDot(comm, v, v))
+}
+
+// DistVecGather assembles the full vector from its distributed parts in all processors
+//  Input:
+//   vloc -- local part of v
+//   part -- [nproc+1] partition of v
+//  Output:
+//   v -- full vector [pre-allocated]
+//  NOTE: collective operation; comm may be nil (serial)
+func DistVecGather(comm *mpi.Communicator, v, vloc Vector, part []int) {
+	rank, nproc := distRankSize(comm)
+	if nproc == 1 {
+		copy(v, vloc)
+		return
+	}
+	tmp := NewVector(len(v))
+	copy(tmp[part[rank]:part[rank+1]], vloc)
+	comm.AllReduceSum(v, tmp)
+}
+
+// distRankSize returns the rank and the number of processors; with nil comm, returns (0, 1)
+func distRankSize(comm *mpi.Communicator) (rank, nproc int) {
+	if comm == nil {
+		return 0, 1
+	}
+	rank, nproc = comm.Rank(), comm.Size()
+	if nproc < 1 {
+		return 0, 1
+	}
+	return
+}
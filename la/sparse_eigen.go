@@ -0,0 +1,500 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la/oblas"
+	"github.com/cpmech/gosl/mpi"
+	"github.com/cpmech/gosl/utl"
+)
+
+// SparseEigen computes a few eigenvalues and eigenvectors of the sparse generalised symmetric
+// eigenproblem using the thick-restart Lanczos method (mathematically equivalent to the
+// implicitly restarted Lanczos method for symmetric problems)
+//
+//   K ⋅ x[j] = λ[j] ⋅ M ⋅ x[j]      with K symmetric and M symmetric positive-definite
+//
+//   Which:
+//     "smallest" -- eigenvalues closest to Shift (default: σ = 0); i.e. the smallest ones if
+//                   K is positive-definite. Uses the shift-invert mode: (K - σ⋅M)⁻¹ ⋅ M
+//     "nearest"  -- eigenvalues nearest to Shift. Uses the shift-invert mode: (K - σ⋅M)⁻¹ ⋅ M
+//     "largest"  -- eigenvalues with largest value. Uses the regular mode: M⁻¹ ⋅ K
+//
+//   NOTE: (1) the linear systems with (K - σ⋅M) or M are solved with the SparseSolver
+//             named Solver, which is factorised only once. With SolveOp (matrix-free
+//             operators), these systems are solved with a Krylov solver instead
+//         (2) the eigenvectors are normalised such that xᵀ⋅M⋅x = 1
+//
+//   References:
+//    [1] Wu K and Simon H (2000) Thick-restart Lanczos method for large symmetric eigenvalue
+//        problems. SIAM J. Matrix Anal. Appl. 22(2):602-616
+//    [2] Lehoucq RB, Sorensen DC and Yang C (1998) ARPACK Users' Guide. SIAM
+type SparseEigen struct {
+
+	// settings
+	Which   string            // "smallest", "largest" or "nearest"
+	Shift   float64           // shift σ used with "smallest" and "nearest"
+	Ncv     int               // number of Lanczos vectors; 0 means max(2⋅nev+1, 20)
+	Tol     float64           // tolerance on the relative residual of Ritz pairs
+	MaxIt   int               // maximum number of restarts
+	Solver  string            // kind of SparseSolver to solve linear systems; e.g. "umfpack" or "mumps"
+	Comm    *mpi.Communicator // communicator for the linear solver; required by "mumps"
+	Verbose bool              // show messages
+
+	// settings for SolveOp
+	InnerKind string         // kind of Krylov solver for (K - σ⋅M) or M; default: "gmres" or "cg", respectively
+	InnerTol  float64        // tolerance of the Krylov solver
+	InnerPrec Preconditioner // preconditioner of the Krylov solver (e.g. PrecOp). may be nil
+
+	// statistics
+	NumIt   int // number of restarts performed by the last call to Solve
+	NumOp   int // number of applications of the operator
+	NumConv int // number of converged eigenvalues
+
+	// internal
+	n     int               // dimension
+	k, m  LinearOperator    // K and M (m may be nil)
+	solve func(x, b Vector) // solves the linear system with (K - σ⋅M) or M
+	lis   SparseSolver      // linear solver
+	tri   *Triplet          // matrix to be factorised
+	work  Vector            // workspace
+}
+
+// NewSparseEigen returns a new SparseEigen with default settings
+func NewSparseEigen() (o *SparseEigen) {
+	o = new(SparseEigen)
+	o.Which = "smallest"
+	o.Tol = 1e-10
+	o.MaxIt = 300
+	o.Solver = "umfpack"
+	o.InnerTol = 1e-12
+	return
+}
+
+// Solve computes nev = len(λ) eigenvalues and eigenvectors
+//  Input:
+//   K -- symmetric matrix
+//   M -- symmetric positive-definite matrix. nil means the identity matrix (standard problem)
+//  Output:
+//   λ -- eigenvalues [pre-allocated]; sorted according to Which (e.g. ascending for "smallest")
+//   X -- matrix with the eigenvectors; each column contains one eigenvector [pre-allocated]
+//        X may be nil if the eigenvectors are not needed
+func (o *SparseEigen) Solve(λ Vector, X *Matrix, K, M *CCMatrix) {
+
+	// check
+	if K.m != K.n {
+		chk.Panic("K must be square. %d × %d is invalid\n", K.m, K.n)
+	}
+	if M != nil && (M.m != K.n || M.n != K.n) {
+		chk.Panic("M must have the same dimensions as K. %d × %d != %d × %d\n", M.m, M.n, K.m, K.n)
+	}
+	o.k, o.m = NewOpCCMatrix(K), nil
+	if M != nil {
+		o.m = NewOpCCMatrix(M)
+	}
+	o.n = K.n
+
+	// linear solver
+	shiftInvert := o.shiftInvert()
+	o.tri = nil
+	if shiftInvert {
+		o.tri = spTriLinComb(1, K, -o.Shift, M)
+	} else if M != nil {
+		o.tri = spTriLinComb(0, nil, 1, M)
+	}
+	if o.tri != nil {
+		if o.Solver == "mumps" && o.Comm == nil {
+			chk.Panic("Comm must be given with the \"mumps\" solver\n")
+		}
+		o.lis = NewSparseSolver(o.Solver)
+		defer o.lis.Free()
+		o.lis.Init(o.tri, false, false, "", "", o.Comm) // full matrix: not the triangle expected by symmetric solvers
+		o.lis.Fact()
+		o.solve = func(x, b Vector) { o.lis.Solve(x, b, false) }
+	}
+	o.lanczos(λ, X, shiftInvert)
+}
+
+// SolveOp computes nev = len(λ) eigenvalues and eigenvectors of the problem given by (possibly
+// matrix-free or block) linear operators
+//  Input:
+//   K -- symmetric operator
+//   M -- symmetric positive-definite operator. nil means the identity (standard problem)
+//  Output:
+//   λ -- eigenvalues [pre-allocated]; sorted according to Which (e.g. ascending for "smallest")
+//   X -- matrix with the eigenvectors; each column contains one eigenvector [pre-allocated]
+//        X may be nil if the eigenvectors are not needed
+//  NOTE: the linear systems with (K - σ⋅M) or M are solved by a Krylov solver with InnerKind,
+//        InnerTol and InnerPrec; thus, the accuracy of the eigenpairs is limited by InnerTol
+func (o *SparseEigen) SolveOp(λ Vector, X *Matrix, K, M LinearOperator) {
+
+	// check
+	m, n := K.Size()
+	if m != n {
+		chk.Panic("K must be square. %d × %d is invalid\n", m, n)
+	}
+	if M != nil {
+		if mm, nn := M.Size(); mm != n || nn != n {
+			chk.Panic("M must have the same dimensions as K. %d × %d != %d × %d\n", mm, nn, m, n)
+		}
+	}
+	o.k, o.m = K, M
+	o.n = n
+
+	// linear solver
+	shiftInvert := o.shiftInvert()
+	var a LinearOperator
+	kind := o.InnerKind
+	if shiftInvert {
+		a = K
+		if o.Shift != 0 {
+			tmp := NewVector(n)
+			a = NewOpFunc(n, n, func(y, x Vector) {
+				K.Apply(y, x)
+				if M == nil {
+					copy(tmp, x)
+				} else {
+					M.Apply(tmp, x)
+				}
+				for i := 0; i < n; i++ {
+					y[i] -= o.Shift * tmp[i]
+				}
+			}, nil)
+		}
+		if kind == "" {
+			kind = "gmres"
+		}
+	} else if M != nil {
+		a = M
+		if kind == "" {
+			kind = "cg"
+		}
+	}
+	if a != nil {
+		lis := NewKrylov(kind)
+		lis.Tol = o.InnerTol
+		lis.Prec = o.InnerPrec
+		lis.InitOp(a, false)
+		lis.Fact()
+		o.solve = func(x, b Vector) { lis.Solve(x, b, false) }
+	}
+	o.lanczos(λ, X, shiftInvert)
+}
+
+// shiftInvert checks Which and returns whether the shift-invert mode is to be used or not
+func (o *SparseEigen) shiftInvert() bool {
+	switch o.Which {
+	case "smallest", "nearest":
+		return true
+	case "largest":
+		return false
+	}
+	chk.Panic("Which = %q is invalid. options are \"smallest\", \"largest\" or \"nearest\"\n", o.Which)
+	return false
+}
+
+// lanczos runs the thick-restart Lanczos method after K, M and the linear solver have been set
+func (o *SparseEigen) lanczos(λ Vector, X *Matrix, shiftInvert bool) {
+
+	// check
+	nev := len(λ)
+	if nev < 1 || nev >= o.n {
+		chk.Panic("number of requested eigenvalues must be in [1, %d). nev = %d is invalid\n", o.n, nev)
+	}
+	if X != nil && (X.M != o.n || X.N < nev) {
+		chk.Panic("matrix of eigenvectors must be (%d × %d). %d × %d is invalid\n", o.n, nev, X.M, X.N)
+	}
+	ncv := o.Ncv
+	if ncv == 0 {
+		ncv = utl.Imax(2*nev+1, 20)
+	}
+	if ncv > o.n {
+		ncv = o.n
+	}
+	if ncv <= nev {
+		chk.Panic("number of Lanczos vectors must be greater than nev. ncv = %d, nev = %d\n", ncv, nev)
+	}
+	o.work = NewVector(o.n)
+	o.NumIt, o.NumOp, o.NumConv = 0, 0, 0
+
+	// Lanczos basis and projected matrix
+	V := make([]Vector, ncv+1)
+	for j := 0; j <= ncv; j++ {
+		V[j] = NewVector(o.n)
+	}
+	T := NewMatrix(ncv, ncv)
+	h := NewVector(ncv + 1)
+	w, bw := NewVector(o.n), NewVector(o.n)
+
+	// starting vector
+	rng := rand.New(rand.NewSource(1234))
+	for i := 0; i < o.n; i++ {
+		V[0][i] = rng.Float64() - 0.5
+	}
+	o.normalise(V[0])
+
+	// auxiliary
+	θ := NewVector(ncv)
+	Y := NewMatrix(ncv, ncv)
+	idx := make([]int, ncv)
+	var β float64
+	kept := 0
+
+	// restarts
+	for o.NumIt = 0; o.NumIt < o.MaxIt; o.NumIt++ {
+
+		// Lanczos iterations with full reorthogonalisation
+		for j := kept; j < ncv; j++ {
+			o.operator(w, V[j], shiftInvert)
+			for i := j + 1; i < ncv; i++ {
+				T.Set(i, j, 0)
+				T.Set(j, i, 0)
+			}
+			for pass := 0; pass < 2; pass++ {
+				o.bprod(bw, w)
+				for i := 0; i <= j; i++ {
+					h[i] = VecDot(V[i], bw)
+					for l := 0; l < o.n; l++ {
+						w[l] -= h[i] * V[i][l]
+					}
+					if pass == 0 {
+						T.Set(i, j, h[i])
+					} else {
+						T.Add(i, j, h[i])
+					}
+					T.Set(j, i, T.Get(i, j))
+				}
+			}
+			β = o.bnorm(w)
+			if β < 1e-14*math.Abs(T.Get(j, j)) || β == 0 {
+				β = 0
+				if j < ncv-1 { // invariant subspace: continue with a new random vector
+					for i := 0; i < o.n; i++ {
+						w[i] = rng.Float64() - 0.5
+					}
+					o.orthogonalise(w, V[:j+1], bw)
+					o.normalise(w)
+					copy(V[j+1], w)
+					continue
+				}
+			}
+			if β == 0 {
+				V[j+1].Fill(0)
+				continue
+			}
+			V[j+1].Apply(1.0/β, w)
+		}
+
+		// Ritz values and vectors
+		symEigen(θ, Y, T.GetCopy())
+		for i := 0; i < ncv; i++ {
+			idx[i] = i
+		}
+		sort.Slice(idx, func(a, b int) bool {
+			if shiftInvert {
+				return math.Abs(θ[idx[a]]) > math.Abs(θ[idx[b]])
+			}
+			return θ[idx[a]] > θ[idx[b]]
+		})
+
+		// check convergence
+		o.NumConv = 0
+		for i := 0; i < nev; i++ {
+			c := idx[i]
+			resid := math.Abs(β * Y.Get(ncv-1, c))
+			if resid <= o.Tol*math.Abs(θ[c]) {
+				o.NumConv++
+			}
+		}
+		if o.Verbose {
+			io.Pf("SparseEigen: restart = %3d  nconv = %3d  nop = %5d\n", o.NumIt, o.NumConv, o.NumOp)
+		}
+		if o.NumConv == nev {
+			break
+		}
+
+		// thick restart: keep the wanted Ritz vectors and the residual vector
+		kept = utl.Imin(nev+(ncv-nev)/2, ncv-1)
+		ritz := make([]Vector, kept)
+		for i := 0; i < kept; i++ {
+			ritz[i] = NewVector(o.n)
+			c := idx[i]
+			for l := 0; l < ncv; l++ {
+				yl := Y.Get(l, c)
+				for r := 0; r < o.n; r++ {
+					ritz[i][r] += yl * V[l][r]
+				}
+			}
+		}
+		copy(V[kept], V[ncv])
+		for i := 0; i < kept; i++ {
+			copy(V[i], ritz[i])
+		}
+		T.Fill(0)
+		for i := 0; i < kept; i++ {
+			c := idx[i]
+			T.Set(i, i, θ[c])
+			T.Set(i, kept, β*Y.Get(ncv-1, c))
+			T.Set(kept, i, β*Y.Get(ncv-1, c))
+		}
+	}
+	if o.NumConv < nev {
+		chk.Panic("SparseEigen: only %d of %d eigenvalues converged after %d restarts\n", o.NumConv, nev, o.NumIt)
+	}
+
+	// results
+	for i := 0; i < nev; i++ {
+		c := idx[i]
+		if shiftInvert {
+			λ[i] = o.Shift + 1.0/θ[c]
+		} else {
+			λ[i] = θ[c]
+		}
+	}
+	order := make([]int, nev)
+	for i := 0; i < nev; i++ {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		switch o.Which {
+		case "largest":
+			return λ[order[a]] > λ[order[b]]
+		case "nearest":
+			return math.Abs(λ[order[a]]-o.Shift) < math.Abs(λ[order[b]]-o.Shift)
+		}
+		return λ[order[a]] < λ[order[b]]
+	})
+	sorted := NewVector(nev)
+	for i := 0; i < nev; i++ {
+		sorted[i] = λ[order[i]]
+	}
+	copy(λ, sorted)
+	if X == nil {
+		return
+	}
+	x := NewVector(o.n)
+	for i := 0; i < nev; i++ {
+		c := idx[order[i]]
+		x.Fill(0)
+		for l := 0; l < ncv; l++ {
+			yl := Y.Get(l, c)
+			for r := 0; r < o.n; r++ {
+				x[r] += yl * V[l][r]
+			}
+		}
+		o.normalise(x)
+		copy(X.Col(i), x)
+	}
+}
+
+// operator computes w := OP ⋅ v, where OP = (K - σ⋅M)⁻¹ ⋅ M (shift-invert) or OP = M⁻¹ ⋅ K (regular)
+func (o *SparseEigen) operator(w, v Vector, shiftInvert bool) {
+	o.NumOp++
+	if shiftInvert {
+		o.bprod(o.work, v)
+		o.solve(w, o.work)
+		return
+	}
+	o.k.Apply(o.work, v)
+	if o.m == nil {
+		copy(w, o.work)
+		return
+	}
+	o.solve(w, o.work)
+}
+
+// bprod computes bv := M ⋅ v (or bv := v if M is nil)
+func (o *SparseEigen) bprod(bv, v Vector) {
+	if o.m == nil {
+		copy(bv, v)
+		return
+	}
+	o.m.Apply(bv, v)
+}
+
+// bnorm returns the M-norm of v: sqrt(vᵀ⋅M⋅v)
+func (o *SparseEigen) bnorm(v Vector) float64 {
+	o.bprod(o.work, v)
+	return math.Sqrt(math.Abs(VecDot(v, o.work)))
+}
+
+// normalise normalises v such that vᵀ⋅M⋅v = 1
+func (o *SparseEigen) normalise(v Vector) {
+	nrm := o.bnorm(v)
+	if nrm == 0 {
+		chk.Panic("cannot normalise zero vector\n")
+	}
+	v.Apply(1.0/nrm, v)
+}
+
+// orthogonalise makes v M-orthogonal to all vectors in V
+func (o *SparseEigen) orthogonalise(v Vector, V []Vector, bv Vector) {
+	for pass := 0; pass < 2; pass++ {
+		o.bprod(bv, v)
+		for _, u := range V {
+			c := VecDot(u, bv)
+			for l := 0; l < len(v); l++ {
+				v[l] -= c * u[l]
+			}
+		}
+	}
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// spTriLinComb returns a new triplet with the linear combination of two column-compressed
+// matrices: t := α⋅a + β⋅b. Any of a or b may be nil
+func spTriLinComb(α float64, a *CCMatrix, β float64, b *CCMatrix) (t *Triplet) {
+	var m, n, nnz int
+	for _, c := range []*CCMatrix{a, b} {
+		if c != nil {
+			m, n = c.m, c.n
+			nnz += c.p[c.n]
+		}
+	}
+	if b == nil { // identity
+		nnz += n
+	}
+	t = NewTriplet(m, n, nnz)
+	for _, pair := range []struct {
+		s float64
+		c *CCMatrix
+	}{{α, a}, {β, b}} {
+		if pair.c == nil {
+			continue
+		}
+		for j := 0; j < pair.c.n; j++ {
+			for k := pair.c.p[j]; k < pair.c.p[j+1]; k++ {
+				t.Put(pair.c.i[k], j, pair.s*pair.c.x[k])
+			}
+		}
+	}
+	if b == nil {
+		for i := 0; i < n; i++ {
+			t.Put(i, i, β)
+		}
+	}
+	return
+}
+
+// symEigen computes all eigenvalues and eigenvectors of a dense symmetric matrix
+//
+//   A = Q ⋅ diag(w) ⋅ Qᵀ
+//
+//  Input:
+//   a -- symmetric matrix (modified)
+//  Output:
+//   w -- eigenvalues (ascending) [pre-allocated]
+//   Q -- matrix with the eigenvectors in columns [pre-allocated]
+func symEigen(w Vector, Q, a *Matrix) {
+	oblas.Dsyev(true, true, a.M, a.Data, a.M, w)
+	copy(Q.Data, a.Data)
+}
@@ -0,0 +1,563 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// This file implements readers and writers for the Matrix Market (coordinate) format and a reader
+// for the Rutherford-Boeing (and Harwell-Boeing) format. These are the formats used by the
+// SuiteSparse Matrix Collection (https://sparse.tamu.edu) and the Matrix Market
+// (https://math.nist.gov/MatrixMarket).
+//
+//   The symmetry of the matrices is given by one of the following strings:
+//     "general"        -- all entries are stored
+//     "symmetric"      -- only the lower triangle is stored: a[j][i] =  a[i][j]
+//     "skew-symmetric" -- only the lower triangle is stored: a[j][i] = -a[i][j] (and a[i][i] = 0)
+//     "hermitian"      -- only the lower triangle is stored: a[j][i] = conj(a[i][j])
+//
+//   When reading, "mirror" indicates that the (upper) triangle must be generated from the stored
+//   (lower) one; i.e. the full matrix is obtained. Otherwise, the triangular form is kept.
+
+// --------------------------------------------------------------------------------------------------
+// Matrix Market ------------------------------------------------------------------------------------
+// --------------------------------------------------------------------------------------------------
+
+// ReadMatrixMarket reads a Matrix Market file (coordinate format) with real, integer or pattern
+// entries (pattern entries are set to 1)
+//
+//   filename -- the file name (".mtx")
+//   mirror   -- generate the upper triangle of symmetric matrices; otherwise keep the triangular form
+//
+//   Output:
+//     symmetry -- the symmetry in the header: "general", "symmetric" or "skew-symmetric"
+//
+func (o *Triplet) ReadMatrixMarket(filename string, mirror bool) (symmetry string) {
+	d := readMatrixMarket(filename)
+	if d.field == "complex" {
+		chk.Panic("cannot read complex matrix into real Triplet. file = <%s>\n", filename)
+	}
+	d.toTriplet(o, mirror)
+	return d.symmetry
+}
+
+// ReadMatrixMarket reads a Matrix Market file (coordinate format) with real, integer, complex or
+// pattern entries (pattern entries are set to 1)
+//
+//   filename -- the file name (".mtx")
+//   mirror   -- generate the upper triangle of symmetric matrices; otherwise keep the triangular form
+//
+//   Output:
+//     symmetry -- the symmetry in the header: "general", "symmetric", "skew-symmetric" or "hermitian"
+//
+func (o *TripletC) ReadMatrixMarket(filename string, mirror bool) (symmetry string) {
+	d := readMatrixMarket(filename)
+	d.toTripletC(o, mirror)
+	return d.symmetry
+}
+
+// ReadMatrixMarket reads a Matrix Market file (coordinate format) with real, integer or pattern
+// entries into a column-compressed matrix (see Triplet.ReadMatrixMarket)
+func (o *CCMatrix) ReadMatrixMarket(filename string, mirror bool) (symmetry string) {
+	var t Triplet
+	symmetry = t.ReadMatrixMarket(filename, mirror)
+	*o = *t.ToMatrix(nil)
+	return
+}
+
+// WriteMatrixMarket writes a Matrix Market file (coordinate format)
+//
+//  NOTE: this method will create a CCMatrix first because
+//        duplicates must be added before saving the file
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".mtx" will be added
+//  symmetry -- "general" (or ""), "symmetric" or "skew-symmetric". In the last two cases, only the
+//              lower triangle is written (the matrix is not checked for symmetry)
+func (o *Triplet) WriteMatrixMarket(dirout, fnkey, symmetry string) (cmat *CCMatrix) {
+	cmat = o.ToMatrix(nil)
+	cmat.WriteMatrixMarket(dirout, fnkey, symmetry)
+	return
+}
+
+// WriteMatrixMarket writes a Matrix Market file (coordinate format)
+//
+//  NOTE: this method will create a CCMatrixC first because
+//        duplicates must be added before saving the file
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".mtx" will be added
+//  symmetry -- "general" (or ""), "symmetric", "skew-symmetric" or "hermitian". In the last
+//              three cases, only the lower triangle is written (the matrix is not checked)
+func (o *TripletC) WriteMatrixMarket(dirout, fnkey, symmetry string) (cmat *CCMatrixC) {
+	cmat = o.ToMatrix(nil)
+	cmat.WriteMatrixMarket(dirout, fnkey, symmetry)
+	return
+}
+
+// WriteMatrixMarket writes a Matrix Market file (coordinate format)
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".mtx" will be added
+//  symmetry -- "general" (or ""), "symmetric" or "skew-symmetric". In the last two cases, only the
+//              lower triangle is written (the matrix is not checked for symmetry)
+func (o *CCMatrix) WriteMatrixMarket(dirout, fnkey, symmetry string) {
+	symmetry = mmCheckSymmetry(symmetry, false)
+	var bfa, bfb bytes.Buffer
+	var nnz int
+	for j := 0; j < o.n; j++ {
+		for p := o.p[j]; p < o.p[j+1]; p++ {
+			if mmSkip(symmetry, o.i[p], j) {
+				continue
+			}
+			io.Ff(&bfb, "%d %d %.17g\n", o.i[p]+1, j+1, o.x[p])
+			nnz++
+		}
+	}
+	io.Ff(&bfa, "%%%%MatrixMarket matrix coordinate real %s\n", symmetry)
+	io.Ff(&bfa, "%d %d %d\n", o.m, o.n, nnz)
+	io.WriteFileVD(dirout, fnkey+".mtx", &bfa, &bfb)
+}
+
+// WriteMatrixMarket writes a Matrix Market file (coordinate format)
+//
+//  dirout   -- directory for output. will be created
+//  fnkey    -- filename key (filename without extension). ".mtx" will be added
+//  symmetry -- "general" (or ""), "symmetric", "skew-symmetric" or "hermitian". In the last
+//              three cases, only the lower triangle is written (the matrix is not checked)
+func (o *CCMatrixC) WriteMatrixMarket(dirout, fnkey, symmetry string) {
+	symmetry = mmCheckSymmetry(symmetry, true)
+	var bfa, bfb bytes.Buffer
+	var nnz int
+	for j := 0; j < o.n; j++ {
+		for p := o.p[j]; p < o.p[j+1]; p++ {
+			if mmSkip(symmetry, o.i[p], j) {
+				continue
+			}
+			io.Ff(&bfb, "%d %d %.17g %.17g\n", o.i[p]+1, j+1, real(o.x[p]), imag(o.x[p]))
+			nnz++
+		}
+	}
+	io.Ff(&bfa, "%%%%MatrixMarket matrix coordinate complex %s\n", symmetry)
+	io.Ff(&bfa, "%d %d %d\n", o.m, o.n, nnz)
+	io.WriteFileVD(dirout, fnkey+".mtx", &bfa, &bfb)
+}
+
+// --------------------------------------------------------------------------------------------------
+// Rutherford-Boeing --------------------------------------------------------------------------------
+// --------------------------------------------------------------------------------------------------
+
+// ReadRutherfordBoeing reads a Rutherford-Boeing (or Harwell-Boeing) file with an assembled
+// real, integer or pattern matrix (pattern entries are set to 1). Right-hand sides are ignored.
+//
+//   filename -- the file name (e.g. ".rb" or ".rua")
+//   mirror   -- generate the upper triangle of symmetric matrices; otherwise keep the triangular form
+//
+//   Output:
+//     symmetry -- "general", "symmetric" or "skew-symmetric" (from the matrix type)
+//
+func (o *Triplet) ReadRutherfordBoeing(filename string, mirror bool) (symmetry string) {
+	d := readRutherfordBoeing(filename)
+	if d.field == "complex" {
+		chk.Panic("cannot read complex matrix into real Triplet. file = <%s>\n", filename)
+	}
+	d.toTriplet(o, mirror)
+	return d.symmetry
+}
+
+// ReadRutherfordBoeing reads a Rutherford-Boeing (or Harwell-Boeing) file with an assembled
+// real, integer, complex or pattern matrix (pattern entries are set to 1). Right-hand sides are ignored.
+//
+//   filename -- the file name (e.g. ".rb" or ".cua")
+//   mirror   -- generate the upper triangle of symmetric matrices; otherwise keep the triangular form
+//
+//   Output:
+//     symmetry -- "general", "symmetric", "skew-symmetric" or "hermitian" (from the matrix type)
+//
+func (o *TripletC) ReadRutherfordBoeing(filename string, mirror bool) (symmetry string) {
+	d := readRutherfordBoeing(filename)
+	d.toTripletC(o, mirror)
+	return d.symmetry
+}
+
+// --------------------------------------------------------------------------------------------------
+// auxiliary ----------------------------------------------------------------------------------------
+// --------------------------------------------------------------------------------------------------
+
+// spFileData holds the entries of a sparse matrix read from file
+type spFileData struct {
+	m, n     int       // dimensions
+	field    string    // "real", "integer", "complex" or "pattern"
+	symmetry string    // "general", "symmetric", "skew-symmetric" or "hermitian"
+	i, j     []int     // 0-based indices
+	x, z     []float64 // values: real and imaginary (complex only) parts
+}
+
+// put appends an entry
+func (o *spFileData) put(i, j int, x, z float64) {
+	if i < 0 || i >= o.m || j < 0 || j >= o.n {
+		chk.Panic("index (%d,%d) is out of range for %d x %d matrix\n", i+1, j+1, o.m, o.n)
+	}
+	o.i = append(o.i, i)
+	o.j = append(o.j, j)
+	o.x = append(o.x, x)
+	o.z = append(o.z, z)
+}
+
+// numMirrored returns the number of entries after mirroring
+func (o *spFileData) numMirrored(mirror bool) (nnz int) {
+	nnz = len(o.i)
+	if mirror && o.symmetry != "general" {
+		for k := 0; k < len(o.i); k++ {
+			if o.i[k] != o.j[k] {
+				nnz++
+			}
+		}
+	}
+	return
+}
+
+// toTriplet initialises and fills a real triplet
+func (o *spFileData) toTriplet(t *Triplet, mirror bool) {
+	t.Init(o.m, o.n, o.numMirrored(mirror))
+	for k := 0; k < len(o.i); k++ {
+		t.Put(o.i[k], o.j[k], o.x[k])
+		if mirror && o.symmetry != "general" && o.i[k] != o.j[k] {
+			if o.symmetry == "skew-symmetric" {
+				t.Put(o.j[k], o.i[k], -o.x[k])
+			} else {
+				t.Put(o.j[k], o.i[k], o.x[k])
+			}
+		}
+	}
+}
+
+// toTripletC initialises and fills a complex triplet
+func (o *spFileData) toTripletC(t *TripletC, mirror bool) {
+	t.Init(o.m, o.n, o.numMirrored(mirror))
+	for k := 0; k < len(o.i); k++ {
+		t.Put(o.i[k], o.j[k], complex(o.x[k], o.z[k]))
+		if mirror && o.symmetry != "general" && o.i[k] != o.j[k] {
+			switch o.symmetry {
+			case "skew-symmetric":
+				t.Put(o.j[k], o.i[k], complex(-o.x[k], -o.z[k]))
+			case "hermitian":
+				t.Put(o.j[k], o.i[k], complex(o.x[k], -o.z[k]))
+			default:
+				t.Put(o.j[k], o.i[k], complex(o.x[k], o.z[k]))
+			}
+		}
+	}
+}
+
+// readMatrixMarket reads a Matrix Market file in coordinate format
+func readMatrixMarket(filename string) (d *spFileData) {
+	d = new(spFileData)
+	header, sizes := false, false
+	nnz := 0
+	io.ReadLines(filename, func(idx int, line string) (stop bool) {
+
+		// header
+		if !header {
+			r := strings.Fields(strings.ToLower(line))
+			if len(r) != 5 || r[0] != "%%matrixmarket" || r[1] != "matrix" {
+				chk.Panic("invalid Matrix Market header: %q. file = <%s>\n", line, filename)
+			}
+			if r[2] != "coordinate" {
+				chk.Panic("only the coordinate format is supported. format = %q. file = <%s>\n", r[2], filename)
+			}
+			d.field, d.symmetry = r[3], r[4]
+			switch d.field {
+			case "real", "integer", "complex", "pattern":
+			default:
+				chk.Panic("invalid field in Matrix Market header: %q. file = <%s>\n", d.field, filename)
+			}
+			switch d.symmetry {
+			case "general", "symmetric", "skew-symmetric":
+			case "hermitian":
+				if d.field != "complex" {
+					d.symmetry = "symmetric"
+				}
+			default:
+				chk.Panic("invalid symmetry in Matrix Market header: %q. file = <%s>\n", d.symmetry, filename)
+			}
+			header = true
+			return
+		}
+
+		// skip comments and empty lines
+		r := strings.Fields(line)
+		if len(r) == 0 || strings.HasPrefix(r[0], "%") {
+			return
+		}
+
+		// sizes
+		if !sizes {
+			if len(r) != 3 {
+				chk.Panic("the size line must have 3 columns (m,n,nnz). file = <%s>\n", filename)
+			}
+			d.m, d.n, nnz = io.Atoi(r[0]), io.Atoi(r[1]), io.Atoi(r[2])
+			d.i, d.j = make([]int, 0, nnz), make([]int, 0, nnz)
+			d.x, d.z = make([]float64, 0, nnz), make([]float64, 0, nnz)
+			sizes = true
+			return
+		}
+
+		// entries
+		ncol := 3
+		switch d.field {
+		case "pattern":
+			ncol = 2
+		case "complex":
+			ncol = 4
+		}
+		if len(r) < ncol {
+			chk.Panic("the entries in %s matrices must have %d columns. line = %q. file = <%s>\n", d.field, ncol, line, filename)
+		}
+		i, j, x, z := io.Atoi(r[0])-1, io.Atoi(r[1])-1, 1.0, 0.0
+		if ncol > 2 {
+			x = io.Atof(r[2])
+		}
+		if ncol > 3 {
+			z = io.Atof(r[3])
+		}
+		d.put(i, j, x, z)
+		return
+	})
+	if !sizes {
+		chk.Panic("the size line is missing. file = <%s>\n", filename)
+	}
+	if len(d.i) != nnz {
+		chk.Panic("the number of entries (%d) is different than the one in the size line (%d). file = <%s>\n", len(d.i), nnz, filename)
+	}
+	return
+}
+
+// readRutherfordBoeing reads a Rutherford-Boeing (or Harwell-Boeing) file
+//
+//   line 1: title and key
+//   line 2: TOTCRD PTRCRD INDCRD VALCRD [RHSCRD]  (the last one is only in Harwell-Boeing files)
+//   line 3: MXTYPE NROW NCOL NNZERO [NELTVL]
+//   line 4: PTRFMT INDFMT VALFMT [RHSFMT]
+//   line 5: only if RHSCRD > 0 (ignored)
+//   then: column pointers, row indices and values in fixed-width Fortran format
+//
+func readRutherfordBoeing(filename string) (d *spFileData) {
+
+	// read lines
+	var lines []string
+	io.ReadLines(filename, func(idx int, line string) (stop bool) {
+		lines = append(lines, line)
+		return
+	})
+	if len(lines) < 4 {
+		chk.Panic("Rutherford-Boeing file must have at least 4 lines. file = <%s>\n", filename)
+	}
+
+	// header
+	r := strings.Fields(lines[1])
+	if len(r) < 4 {
+		chk.Panic("the second line must have at least 4 integers. file = <%s>\n", filename)
+	}
+	ptrcrd, indcrd, valcrd, rhscrd := io.Atoi(r[1]), io.Atoi(r[2]), io.Atoi(r[3]), 0
+	if len(r) > 4 {
+		rhscrd = io.Atoi(r[4])
+	}
+	r = strings.Fields(lines[2])
+	if len(r) < 4 || len(r[0]) != 3 {
+		chk.Panic("the third line must have the matrix type and 3 integers. file = <%s>\n", filename)
+	}
+	mxtype := strings.ToLower(r[0])
+	d = new(spFileData)
+	d.m, d.n = io.Atoi(r[1]), io.Atoi(r[2])
+	nnz := io.Atoi(r[3])
+	switch mxtype[0] {
+	case 'r':
+		d.field = "real"
+	case 'i':
+		d.field = "integer"
+	case 'c':
+		d.field = "complex"
+	case 'p', 'q':
+		d.field = "pattern"
+	default:
+		chk.Panic("invalid matrix type %q. file = <%s>\n", mxtype, filename)
+	}
+	switch mxtype[1] {
+	case 'u', 'r':
+		d.symmetry = "general"
+	case 's':
+		d.symmetry = "symmetric"
+	case 'z':
+		d.symmetry = "skew-symmetric"
+	case 'h':
+		d.symmetry = "hermitian"
+		if d.field != "complex" {
+			d.symmetry = "symmetric"
+		}
+	default:
+		chk.Panic("invalid matrix type %q. file = <%s>\n", mxtype, filename)
+	}
+	if mxtype[2] != 'a' {
+		chk.Panic("only assembled matrices are supported. type = %q. file = <%s>\n", mxtype, filename)
+	}
+	r = strings.Fields(lines[3])
+	if len(r) < 2 || (d.field != "pattern" && valcrd > 0 && len(r) < 3) {
+		chk.Panic("the fourth line must have the Fortran formats. file = <%s>\n", filename)
+	}
+	ptrfmt, indfmt := r[0], r[1]
+	valfmt := ""
+	if len(r) > 2 {
+		valfmt = r[2]
+	}
+
+	// data
+	start := 4
+	if rhscrd > 0 {
+		start = 5
+	}
+	if len(lines) < start+ptrcrd+indcrd+valcrd {
+		chk.Panic("the file has fewer lines than indicated in the header. file = <%s>\n", filename)
+	}
+	ptr := rbReadInts(lines[start:start+ptrcrd], ptrfmt, d.n+1, filename)
+	ind := rbReadInts(lines[start+ptrcrd:start+ptrcrd+indcrd], indfmt, nnz, filename)
+	var val []float64
+	if d.field != "pattern" && valcrd > 0 {
+		nval := nnz
+		if d.field == "complex" {
+			nval = 2 * nnz
+		}
+		val = rbReadFloats(lines[start+ptrcrd+indcrd:start+ptrcrd+indcrd+valcrd], valfmt, nval, filename)
+	}
+
+	// entries
+	d.i, d.j = make([]int, 0, nnz), make([]int, 0, nnz)
+	d.x, d.z = make([]float64, 0, nnz), make([]float64, 0, nnz)
+	for j := 0; j < d.n; j++ {
+		for p := ptr[j] - 1; p < ptr[j+1]-1; p++ {
+			x, z := 1.0, 0.0
+			switch {
+			case val == nil:
+			case d.field == "complex":
+				x, z = val[2*p], val[2*p+1]
+			default:
+				x = val[p]
+			}
+			d.put(ind[p]-1, j, x, z)
+		}
+	}
+	return
+}
+
+// rbParseFormat parses a Fortran format such as (10I8), (1P,4E20.12) or (5D16.8) and returns the
+// number of items per line and the width of each item
+func rbParseFormat(format, filename string) (perLine, width int) {
+	f := strings.ToUpper(strings.Trim(format, "()"))
+	if k := strings.LastIndex(f, ","); k >= 0 { // remove scale factor, e.g. 1P,
+		f = f[k+1:]
+	}
+	if k := strings.Index(f, "P"); k >= 0 { // remove scale factor, e.g. 1P
+		f = f[k+1:]
+	}
+	k := strings.IndexAny(f, "IEDFG")
+	if k < 1 {
+		chk.Panic("cannot parse Fortran format %q. file = <%s>\n", format, filename)
+	}
+	perLine = io.Atoi(f[:k])
+	w := f[k+1:]
+	if l := strings.Index(w, "."); l >= 0 {
+		w = w[:l]
+	}
+	width = io.Atoi(w)
+	return
+}
+
+// rbFields splits the lines into fixed-width fields
+func rbFields(lines []string, format string, n int, filename string) (fields []string) {
+	perLine, width := rbParseFormat(format, filename)
+	fields = make([]string, 0, n)
+	for _, line := range lines {
+		for k := 0; k < perLine && len(fields) < n; k++ {
+			a, b := k*width, (k+1)*width
+			if a >= len(line) {
+				break
+			}
+			if b > len(line) {
+				b = len(line)
+			}
+			s := strings.TrimSpace(line[a:b])
+			if s == "" {
+				break
+			}
+			fields = append(fields, s)
+		}
+	}
+	if len(fields) != n {
+		chk.Panic("cannot read %d items with format %q (%d read). file = <%s>\n", n, format, len(fields), filename)
+	}
+	return
+}
+
+// rbReadInts reads n integers given in Fortran format
+func rbReadInts(lines []string, format string, n int, filename string) (res []int) {
+	fields := rbFields(lines, format, n, filename)
+	res = make([]int, n)
+	for k, s := range fields {
+		res[k] = io.Atoi(s)
+	}
+	return
+}
+
+// rbReadFloats reads n real numbers given in Fortran format. The exponent may be indicated by
+// 'D' or omitted (e.g. 1.5-100) as allowed by Fortran
+func rbReadFloats(lines []string, format string, n int, filename string) (res []float64) {
+	fields := rbFields(lines, format, n, filename)
+	res = make([]float64, n)
+	for k, s := range fields {
+		s = strings.Map(func(r rune) rune {
+			if r == 'D' || r == 'd' {
+				return 'E'
+			}
+			return r
+		}, s)
+		if l := strings.LastIndexAny(s, "+-"); l > 0 && s[l-1] != 'E' && s[l-1] != 'e' {
+			s = s[:l] + "E" + s[l:]
+		}
+		res[k] = io.Atof(s)
+	}
+	return
+}
+
+// mmCheckSymmetry checks the symmetry string used by the Matrix Market writers
+func mmCheckSymmetry(symmetry string, isComplex bool) string {
+	switch symmetry {
+	case "", "general":
+		return "general"
+	case "symmetric", "skew-symmetric":
+		return symmetry
+	case "hermitian":
+		if isComplex {
+			return symmetry
+		}
+	}
+	chk.Panic("invalid symmetry %q\n", symmetry)
+	return ""
+}
+
+// mmSkip tells whether the entry (i,j) must be skipped when writing matrices with the given symmetry
+func mmSkip(symmetry string, i, j int) bool {
+	switch symmetry {
+	case "symmetric", "hermitian":
+		return i < j
+	case "skew-symmetric":
+		return i <= j
+	}
+	return false
+}
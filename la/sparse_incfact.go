@@ -0,0 +1,581 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
+
+// IncFactStats holds diagnostic information about incomplete factorisations
+type IncFactStats struct {
+	NnzA       int     // number of non-zeros in A (in lower(A) for incomplete Cholesky)
+	NnzF       int     // number of non-zeros in the factors (L and U, or L only; including the diagonal)
+	Fill       float64 // fill ratio = NnzF / NnzA
+	Breakdowns int     // number of pivot breakdowns; each one triggers a shifted re-factorisation
+	BreakRow   int     // row of the last pivot breakdown; -1 if none
+	Shift      float64 // relative diagonal shift α of the successful factorisation; 0 if none
+}
+
+// IncFactShift holds the settings for the recovery from pivot breakdowns in incomplete
+// factorisations. After a breakdown (zero, tiny or non-positive pivot), the factorisation is
+// recomputed with the diagonal shifted as follows
+//
+//   Aᵢᵢ ← Aᵢᵢ + α ⋅ dᵢ   with   dᵢ = |Aᵢᵢ|  or  dᵢ = max_j |Aᵢⱼ|  if Aᵢᵢ = 0
+//
+//   where α = ShiftIni in the first attempt and α is doubled in each subsequent attempt
+type IncFactShift struct {
+	ShiftIni  float64 // initial relative shift α [default = 1e-3]
+	MaxShifts int     // maximum number of shifted re-factorisations [default = 20]
+	NoShift   bool    // panic at the first breakdown instead of shifting the diagonal
+}
+
+// incFactPivTol defines the breakdown of pivots: |pivot| ≤ incFactPivTol ⋅ dᵢ
+const incFactPivTol = 1e-12
+
+// ILU(k) ///////////////////////////////////////////////////////////////////////////////////////////
+
+// IluK implements the incomplete LU factorisation with level-of-fill k
+//
+//   M = L ⋅ U ≈ A
+//
+//   where the fill-ins with level greater than k are discarded. The level of the entries of A
+//   (and of the diagonal) is 0 and the level of a fill-in created by the elimination of Aᵢⱼ with
+//   row j is lev(Aᵢⱼ) + lev(Uⱼₗ) + 1. Thus, ILU(0) keeps the pattern of A.
+//
+//   NOTE: (1) Factor may be called several times (e.g. with new values of A)
+//         (2) IluK satisfies the Preconditioner interface; e.g. Krylov.Prec = &IluK{Level: 1}
+//
+//   Reference:
+//    [1] Saad Y (2003) Iterative Methods for Sparse Linear Systems, 2nd Edition. SIAM
+type IluK struct {
+	Level int          // level of fill k
+	Shift IncFactShift // recovery from pivot breakdown
+	Stats IncFactStats // diagnostics of the last factorisation
+	iluFactors
+	lev []int // levels of the entries in the factors
+}
+
+// Factor computes the incomplete factorisation of a (square) matrix
+func (o *IluK) Factor(a *CCMatrix) {
+	if a.m != a.n {
+		chk.Panic("ILU(k) requires a square matrix. %d × %d is invalid\n", a.m, a.n)
+	}
+	ap, aj, ax := spRowCompress(a)
+	o.symbolic(a.n, ap, aj)
+	d := incFactDiag(a.n, ap, aj, ax)
+	o.lu = make([]float64, len(o.rj))
+	o.Shift.run("ILU(k)", &o.Stats, func(α float64) (brk int, pivot float64) {
+		return o.ikj(a.n, ap, aj, ax, d, α)
+	})
+	o.Stats.set(ap[a.n], len(o.rj))
+}
+
+// Init initialises the preconditioner (calls Factor)
+func (o *IluK) Init(a *CCMatrix) {
+	o.Factor(a)
+}
+
+// Apply applies the preconditioner: z := M⁻¹ ⋅ r (calls Solve)
+func (o *IluK) Apply(z, r Vector) {
+	o.Solve(z, r)
+}
+
+// symbolic computes the pattern of the factors with level of fill ≤ k
+func (o *IluK) symbolic(n int, ap, aj []int) {
+	o.rp = make([]int, n+1)
+	o.dpos = make([]int, n)
+	o.rj, o.lev = o.rj[:0], o.lev[:0]
+	next := make([]int, n+1) // linked list with the (sorted) columns of the current row; n ends the list
+	levw := make([]int, n)   // level of entries in the current row
+	inrow := make([]bool, n) // column is in the current row
+	for i := 0; i < n; i++ {
+
+		// pattern of row i of A and the diagonal
+		head, prev := n, n
+		add := func(c int) {
+			if inrow[c] {
+				return
+			}
+			if prev == n {
+				head = c
+			} else {
+				next[prev] = c
+			}
+			prev, next[c], levw[c], inrow[c] = c, n, 0, true
+		}
+		diag := false
+		for k := ap[i]; k < ap[i+1]; k++ {
+			if !diag && aj[k] > i {
+				add(i)
+				diag = true
+			}
+			if aj[k] == i {
+				diag = true
+			}
+			add(aj[k])
+		}
+		if !diag {
+			add(i)
+		}
+
+		// fill-in due to the elimination with previous rows
+		for j := head; j < i; j = next[j] {
+			prev = j
+			for l := o.dpos[j] + 1; l < o.rp[j+1]; l++ {
+				c := o.rj[l]
+				lev := levw[j] + o.lev[l] + 1
+				if lev > o.Level {
+					continue
+				}
+				if inrow[c] {
+					if lev < levw[c] {
+						levw[c] = lev
+					}
+					prev = c
+					continue
+				}
+				for next[prev] < c {
+					prev = next[prev]
+				}
+				next[c], next[prev] = next[prev], c
+				levw[c], inrow[c] = lev, true
+				prev = c
+			}
+		}
+
+		// store row i
+		for c := head; c != n; c = next[c] {
+			if c == i {
+				o.dpos[i] = len(o.rj)
+			}
+			o.rj = append(o.rj, c)
+			o.lev = append(o.lev, levw[c])
+			inrow[c] = false
+		}
+		o.rp[i+1] = len(o.rj)
+	}
+}
+
+// ILUT /////////////////////////////////////////////////////////////////////////////////////////////
+
+// Ilut implements the incomplete LU factorisation with threshold dropping ILUT(τ,p)
+//
+//   M = L ⋅ U ≈ A
+//
+//   where, in each row i, the entries smaller than τ ⋅ ‖aᵢ‖₂ are dropped and only the largest
+//   nnz(aᵢ) + MaxFill entries are kept in the L and U parts; nnz(aᵢ) being the number of entries
+//   of row i of A in the corresponding part. The diagonal is always kept.
+//
+//   NOTE: (1) Factor may be called several times (e.g. with new values of A)
+//         (2) Ilut satisfies the Preconditioner interface; e.g. Krylov.Prec = &Ilut{DropTol: 1e-3}
+//
+//   Reference:
+//    [1] Saad Y (1994) ILUT: A dual threshold incomplete LU factorization. Numerical Linear
+//        Algebra with Applications, 1(4):387-402
+type Ilut struct {
+	DropTol float64      // drop tolerance τ [default = 1e-4]
+	MaxFill int          // maximum number of fill-ins in each row of L and U [default = 10]
+	Shift   IncFactShift // recovery from pivot breakdown
+	Stats   IncFactStats // diagnostics of the last factorisation
+	iluFactors
+}
+
+// Factor computes the incomplete factorisation of a (square) matrix
+func (o *Ilut) Factor(a *CCMatrix) {
+	if a.m != a.n {
+		chk.Panic("ILUT requires a square matrix. %d × %d is invalid\n", a.m, a.n)
+	}
+	if o.DropTol <= 0 {
+		o.DropTol = 1e-4
+	}
+	if o.MaxFill <= 0 {
+		o.MaxFill = 10
+	}
+	ap, aj, ax := spRowCompress(a)
+	d := incFactDiag(a.n, ap, aj, ax)
+	o.Shift.run("ILUT", &o.Stats, func(α float64) (brk int, pivot float64) {
+		return o.numeric(a.n, ap, aj, ax, d, α)
+	})
+	o.Stats.set(ap[a.n], len(o.rj))
+}
+
+// Init initialises the preconditioner (calls Factor)
+func (o *Ilut) Init(a *CCMatrix) {
+	o.Factor(a)
+}
+
+// Apply applies the preconditioner: z := M⁻¹ ⋅ r (calls Solve)
+func (o *Ilut) Apply(z, r Vector) {
+	o.Solve(z, r)
+}
+
+// numeric computes the factors with dropping. It returns the row with pivot breakdown or -1
+func (o *Ilut) numeric(n int, ap, aj []int, ax, d []float64, α float64) (brk int, pivot float64) {
+	o.rp = make([]int, n+1)
+	o.dpos = make([]int, n)
+	o.rj, o.lu = o.rj[:0], o.lu[:0]
+	w := make([]float64, n) // working row
+	inw := make([]bool, n)  // column is in the working row
+	var lcols, ucols []int  // columns of the L and U parts of the working row
+	var lnext ilutHeap      // columns of the L part to be eliminated
+	for i := 0; i < n; i++ {
+
+		// working row := row i of A (with shifted diagonal)
+		lcols, ucols, lnext = lcols[:0], ucols[:0], lnext[:0]
+		nrm := 0.0
+		nnzL, nnzU := 0, 0
+		set := func(c int, v float64) {
+			w[c], inw[c] = v, true
+			if c < i {
+				heap.Push(&lnext, c)
+			} else {
+				ucols = append(ucols, c)
+			}
+		}
+		set(i, α*d[i])
+		for k := ap[i]; k < ap[i+1]; k++ {
+			c := aj[k]
+			nrm += ax[k] * ax[k]
+			switch {
+			case c < i:
+				nnzL++
+			case c > i:
+				nnzU++
+			}
+			if inw[c] {
+				w[c] += ax[k]
+			} else {
+				set(c, ax[k])
+			}
+		}
+		tol := o.DropTol * math.Sqrt(nrm)
+
+		// elimination with previous rows (in increasing order of columns)
+		for lnext.Len() > 0 {
+			k := heap.Pop(&lnext).(int)
+			wk := w[k] / o.lu[o.dpos[k]]
+			if math.Abs(wk) < tol {
+				w[k], inw[k] = 0, false
+				continue
+			}
+			w[k] = wk
+			lcols = append(lcols, k)
+			for l := o.dpos[k] + 1; l < o.rp[k+1]; l++ {
+				c := o.rj[l]
+				if inw[c] {
+					w[c] -= wk * o.lu[l]
+				} else {
+					set(c, -wk*o.lu[l])
+				}
+			}
+		}
+
+		// dropping and storage
+		pivot = w[i]
+		o.store(lcols, w, tol, nnzL+o.MaxFill, -1)
+		o.dpos[i] = len(o.rj)
+		o.rj, o.lu = append(o.rj, i), append(o.lu, pivot)
+		o.store(ucols, w, tol, nnzU+o.MaxFill, i)
+		o.rp[i+1] = len(o.rj)
+		for _, c := range lcols {
+			inw[c] = false
+		}
+		for _, c := range ucols {
+			inw[c] = false
+		}
+		if math.Abs(pivot) <= incFactPivTol*d[i] || math.IsNaN(pivot) {
+			return i, pivot
+		}
+	}
+	return -1, 0
+}
+
+// store appends the (at most) p largest entries of w[cols] with |w| ≥ tol to the factors,
+// skipping column "skip"; the columns are stored in increasing order
+func (o *Ilut) store(cols []int, w []float64, tol float64, p int, skip int) {
+	kept := make([]int, 0, len(cols))
+	for _, c := range cols {
+		if c != skip && math.Abs(w[c]) >= tol {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) > p {
+		sort.Slice(kept, func(a, b int) bool { return math.Abs(w[kept[a]]) > math.Abs(w[kept[b]]) })
+		kept = kept[:p]
+	}
+	sort.Ints(kept)
+	for _, c := range kept {
+		o.rj, o.lu = append(o.rj, c), append(o.lu, w[c])
+	}
+}
+
+// ilutHeap implements a min-heap of column indices
+type ilutHeap []int
+
+func (h ilutHeap) Len() int            { return len(h) }
+func (h ilutHeap) Less(a, b int) bool  { return h[a] < h[b] }
+func (h ilutHeap) Swap(a, b int)       { h[a], h[b] = h[b], h[a] }
+func (h *ilutHeap) Push(x interface{}) { *h = append(*h, x.(int)) }
+func (h *ilutHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// IC(0) ////////////////////////////////////////////////////////////////////////////////////////////
+
+// Ic0 implements the incomplete Cholesky factorisation with zero fill-in
+//
+//   M = L ⋅ Lᵀ ≈ A   with   pattern(L) = pattern(lower(A)) ∪ diagonal
+//
+//   NOTE: (1) A must be symmetric; only its lower triangle is used. Even if A is positive-definite,
+//             the incomplete factorisation may break down (non-positive pivot); then, the
+//             diagonal is shifted according to the Shift settings
+//         (2) Factor may be called several times (e.g. with new values of A)
+//         (3) Ic0 satisfies the Preconditioner interface; e.g. Krylov.Prec = new(Ic0)
+type Ic0 struct {
+	Shift  IncFactShift // recovery from pivot breakdown
+	Stats  IncFactStats // diagnostics of the last factorisation
+	rp, rj []int        // row pointers and column indices of L (sorted; diagonal is the last in row)
+	lx     []float64    // values of L
+}
+
+// Factor computes the incomplete factorisation of a symmetric matrix
+func (o *Ic0) Factor(a *CCMatrix) {
+	if a.m != a.n {
+		chk.Panic("IC(0) requires a square matrix. %d × %d is invalid\n", a.m, a.n)
+	}
+
+	// lower triangle of a in row-compressed format (plus diagonal)
+	ap, aj, ax := spRowCompress(a)
+	n := a.n
+	d := incFactDiag(n, ap, aj, ax)
+	o.rp = make([]int, n+1)
+	o.rj = o.rj[:0]
+	lower := make([]float64, 0, ap[n])
+	nnzA := 0
+	for i := 0; i < n; i++ {
+		diag := 0.0
+		for k := ap[i]; k < ap[i+1]; k++ {
+			switch {
+			case aj[k] < i:
+				o.rj = append(o.rj, aj[k])
+				lower = append(lower, ax[k])
+				nnzA++
+			case aj[k] == i:
+				diag += ax[k]
+				nnzA++
+			}
+		}
+		o.rj = append(o.rj, i)
+		lower = append(lower, diag)
+		o.rp[i+1] = len(o.rj)
+	}
+	o.lx = make([]float64, len(lower))
+
+	// factorisation
+	o.Shift.run("IC(0)", &o.Stats, func(α float64) (brk int, pivot float64) {
+		copy(o.lx, lower)
+		for i := 0; i < n; i++ {
+			o.lx[o.rp[i+1]-1] += α * d[i]
+		}
+		return o.numeric(n, d)
+	})
+	o.Stats.set(nnzA, len(o.rj))
+}
+
+// Init initialises the preconditioner (calls Factor)
+func (o *Ic0) Init(a *CCMatrix) {
+	o.Factor(a)
+}
+
+// Apply applies the preconditioner: z := M⁻¹ ⋅ r (calls Solve)
+func (o *Ic0) Apply(z, r Vector) {
+	o.Solve(z, r)
+}
+
+// Solve solves M ⋅ x = b; i.e. x := M⁻¹ ⋅ b = L⁻ᵀ ⋅ L⁻¹ ⋅ b
+//  NOTE: x and b may be the same vector
+func (o *Ic0) Solve(x, b Vector) {
+	n := len(b)
+	for i := 0; i < n; i++ {
+		sum := b[i]
+		last := o.rp[i+1] - 1
+		for k := o.rp[i]; k < last; k++ {
+			sum -= o.lx[k] * x[o.rj[k]]
+		}
+		x[i] = sum / o.lx[last]
+	}
+	for i := n - 1; i >= 0; i-- {
+		last := o.rp[i+1] - 1
+		x[i] /= o.lx[last]
+		for k := o.rp[i]; k < last; k++ {
+			x[o.rj[k]] -= o.lx[k] * x[i]
+		}
+	}
+}
+
+// numeric performs the row-oriented factorisation restricted to the pattern of L. It returns the
+// row with pivot breakdown or -1
+func (o *Ic0) numeric(n int, d []float64) (brk int, pivot float64) {
+	for i := 0; i < n; i++ {
+		for k := o.rp[i]; k < o.rp[i+1]; k++ {
+			j := o.rj[k]
+			// sum over common columns c < j of rows i and j
+			sum := o.lx[k]
+			p, q := o.rp[i], o.rp[j]
+			for p < k && q < o.rp[j+1]-1 {
+				switch {
+				case o.rj[p] == o.rj[q]:
+					sum -= o.lx[p] * o.lx[q]
+					p++
+					q++
+				case o.rj[p] < o.rj[q]:
+					p++
+				default:
+					q++
+				}
+			}
+			if j < i {
+				o.lx[k] = sum / o.lx[o.rp[j+1]-1]
+				continue
+			}
+			if sum <= incFactPivTol*d[i] || math.IsNaN(sum) {
+				return i, sum
+			}
+			o.lx[k] = math.Sqrt(sum)
+		}
+	}
+	return -1, 0
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// iluFactors holds L (unit diagonal; not stored) and U in row-compressed format
+type iluFactors struct {
+	rp, rj []int     // row pointers and column indices (sorted)
+	lu     []float64 // values of L and U
+	dpos   []int     // position of diagonal entries in rj and lu
+}
+
+// Solve solves M ⋅ x = b; i.e. x := M⁻¹ ⋅ b = U⁻¹ ⋅ L⁻¹ ⋅ b
+//  NOTE: x and b may be the same vector
+func (o *iluFactors) Solve(x, b Vector) {
+	n := len(b)
+	for i := 0; i < n; i++ {
+		sum := b[i]
+		for k := o.rp[i]; k < o.dpos[i]; k++ {
+			sum -= o.lu[k] * x[o.rj[k]]
+		}
+		x[i] = sum
+	}
+	for i := n - 1; i >= 0; i-- {
+		sum := x[i]
+		for k := o.dpos[i] + 1; k < o.rp[i+1]; k++ {
+			sum -= o.lu[k] * x[o.rj[k]]
+		}
+		x[i] = sum / o.lu[o.dpos[i]]
+	}
+}
+
+// ikj performs the IKJ variant of Gaussian elimination restricted to the pattern of the factors,
+// which must contain the pattern of A and the diagonal. It returns the row with pivot breakdown or -1
+func (o *iluFactors) ikj(n int, ap, aj []int, ax, d []float64, α float64) (brk int, pivot float64) {
+	iw := make([]int, n) // maps column index to position in current row
+	for i := 0; i < n; i++ {
+		iw[i] = -1
+	}
+	for i := 0; i < n; i++ {
+		for k := o.rp[i]; k < o.rp[i+1]; k++ {
+			iw[o.rj[k]] = k
+			o.lu[k] = 0
+		}
+		for k := ap[i]; k < ap[i+1]; k++ {
+			o.lu[iw[aj[k]]] += ax[k]
+		}
+		o.lu[o.dpos[i]] += α * d[i]
+		for k := o.rp[i]; k < o.dpos[i]; k++ {
+			j := o.rj[k]
+			o.lu[k] /= o.lu[o.dpos[j]]
+			for l := o.dpos[j] + 1; l < o.rp[j+1]; l++ {
+				if pos := iw[o.rj[l]]; pos >= 0 {
+					o.lu[pos] -= o.lu[k] * o.lu[l]
+				}
+			}
+		}
+		for k := o.rp[i]; k < o.rp[i+1]; k++ {
+			iw[o.rj[k]] = -1
+		}
+		pivot = o.lu[o.dpos[i]]
+		if math.Abs(pivot) <= incFactPivTol*d[i] || math.IsNaN(pivot) {
+			return i, pivot
+		}
+	}
+	return -1, 0
+}
+
+// run calls factor with increasing shifts until no pivot breakdown occurs
+func (o IncFactShift) run(name string, stats *IncFactStats, factor func(α float64) (brk int, pivot float64)) {
+	if o.ShiftIni <= 0 {
+		o.ShiftIni = 1e-3
+	}
+	if o.MaxShifts <= 0 {
+		o.MaxShifts = 20
+	}
+	stats.Breakdowns, stats.BreakRow, stats.Shift = 0, -1, 0
+	α := 0.0
+	for {
+		brk, pivot := factor(α)
+		if brk < 0 {
+			stats.Shift = α
+			return
+		}
+		stats.Breakdowns++
+		stats.BreakRow = brk
+		if o.NoShift || stats.Breakdowns > o.MaxShifts {
+			chk.Panic("%s factorisation failed due to pivot breakdown (%g) at row %d. shift = %g\n", name, pivot, brk, α)
+		}
+		if α == 0 {
+			α = o.ShiftIni
+		} else {
+			α *= 2
+		}
+	}
+}
+
+// set sets the number of non-zeros and the fill ratio
+func (o *IncFactStats) set(nnzA, nnzF int) {
+	o.NnzA, o.NnzF = nnzA, nnzF
+	o.Fill = float64(nnzF) / float64(utl.Imax(nnzA, 1))
+}
+
+// incFactDiag returns the scaling of the diagonal shift: dᵢ = |Aᵢᵢ| or max_j |Aᵢⱼ| if Aᵢᵢ = 0
+// (or 1 if row i is empty)
+func incFactDiag(n int, rp, rj []int, rx []float64) (d []float64) {
+	d = make([]float64, n)
+	for i := 0; i < n; i++ {
+		rowmax := 0.0
+		for k := rp[i]; k < rp[i+1]; k++ {
+			if rj[k] == i {
+				d[i] += rx[k]
+			}
+			rowmax = math.Max(rowmax, math.Abs(rx[k]))
+		}
+		d[i] = math.Abs(d[i])
+		if d[i] == 0 {
+			d[i] = rowmax
+		}
+		if d[i] == 0 {
+			d[i] = 1
+		}
+	}
+	return
+}
@@ -93,6 +93,20 @@ func (o *Triplet) PutCCMatAndMatT(a *CCMatrix) {
 	}
 }
 
+// PutMatAt adds the content of a triplet "a" to triplet "o" with the top-left corner of "a" at (i0,j0)
+// ex:    0   1   2   3
+//      [... ... ... ...] 0
+//      [... a00 a01 ...] 1  =>  o.PutMatAt(1, 1, a)
+//      [... a10 a11 ...] 2
+func (o *Triplet) PutMatAt(i0, j0 int, a *Triplet) {
+	if i0+a.m > o.m || j0+a.n > o.n {
+		chk.Panic("cannot put larger matrix into sparse matrix.\nb[%d:,%d:] := a with len(a)=(%d,%d) and len(b)=(%d,%d)", i0, j0, a.m, a.n, o.m, o.n)
+	}
+	for k := 0; k < a.pos; k++ {
+		o.Put(i0+a.i[k], j0+a.j[k], a.x[k])
+	}
+}
+
 // Start (re)starts index for inserting items using the Put command
 func (o *Triplet) Start() {
 	o.pos = 0
@@ -371,3 +385,75 @@ func (o *CCMatrixC) ToDense() (res *MatrixC) {
 	}
 	return
 }
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// tripletToCol converts the first nnz entries of a triplet (ti,tj,tx) to column-compressed form
+// (ap,ai,ax). Duplicates are summed; thus ap[n] (the actual number of non-zeros) may be smaller
+// than nnz. The row indices come out sorted because the conversion goes through row-form.
+func tripletToCol(m, n, nnz int, ti, tj []int, tx []float64, ap, ai []int, ax []float64) {
+
+	// row-form: count entries in each row
+	rp := make([]int, m+1)
+	for k := 0; k < nnz; k++ {
+		i, j := ti[k], tj[k]
+		if i < 0 || i >= m || j < 0 || j >= n {
+			chk.Panic("triplet has invalid index (%d,%d) for %d x %d matrix\n", i, j, m, n)
+		}
+		rp[i+1]++
+	}
+	for i := 0; i < m; i++ {
+		rp[i+1] += rp[i]
+	}
+
+	// row-form: scatter entries
+	rj := make([]int, nnz)
+	rx := make([]float64, nnz)
+	w := make([]int, m)
+	copy(w, rp[:m])
+	for k := 0; k < nnz; k++ {
+		p := w[ti[k]]
+		rj[p], rx[p] = tj[k], tx[k]
+		w[ti[k]]++
+	}
+
+	// row-form: sum duplicates and count entries in each column
+	last := make([]int, n) // position of the last entry in column j within the current row
+	for j := 0; j < n; j++ {
+		last[j] = -1
+	}
+	rnz := make([]int, m) // number of unique entries in each row
+	for j := 0; j <= n; j++ {
+		ap[j] = 0
+	}
+	for i := 0; i < m; i++ {
+		start := rp[i]
+		pdest := start
+		for p := rp[i]; p < rp[i+1]; p++ {
+			j := rj[p]
+			if last[j] >= start {
+				rx[last[j]] += rx[p]
+			} else {
+				last[j] = pdest
+				rj[pdest], rx[pdest] = j, rx[p]
+				pdest++
+				ap[j+1]++
+			}
+		}
+		rnz[i] = pdest - start
+	}
+	for j := 0; j < n; j++ {
+		ap[j+1] += ap[j]
+	}
+
+	// transpose row-form into column-form (row indices are sorted)
+	cw := make([]int, n)
+	copy(cw, ap[:n])
+	for i := 0; i < m; i++ {
+		for p := rp[i]; p < rp[i]+rnz[i]; p++ {
+			q := cw[rj[p]]
+			ai[q], ax[q] = i, rx[p]
+			cw[rj[p]]++
+		}
+	}
+}
@@ -0,0 +1,789 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Ordering computes a symmetric permutation of the rows and columns of a square sparse matrix in
+// order to reduce its bandwidth or the fill-in of its factorisation. Only the sparsity pattern of
+// A + Aᵀ (without the diagonal) is considered; thus, values and duplicates are irrelevant.
+//
+//  kind -- "rcm" : reverse Cuthill-McKee (bandwidth and profile reduction)
+//          "amd" : approximate minimum degree (fill-in reduction)
+//          "nd"  : nested dissection (fill-in reduction). METIS is used to compute the
+//                  bisections when available; otherwise, level structures are used.
+//
+//  perm -- permutation vector: row/column k of the reordered matrix B corresponds to row/column
+//          perm[k] of the original matrix A; i.e.  B = P⋅A⋅Pᵀ  with  B[k,l] = A[perm[k],perm[l]]
+//
+func (o *Triplet) Ordering(kind string) (perm []int) {
+	if o.m != o.n {
+		chk.Panic("ordering requires a square matrix. %d x %d is invalid\n", o.m, o.n)
+	}
+	return newSpGraph(o.n, o.pos, o.i, o.j).ordering(kind)
+}
+
+// Ordering computes a symmetric permutation of the rows and columns of a square sparse matrix
+// (see Triplet.Ordering)
+func (o *CCMatrix) Ordering(kind string) (perm []int) {
+	if o.m != o.n {
+		chk.Panic("ordering requires a square matrix. %d x %d is invalid\n", o.m, o.n)
+	}
+	ii, jj := o.indices()
+	return newSpGraph(o.n, len(ii), ii, jj).ordering(kind)
+}
+
+// Permute returns a new triplet with the rows and columns permuted symmetrically
+//
+//   B = P⋅A⋅Pᵀ   with   B[k,l] = A[perm[k],perm[l]]
+//
+func (o *Triplet) Permute(perm []int) (b *Triplet) {
+	iperm := spCheckPerm(o.m, o.n, perm)
+	b = NewTriplet(o.m, o.n, o.pos)
+	for k := 0; k < o.pos; k++ {
+		b.Put(iperm[o.i[k]], iperm[o.j[k]], o.x[k])
+	}
+	return
+}
+
+// Permute returns a new column-compressed matrix with the rows and columns permuted symmetrically
+//
+//   B = P⋅A⋅Pᵀ   with   B[k,l] = A[perm[k],perm[l]]
+//
+//  NOTE: the row indices in each column of B are sorted
+//
+func (o *CCMatrix) Permute(perm []int) (b *CCMatrix) {
+	iperm := spCheckPerm(o.m, o.n, perm)
+	ii, jj := o.indices()
+	for k := range ii {
+		ii[k], jj[k] = iperm[ii[k]], iperm[jj[k]]
+	}
+	nnz := len(ii)
+	b = &CCMatrix{m: o.m, n: o.n, nnz: nnz, p: make([]int, o.n+1), i: make([]int, nnz), x: make([]float64, nnz)}
+	tripletToCol(o.m, o.n, nnz, ii, jj, o.x[:nnz], b.p, b.i, b.x)
+	return
+}
+
+// Bandwidth computes the bandwidth and the profile of the (symmetrically permuted) matrix
+//
+//   B = P⋅A⋅Pᵀ   with   B[k,l] = A[perm[k],perm[l]]
+//
+//  perm -- permutation vector; use nil for the identity (i.e. to analyse A)
+//
+//  bandwidth -- max |k - l| for all non-zero B[k,l]
+//  profile   -- Σ_k (k - f_k) where f_k is the column of the first non-zero in row k of the
+//               pattern of B + Bᵀ (or k if there are no entries to the left of the diagonal)
+//
+func (o *Triplet) Bandwidth(perm []int) (bandwidth, profile int) {
+	return spBandwidth(o.m, o.n, o.pos, o.i, o.j, perm)
+}
+
+// Bandwidth computes the bandwidth and the profile of the (symmetrically permuted) matrix
+// (see Triplet.Bandwidth)
+func (o *CCMatrix) Bandwidth(perm []int) (bandwidth, profile int) {
+	ii, jj := o.indices()
+	return spBandwidth(o.m, o.n, len(ii), ii, jj, perm)
+}
+
+// PermInv returns the inverse of a permutation vector; i.e. iperm[perm[k]] = k
+func PermInv(perm []int) (iperm []int) {
+	iperm = make([]int, len(perm))
+	for k, p := range perm {
+		iperm[p] = k
+	}
+	return
+}
+
+// VecPermute applies a permutation to a vector: res[k] = v[perm[k]]  (i.e. res = P⋅v)
+func VecPermute(res Vector, perm []int, v Vector) {
+	for k, p := range perm {
+		res[k] = v[p]
+	}
+}
+
+// VecPermuteInv applies the inverse of a permutation to a vector: res[perm[k]] = v[k]
+// (i.e. res = Pᵀ⋅v)
+func VecPermuteInv(res Vector, perm []int, v Vector) {
+	for k, p := range perm {
+		res[p] = v[k]
+	}
+}
+
+// graph of matrix //////////////////////////////////////////////////////////////////////////////////
+
+// spGraph holds the adjacency structure of the pattern of A + Aᵀ without the diagonal
+type spGraph struct {
+	n    int   // number of vertices (rows/columns)
+	xadj []int // pointers to the adjacency lists (size = n+1)
+	adj  []int // adjacency lists (sorted and without duplicates)
+}
+
+// ndBisector computes a two-way partition (parts[v] = 0 or 1) of a graph for nested dissection.
+// It is set when METIS is available; otherwise level structures are employed.
+var ndBisector func(n int, xadj, adj []int) (parts []int)
+
+// ndLeafSize is the maximum number of vertices of subgraphs that are not dissected any further
+// (they are ordered with AMD instead)
+var ndLeafSize = 64
+
+// newSpGraph builds the graph of A + Aᵀ from the (i,j) indices of the first nnz entries
+func newSpGraph(n, nnz int, ii, jj []int) (o *spGraph) {
+
+	// count
+	o = &spGraph{n: n, xadj: make([]int, n+1)}
+	for k := 0; k < nnz; k++ {
+		i, j := ii[k], jj[k]
+		if i < 0 || i >= n || j < 0 || j >= n {
+			chk.Panic("matrix has invalid index (%d,%d) for %d x %d matrix\n", i, j, n, n)
+		}
+		if i != j {
+			o.xadj[i+1]++
+			o.xadj[j+1]++
+		}
+	}
+	for i := 0; i < n; i++ {
+		o.xadj[i+1] += o.xadj[i]
+	}
+
+	// fill
+	adj := make([]int, o.xadj[n])
+	w := make([]int, n)
+	copy(w, o.xadj[:n])
+	for k := 0; k < nnz; k++ {
+		i, j := ii[k], jj[k]
+		if i != j {
+			adj[w[i]] = j
+			w[i]++
+			adj[w[j]] = i
+			w[j]++
+		}
+	}
+
+	// sort and remove duplicates
+	o.adj = adj[:0]
+	start := 0
+	for i := 0; i < n; i++ {
+		list := adj[start:o.xadj[i+1]]
+		start = o.xadj[i+1]
+		sort.Ints(list)
+		o.xadj[i] = len(o.adj)
+		for k, j := range list {
+			if k == 0 || j != list[k-1] {
+				o.adj = append(o.adj, j)
+			}
+		}
+	}
+	o.xadj[n] = len(o.adj)
+	return
+}
+
+// ordering computes the permutation of the given kind
+func (o *spGraph) ordering(kind string) (perm []int) {
+	switch kind {
+	case "rcm":
+		return o.rcm()
+	case "amd":
+		return o.amd()
+	case "nd":
+		return o.nd()
+	}
+	chk.Panic("ordering %q is not available. options are \"rcm\", \"amd\" or \"nd\"\n", kind)
+	return
+}
+
+// degree returns the number of neighbours of v with label[u] == id
+func (o *spGraph) degree(v int, label []int, id int) (deg int) {
+	for _, u := range o.adj[o.xadj[v]:o.xadj[v+1]] {
+		if label[u] == id {
+			deg++
+		}
+	}
+	return
+}
+
+// levels computes the rooted level structure of the connected component containing root and
+// restricted to the vertices with label[v] == id. It returns the vertices in breadth-first order and
+// the start of each level in this list (i.e. level k = verts[xlev[k]:xlev[k+1]]).
+//  NOTE: dist is a workspace (size = n) which must be filled with -1 and is left untouched
+func (o *spGraph) levels(root int, label []int, id int, dist []int) (verts, xlev []int) {
+	verts = append(verts, root)
+	dist[root] = 0
+	for head := 0; head < len(verts); head++ {
+		v := verts[head]
+		for _, u := range o.adj[o.xadj[v]:o.xadj[v+1]] {
+			if label[u] == id && dist[u] < 0 {
+				dist[u] = dist[v] + 1
+				verts = append(verts, u)
+			}
+		}
+	}
+	xlev = append(xlev, 0)
+	for k := 1; k < len(verts); k++ {
+		if dist[verts[k]] != dist[verts[k-1]] {
+			xlev = append(xlev, k)
+		}
+	}
+	xlev = append(xlev, len(verts))
+	for _, v := range verts {
+		dist[v] = -1
+	}
+	return
+}
+
+// peripheral finds a pseudo-peripheral vertex using the algorithm of George and Liu (1979): the
+// search is restarted from a vertex with minimum degree in the last level as long as the number
+// of levels increases. The level structure rooted at this vertex is also returned.
+func (o *spGraph) peripheral(start int, label []int, id int, dist []int) (root int, verts, xlev []int) {
+	root = start
+	verts, xlev = o.levels(root, label, id, dist)
+	for {
+		nlev := len(xlev) - 1
+		best, mindeg := -1, 0
+		for _, v := range verts[xlev[nlev-1]:] {
+			if deg := o.degree(v, label, id); best < 0 || deg < mindeg {
+				best, mindeg = v, deg
+			}
+		}
+		v, x := o.levels(best, label, id, dist)
+		if len(x) <= len(xlev) {
+			return
+		}
+		root, verts, xlev = best, v, x
+	}
+}
+
+// reverse Cuthill-McKee ////////////////////////////////////////////////////////////////////////////
+
+// rcm computes the reverse Cuthill-McKee ordering. Each connected component is numbered from a
+// pseudo-peripheral vertex by visiting the neighbours in increasing order of degree.
+func (o *spGraph) rcm() (perm []int) {
+	label := make([]int, o.n)
+	dist := make([]int, o.n)
+	deg := make([]int, o.n)
+	for v := 0; v < o.n; v++ {
+		dist[v] = -1
+		deg[v] = o.xadj[v+1] - o.xadj[v]
+	}
+	done := make([]bool, o.n)
+	perm = make([]int, 0, o.n)
+	var nbrs []int
+	for start := 0; start < o.n; start++ {
+		if done[start] {
+			continue
+		}
+		root, _, _ := o.peripheral(start, label, 0, dist)
+		head := len(perm)
+		perm = append(perm, root)
+		done[root] = true
+		for ; head < len(perm); head++ {
+			v := perm[head]
+			nbrs = nbrs[:0]
+			for _, u := range o.adj[o.xadj[v]:o.xadj[v+1]] {
+				if !done[u] {
+					done[u] = true
+					nbrs = append(nbrs, u)
+				}
+			}
+			sort.SliceStable(nbrs, func(a, b int) bool { return deg[nbrs[a]] < deg[nbrs[b]] })
+			perm = append(perm, nbrs...)
+		}
+	}
+	for i, j := 0, len(perm)-1; i < j; i, j = i+1, j-1 {
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	return
+}
+
+// approximate minimum degree ///////////////////////////////////////////////////////////////////////
+
+// amd computes the approximate minimum degree ordering of Amestoy, Davis and Duff (1996). The
+// elimination is simulated on the quotient graph, where each variable i is adjacent to variables
+// A[i] and elements E[i] (eliminated pivots), and each element e holds the variables L[e] of the
+// corresponding column of the Cholesky factor. The external degrees are approximated by
+//
+//   dᵢ = min( n_left - |i| ,  dᵢ_old + |Lp \ i| ,  |Aᵢ \ i| + |Lp \ i| + Σ_{e ∈ Eᵢ \ p} |Le \ Lp| )
+//
+// Indistinguishable variables are merged into supervariables, elements that become subsets of the
+// new element are absorbed (aggressive absorption) and dense rows are ordered last.
+func (o *spGraph) amd() (perm []int) {
+
+	// constants
+	const (
+		variable = iota
+		element
+		dead // absorbed element, merged variable or dense row
+	)
+	n := o.n
+	perm = make([]int, 0, n)
+	if n == 0 {
+		return
+	}
+	dense := utl.Imax(16, int(10*math.Sqrt(float64(n))))
+
+	// quotient graph
+	status := make([]int, n)
+	nv := make([]int, n)        // weights of supervariables
+	members := make([][]int, n) // variables merged into supervariables
+	A := make([][]int, n)
+	E := make([][]int, n)
+	L := make([][]int, n)
+	deg := make([]int, n)
+	var denseRows []int
+	nleft := n
+	for i := 0; i < n; i++ {
+		nv[i] = 1
+		members[i] = []int{i}
+		deg[i] = o.xadj[i+1] - o.xadj[i]
+		if deg[i] > dense {
+			status[i] = dead
+			denseRows = append(denseRows, i)
+			nleft--
+		}
+	}
+	for i := 0; i < n; i++ {
+		if status[i] == variable {
+			A[i] = make([]int, 0, deg[i])
+			for _, j := range o.adj[o.xadj[i]:o.xadj[i+1]] {
+				if status[j] == variable {
+					A[i] = append(A[i], j)
+				}
+			}
+			deg[i] = len(A[i])
+		}
+	}
+
+	// degree lists
+	head := make([]int, n+1)
+	next := make([]int, n)
+	prev := make([]int, n)
+	for d := 0; d <= n; d++ {
+		head[d] = -1
+	}
+	insert := func(i int) {
+		d := deg[i]
+		prev[i], next[i] = -1, head[d]
+		if head[d] >= 0 {
+			prev[head[d]] = i
+		}
+		head[d] = i
+	}
+	remove := func(i int) {
+		if prev[i] >= 0 {
+			next[prev[i]] = next[i]
+		} else {
+			head[deg[i]] = next[i]
+		}
+		if next[i] >= 0 {
+			prev[next[i]] = prev[i]
+		}
+	}
+	mindeg := n
+	for i := 0; i < n; i++ {
+		if status[i] == variable {
+			insert(i)
+			mindeg = utl.Imin(mindeg, deg[i])
+		}
+	}
+
+	// workspaces
+	mark := make([]int, n) // mark[i] == tag ⇒ i ∈ Lp
+	wtag := make([]int, n) // wtag[e] == tag ⇒ w[e] = |Le \ Lp| has been computed
+	w := make([]int, n)
+	hash := make(map[int][]int)
+	tag := 0
+
+	// eliminate
+	for nleft > 0 {
+
+		// pivot with minimum approximate degree
+		for head[mindeg] < 0 {
+			mindeg++
+		}
+		p := head[mindeg]
+		remove(p)
+		tag++
+
+		// new element: Lp = (Ap ∪ ⋃ Le) \ p  for e ∈ Ep
+		mark[p] = tag
+		var Lp []int
+		for _, e := range E[p] {
+			if status[e] != element {
+				continue
+			}
+			for _, i := range L[e] {
+				if status[i] == variable && mark[i] != tag {
+					mark[i] = tag
+					Lp = append(Lp, i)
+				}
+			}
+			status[e] = dead // absorbed into p
+			L[e] = nil
+		}
+		for _, i := range A[p] {
+			if status[i] == variable && mark[i] != tag {
+				mark[i] = tag
+				Lp = append(Lp, i)
+			}
+		}
+		status[p] = element
+		A[p], E[p] = nil, nil
+		perm = append(perm, members[p]...)
+		nleft -= nv[p]
+
+		// |Le \ Lp| for all elements adjacent to the variables in Lp
+		for _, i := range Lp {
+			remove(i)
+			for _, e := range E[i] {
+				if status[e] != element {
+					continue
+				}
+				if wtag[e] != tag {
+					wtag[e] = tag
+					w[e] = 0
+					for _, k := range L[e] {
+						if status[k] == variable {
+							w[e] += nv[k]
+						}
+					}
+				}
+				w[e] -= nv[i]
+			}
+		}
+
+		// prune lists, absorb elements and eliminate variables adjacent to p only (mass elimination)
+		degme := 0
+		live := Lp[:0]
+		for _, i := range Lp {
+			newE := []int{p}
+			for _, e := range E[i] {
+				if status[e] != element {
+					continue
+				}
+				if w[e] == 0 { // Le ⊆ Lp
+					status[e] = dead
+					L[e] = nil
+					continue
+				}
+				newE = append(newE, e)
+			}
+			newA := A[i][:0]
+			for _, j := range A[i] {
+				if status[j] == variable && mark[j] != tag {
+					newA = append(newA, j)
+				}
+			}
+			A[i], E[i] = newA, newE
+			if len(newA) == 0 && len(newE) == 1 {
+				status[i] = dead
+				perm = append(perm, members[i]...)
+				nleft -= nv[i]
+				continue
+			}
+			live = append(live, i)
+			degme += nv[i]
+		}
+		Lp = live
+		L[p] = Lp
+
+		// detect indistinguishable variables: same Aᵢ and Eᵢ
+		for k := range hash {
+			delete(hash, k)
+		}
+		for _, i := range Lp {
+			h := 0
+			for _, j := range A[i] {
+				h += j
+			}
+			for _, e := range E[i] {
+				h += e
+			}
+			hash[h] = append(hash[h], i)
+		}
+		for _, bucket := range hash {
+			for a := 0; a < len(bucket); a++ {
+				i := bucket[a]
+				if status[i] != variable {
+					continue
+				}
+				for b := a + 1; b < len(bucket); b++ {
+					j := bucket[b]
+					if status[j] == variable && amdSameSet(A[i], A[j]) && amdSameSet(E[i], E[j]) {
+						nv[i] += nv[j]
+						members[i] = append(members[i], members[j]...)
+						status[j], nv[j], members[j] = dead, 0, nil
+						A[j], E[j] = nil, nil
+					}
+				}
+			}
+		}
+
+		// approximate degrees
+		live = Lp[:0]
+		for _, i := range Lp {
+			if status[i] != variable {
+				continue
+			}
+			live = append(live, i)
+			ext := 0
+			for _, j := range A[i] {
+				if status[j] == variable {
+					ext += nv[j]
+				}
+			}
+			for _, e := range E[i][1:] {
+				if status[e] == element {
+					ext += w[e]
+				}
+			}
+			d := utl.Imin(nleft-nv[i], deg[i]+degme-nv[i])
+			d = utl.Imin(d, ext+degme-nv[i])
+			deg[i] = utl.Imax(d, 0)
+			insert(i)
+			mindeg = utl.Imin(mindeg, deg[i])
+		}
+		L[p] = live
+	}
+
+	// dense rows are ordered last
+	perm = append(perm, denseRows...)
+	return
+}
+
+// amdSameSet tells whether two lists with distinct items hold the same set
+func amdSameSet(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[int]bool, len(a))
+	for _, k := range a {
+		set[k] = true
+	}
+	for _, k := range b {
+		if !set[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// nested dissection ////////////////////////////////////////////////////////////////////////////////
+
+// nd computes the nested dissection ordering: the graph is recursively split by vertex separators,
+// which are numbered after the two parts they separate. Small subgraphs are ordered with AMD.
+func (o *spGraph) nd() (perm []int) {
+	label := make([]int, o.n) // each subgraph being dissected has a unique label
+	dist := make([]int, o.n)
+	loc := make([]int, o.n)
+	for v := 0; v < o.n; v++ {
+		dist[v], loc[v] = -1, -1
+	}
+	verts := make([]int, o.n)
+	for v := 0; v < o.n; v++ {
+		verts[v] = v
+	}
+	perm = make([]int, 0, o.n)
+	nextID := 0
+	var dissect func(verts []int, id int)
+	dissect = func(verts []int, id int) {
+		if len(verts) <= ndLeafSize {
+			perm = append(perm, o.leafAMD(verts, label, id, loc)...)
+			return
+		}
+		sep, a, b := o.bisect(verts, label, id, dist, loc)
+		if len(a) == 0 || len(b) == 0 {
+			perm = append(perm, o.leafAMD(verts, label, id, loc)...)
+			return
+		}
+		for _, v := range sep {
+			label[v] = -1
+		}
+		ida, idb := nextID+1, nextID+2
+		nextID += 2
+		for _, v := range a {
+			label[v] = ida
+		}
+		for _, v := range b {
+			label[v] = idb
+		}
+		dissect(a, ida)
+		dissect(b, idb)
+		perm = append(perm, sep...)
+	}
+	dissect(verts, 0)
+	return
+}
+
+// subgraph extracts the subgraph with vertices verts (which must have label == id). The local
+// index of vertex verts[k] is k.
+//  NOTE: loc is a workspace (size = n) which must be filled with -1 and is left untouched
+func (o *spGraph) subgraph(verts, label []int, id int, loc []int) (sub *spGraph) {
+	for k, v := range verts {
+		loc[v] = k
+	}
+	sub = &spGraph{n: len(verts), xadj: make([]int, len(verts)+1)}
+	for k, v := range verts {
+		for _, u := range o.adj[o.xadj[v]:o.xadj[v+1]] {
+			if label[u] == id {
+				sub.adj = append(sub.adj, loc[u])
+			}
+		}
+		sub.xadj[k+1] = len(sub.adj)
+	}
+	for _, v := range verts {
+		loc[v] = -1
+	}
+	return
+}
+
+// leafAMD orders a subgraph using AMD
+func (o *spGraph) leafAMD(verts, label []int, id int, loc []int) (perm []int) {
+	perm = o.subgraph(verts, label, id, loc).amd()
+	for k, l := range perm {
+		perm[k] = verts[l]
+	}
+	return
+}
+
+// bisect splits a subgraph into the parts a and b and the separator sep such that there are no
+// edges between a and b. Disconnected subgraphs are split without separator.
+func (o *spGraph) bisect(verts, label []int, id int, dist, loc []int) (sep, a, b []int) {
+
+	// disconnected subgraph
+	comp, _ := o.levels(verts[0], label, id, dist)
+	if len(comp) < len(verts) {
+		for _, v := range comp {
+			dist[v] = 0
+		}
+		for _, v := range verts {
+			if dist[v] == 0 {
+				a = append(a, v)
+			} else {
+				b = append(b, v)
+			}
+		}
+		for _, v := range comp {
+			dist[v] = -1
+		}
+		return
+	}
+
+	// METIS: edge separator ⇒ vertex separator = smallest boundary
+	if ndBisector != nil {
+		sub := o.subgraph(verts, label, id, loc)
+		parts := ndBisector(sub.n, sub.xadj, sub.adj)
+		var bnd [2][]int
+		for k := 0; k < sub.n; k++ {
+			for _, l := range sub.adj[sub.xadj[k]:sub.xadj[k+1]] {
+				if parts[l] != parts[k] {
+					bnd[parts[k]] = append(bnd[parts[k]], k)
+					break
+				}
+			}
+		}
+		s := 0
+		if len(bnd[1]) < len(bnd[0]) {
+			s = 1
+		}
+		for _, k := range bnd[s] {
+			parts[k] = 2
+		}
+		for k, v := range verts {
+			switch parts[k] {
+			case 0:
+				a = append(a, v)
+			case 1:
+				b = append(b, v)
+			default:
+				sep = append(sep, v)
+			}
+		}
+		return
+	}
+
+	// level structure: separator = middle level
+	_, lverts, xlev := o.peripheral(verts[0], label, id, dist)
+	nlev := len(xlev) - 1
+	if nlev < 3 {
+		return nil, verts, nil
+	}
+	mid := 1
+	for mid < nlev-2 && xlev[mid+1] < len(lverts)/2 {
+		mid++
+	}
+	a = lverts[:xlev[mid]]
+	sep = lverts[xlev[mid]:xlev[mid+1]]
+	b = lverts[xlev[mid+1]:]
+	return
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// indices returns the (i,j) indices of the non-zero entries of a column-compressed matrix
+func (o *CCMatrix) indices() (ii, jj []int) {
+	nnz := o.p[o.n]
+	ii, jj = make([]int, nnz), make([]int, nnz)
+	for j := 0; j < o.n; j++ {
+		for k := o.p[j]; k < o.p[j+1]; k++ {
+			ii[k], jj[k] = o.i[k], j
+		}
+	}
+	return
+}
+
+// spCheckPerm checks a permutation vector for a square matrix and returns its inverse
+func spCheckPerm(m, n int, perm []int) (iperm []int) {
+	if m != n {
+		chk.Panic("symmetric permutation requires a square matrix. %d x %d is invalid\n", m, n)
+	}
+	if len(perm) != n {
+		chk.Panic("permutation vector must have length equal to %d. %d is invalid\n", n, len(perm))
+	}
+	iperm = make([]int, n)
+	for k := range iperm {
+		iperm[k] = -1
+	}
+	for k, p := range perm {
+		if p < 0 || p >= n || iperm[p] >= 0 {
+			chk.Panic("invalid permutation vector. perm[%d] = %d\n", k, p)
+		}
+		iperm[p] = k
+	}
+	return
+}
+
+// spBandwidth computes the bandwidth and profile of P⋅A⋅Pᵀ
+func spBandwidth(m, n, nnz int, ii, jj []int, perm []int) (bandwidth, profile int) {
+	var iperm []int
+	if perm != nil {
+		iperm = spCheckPerm(m, n, perm)
+	}
+	first := make([]int, m)
+	for i := 0; i < m; i++ {
+		first[i] = i
+	}
+	for k := 0; k < nnz; k++ {
+		i, j := ii[k], jj[k]
+		if iperm != nil {
+			i, j = iperm[i], iperm[j]
+		}
+		if i < j {
+			i, j = j, i
+		}
+		bandwidth = utl.Imax(bandwidth, i-j)
+		if i < m && j < first[i] {
+			first[i] = j
+		}
+	}
+	for i := 0; i < m; i++ {
+		profile += i - first[i]
+	}
+	return
+}
@@ -0,0 +1,31 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !windows,!darwin,cgo,!purego
+
+package la
+
+import "github.com/cpmech/gosl/graph"
+
+func init() {
+	ndBisector = func(n int, xadj, adj []int) (parts []int) {
+		parts = make([]int, n)
+		if n < 2 || len(adj) == 0 {
+			return
+		}
+		xa := make([]int32, len(xadj))
+		for k, v := range xadj {
+			xa[k] = int32(v)
+		}
+		ad := make([]int32, len(adj))
+		for k, v := range adj {
+			ad[k] = int32(v)
+		}
+		_, p := graph.MetisPartition(2, n, xa, ad, true)
+		for k, v := range p {
+			parts[k] = int(v)
+		}
+		return
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import "github.com/cpmech/gosl/chk"
+
+// Preconditioner defines an approximation M of a sparse matrix A whose inverse is cheap to
+// apply. It is used to accelerate the convergence of iterative (Krylov) solvers.
+//
+//   Given:  M ≈ A    compute   z = M⁻¹ ⋅ r
+//
+type Preconditioner interface {
+	Init(a *CCMatrix)  // initialises (or re-initialises) the preconditioner with the values of "a"
+	Apply(z, r Vector) // applies the preconditioner: z := M⁻¹ ⋅ r
+}
+
+// precondMaker defines a function that makes preconditioners
+type precondMaker func() Preconditioner
+
+// precondDB implements a database of Preconditioner makers
+var precondDB = make(map[string]precondMaker)
+
+// NewPreconditioner finds a Preconditioner in database or panic
+//   kind -- "jacobi", "ssor", "ilu0", "ic0", "ilut" or "amg" (with default settings)
+func NewPreconditioner(kind string) Preconditioner {
+	if maker, ok := precondDB[kind]; ok {
+		return maker()
+	}
+	chk.Panic("cannot find Preconditioner named %q in database", kind)
+	return nil
+}
+
+// PrecOp implements a Preconditioner given by a linear operator approximating A⁻¹; e.g. a
+// block-diagonal operator for saddle-point problems
+//
+//   z := Op ⋅ r
+//
+type PrecOp struct {
+	Op LinearOperator // operator approximating A⁻¹
+}
+
+// Init does nothing since the operator is given (a may be nil)
+func (o *PrecOp) Init(a *CCMatrix) {}
+
+// Apply applies the preconditioner: z := Op ⋅ r
+func (o *PrecOp) Apply(z, r Vector) {
+	o.Op.Apply(z, r)
+}
+
+// Jacobi ///////////////////////////////////////////////////////////////////////////////////////////
+
+// PrecJacobi implements the Jacobi (diagonal) preconditioner
+//
+//   M = diag(A)
+//
+type PrecJacobi struct {
+	invD Vector // inverse of diagonal values
+}
+
+// Init initialises the preconditioner
+func (o *PrecJacobi) Init(a *CCMatrix) {
+	o.invD = NewVector(a.n)
+	for j := 0; j < a.n; j++ {
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			if a.i[k] == j {
+				o.invD[j] += a.x[k]
+			}
+		}
+	}
+	for i := 0; i < a.n; i++ {
+		if o.invD[i] == 0 {
+			chk.Panic("Jacobi preconditioner requires non-zero diagonal values. A[%d,%d] == 0\n", i, i)
+		}
+		o.invD[i] = 1.0 / o.invD[i]
+	}
+}
+
+// Apply applies the preconditioner: z := M⁻¹ ⋅ r
+func (o *PrecJacobi) Apply(z, r Vector) {
+	for i := 0; i < len(r); i++ {
+		z[i] = o.invD[i] * r[i]
+	}
+}
+
+// SSOR /////////////////////////////////////////////////////////////////////////////////////////////
+
+// PrecSsor implements the Symmetric Successive Over-Relaxation preconditioner
+//
+//          ω      / D     \   / D \⁻¹  / D     \
+//   M = ——————— ⋅ | — + L | ⋅ | — |  ⋅ | — + U |
+//        2 - ω    \ ω     /   \ ω /    \ ω     /
+//
+//   where A = L + D + U and 0 < ω < 2. ω = 1 corresponds to symmetric Gauss-Seidel
+type PrecSsor struct {
+	Omega float64 // relaxation factor ω. if zero, ω = 1 is used
+
+	// row-compressed data
+	rp, rj []int     // row pointers and column indices
+	rx     []float64 // values
+	diag   []float64 // diagonal values
+}
+
+// Init initialises the preconditioner
+func (o *PrecSsor) Init(a *CCMatrix) {
+	if o.Omega == 0 {
+		o.Omega = 1
+	}
+	if o.Omega <= 0 || o.Omega >= 2 {
+		chk.Panic("SSOR relaxation factor must be in (0, 2). ω = %g is invalid\n", o.Omega)
+	}
+	o.rp, o.rj, o.rx = spRowCompress(a)
+	o.diag = spRowDiag(a.n, o.rp, o.rj, o.rx)
+}
+
+// Apply applies the preconditioner: z := M⁻¹ ⋅ r
+func (o *PrecSsor) Apply(z, r Vector) {
+	n := len(r)
+	ω := o.Omega
+
+	// forward sweep: (D/ω + L) ⋅ y = r
+	for i := 0; i < n; i++ {
+		sum := r[i]
+		for k := o.rp[i]; k < o.rp[i+1]; k++ {
+			if o.rj[k] < i {
+				sum -= o.rx[k] * z[o.rj[k]]
+			}
+		}
+		z[i] = sum * ω / o.diag[i]
+	}
+
+	// scaling: y := (D/ω) ⋅ y
+	for i := 0; i < n; i++ {
+		z[i] *= o.diag[i] / ω
+	}
+
+	// backward sweep: (D/ω + U) ⋅ z = y
+	for i := n - 1; i >= 0; i-- {
+		sum := z[i]
+		for k := o.rp[i]; k < o.rp[i+1]; k++ {
+			if o.rj[k] > i {
+				sum -= o.rx[k] * z[o.rj[k]]
+			}
+		}
+		z[i] = sum * ω / o.diag[i]
+	}
+
+	// scaling: z := (2-ω)/ω ⋅ z
+	c := (2.0 - ω) / ω
+	for i := 0; i < n; i++ {
+		z[i] *= c
+	}
+}
+
+// ILU(0) ///////////////////////////////////////////////////////////////////////////////////////////
+
+// PrecIlu0 implements the incomplete LU factorisation with zero fill-in
+//
+//   M = L ⋅ U   with   pattern(L + U) = pattern(A) ∪ diagonal
+//
+//   NOTE: this is IluK with Level = 0; see IluK for the settings and diagnostics
+type PrecIlu0 struct {
+	IluK
+}
+
+// IC(0) ////////////////////////////////////////////////////////////////////////////////////////////
+
+// PrecIc0 implements the incomplete Cholesky factorisation with zero fill-in
+//
+//   M = L ⋅ Lᵀ   with   pattern(L) = pattern(lower(A)) ∪ diagonal
+//
+//   NOTE: A must be symmetric positive-definite; see Ic0 for the settings and diagnostics
+type PrecIc0 struct {
+	Ic0
+}
+
+// auxiliary ////////////////////////////////////////////////////////////////////////////////////////
+
+// spRowCompress returns the row-compressed representation of a column-compressed matrix.
+// The column indices within each row are sorted in increasing order.
+func spRowCompress(a *CCMatrix) (rp, rj []int, rx []float64) {
+	nnz := a.p[a.n]
+	rp = make([]int, a.m+1)
+	rj = make([]int, nnz)
+	rx = make([]float64, nnz)
+	for k := 0; k < nnz; k++ {
+		rp[a.i[k]+1]++
+	}
+	for i := 0; i < a.m; i++ {
+		rp[i+1] += rp[i]
+	}
+	next := make([]int, a.m)
+	copy(next, rp[:a.m])
+	for j := 0; j < a.n; j++ {
+		for k := a.p[j]; k < a.p[j+1]; k++ {
+			pos := next[a.i[k]]
+			rj[pos], rx[pos] = j, a.x[k]
+			next[a.i[k]]++
+		}
+	}
+	return
+}
+
+// spRowDiag returns the diagonal of a row-compressed matrix; it panics if any diagonal is zero
+func spRowDiag(n int, rp, rj []int, rx []float64) (diag []float64) {
+	diag = make([]float64, n)
+	for i := 0; i < n; i++ {
+		for k := rp[i]; k < rp[i+1]; k++ {
+			if rj[k] == i {
+				diag[i] += rx[k]
+			}
+		}
+		if diag[i] == 0 {
+			chk.Panic("preconditioner requires non-zero diagonal values. A[%d,%d] == 0\n", i, i)
+		}
+	}
+	return
+}
+
+// add preconditioners to database /////////////////////////////////////////////////////////////////
+
+func init() {
+	precondDB["jacobi"] = func() Preconditioner { return new(PrecJacobi) }
+	precondDB["ssor"] = func() Preconditioner { return new(PrecSsor) }
+	precondDB["ilu0"] = func() Preconditioner { return new(PrecIlu0) }
+	precondDB["ic0"] = func() Preconditioner { return new(PrecIc0) }
+	precondDB["ilut"] = func() Preconditioner { return new(Ilut) }
+}
@@ -5,6 +5,8 @@
 package la
 
 import (
+	"math"
+
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/mpi"
 )
@@ -39,6 +41,38 @@ func NewSparseSolver(kind string) SparseSolver {
 	return nil
 }
 
+// SparseSolverDirect extends SparseSolver with the operations available in the direct solvers
+// (UMFPACK and MUMPS). Use a type assertion or NewSparseSolverDirect to access them
+//
+//   RefactorNumeric -- performs the numeric factorisation only, reusing the symbolic analysis
+//                      of the previous Fact. The values of the triplet may change but the
+//                      sparsity pattern must not (e.g. Jacobian matrices in Newton iterations)
+//   SolveMany       -- solves A ⋅ X = B for many right-hand sides (columns of B)
+//   SolveTranspose  -- solves Aᵀ ⋅ x = b (e.g. for adjoint sensitivities)
+//   Determinant     -- returns det(A) = mantissa ⋅ 10^exponent (to avoid overflow)
+//   Inertia         -- returns the number of negative, zero and positive eigenvalues of a
+//                      symmetric matrix
+//
+type SparseSolverDirect interface {
+	SparseSolver
+	RefactorNumeric()
+	SolveMany(X, B *Matrix, bIsDistr bool)
+	SolveTranspose(x, b Vector, bIsDistr bool)
+	Determinant() (mantissa, exponent float64)
+	Inertia() (nneg, nzero, npos int)
+}
+
+// NewSparseSolverDirect finds a direct SparseSolver in database or panic
+//   kind -- "umfpack" or "mumps"
+//   NOTE: remember to call Free() to release allocated resources
+func NewSparseSolverDirect(kind string) SparseSolverDirect {
+	if s, ok := NewSparseSolver(kind).(SparseSolverDirect); ok {
+		return s
+	}
+	chk.Panic("SparseSolver named %q is not a direct solver", kind)
+	return nil
+}
+
 // complex /////////////////////////////////////////////////////////////////////////////////////////
 
 // SparseSolverC solves sparse linear systems using UMFPACK or MUMPS (complex version)
@@ -68,6 +102,28 @@ func NewSparseSolverC(kind string) SparseSolverC {
 	return nil
 }
 
+// SparseSolverDirectC extends SparseSolverC with the operations available in the direct solvers
+// (complex version; see SparseSolverDirect)
+//
+//   SolveTranspose  -- solves Aᵀ ⋅ x = b (not the conjugate transpose)
+//
+type SparseSolverDirectC interface {
+	SparseSolverC
+	RefactorNumeric()
+	SolveMany(X, B *MatrixC, bIsDistr bool)
+	SolveTranspose(x, b VectorC, bIsDistr bool)
+}
+
+// NewSparseSolverDirectC finds a direct SparseSolverC in database or panic
+//   NOTE: remember to call Free() to release allocated resources
+func NewSparseSolverDirectC(kind string) SparseSolverDirectC {
+	if s, ok := NewSparseSolverC(kind).(SparseSolverDirectC); ok {
+		return s
+	}
+	chk.Panic("SparseSolverC named %q is not a direct solver", kind)
+	return nil
+}
+
 // high-level functions ////////////////////////////////////////////////////////////////////////////
 
 // SpSolve solves a sparse linear system (using UMFPACK)
@@ -113,3 +169,22 @@ func SpSolveC(A *TripletC, b VectorC) (x VectorC) {
 	o.Solve(x, b, false) // x := inv(A) * b
 	return
 }
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// spDetNormalise scales the mantissa of mantissa ⋅ 10^exponent such that 1 ≤ |mantissa| < 10
+// (as in UMFPACK's get_determinant). A zero (or non-finite) mantissa is returned unchanged
+func spDetNormalise(mantissa, exponent float64) (float64, float64) {
+	if mantissa == 0 || math.IsInf(mantissa, 0) || math.IsNaN(mantissa) {
+		return mantissa, exponent
+	}
+	for math.Abs(mantissa) >= 10 {
+		mantissa /= 10
+		exponent++
+	}
+	for math.Abs(mantissa) < 1 {
+		mantissa *= 10
+		exponent--
+	}
+	return mantissa, exponent
+}
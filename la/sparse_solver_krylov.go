@@ -0,0 +1,447 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/mpi"
+)
+
+// Krylov implements preconditioned Krylov subspace (iterative) solvers for sparse linear systems
+//
+//   Given:  A ⋅ x = b    find x   such that   ‖b - A ⋅ x‖ ≤ Tol ⋅ ‖b‖
+//
+//   kind:
+//     "cg"       -- Conjugate Gradients. A must be symmetric positive-definite
+//     "bicgstab" -- Bi-Conjugate Gradients Stabilised
+//     "gmres"    -- restarted Generalised Minimal Residual GMRES(m)
+//
+//   NOTE: (1) the settings may be changed after NewSparseSolver and before Solve; e.g.:
+//               o := NewSparseSolver("cg").(*Krylov)
+//               o.Precond = "ic0"
+//         (2) the preconditioners are applied symmetrically for CG and on the right for BiCGStab
+//             and GMRES; thus the convergence is always checked with the unpreconditioned residual
+//
+//   References:
+//    [1] Barrett R et al. (1994) Templates for the Solution of Linear Systems: Building Blocks
+//        for Iterative Methods, 2nd Edition. SIAM
+//    [2] Saad Y (2003) Iterative Methods for Sparse Linear Systems, 2nd Edition. SIAM
+type Krylov struct {
+
+	// settings
+	Tol     float64        // tolerance on the relative residual ‖b - A⋅x‖ / ‖b‖
+	MaxIt   int            // maximum number of iterations
+	Restart int            // number of iterations before restarting GMRES(m)
+	Precond string         // kind of preconditioner; see NewPreconditioner. "" means none
+	Prec    Preconditioner // user-defined preconditioner; overrides Precond if not nil
+	UseX0   bool           // use the values in x as initial guess; otherwise x₀ = 0
+
+	// statistics
+	NumIt   int       // number of iterations performed by the last call to Solve
+	Resid   float64   // relative residual ‖b - A⋅x‖ / ‖b‖ at the end of the last call to Solve
+	History []float64 // relative residual at every iteration of the last call to Solve (History[0] refers to x₀)
+
+	// internal
+	kind        string         // "cg", "bicgstab" or "gmres"
+	verbose     bool           // show messages
+	t           *Triplet       // triplet with A
+	a           *CCMatrix      // column-compressed A (nil if a matrix-free operator is given)
+	op          LinearOperator // operator computing A ⋅ x
+	dist        *DistMatrix    // distributed matrix (nil if not distributed)
+	comm        *mpi.Communicator
+	prec        Preconditioner
+	initialised bool
+	factorised  bool
+}
+
+// newKrylov returns a new Krylov solver with default settings
+func newKrylov(kind string) *Krylov {
+	return &Krylov{kind: kind, Tol: 1e-8, MaxIt: 1000, Restart: 30}
+}
+
+// NewKrylov returns a new Krylov solver ("cg", "bicgstab" or "gmres") with default settings;
+// e.g. to be initialised with InitOp
+func NewKrylov(kind string) *Krylov {
+	switch kind {
+	case "cg", "bicgstab", "gmres":
+		return newKrylov(kind)
+	}
+	chk.Panic("Krylov solver %q is not available\n", kind)
+	return nil
+}
+
+// Init initialises the Krylov solver
+//  NOTE: ordering, scaling and comm are not used
+func (o *Krylov) Init(t *Triplet, symmetric, verbose bool, ordering, scaling string, comm *mpi.Communicator) {
+	if o.initialised {
+		chk.Panic("solver must be initialised just once\n")
+	}
+	if t.pos == 0 {
+		chk.Panic("triplet must have at least one item for initialisation\n")
+	}
+	if t.m != t.n {
+		chk.Panic("Krylov solvers require a square matrix. %d × %d is invalid\n", t.m, t.n)
+	}
+	o.t = t
+	o.verbose = verbose
+	o.initialised = true
+}
+
+// InitOp initialises the Krylov solver with a (possibly matrix-free or block) linear operator
+// instead of a triplet
+//
+//   NOTE: (1) named preconditioners (Precond) require that op is an *OpCCMatrix; otherwise, a
+//             user-defined preconditioner (Prec; e.g. PrecOp) that does not use the matrix in
+//             its Init method must be given
+//         (2) Fact must still be called (once) before Solve
+//
+func (o *Krylov) InitOp(op LinearOperator, verbose bool) {
+	if o.initialised {
+		chk.Panic("solver must be initialised just once\n")
+	}
+	m, n := op.Size()
+	if m != n {
+		chk.Panic("Krylov solvers require a square operator. %d × %d is invalid\n", m, n)
+	}
+	o.op = op
+	if cc, ok := op.(*OpCCMatrix); ok {
+		o.a = cc.A
+	}
+	o.verbose = verbose
+	o.initialised = true
+}
+
+// InitDist initialises the Krylov solver with a matrix distributed by rows over the processors
+// of a communicator. The vectors x and b in Solve are then the local parts of the distributed
+// vectors and the dot products and norms are reduced over all processors
+//
+//   NOTE: (1) Solve is a collective operation: all processors must call it
+//         (2) named preconditioners (Precond) are applied to the local diagonal block of A;
+//             i.e. block-Jacobi preconditioning without communication
+//         (3) Fact must still be called (once) before Solve
+//
+func (o *Krylov) InitDist(a *DistMatrix, verbose bool) {
+	if a.M != a.N {
+		chk.Panic("Krylov solvers require a square matrix. %d × %d is invalid\n", a.M, a.N)
+	}
+	if a.nrow != a.ncol {
+		chk.Panic("partitions of rows and columns must be equal. %d rows != %d columns\n", a.nrow, a.ncol)
+	}
+	o.InitOp(a, verbose)
+	o.a = a.LocalDiag()
+	o.dist = a
+	o.comm = a.comm
+}
+
+// Free frees memory
+func (o *Krylov) Free() {
+}
+
+// Fact converts the triplet (if any) to column-compressed format and initialises the preconditioner
+func (o *Krylov) Fact() {
+	if !o.initialised {
+		chk.Panic("linear solver must be initialised first\n")
+	}
+	o.factorised = false
+	if o.t != nil {
+		if o.a != nil && len(o.a.i) != o.t.pos {
+			o.a = nil // number of entries in triplet has changed
+		}
+		o.a = o.t.ToMatrix(o.a)
+		o.op = NewOpCCMatrix(o.a)
+	}
+	o.prec = o.Prec
+	if o.prec == nil && o.Precond != "" {
+		o.prec = NewPreconditioner(o.Precond)
+	}
+	if o.prec != nil {
+		if o.a == nil && o.Prec == nil {
+			chk.Panic("preconditioner %q requires a sparse matrix; use Prec (e.g. PrecOp) with matrix-free operators\n", o.Precond)
+		}
+		o.prec.Init(o.a)
+	}
+	o.factorised = true
+}
+
+// Solve solves the linear system iteratively
+//
+//   Given:  A ⋅ x = b    find x   such that   x = A⁻¹ ⋅ b
+//
+//   NOTE: bIsDistr is not used; with InitDist, x and b are the local parts of distributed vectors
+func (o *Krylov) Solve(x, b Vector, bIsDistr bool) {
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+	if !o.UseX0 {
+		x.Fill(0)
+	}
+	o.NumIt = 0
+	o.History = o.History[:0]
+	nb := o.norm(b)
+	if nb == 0 {
+		x.Fill(0)
+		o.Resid = 0
+		o.History = append(o.History, 0)
+		return
+	}
+	switch o.kind {
+	case "cg":
+		o.cg(x, b, nb)
+	case "bicgstab":
+		o.bicgstab(x, b, nb)
+	case "gmres":
+		o.gmres(x, b, nb)
+	default:
+		chk.Panic("Krylov solver %q is not available\n", o.kind)
+	}
+	if o.Resid > o.Tol {
+		chk.Panic("%s: cannot converge after %d iterations. relative residual = %g\n", o.kind, o.NumIt, o.Resid)
+	}
+}
+
+// cg implements the preconditioned Conjugate Gradients method
+func (o *Krylov) cg(x, b Vector, nb float64) {
+	n := len(b)
+	r, z, p, q := NewVector(n), NewVector(n), NewVector(n), NewVector(n)
+	o.residual(r, x, b)
+	if o.check(o.norm(r) / nb) {
+		return
+	}
+	o.precond(z, r)
+	copy(p, z)
+	ρ := o.dot(r, z)
+	for o.NumIt < o.MaxIt {
+		o.NumIt++
+		o.op.Apply(q, p)
+		pq := o.dot(p, q)
+		if pq <= 0 {
+			chk.Panic("cg: matrix is not positive-definite (pᵀ⋅A⋅p = %g)\n", pq)
+		}
+		α := ρ / pq
+		for i := 0; i < n; i++ {
+			x[i] += α * p[i]
+			r[i] -= α * q[i]
+		}
+		if o.check(o.norm(r) / nb) {
+			return
+		}
+		o.precond(z, r)
+		ρnew := o.dot(r, z)
+		β := ρnew / ρ
+		ρ = ρnew
+		for i := 0; i < n; i++ {
+			p[i] = z[i] + β*p[i]
+		}
+	}
+}
+
+// bicgstab implements the (right) preconditioned Bi-Conjugate Gradients Stabilised method
+func (o *Krylov) bicgstab(x, b Vector, nb float64) {
+	n := len(b)
+	r, r0 := NewVector(n), NewVector(n)
+	p, v, s, t := NewVector(n), NewVector(n), NewVector(n), NewVector(n)
+	ph, sh := NewVector(n), NewVector(n)
+	o.residual(r, x, b)
+	if o.check(o.norm(r) / nb) {
+		return
+	}
+	copy(r0, r)
+	ρ, α, ω := 1.0, 1.0, 1.0
+	for o.NumIt < o.MaxIt {
+		o.NumIt++
+		ρnew := o.dot(r0, r)
+		if ρnew == 0 {
+			chk.Panic("bicgstab: breakdown with ρ = 0\n")
+		}
+		β := (ρnew / ρ) * (α / ω)
+		ρ = ρnew
+		for i := 0; i < n; i++ {
+			p[i] = r[i] + β*(p[i]-ω*v[i])
+		}
+		o.precond(ph, p)
+		o.op.Apply(v, ph)
+		α = ρ / o.dot(r0, v)
+		for i := 0; i < n; i++ {
+			s[i] = r[i] - α*v[i]
+		}
+		if ns := o.norm(s) / nb; ns <= o.Tol {
+			for i := 0; i < n; i++ {
+				x[i] += α * ph[i]
+			}
+			o.check(ns)
+			return
+		}
+		o.precond(sh, s)
+		o.op.Apply(t, sh)
+		tt := o.dot(t, t)
+		if tt == 0 {
+			chk.Panic("bicgstab: breakdown with tᵀ⋅t = 0\n")
+		}
+		ω = o.dot(t, s) / tt
+		for i := 0; i < n; i++ {
+			x[i] += α*ph[i] + ω*sh[i]
+			r[i] = s[i] - ω*t[i]
+		}
+		if o.check(o.norm(r) / nb) {
+			return
+		}
+		if ω == 0 {
+			chk.Panic("bicgstab: breakdown with ω = 0\n")
+		}
+	}
+}
+
+// gmres implements the (right) preconditioned restarted Generalised Minimal Residual method
+func (o *Krylov) gmres(x, b Vector, nb float64) {
+	n := len(b)
+	m, ntot := o.Restart, n
+	if o.dist != nil {
+		ntot = o.dist.N
+	}
+	if m < 1 || m > ntot {
+		m = ntot
+	}
+	r, w, z := NewVector(n), NewVector(n), NewVector(n)
+	V := make([]Vector, m+1) // Krylov basis
+	for j := 0; j <= m; j++ {
+		V[j] = NewVector(n)
+	}
+	H := NewMatrix(m+1, m) // Hessenberg matrix
+	cs, sn, g, y := NewVector(m), NewVector(m), NewVector(m+1), NewVector(m)
+	o.residual(r, x, b)
+	β := o.norm(r)
+	if o.check(β / nb) {
+		return
+	}
+	for o.NumIt < o.MaxIt {
+
+		// start Arnoldi process
+		V[0].Apply(1.0/β, r)
+		g.Fill(0)
+		g[0] = β
+		k := 0
+		for k < m && o.NumIt < o.MaxIt {
+			o.NumIt++
+
+			// w := A ⋅ M⁻¹ ⋅ v[k]
+			o.precond(z, V[k])
+			o.op.Apply(w, z)
+
+			// modified Gram-Schmidt
+			for i := 0; i <= k; i++ {
+				h := o.dot(w, V[i])
+				H.Set(i, k, h)
+				for l := 0; l < n; l++ {
+					w[l] -= h * V[i][l]
+				}
+			}
+			hkk := o.norm(w)
+			H.Set(k+1, k, hkk)
+			if hkk > 0 {
+				V[k+1].Apply(1.0/hkk, w)
+			}
+
+			// apply previous Givens rotations to new column of H
+			for i := 0; i < k; i++ {
+				hi, hi1 := H.Get(i, k), H.Get(i+1, k)
+				H.Set(i, k, cs[i]*hi+sn[i]*hi1)
+				H.Set(i+1, k, -sn[i]*hi+cs[i]*hi1)
+			}
+
+			// compute and apply new rotation
+			hk, hk1 := H.Get(k, k), H.Get(k+1, k)
+			den := math.Hypot(hk, hk1)
+			if den == 0 {
+				chk.Panic("gmres: breakdown with zero Hessenberg column\n")
+			}
+			cs[k], sn[k] = hk/den, hk1/den
+			H.Set(k, k, den)
+			H.Set(k+1, k, 0)
+			g[k+1] = -sn[k] * g[k]
+			g[k] = cs[k] * g[k]
+			k++
+
+			// check convergence using the estimate |g[k]| = ‖b - A⋅xₖ‖
+			if o.check(math.Abs(g[k])/nb) || hkk == 0 {
+				break
+			}
+		}
+
+		// solve H ⋅ y = g (upper triangular) and update x := x + M⁻¹ ⋅ V ⋅ y
+		for i := k - 1; i >= 0; i-- {
+			y[i] = g[i]
+			for j := i + 1; j < k; j++ {
+				y[i] -= H.Get(i, j) * y[j]
+			}
+			y[i] /= H.Get(i, i)
+		}
+		w.Fill(0)
+		for j := 0; j < k; j++ {
+			for l := 0; l < n; l++ {
+				w[l] += y[j] * V[j][l]
+			}
+		}
+		o.precond(z, w)
+		for l := 0; l < n; l++ {
+			x[l] += z[l]
+		}
+
+		// replace the last estimate by the true residual
+		o.residual(r, x, b)
+		β = o.norm(r)
+		o.History = o.History[:len(o.History)-1]
+		if o.check(β / nb) {
+			return
+		}
+	}
+}
+
+// residual computes r := b - A ⋅ x
+func (o *Krylov) residual(r, x, b Vector) {
+	o.op.Apply(r, x)
+	for i := 0; i < len(b); i++ {
+		r[i] = b[i] - r[i]
+	}
+}
+
+// dot returns the dot product u⋅v (reduced over all processors if distributed)
+func (o *Krylov) dot(u, v Vector) float64 {
+	return DistVecDot(o.comm, u, v)
+}
+
+// norm returns the Euclidean norm of v (reduced over all processors if distributed)
+func (o *Krylov) norm(v Vector) float64 {
+	return math.Sqrt(o.dot(v, v))
+}
+
+// precond applies the preconditioner z := M⁻¹ ⋅ r or copies r into z if there is no preconditioner
+func (o *Krylov) precond(z, r Vector) {
+	if o.prec == nil {
+		copy(z, r)
+		return
+	}
+	o.prec.Apply(z, r)
+}
+
+// check records the relative residual and returns true if converged
+func (o *Krylov) check(resid float64) (converged bool) {
+	o.Resid = resid
+	o.History = append(o.History, resid)
+	if o.verbose {
+		io.Pf("%s: it = %4d  ‖r‖/‖b‖ = %23.15e\n", o.kind, o.NumIt, resid)
+	}
+	return resid <= o.Tol
+}
+
+// add solvers to database /////////////////////////////////////////////////////////////////////////
+
+func init() {
+	spSolverDB["cg"] = func() SparseSolver { return newKrylov("cg") }
+	spSolverDB["bicgstab"] = func() SparseSolver { return newKrylov("bicgstab") }
+	spSolverDB["gmres"] = func() SparseSolver { return newKrylov("gmres") }
+}
@@ -21,6 +21,7 @@ int NumDataC = 0;
 import "C"
 
 import (
+	"math"
 	"unsafe"
 
 	"github.com/cpmech/gosl/chk"
@@ -41,8 +42,10 @@ type Mumps struct {
 	data *C.DMUMPS_STRUC_C
 
 	// derived
+	symmetric   bool
 	initialised bool
 	factorised  bool
+	detComputed bool // the last factorisation has computed the determinant
 }
 
 // Init initialises mumps for sparse linear systems with real numbers
@@ -113,6 +116,8 @@ func (o *Mumps) Init(t *Triplet, symmetric, verbose bool, ordering, scaling stri
 	o.data.icntl[14-1] = 5000 // % increase of working space
 	o.data.icntl[18-1] = 3    // distributed matrix
 	o.data.icntl[23-1] = 2000 // max 2000Mb per processor // TODO: check this
+	o.data.icntl[33-1] = 0    // do not compute the determinant (see Determinant)
+	o.symmetric = symmetric
 
 	// set ordering and scaling
 	ord, sca := mumOrderingScaling(ordering, scaling)
@@ -155,6 +160,7 @@ func (o *Mumps) Fact() {
 
 	// success
 	o.factorised = true
+	o.detComputed = o.data.icntl[33-1] != 0
 }
 
 // Solve solves sparse linear systems using MUMPS or MUMPS
@@ -195,6 +201,126 @@ func (o *Mumps) Solve(x, b Vector, bIsDistr bool) {
 	o.comm.BcastFromRoot(x)
 }
 
+// RefactorNumeric performs the numeric factorisation only. With MUMPS, the analysis (symbolic
+// factorisation) is carried out in Init; thus this function is the same as Fact and the values of
+// the triplet may change but not its sparsity pattern
+func (o *Mumps) RefactorNumeric() {
+	o.Fact()
+}
+
+// SolveTranspose solves the transposed system using MUMPS
+//
+//   Given:  Aᵀ ⋅ x = b    find x   such that   x = A⁻ᵀ ⋅ b
+//
+//   bIsDistr -- this flag tells that the right-hand-side vector 'b' is distributed.
+//
+func (o *Mumps) SolveTranspose(x, b Vector, bIsDistr bool) {
+	o.data.icntl[9-1] = 2 // ≠1 ⇒ solve Aᵀ ⋅ x = b
+	defer func() { o.data.icntl[9-1] = 1 }()
+	o.Solve(x, b, bIsDistr)
+}
+
+// SolveMany solves sparse linear systems with many right-hand sides using MUMPS
+//
+//   Given:  A ⋅ X = B    find X   such that   X = A⁻¹ ⋅ B
+//
+//   bIsDistr -- this flag tells that the right-hand-side matrix 'B' is distributed.
+//
+func (o *Mumps) SolveMany(X, B *Matrix, bIsDistr bool) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+	if X.M != o.t.m || B.M != o.t.m || X.N != B.N {
+		chk.Panic("dimensions of X (%d×%d) and B (%d×%d) are incompatible with A (%d×%d)\n", X.M, X.N, B.M, B.N, o.t.m, o.t.m)
+	}
+
+	// set RHS in processor # 0
+	if bIsDistr { // B is distributed => must join
+		o.comm.ReduceSum(X.Data, B.Data) // X := join(B)
+	} else {
+		if o.comm.Rank() == 0 {
+			copy(X.Data, B.Data)
+		}
+	}
+
+	// only proc # 0 needs the RHS (column-major with leading dimension n)
+	if o.comm.Rank() == 0 {
+		o.data.rhs = (*C.double)(unsafe.Pointer(&X.Data[0]))
+		o.data.nrhs = C.int(B.N)
+		o.data.lrhs = C.int(B.M)
+	}
+
+	// solve
+	o.data.job = 3     // solution code
+	C.dmumps_c(o.data) // solve
+	o.data.nrhs = 1
+	if o.data.info[1-1] < 0 {
+		chk.Panic("solver failed: %v\n", mumErr(o.data.info[1-1], o.data.info[2-1]))
+	}
+
+	// broadcast from root
+	o.comm.BcastFromRoot(X.Data)
+}
+
+// Determinant returns the determinant of A computed by MUMPS during the factorisation
+//
+//   det(A) = mantissa ⋅ 10^exponent    with 1 ≤ |mantissa| < 10
+//
+//   NOTE: (1) MUMPS returns det(A) = RINFOG(12) ⋅ 2^INFOG(34), which is converted to base 10
+//         (2) the determinant is not computed by Fact because it adds work to every
+//             factorisation (ICNTL(33)); thus, the first call after a factorisation refactorises A
+//             with ICNTL(33) = 1. The values of the triplet must not be modified in between
+//         (3) with MPI, this function must be called by all processors
+//
+func (o *Mumps) Determinant() (mantissa, exponent float64) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+
+	// factorise again with the computation of the determinant
+	if !o.detComputed {
+		o.data.icntl[33-1] = 1
+		defer func() { o.data.icntl[33-1] = 0 }()
+		o.Fact()
+	}
+
+	// convert from base 2 to base 10
+	mantissa = float64(o.data.rinfog[12-1])
+	if mantissa == 0 {
+		return
+	}
+	l := float64(o.data.infog[34-1]) * math.Log10(2)
+	exponent = math.Floor(l)
+	mantissa *= math.Pow(10, l-exponent)
+	return spDetNormalise(mantissa, exponent)
+}
+
+// Inertia returns the number of negative, zero and positive eigenvalues of a symmetric matrix
+// computed from the pivots (Sylvester's law of inertia)
+//  NOTE: (1) the solver must be initialised with symmetric = true
+//        (2) MUMPS does not factorise singular matrices unless null pivot detection is activated
+//            (ICNTL(24)); thus nzero is the number of null pivots INFOG(28), usually zero
+func (o *Mumps) Inertia() (nneg, nzero, npos int) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+	if !o.symmetric {
+		chk.Panic("inertia is only available for symmetric matrices\n")
+	}
+
+	// results
+	nneg = int(o.data.infog[12-1])
+	nzero = int(o.data.infog[28-1])
+	npos = o.t.m - nneg - nzero
+	return
+}
+
 // complex /////////////////////////////////////////////////////////////////////////////////////////
 
 // MumpsC wraps the MUMPS solver (complex version)
@@ -210,6 +336,7 @@ type MumpsC struct {
 	data *C.ZMUMPS_STRUC_C
 
 	// derived
+	symmetric   bool
 	initialised bool
 	factorised  bool
 }
@@ -282,6 +409,7 @@ func (o *MumpsC) Init(t *TripletC, symmetric, verbose bool, ordering, scaling st
 	o.data.icntl[14-1] = 5000 // % increase of working space
 	o.data.icntl[18-1] = 3    // distributed matrix
 	o.data.icntl[23-1] = 2000 // max 2000Mb per processor // TODO: check this
+	o.symmetric = symmetric
 
 	// set ordering and scaling
 	ord, sca := mumOrderingScaling(ordering, scaling)
@@ -364,6 +492,69 @@ func (o *MumpsC) Solve(x, b VectorC, bIsDistr bool) {
 	o.comm.BcastFromRootC(x)
 }
 
+// RefactorNumeric performs the numeric factorisation only. With MUMPS, the analysis (symbolic
+// factorisation) is carried out in Init; thus this function is the same as Fact and the values of
+// the triplet may change but not its sparsity pattern
+func (o *MumpsC) RefactorNumeric() {
+	o.Fact()
+}
+
+// SolveTranspose solves the transposed system using MUMPS
+//
+//   Given:  Aᵀ ⋅ x = b    find x   such that   x = A⁻ᵀ ⋅ b
+//
+//   bIsDistr -- this flag tells that the right-hand-side vector 'b' is distributed.
+//
+func (o *MumpsC) SolveTranspose(x, b VectorC, bIsDistr bool) {
+	o.data.icntl[9-1] = 2 // ≠1 ⇒ solve Aᵀ ⋅ x = b
+	defer func() { o.data.icntl[9-1] = 1 }()
+	o.Solve(x, b, bIsDistr)
+}
+
+// SolveMany solves sparse linear systems with many right-hand sides using MUMPS
+//
+//   Given:  A ⋅ X = B    find X   such that   X = A⁻¹ ⋅ B
+//
+//   bIsDistr -- this flag tells that the right-hand-side matrix 'B' is distributed.
+//
+func (o *MumpsC) SolveMany(X, B *MatrixC, bIsDistr bool) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+	if X.M != o.t.m || B.M != o.t.m || X.N != B.N {
+		chk.Panic("dimensions of X (%d×%d) and B (%d×%d) are incompatible with A (%d×%d)\n", X.M, X.N, B.M, B.N, o.t.m, o.t.m)
+	}
+
+	// set RHS in processor # 0
+	if bIsDistr { // B is distributed => must join
+		o.comm.ReduceSumC(X.Data, B.Data) // X := join(B)
+	} else {
+		if o.comm.Rank() == 0 {
+			copy(X.Data, B.Data)
+		}
+	}
+
+	// only proc # 0 needs the RHS (column-major with leading dimension n)
+	if o.comm.Rank() == 0 {
+		o.data.rhs = (*C.ZMUMPS_COMPLEX)(unsafe.Pointer(&X.Data[0]))
+		o.data.nrhs = C.int(B.N)
+		o.data.lrhs = C.int(B.M)
+	}
+
+	// solve
+	o.data.job = 3     // solution code
+	C.zmumps_c(o.data) // solve
+	o.data.nrhs = 1
+	if o.data.info[1-1] < 0 {
+		chk.Panic("solver failed: %v\n", mumErr(o.data.info[1-1], o.data.info[2-1]))
+	}
+
+	// broadcast from root
+	o.comm.BcastFromRootC(X.Data)
+}
+
 // auxiliary ///////////////////////////////////////////////////////////////////////////////////////
 
 // mumOrderingScaling sets the ordering and scaling methods for MUMPS
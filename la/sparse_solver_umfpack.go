@@ -47,7 +47,12 @@ type Umfpack struct {
 	ai *C.LONG
 	ax *C.double
 
+	// pattern used in the symbolic factorisation
+	symbAp []int
+	symbAi []int
+
 	// derived
+	symmetric   bool
 	initialised bool
 	factorised  bool
 	symbFact    bool
@@ -87,6 +92,7 @@ func (o *Umfpack) Init(t *Triplet, symmetric, verbose bool, ordering, scaling st
 	C.umfpack_dl_defaults(o.uctrl)
 
 	// flags
+	o.symmetric = symmetric
 	if symmetric {
 		o.ctrl[C.UMFPACK_STRATEGY] = C.UMFPACK_STRATEGY_SYMMETRIC
 	}
@@ -135,6 +141,8 @@ func (o *Umfpack) Fact() {
 		chk.Panic("symbolic factorised failed (UMFPACK error: %s)\n", umfErr(code))
 	}
 	o.symbFact = true
+	o.symbAp = append(o.symbAp[:0], o.apData...)
+	o.symbAi = append(o.symbAi[:0], o.aiData[:o.apData[o.t.n]]...)
 
 	// numeric factorisation
 	if o.numeFact {
@@ -173,6 +181,155 @@ func (o *Umfpack) Solve(x, b Vector, dummy bool) {
 	}
 }
 
+// RefactorNumeric performs the numeric factorisation only, reusing the symbolic analysis of the
+// previous factorisation; i.e. the values of the triplet may change but not the sparsity pattern.
+// If the pattern has changed (or Fact has not been called yet), the full factorisation is
+// performed instead
+func (o *Umfpack) RefactorNumeric() {
+
+	// check
+	if !o.initialised {
+		chk.Panic("linear solver must be initialised first\n")
+	}
+	if !o.symbFact {
+		o.Fact()
+		return
+	}
+	o.factorised = false
+
+	// convert triplet to column-compressed format
+	code := C.umfpack_dl_triplet_to_col(C.LONG(o.t.m), C.LONG(o.t.n), C.LONG(o.t.pos), o.ti, o.tj, o.tx, o.ap, o.ai, o.ax, nil)
+	if code != C.UMFPACK_OK {
+		chk.Panic("conversion failed (UMFPACK error: %s)\n", umfErr(code))
+	}
+	if !umfSamePattern(o.apData, o.aiData, o.symbAp, o.symbAi) {
+		o.Fact()
+		return
+	}
+
+	// numeric factorisation
+	if o.numeFact {
+		C.umfpack_dl_free_numeric(&o.unum)
+		o.numeFact = false
+	}
+	code = C.umfpack_dl_numeric(o.ap, o.ai, o.ax, o.usymb, &o.unum, o.uctrl, o.uinfo)
+	if code != C.UMFPACK_OK {
+		chk.Panic("numeric factorisation failed (UMFPACK error: %s)\n", umfErr(code))
+	}
+	o.numeFact = true
+
+	// success
+	o.factorised = true
+}
+
+// SolveTranspose solves the transposed system using UMFPACK
+//
+//   Given:  Aᵀ ⋅ x = b    find x   such that   x = A⁻ᵀ ⋅ b
+//
+func (o *Umfpack) SolveTranspose(x, b Vector, dummy bool) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+
+	// pointers
+	px := (*C.double)(unsafe.Pointer(&x[0]))
+	pb := (*C.double)(unsafe.Pointer(&b[0]))
+
+	// solve
+	code := C.umfpack_dl_solve(C.UMFPACK_At, o.ap, o.ai, o.ax, px, pb, o.unum, o.uctrl, o.uinfo)
+	if code != C.UMFPACK_OK {
+		chk.Panic("solve failed (UMFPACK error: %s)\n", umfErr(code))
+	}
+}
+
+// SolveMany solves sparse linear systems with many right-hand sides using UMFPACK
+//
+//   Given:  A ⋅ X = B    find X   such that   X = A⁻¹ ⋅ B
+//
+func (o *Umfpack) SolveMany(X, B *Matrix, dummy bool) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+	if X.M != o.t.n || B.M != o.t.n || X.N != B.N {
+		chk.Panic("dimensions of X (%d×%d) and B (%d×%d) are incompatible with A (%d×%d)\n", X.M, X.N, B.M, B.N, o.t.n, o.t.n)
+	}
+
+	// solve each column
+	for j := 0; j < B.N; j++ {
+		o.Solve(X.Col(j), B.Col(j), false)
+	}
+}
+
+// Determinant returns the determinant of A computed by UMFPACK from the LU factors
+//
+//   det(A) = mantissa ⋅ 10^exponent    with 1 ≤ |mantissa| < 10
+//
+func (o *Umfpack) Determinant() (mantissa, exponent float64) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+
+	// compute determinant
+	var mx, ex C.double
+	code := C.umfpack_dl_get_determinant(&mx, &ex, o.unum, o.uinfo)
+	if code < 0 {
+		chk.Panic("cannot compute determinant (UMFPACK error: %s)\n", umfErr(code))
+	}
+	return float64(mx), float64(ex)
+}
+
+// Inertia returns the number of negative, zero and positive eigenvalues of a symmetric matrix
+// computed from the signs of the pivots (Sylvester's law of inertia)
+//  NOTE: (1) the solver must be initialised with symmetric = true
+//        (2) only diagonal pivots may have been used; i.e. the row and column permutations must
+//            be the same. Otherwise, use MUMPS
+func (o *Umfpack) Inertia() (nneg, nzero, npos int) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+	if !o.symmetric {
+		chk.Panic("inertia is only available for symmetric matrices\n")
+	}
+
+	// get permutations and diagonal of U
+	n := o.t.n
+	P := make([]int, n)
+	Q := make([]int, n)
+	D := make([]float64, n)
+	var doRecip C.LONG
+	pP := (*C.LONG)(unsafe.Pointer(&P[0]))
+	pQ := (*C.LONG)(unsafe.Pointer(&Q[0]))
+	pD := (*C.double)(unsafe.Pointer(&D[0]))
+	code := C.umfpack_dl_get_numeric(nil, nil, nil, nil, nil, nil, pP, pQ, pD, &doRecip, nil, o.unum)
+	if code != C.UMFPACK_OK {
+		chk.Panic("cannot get numeric factorisation (UMFPACK error: %s)\n", umfErr(code))
+	}
+
+	// count signs of pivots
+	for k := 0; k < n; k++ {
+		if P[k] != Q[k] {
+			chk.Panic("inertia is not available because off-diagonal pivots were used (row %d and column %d at step %d)\n", P[k], Q[k], k)
+		}
+		switch {
+		case D[k] < 0:
+			nneg++
+		case D[k] > 0:
+			npos++
+		default:
+			nzero++
+		}
+	}
+	return
+}
+
 // complex /////////////////////////////////////////////////////////////////////////////////////////
 
 // UmfpackC wraps the UMFPACK solver (complex version)
@@ -200,7 +357,12 @@ type UmfpackC struct {
 	ai *C.LONG
 	ax *C.double
 
+	// pattern used in the symbolic factorisation
+	symbAp []int
+	symbAi []int
+
 	// derived
+	symmetric   bool
 	initialised bool
 	factorised  bool
 	symbFact    bool
@@ -240,6 +402,7 @@ func (o *UmfpackC) Init(t *TripletC, symmetric, verbose bool, ordering, scaling
 	C.umfpack_zl_defaults(o.uctrl)
 
 	// flags
+	o.symmetric = symmetric
 	if symmetric {
 		o.ctrl[C.UMFPACK_STRATEGY] = C.UMFPACK_STRATEGY_SYMMETRIC
 	}
@@ -288,6 +451,8 @@ func (o *UmfpackC) Fact() {
 		chk.Panic("symbolic factorised failed (UMFPACK error: %s)\n", umfErr(code))
 	}
 	o.symbFact = true
+	o.symbAp = append(o.symbAp[:0], o.apData...)
+	o.symbAi = append(o.symbAi[:0], o.aiData[:o.apData[o.t.n]]...)
 
 	// numeric factorisation
 	if o.numeFact {
@@ -326,6 +491,109 @@ func (o *UmfpackC) Solve(x, b VectorC, dummy bool) {
 	}
 }
 
+// RefactorNumeric performs the numeric factorisation only, reusing the symbolic analysis of the
+// previous factorisation; i.e. the values of the triplet may change but not the sparsity pattern.
+// If the pattern has changed (or Fact has not been called yet), the full factorisation is
+// performed instead
+func (o *UmfpackC) RefactorNumeric() {
+
+	// check
+	if !o.initialised {
+		chk.Panic("linear solver must be initialised first\n")
+	}
+	if !o.symbFact {
+		o.Fact()
+		return
+	}
+	o.factorised = false
+
+	// convert triplet to column-compressed format
+	code := C.umfpack_zl_triplet_to_col(C.LONG(o.t.m), C.LONG(o.t.n), C.LONG(o.t.pos), o.ti, o.tj, o.tx, nil, o.ap, o.ai, o.ax, nil, nil)
+	if code != C.UMFPACK_OK {
+		chk.Panic("conversion failed (UMFPACK error: %s)\n", umfErr(code))
+	}
+	if !umfSamePattern(o.apData, o.aiData, o.symbAp, o.symbAi) {
+		o.Fact()
+		return
+	}
+
+	// numeric factorisation
+	if o.numeFact {
+		C.umfpack_zl_free_numeric(&o.unum)
+		o.numeFact = false
+	}
+	code = C.umfpack_zl_numeric(o.ap, o.ai, o.ax, nil, o.usymb, &o.unum, o.uctrl, o.uinfo)
+	if code != C.UMFPACK_OK {
+		chk.Panic("numeric factorisation failed (UMFPACK error: %s)\n", umfErr(code))
+	}
+	o.numeFact = true
+
+	// success
+	o.factorised = true
+}
+
+// SolveTranspose solves the transposed system using UMFPACK
+//
+//   Given:  Aᵀ ⋅ x = b    find x   such that   x = A⁻ᵀ ⋅ b
+//
+func (o *UmfpackC) SolveTranspose(x, b VectorC, dummy bool) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+
+	// pointers
+	px := (*C.double)(unsafe.Pointer(&x[0]))
+	pb := (*C.double)(unsafe.Pointer(&b[0]))
+
+	// solve
+	code := C.umfpack_zl_solve(C.UMFPACK_Aat, o.ap, o.ai, o.ax, nil, px, nil, pb, nil, o.unum, o.uctrl, o.uinfo)
+	if code != C.UMFPACK_OK {
+		chk.Panic("solve failed (UMFPACK error: %s)\n", umfErr(code))
+	}
+}
+
+// SolveMany solves sparse linear systems with many right-hand sides using UMFPACK
+//
+//   Given:  A ⋅ X = B    find X   such that   X = A⁻¹ ⋅ B
+//
+func (o *UmfpackC) SolveMany(X, B *MatrixC, dummy bool) {
+
+	// check
+	if !o.factorised {
+		chk.Panic("factorisation must be performed first\n")
+	}
+	if X.M != o.t.n || B.M != o.t.n || X.N != B.N {
+		chk.Panic("dimensions of X (%d×%d) and B (%d×%d) are incompatible with A (%d×%d)\n", X.M, X.N, B.M, B.N, o.t.n, o.t.n)
+	}
+
+	// solve each column
+	for j := 0; j < B.N; j++ {
+		o.Solve(X.Col(j), B.Col(j), false)
+	}
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// umfSamePattern checks whether the column-compressed pattern (ap, ai) is equal to (bp, bi)
+func umfSamePattern(ap, ai, bp, bi []int) bool {
+	if len(ap) != len(bp) {
+		return false
+	}
+	for j := range ap {
+		if ap[j] != bp[j] {
+			return false
+		}
+	}
+	for p := 0; p < ap[len(ap)-1]; p++ {
+		if ai[p] != bi[p] {
+			return false
+		}
+	}
+	return true
+}
+
 // add solvers to database /////////////////////////////////////////////////////////////////////////
 
 func init() {
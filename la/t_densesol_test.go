@@ -6,9 +6,11 @@ package la
 
 import (
 	"math"
+	"math/cmplx"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
 )
 
 func calcLLt(L *Matrix) (LLt *Matrix) {
@@ -147,3 +149,67 @@ func TestSPDsolve02(tst *testing.T) {
 	})
 	chk.Array(tst, "X = inv(a) * B", 1e-13, X, []float64{0, 4, 7, -1, 8})
 }
+
+func TestDenSolveC01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("DenSolveC01")
+
+	a := NewMatrixDeep2c([][]complex128{
+		{2 + 1i, 1, 0 - 1i},
+		{1 - 2i, 3, 1 + 1i},
+		{0, 1 + 1i, 4 - 1i},
+	})
+	xCorrect := []complex128{1 - 1i, 2i, -3 + 0.5i}
+	b := NewVectorC(3)
+	MatVecMulC(b, 1, a, xCorrect)
+	x := NewVectorC(3)
+	DenSolveC(x, a, b, true)
+	TestSolverResidualC(tst, a, x, b, 1e-14)
+	chk.ArrayC(tst, "x = inv(a) * b", 1e-14, x, xCorrect)
+}
+
+func TestCholeskyC01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("CholeskyC01")
+
+	a := NewMatrixDeep2c([][]complex128{ // Hermitian positive-definite
+		{+4 + 0i, 0 + 1i, -3 + 1i, 0 + 2i},
+		{+0 - 1i, 3 + 0i, +1 + 0i, 2 + 0i},
+		{-3 - 1i, 1 + 0i, +4 + 0i, 1 - 1i},
+		{+0 - 2i, 2 + 0i, +1 + 1i, 4 + 0i},
+	})
+	L := NewMatrixC(4, 4)
+	CholeskyC(L, a) // L is such that: A = L * conj(transp(L))
+	chk.Deep2c(tst, "L", 1e-15, L.GetDeep2(), [][]complex128{
+		{+2.0 + 0.0e+00i, 0, 0, 0},
+		{+0.0 - 5.0e-01i, +1.658312395177700e+00, 0, 0},
+		{-1.5 - 5.0e-01i, +4.522670168666454e-01 + 4.522670168666454e-01i, +1.044465935734187e+00, 0},
+		{+0.0 - 1.0e+00i, +9.045340337332909e-01, +8.703882797784884e-02 - 8.703882797784884e-02i, +1.471960144387974e+00},
+	})
+	LLh := NewMatrixC(4, 4)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				LLh.Add(i, j, L.Get(i, k)*cmplx.Conj(L.Get(j, k)))
+			}
+		}
+	}
+	chk.Deep2c(tst, "a = LLh", 1e-15, LLh.GetDeep2(), a.GetDeep2())
+
+	// not positive-definite
+	defer func() {
+		if err := recover(); err != nil {
+			if chk.Verbose {
+				io.Pf("OK, caught the following message:\n\n\t%v\n", err)
+			}
+		} else {
+			tst.Errorf("\n\tTEST FAILED. CholeskyC should have panicked\n")
+		}
+	}()
+	CholeskyC(L, NewMatrixDeep2c([][]complex128{
+		{1, 2i},
+		{-2i, 1},
+	}))
+}
@@ -195,3 +195,49 @@ func TestEigen05(tst *testing.T) {
 	EigenVecR(v3, w3, A, true)
 	chk.Deep2c(tst, "v3", 1e-15, v3.GetDeep2(), vRef)
 }
+
+func TestEigenHermitian01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("EigenHermitian01")
+
+	// Pauli matrix σy
+	A := NewMatrixDeep2c([][]complex128{
+		{0, -1i},
+		{1i, 0},
+	})
+	w := NewVector(2)
+	v := NewMatrixC(2, 2)
+	HermitianEigen(w, v, A, true)
+	chk.Array(tst, "w(σy)", 1e-15, w, []float64{-1, 1})
+	CheckEigenVecHermitian(tst, A, w, v, 1e-15)
+
+	// 4 x 4
+	A = NewMatrixDeep2c([][]complex128{
+		{+4 + 0i, 0 + 1i, -3 + 1i, 0 + 2i},
+		{+0 - 1i, 3 + 0i, +1 + 0i, 2 + 0i},
+		{-3 - 1i, 1 + 0i, +4 + 0i, 1 - 1i},
+		{+0 - 2i, 2 + 0i, +1 + 1i, 4 + 0i},
+	})
+	w = NewVector(4)
+	v = NewMatrixC(4, 4)
+	HermitianEigen(w, v, A, true)
+	io.Pforan("w = %v\n", w)
+	CheckEigenVecHermitian(tst, A, w, v, 1e-14)
+	chk.Float64(tst, "Σλ = tr(A)", 1e-14, w.Accum(), 15)
+
+	// eigenvalues only
+	w2 := NewVector(4)
+	HermitianEigen(w2, nil, A, true)
+	chk.Array(tst, "w2", 1e-14, w2, w)
+
+	// real symmetric: same as Jacobi
+	B := NewMatrixDeep2([][]float64{
+		{2, 1, 0},
+		{1, 2, 1},
+		{0, 1, 2},
+	})
+	w = NewVector(3)
+	HermitianEigen(w, nil, B.GetComplex(), false)
+	chk.Array(tst, "w(B)", 1e-15, w, []float64{2 - math.Sqrt2, 2, 2 + math.Sqrt2})
+}
@@ -0,0 +1,260 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestMatExp01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatExp01. exponential: closed forms")
+
+	// generator of rotations: all Padé degrees and scaling and squaring
+	for _, θ := range []float64{0.005, 0.1, 0.4, 1, 2.5, 30} {
+		a := NewMatrixDeep2([][]float64{
+			{0, -θ},
+			{θ, 0},
+		})
+		e := NewMatrix(2, 2)
+		MatExp(e, a)
+		c, s := math.Cos(θ), math.Sin(θ)
+		chk.Deep2(tst, io.Sf("exp(θ=%g)", θ), 1e-13, e.GetDeep2(), [][]float64{
+			{c, -s},
+			{s, c},
+		})
+	}
+
+	// nilpotent: exp(N) = I + N + N²/2
+	n := NewMatrixDeep2([][]float64{
+		{0, 1, 2},
+		{0, 0, 3},
+		{0, 0, 0},
+	})
+	e := NewMatrix(3, 3)
+	MatExp(e, n)
+	chk.Deep2(tst, "exp(N)", 1e-15, e.GetDeep2(), [][]float64{
+		{1, 1, 3.5},
+		{0, 1, 3},
+		{0, 0, 1},
+	})
+
+	// diagonal
+	d := NewMatrixDeep2([][]float64{
+		{-1, 0, 0, 0},
+		{0, 1e-3, 0, 0},
+		{0, 0, 4, 0},
+		{0, 0, 0, 10},
+	})
+	e = NewMatrix(4, 4)
+	MatExp(e, d)
+	for i, λ := range []float64{-1, 1e-3, 4, 10} {
+		chk.Float64(tst, io.Sf("exp(D)[%d,%d]", i, i), 1e-13*math.Exp(λ), e.Get(i, i), math.Exp(λ))
+	}
+
+	// Jordan block
+	λ := -3.0
+	j := NewMatrixDeep2([][]float64{
+		{λ, 1},
+		{0, λ},
+	})
+	e = NewMatrix(2, 2)
+	MatExp(e, j)
+	chk.Deep2(tst, "exp(J)", 1e-15, e.GetDeep2(), [][]float64{
+		{math.Exp(λ), math.Exp(λ)},
+		{0, math.Exp(λ)},
+	})
+}
+
+func TestMatExp02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatExp02. φ-functions")
+
+	// diagonal (with zero) ⇒ φ₁(λ) = (eᴸ - 1)/λ and φ₂(λ) = (eᴸ - 1 - λ)/λ²
+	a := NewMatrixDeep2([][]float64{
+		{2, 0, 0},
+		{0, -1, 0},
+		{0, 0, 0},
+	})
+	phi := []*Matrix{NewMatrix(3, 3), NewMatrix(3, 3), NewMatrix(3, 3)}
+	MatPhi(phi, a)
+	for i, λ := range []float64{2, -1, 0} {
+		φ0, φ1, φ2 := math.Exp(λ), 1.0, 0.5
+		if λ != 0 {
+			φ1 = (math.Exp(λ) - 1) / λ
+			φ2 = (math.Exp(λ) - 1 - λ) / (λ * λ)
+		}
+		chk.Float64(tst, io.Sf("φ₀(%g)", λ), 1e-14, phi[0].Get(i, i), φ0)
+		chk.Float64(tst, io.Sf("φ₁(%g)", λ), 1e-14, phi[1].Get(i, i), φ1)
+		chk.Float64(tst, io.Sf("φ₂(%g)", λ), 1e-14, phi[2].Get(i, i), φ2)
+	}
+
+	// recurrence: φₖ₊₁(A)⋅A = φₖ(A) - I/k!
+	b := incFactUnsym().ToDense()
+	b.Apply(0.5, b)
+	phi = []*Matrix{NewMatrix(5, 5), NewMatrix(5, 5), NewMatrix(5, 5), NewMatrix(5, 5)}
+	MatPhi(phi, b)
+	fact := 1.0
+	for k := 0; k < 3; k++ {
+		if k > 0 {
+			fact *= float64(k)
+		}
+		lhs := NewMatrix(5, 5)
+		MatMatMul(lhs, 1, phi[k+1], b)
+		rhs := phi[k].GetCopy()
+		for i := 0; i < 5; i++ {
+			rhs.Add(i, i, -1/fact)
+		}
+		chk.Deep2(tst, io.Sf("φ%d⋅A", k+1), 1e-13*rhs.NormInf(), lhs.GetDeep2(), rhs.GetDeep2())
+	}
+}
+
+func TestMatSqrtLog01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatSqrtLog01. square root and logarithm")
+
+	// upper triangular
+	a := NewMatrixDeep2([][]float64{
+		{4, 1},
+		{0, 9},
+	})
+	r := NewMatrix(2, 2)
+	MatSqrt(r, a)
+	chk.Deep2(tst, "sqrt(A)", 1e-15, r.GetDeep2(), [][]float64{
+		{2, 0.2},
+		{0, 3},
+	})
+	l := NewMatrix(2, 2)
+	MatLog(l, a)
+	chk.Deep2(tst, "log(A)", 1e-14, l.GetDeep2(), [][]float64{
+		{math.Log(4), (math.Log(9) - math.Log(4)) / 5},
+		{0, math.Log(9)},
+	})
+
+	// rotation (complex eigenvalues): sqrt(R(θ)) = R(θ/2) and log(R(θ)) = [[0,-θ],[θ,0]]
+	θ := 2.5
+	c, s := math.Cos(θ), math.Sin(θ)
+	rot := NewMatrixDeep2([][]float64{
+		{c, -s},
+		{s, c},
+	})
+	MatSqrt(r, rot)
+	chk.Deep2(tst, "sqrt(R)", 1e-15, r.GetDeep2(), [][]float64{
+		{math.Cos(θ / 2), -math.Sin(θ / 2)},
+		{math.Sin(θ / 2), math.Cos(θ / 2)},
+	})
+	MatLog(l, rot)
+	chk.Deep2(tst, "log(R)", 1e-14, l.GetDeep2(), [][]float64{
+		{0, -θ},
+		{θ, 0},
+	})
+
+	// non-symmetric matrix: sqrt(A)² = A and log(exp(B)) = B
+	b := incFactUnsym().ToDense()
+	r = NewMatrix(5, 5)
+	MatSqrt(r, b)
+	r2 := NewMatrix(5, 5)
+	MatMatMul(r2, 1, r, r)
+	chk.Deep2(tst, "sqrt(A)²", 1e-13, r2.GetDeep2(), b.GetDeep2())
+	b.Apply(0.3, b)
+	e := NewMatrix(5, 5)
+	MatExp(e, b)
+	l = NewMatrix(5, 5)
+	MatLog(l, e)
+	chk.Deep2(tst, "log(exp(B))", 1e-13, l.GetDeep2(), b.GetDeep2())
+
+	// eigenvalue on the negative real axis
+	defer func() {
+		if err := recover(); err != nil {
+			if chk.Verbose {
+				io.Pf("OK, caught the following message:\n\n\t%v\n", err)
+			}
+		} else {
+			tst.Errorf("\n\tTEST FAILED. MatSqrt should have panicked\n")
+		}
+	}()
+	MatSqrt(r, NewMatrixDeep2([][]float64{
+		{-1, 0},
+		{0, 1},
+	}))
+}
+
+func TestSpExpv01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpExpv01. action of the exponential")
+
+	// 1D Laplacian
+	n := 100
+	t := NewTriplet(n, n, 3*n)
+	h2 := float64((n + 1) * (n + 1))
+	for i := 0; i < n; i++ {
+		t.Put(i, i, -2*h2)
+		if i > 0 {
+			t.Put(i, i-1, h2)
+			t.Put(i-1, i, h2)
+		}
+	}
+	a := t.ToMatrix(nil)
+	v := NewVectorMapped(n, func(i int) float64 { return math.Sin(math.Pi*float64(i+1)/float64(n+1)) + 0.1*float64(i%3) })
+
+	// reference: dense exponential
+	for _, τ := range []float64{1e-3, -1e-4} {
+		ad := t.ToDense()
+		ad.Apply(τ, ad)
+		e := NewMatrix(n, n)
+		MatExp(e, ad)
+		wRef := NewVector(n)
+		MatVecMul(wRef, 1, e, v)
+		w := NewVector(n)
+		nsteps, errEst := SpExpv(w, τ, a, v, 20, 1e-10)
+		io.Pforan("τ = %g: nsteps = %d  err = %g\n", τ, nsteps, errEst)
+		chk.Array(tst, io.Sf("exp(%g⋅A)⋅v", τ), 1e-8*wRef.Norm(), w, wRef)
+		if nsteps < 2 {
+			tst.Errorf("time stepping should have been used: nsteps = %d\n", nsteps)
+		}
+	}
+
+	// eigenvector: exp(t⋅A)⋅v = exp(t⋅λ)⋅v
+	λ := -4 * h2 * math.Pow(math.Sin(math.Pi/float64(2*(n+1))), 2)
+	u := NewVectorMapped(n, func(i int) float64 { return math.Sin(math.Pi * float64(i+1) / float64(n+1)) })
+	w := NewVector(n)
+	SpExpv(w, 0.05, a, u, 0, 0)
+	uRef := u.GetCopy()
+	uRef.Apply(math.Exp(0.05*λ), u)
+	chk.Array(tst, "exp(t⋅A)⋅u", 1e-7*uRef.Norm(), w, uRef)
+
+	// small matrix: happy breakdown
+	b := incFactUnsym()
+	e := NewMatrix(5, 5)
+	MatExp(e, b.ToDense())
+	x := []float64{1, -1, 2, 0, 1}
+	wRef := NewVector(5)
+	MatVecMul(wRef, 1, e, x)
+	w = NewVector(5)
+	nsteps, _ := SpExpv(w, 1, b.ToMatrix(nil), x, 0, 0)
+	chk.Int(tst, "nsteps", nsteps, 1)
+	chk.Array(tst, "exp(B)⋅x", 1e-12*wRef.Norm(), w, wRef)
+
+	// large vectors (n > 150): exp(-I)⋅e0 = exp(-1)⋅e0
+	n = 200
+	c := NewTriplet(n, n, n)
+	for i := 0; i < n; i++ {
+		c.Put(i, i, -1)
+	}
+	e0 := NewVector(n)
+	e0[0] = 1
+	w = NewVector(n)
+	SpExpv(w, 1, c.ToMatrix(nil), e0, 0, 0)
+	chk.Float64(tst, "exp(-I)⋅e0 @ 0", 1e-12, w[0], math.Exp(-1))
+	chk.Float64(tst, "|exp(-I)⋅e0|", 1e-12, w.Norm(), math.Exp(-1))
+}
@@ -6,6 +6,7 @@ package la
 
 import (
 	"math"
+	"math/cmplx"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
@@ -497,3 +498,64 @@ func TestCondNum01(tst *testing.T) {
 	chk.Float64(tst, "condI(b) ", 1e-17, cIb, 25.0)
 	chk.Float64(tst, "condF(b) ", 1e-14, cFb, 18.0)
 }
+
+func TestMatInvC01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatInvC01. complex inverse and pseudo-inverse")
+
+	// square
+	a := NewMatrixDeep2c([][]complex128{
+		{1 + 1i, 2},
+		{3, 4 - 1i},
+	})
+	ai := NewMatrixC(2, 2)
+	det := MatInvC(ai, a, true)
+	chk.Complex128(tst, "det(a)", 1e-15, det, -1+3i)
+	chk.Deep2c(tst, "inv(a)", 1e-15, ai.GetDeep2(), [][]complex128{
+		{(4 - 1i) / (-1 + 3i), -2 / (-1 + 3i)},
+		{-3 / (-1 + 3i), (1 + 1i) / (-1 + 3i)},
+	})
+
+	// non-square (full column rank): a⁺⋅a = I and a⋅a⁺⋅a = a
+	b := NewMatrixDeep2c([][]complex128{
+		{1, 1i},
+		{1i, 2},
+		{1 - 1i, 0},
+	})
+	bi := NewMatrixC(2, 3)
+	MatInvC(bi, b, false)
+	bib := NewMatrixC(2, 2)
+	bbib := NewMatrixC(3, 2)
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			for k := 0; k < 3; k++ {
+				bib.Add(i, j, bi.Get(i, k)*b.Get(k, j))
+			}
+		}
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 2; j++ {
+			for k := 0; k < 2; k++ {
+				bbib.Add(i, j, b.Get(i, k)*bib.Get(k, j))
+			}
+		}
+	}
+	chk.Deep2c(tst, "b⁺⋅b", 1e-15, bib.GetDeep2(), [][]complex128{{1, 0}, {0, 1}})
+	chk.Deep2c(tst, "b⋅b⁺⋅b", 1e-15, bbib.GetDeep2(), b.GetDeep2())
+
+	// pseudo-inverse is the conjugate transpose of the inverse of the conjugate transpose
+	bh := NewMatrixC(2, 3)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 2; j++ {
+			bh.Set(j, i, cmplx.Conj(b.Get(i, j)))
+		}
+	}
+	bhi := NewMatrixC(3, 2)
+	MatInvC(bhi, bh, false)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 2; j++ {
+			chk.Complex128(tst, io.Sf("(bᴴ)⁺[%d,%d]", i, j), 1e-15, bhi.Get(i, j), cmplx.Conj(bi.Get(j, i)))
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// opLaplace1d returns a matrix-free operator with the 1D Laplacian: tridiag(-1, 2, -1)
+func opLaplace1d(n int) *OpFunc {
+	fcn := func(y, x Vector) {
+		for i := 0; i < n; i++ {
+			y[i] = 2 * x[i]
+			if i > 0 {
+				y[i] -= x[i-1]
+			}
+			if i < n-1 {
+				y[i] -= x[i+1]
+			}
+		}
+	}
+	return NewOpFunc(n, n, fcn, fcn)
+}
+
+// opSaddleB returns a (full rank) m × (4m+4) constraint matrix for saddle-point problems
+func opSaddleB(m int) *Triplet {
+	t := NewTriplet(m, 4*m+4, 3*m)
+	for i := 0; i < m; i++ {
+		t.Put(i, 4*i, 1)
+		t.Put(i, 4*i+1, -1)
+		t.Put(i, 4*i+2, 0.5)
+	}
+	return t
+}
+
+func TestOperator01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Operator01. adapters")
+
+	t := incFactUnsym()
+	a := t.ToDense()
+	x := []float64{1, -2, 3, 0.5, -1}
+	y := NewVector(5)
+	yRef := NewVector(5)
+	for _, op := range []LinearOperator{
+		NewOpMatrix(a),
+		NewOpCCMatrix(t.ToMatrix(nil)),
+		NewOpCSRMatrix(t.ToCSR(nil)),
+		NewOpTranspose(NewOpTranspose(NewOpMatrix(a))),
+	} {
+		m, n := op.Size()
+		chk.Int(tst, "m", m, 5)
+		chk.Int(tst, "n", n, 5)
+		chk.Deep2(tst, "OpToMatrix", 1e-15, OpToMatrix(op).GetDeep2(), a.GetDeep2())
+		op.Apply(y, x)
+		MatVecMul(yRef, 1, a, x)
+		chk.Array(tst, "A⋅x", 1e-15, y, yRef)
+		op.ApplyTranspose(y, x)
+		MatTrVecMul(yRef, 1, a, x)
+		chk.Array(tst, "Aᵀ⋅x", 1e-15, y, yRef)
+	}
+
+	// closure
+	op := opLaplace1d(4)
+	chk.Deep2(tst, "Laplacian", 1e-15, OpToMatrix(op).GetDeep2(), [][]float64{
+		{2, -1, 0, 0},
+		{-1, 2, -1, 0},
+		{0, -1, 2, -1},
+		{0, 0, -1, 2},
+	})
+	chk.Deep2(tst, "Laplacianᵀ", 1e-15, OpToMatrix(NewOpTranspose(op)).GetDeep2(), OpToMatrix(op).GetDeep2())
+}
+
+func TestOperator02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Operator02. block operators")
+
+	// blocks
+	A := NewMatrixDeep2([][]float64{
+		{4, 1, 0},
+		{1, 3, 1},
+		{0, 1, 2},
+	})
+	B := NewMatrixDeep2([][]float64{
+		{1, 2, 3},
+		{0, -1, 1},
+	})
+	C := NewMatrixDeep2([][]float64{
+		{-1, 0},
+		{0, -2},
+	})
+
+	// saddle-point operator
+	K := NewOpSaddlePoint(NewOpMatrix(A), NewOpMatrix(B), NewOpMatrix(C))
+	m, n := K.Size()
+	chk.Int(tst, "m", m, 5)
+	chk.Int(tst, "n", n, 5)
+	chk.Ints(tst, "Rows", K.Rows, []int{0, 3, 5})
+	chk.Ints(tst, "Cols", K.Cols, []int{0, 3, 5})
+	kRef := [][]float64{
+		{4, 1, 0, 1, 0},
+		{1, 3, 1, 2, -1},
+		{0, 1, 2, 3, 1},
+		{1, 2, 3, -1, 0},
+		{0, -1, 1, 0, -2},
+	}
+	chk.Deep2(tst, "K", 1e-15, OpToMatrix(K).GetDeep2(), kRef)
+	chk.Deep2(tst, "Kᵀ", 1e-15, OpToMatrix(NewOpTranspose(K)).GetDeep2(), kRef)
+
+	// zero block
+	K = NewOpSaddlePoint(NewOpMatrix(A), NewOpMatrix(B), nil)
+	kRef[3][3], kRef[4][4] = 0, 0
+	chk.Deep2(tst, "K(C=0)", 1e-15, OpToMatrix(K).GetDeep2(), kRef)
+
+	// rectangular blocks: [B  B] and its transpose
+	tb := NewTriplet(2, 3, 6)
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 3; j++ {
+			tb.Put(i, j, B.Get(i, j))
+		}
+	}
+	R := NewOpBlock([][]LinearOperator{{NewOpMatrix(B), NewOpCCMatrix(tb.ToMatrix(nil))}})
+	m, n = R.Size()
+	chk.Int(tst, "m", m, 2)
+	chk.Int(tst, "n", n, 6)
+	chk.Deep2(tst, "R", 1e-15, OpToMatrix(R).GetDeep2(), [][]float64{
+		{1, 2, 3, 1, 2, 3},
+		{0, -1, 1, 0, -1, 1},
+	})
+	chk.Deep2(tst, "Rᵀ", 1e-15, OpToMatrix(NewOpTranspose(R)).GetDeep2(), [][]float64{
+		{1, 0},
+		{2, -1},
+		{3, 1},
+		{1, 0},
+		{2, -1},
+		{3, 1},
+	})
+
+	// incompatible blocks
+	defer func() {
+		if err := recover(); err != nil {
+			if chk.Verbose {
+				io.Pf("OK, caught the following message:\n\n\t%v\n", err)
+			}
+		} else {
+			tst.Errorf("\n\tTEST FAILED. NewOpBlock should have panicked\n")
+		}
+	}()
+	NewOpBlock([][]LinearOperator{{NewOpMatrix(A), NewOpMatrix(B)}})
+}
+
+func TestOperator03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Operator03. Krylov solvers with operators")
+
+	// matrix-free CG
+	n := 50
+	lis := NewKrylov("cg")
+	lis.InitOp(opLaplace1d(n), false)
+	lis.Fact()
+	b := NewVectorMapped(n, func(i int) float64 { return 1 + float64(i%5) })
+	x := NewVector(n)
+	lis.Solve(x, b, false)
+	io.Pforan("cg: nit = %d\n", lis.NumIt)
+	TestSolverResidual(tst, OpToMatrix(opLaplace1d(n)), x, b, 1e-7)
+
+	// matrix-free CG with a user-defined preconditioner
+	lis = NewKrylov("cg")
+	lis.Prec = new(krylovPrecScale)
+	lis.InitOp(opLaplace1d(n), false)
+	lis.Fact()
+	x.Fill(0)
+	lis.Solve(x, b, false)
+	io.Pforan("cg (prec = scale): nit = %d\n", lis.NumIt)
+	TestSolverResidual(tst, OpToMatrix(opLaplace1d(n)), x, b, 1e-7)
+
+	// saddle-point problem with GMRES
+	A := krylovPoisson2d(6).ToMatrix(nil)
+	B := opSaddleB(8).ToMatrix(nil)
+	K := NewOpSaddlePoint(NewOpCCMatrix(A), NewOpCCMatrix(B), nil)
+	nk, _ := K.Size()
+	chk.Int(tst, "size of K", nk, 44)
+	b = NewVectorMapped(nk, func(i int) float64 { return math.Sin(float64(i + 1)) })
+	nit := make([]int, 2)
+	for k, withPrec := range []bool{false, true} {
+		lis = NewKrylov("gmres")
+		lis.Tol = 1e-10
+		if withPrec {
+			// block-diagonal preconditioner with A⁻¹ and S⁻¹, where S = B⋅A⁻¹⋅Bᵀ. Thus, GMRES
+			// converges in (at most) three iterations
+			Ai, Bd := NewMatrix(36, 36), B.ToDense()
+			MatInv(Ai, A.ToDense(), false)
+			AiBt, S, Si := NewMatrix(36, 8), NewMatrix(8, 8), NewMatrix(8, 8)
+			MatMatTrMul(AiBt, 1, Ai, Bd)
+			MatMatMul(S, 1, Bd, AiBt)
+			MatInv(Si, S, false)
+			P := NewOpBlock([][]LinearOperator{
+				{NewOpMatrix(Ai), nil},
+				{nil, NewOpMatrix(Si)},
+			})
+			lis.Prec = &PrecOp{P}
+		}
+		lis.InitOp(K, false)
+		lis.Fact()
+		x = NewVector(nk)
+		lis.Solve(x, b, false)
+		nit[k] = lis.NumIt
+		io.Pforan("gmres (prec = %v): nit = %d\n", withPrec, lis.NumIt)
+		TestSolverResidual(tst, OpToMatrix(K), x, b, 1e-8)
+	}
+	if nit[1] > 3 || nit[0] < 10 {
+		tst.Errorf("block preconditioner should give convergence in three iterations: nit = %v\n", nit)
+	}
+
+	// named preconditioners need a matrix
+	defer func() {
+		if err := recover(); err != nil {
+			if chk.Verbose {
+				io.Pf("OK, caught the following message:\n\n\t%v\n", err)
+			}
+		} else {
+			tst.Errorf("\n\tTEST FAILED. Fact should have panicked\n")
+		}
+	}()
+	lis = NewKrylov("gmres")
+	lis.Precond = "jacobi"
+	lis.InitOp(K, false)
+	lis.Fact()
+}
+
+func TestOperator04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Operator04. SparseEigen with operators")
+
+	// generalised problem: smallest, nearest and largest
+	n := 60
+	K, M := spEigenBar(n)
+	nev := 4
+	for _, which := range []string{"smallest", "nearest", "largest"} {
+		λ := NewVector(nev)
+		X := NewMatrix(n, nev)
+		o := NewSparseEigen()
+		o.Which = which
+		if which == "nearest" {
+			o.Shift = 100
+			o.InnerKind = "bicgstab" // K - σ⋅M is indefinite
+		}
+		o.SolveOp(λ, X, NewOpCCMatrix(K), NewOpCCMatrix(M))
+		λRef := NewVector(nev)
+		o.Solve(λRef, nil, K, M)
+		io.Pforan("%s: λ = %v\n", which, λ)
+		chk.Array(tst, which+": λ", 1e-8*math.Abs(λRef[nev-1]), λ, λRef)
+		spEigenCheck(tst, λ, X, K, M, 1e-7)
+	}
+
+	// matrix-free standard problem: λⱼ = 2 - 2⋅cos(j⋅π/(n+1))
+	λ := NewVector(nev)
+	o := NewSparseEigen()
+	o.SolveOp(λ, nil, opLaplace1d(n), nil)
+	λRef := NewVectorMapped(nev, func(i int) float64 { return 2 - 2*math.Cos(float64(i+1)*math.Pi/float64(n+1)) })
+	chk.Array(tst, "λ(Laplacian)", 1e-10, λ, λRef)
+}
@@ -0,0 +1,179 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func checkQR(tst *testing.T, A *Matrix, tol float64) (o *QR) {
+
+	// decomposition
+	o = NewQR(A)
+	Q := NewMatrix(o.M, o.K)
+	R := NewMatrix(o.K, o.N)
+	o.GetQ(Q)
+	o.GetR(R)
+	io.Pforan("Perm = %v\n", o.Perm)
+
+	// check Qᵀ⋅Q = I
+	QtQ := NewMatrix(o.K, o.K)
+	MatTrMatMul(QtQ, 1, Q, Q)
+	I := NewMatrix(o.K, o.K)
+	I.SetDiag(1)
+	chk.Deep2(tst, "Qᵀ⋅Q", tol, QtQ.GetDeep2(), I.GetDeep2())
+
+	// check Q⋅R = A⋅P
+	QR := NewMatrix(o.M, o.N)
+	MatMatMul(QR, 1, Q, R)
+	AP := NewMatrix(o.M, o.N)
+	for j := 0; j < o.N; j++ {
+		for i := 0; i < o.M; i++ {
+			AP.Set(i, j, A.Get(i, o.Perm[j]))
+		}
+	}
+	chk.Deep2(tst, "Q⋅R", tol, QR.GetDeep2(), AP.GetDeep2())
+	return
+}
+
+func TestQR01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("QR01. full rank")
+
+	// tall matrix
+	A := NewMatrixDeep2([][]float64{
+		{12, -51, 4},
+		{6, 167, -68},
+		{-4, 24, -41},
+		{-1, 1, 0},
+		{2, 0, 3},
+	})
+	o := checkQR(tst, A, 1e-13)
+	chk.Int(tst, "rank", o.Rank(0), 3)
+	chk.Ints(tst, "Perm", o.Perm, []int{1, 2, 0})
+
+	// wide matrix
+	B := A.GetTranspose()
+	o = checkQR(tst, B, 1e-13)
+	chk.Int(tst, "rank", o.Rank(0), 3)
+}
+
+func TestQR02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("QR02. rank deficient")
+
+	// last column = 2 ⋅ first column + second column
+	A := NewMatrixDeep2([][]float64{
+		{1, 2, 0, 4},
+		{4, 5, 1, 13},
+		{7, 8, 0, 22},
+		{1, 0, 2, 2},
+		{2, 1, 1, 5},
+	})
+	o := checkQR(tst, A, 1e-13)
+	chk.Int(tst, "rank", o.Rank(0), 3)
+	chk.Ints(tst, "Perm", o.Perm, []int{3, 2, 1, 0})
+}
+
+func TestLeastSquares01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("LeastSquares01. overdetermined")
+
+	// fitting y = c0 + c1⋅x + c2⋅x²
+	X := []float64{0, 1, 2, 3, 4, 5}
+	Y := []float64{1.1, 1.9, 5.2, 9.8, 17.1, 26.0}
+	A := NewMatrix(len(X), 3)
+	for i, x := range X {
+		A.Set(i, 0, 1)
+		A.Set(i, 1, x)
+		A.Set(i, 2, x*x)
+	}
+	c := NewVector(3)
+	rank := LeastSquares(c, A, Y)
+	chk.Int(tst, "rank", rank, 3)
+
+	// compare with the solution of the normal equations: Aᵀ⋅A⋅c = Aᵀ⋅y
+	AtA := NewMatrix(3, 3)
+	MatTrMatMul(AtA, 1, A, A)
+	Aty := NewVector(3)
+	MatTrVecMul(Aty, 1, A, Y)
+	cNormal := NewVector(3)
+	DenSolve(cNormal, AtA, Aty, true)
+	io.Pforan("c = %v\n", c)
+	chk.Array(tst, "c", 1e-13, c, cNormal)
+
+	// square and non-singular system
+	B := NewMatrixDeep2([][]float64{
+		{2, 1, 1, 3, 2},
+		{1, 2, 2, 1, 1},
+		{1, 2, 9, 1, 5},
+		{3, 1, 1, 7, 1},
+		{2, 1, 5, 1, 8},
+	})
+	b := []float64{-2, 4, 3, -5, 1}
+	x := NewVector(5)
+	rank = LeastSquares(x, B, b)
+	chk.Int(tst, "rank", rank, 5)
+	chk.Array(tst, "x = inv(a) * b", 1e-13, x, []float64{
+		-629.0 / 98.0,
+		+237.0 / 49.0,
+		-53.0 / 49.0,
+		+62.0 / 49.0,
+		+23.0 / 14.0,
+	})
+}
+
+func TestLeastSquares02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("LeastSquares02. minimum-norm")
+
+	// underdetermined: x = Aᵀ⋅inv(A⋅Aᵀ)⋅b
+	A := NewMatrixDeep2([][]float64{
+		{1, 0, 1},
+		{0, 1, 1},
+	})
+	x := NewVector(3)
+	rank := LeastSquares(x, A, []float64{1, 1})
+	chk.Int(tst, "rank", rank, 2)
+	chk.Array(tst, "x", 1e-15, x, []float64{1.0 / 3.0, 1.0 / 3.0, 2.0 / 3.0})
+
+	// single equation
+	A = NewMatrixDeep2([][]float64{{1, 1, 1}})
+	rank = LeastSquares(x, A, []float64{3})
+	chk.Int(tst, "rank", rank, 1)
+	chk.Array(tst, "x", 1e-15, x, []float64{1, 1, 1})
+
+	// rank deficient: compare with the pseudo-inverse
+	A = NewMatrixDeep2([][]float64{
+		{1, 2, 0, 4},
+		{4, 5, 1, 13},
+		{7, 8, 0, 22},
+		{1, 0, 2, 2},
+		{2, 1, 1, 5},
+	})
+	b := []float64{1, 2, 3, 4, 5}
+	x = NewVector(4)
+	rank = LeastSquares(x, A, b)
+	chk.Int(tst, "rank", rank, 3)
+	Ai := NewMatrix(4, 5)
+	MatInv(Ai, A, false)
+	xPinv := NewVector(4)
+	MatVecMul(xPinv, 1, Ai, b)
+	io.Pforan("x = %v\n", x)
+	chk.Array(tst, "x", 1e-13, x, xPinv)
+
+	// zero matrix
+	A = NewMatrix(3, 2)
+	rank = LeastSquares(x[:2], A, []float64{1, 2, 3})
+	chk.Int(tst, "rank", rank, 0)
+	chk.Array(tst, "x", 1e-15, x[:2], nil)
+}
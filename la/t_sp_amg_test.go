@@ -0,0 +1,107 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// amgPoisson3d returns the matrix of the 3D Poisson equation discretised with the 7-point stencil
+func amgPoisson3d(nx int) (t *Triplet) {
+	n := nx * nx * nx
+	t = NewTriplet(n, n, 7*n)
+	for i := 0; i < nx; i++ {
+		for j := 0; j < nx; j++ {
+			for k := 0; k < nx; k++ {
+				r := i + j*nx + k*nx*nx
+				t.Put(r, r, 6)
+				for _, d := range [][]int{{i, 1}, {j, nx}, {k, nx * nx}} {
+					if d[0] > 0 {
+						t.Put(r, r-d[1], -1)
+					}
+					if d[0] < nx-1 {
+						t.Put(r, r+d[1], -1)
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+func TestAmg01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Amg01. standalone solver: methods, smoothers and cycles")
+
+	t := krylovPoisson2d(40)
+	b := NewVectorMapped(t.m, func(i int) float64 { return 1 + math.Sin(float64(i)) })
+	ad := t.ToDense()
+	for _, method := range []string{"sa", "rs"} {
+		for _, smoother := range []string{"gs", "jacobi", "chebyshev"} {
+			for _, cycle := range []string{"V", "W"} {
+				o := NewSparseSolver("amg").(*Amg)
+				o.Method, o.Smoother, o.Cycle = method, smoother, cycle
+				o.Init(t, true, false, "", "", nil)
+				o.Fact()
+				x := NewVector(t.m)
+				o.Solve(x, b, false)
+				io.Pforan("%s %-9s %s: levels = %d  nit = %2d\n", method, smoother, cycle, o.NumLevels(), o.NumIt)
+				TestSolverResidual(tst, ad, x, b, 1e-7*b.Norm())
+				if o.NumLevels() < 3 {
+					tst.Errorf("%s: hierarchy should have at least 3 levels\n", method)
+				}
+				if o.NumIt > 40 {
+					tst.Errorf("%s %s %s: too many cycles: %d\n", method, smoother, cycle, o.NumIt)
+				}
+				if cycle == "V" && smoother == "gs" {
+					io.Pf("%s", o.Summary())
+					oc, gc := o.Complexity()
+					if oc < 1 || oc > 3 || gc < 1 || gc > 2 {
+						tst.Errorf("%s: complexities are invalid: operator = %g, grid = %g\n", method, oc, gc)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAmg02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Amg02. preconditioner for CG")
+
+	t := amgPoisson3d(16)
+	a := t.ToMatrix(nil)
+	b := NewVectorMapped(t.m, func(i int) float64 { return 1 + math.Cos(float64(i)) })
+	nit := make(map[string]int)
+	for _, prec := range []string{"ic0", "amg", "rs"} {
+		o := NewKrylov("cg")
+		o.Tol = 1e-10
+		if prec == "rs" {
+			p := NewPrecAmg()
+			p.Method = "rs"
+			o.Prec = p
+		} else {
+			o.Precond = prec
+		}
+		o.Init(t, true, false, "", "", nil)
+		o.Fact()
+		x := NewVector(t.m)
+		o.Solve(x, b, false)
+		nit[prec] = o.NumIt
+		io.Pforan("cg + %-3s: nit = %d\n", prec, o.NumIt)
+		r := b.GetCopy()
+		SpMatVecMulAdd(r, -1, a, x)
+		chk.Float64(tst, "‖b - A⋅x‖/‖b‖", 1e-10, r.Norm()/b.Norm(), 0)
+	}
+	if nit["amg"] >= nit["ic0"] || nit["rs"] >= nit["ic0"] {
+		tst.Errorf("AMG should reduce the number of iterations: %v\n", nit)
+	}
+}
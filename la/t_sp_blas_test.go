@@ -547,3 +547,115 @@ func TestSpBlas11(tst *testing.T) {
 	SpMatMatTrMul(b4, 1, a4)
 	chk.Deep2(tst, "b4", 1e-17, b4.GetDeep2(), [][]float64{{5}})
 }
+
+func TestSpBlas12(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpBlas12: SpMatMatMul, SpTranspose and SpPtAP")
+
+	// a (4x3) and b (3x5)
+	a := incFactUnsym()
+	ta := NewTriplet(4, 3, 7)
+	ta.Put(0, 0, 1)
+	ta.Put(0, 2, -2)
+	ta.Put(1, 1, 3)
+	ta.Put(2, 0, 4)
+	ta.Put(2, 1, 1)
+	ta.Put(3, 2, 5)
+	ta.Put(3, 2, 1) // repeated
+	tb := NewTriplet(3, 5, 6)
+	tb.Put(0, 0, 2)
+	tb.Put(0, 4, 1)
+	tb.Put(1, 1, -1)
+	tb.Put(2, 0, 1)
+	tb.Put(2, 3, 3)
+	tb.Put(1, 4, 2)
+	A, B := ta.ToMatrix(nil), tb.ToMatrix(nil)
+	cRef := NewMatrix(4, 5)
+	MatMatMul(cRef, 2, A.ToDense(), B.ToDense())
+	C := SpMatMatMul(2, A, B)
+	chk.Int(tst, "c.m", C.m, 4)
+	chk.Int(tst, "c.n", C.n, 5)
+	chk.Deep2(tst, "c := 2⋅a⋅b", 1e-15, C.ToDense().GetDeep2(), cRef.GetDeep2())
+	for j := 0; j < C.n; j++ {
+		for k := C.p[j] + 1; k < C.p[j+1]; k++ {
+			if C.i[k] <= C.i[k-1] {
+				tst.Errorf("row indices of c must be sorted\n")
+			}
+		}
+	}
+
+	// transpose
+	At := SpTranspose(A)
+	chk.Deep2(tst, "aᵀ", 1e-15, At.ToDense().GetDeep2(), A.ToDense().GetTranspose().GetDeep2())
+	chk.Deep2(tst, "(aᵀ)ᵀ", 1e-15, SpTranspose(At).ToDense().GetDeep2(), A.ToDense().GetDeep2())
+
+	// Galerkin product with piecewise-constant prolongation
+	K := a.ToMatrix(nil)
+	tp := NewTriplet(5, 2, 5)
+	for i := 0; i < 5; i++ {
+		tp.Put(i, i/3, 1)
+	}
+	P := tp.ToMatrix(nil)
+	kp, pRef := NewMatrix(5, 2), NewMatrix(2, 2)
+	MatMatMul(kp, 1, K.ToDense(), P.ToDense())
+	MatTrMatMul(pRef, 1, P.ToDense(), kp)
+	chk.Deep2(tst, "pᵀ⋅k⋅p", 1e-14, SpPtAP(K, P).ToDense().GetDeep2(), pRef.GetDeep2())
+
+	// incompatible
+	defer func() {
+		if err := recover(); err != nil {
+			if chk.Verbose {
+				io.Pf("OK, caught the following message:\n\n\t%v\n", err)
+			}
+		} else {
+			tst.Errorf("\n\tTEST FAILED. SpMatMatMul should have panicked\n")
+		}
+	}()
+	SpMatMatMul(1, A, A)
+}
+
+func TestSpBlas13(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpBlas13: SpMatDenseMul, SpGetRow/Col and SpSubMatrix")
+
+	a := incFactUnsym().ToMatrix(nil)
+	ad := a.ToDense()
+
+	// sparse × dense
+	b := NewMatrixDeep2([][]float64{
+		{1, 2},
+		{-1, 0},
+		{3, 1},
+		{0, 2},
+		{2, -2},
+	})
+	c, cRef := NewMatrix(5, 2), NewMatrix(5, 2)
+	SpMatDenseMul(c, 0.5, a, b)
+	MatMatMul(cRef, 0.5, ad, b)
+	chk.Deep2(tst, "a⋅b", 1e-15, c.GetDeep2(), cRef.GetDeep2())
+	SpMatTrDenseMul(c, 0.5, a, b)
+	MatTrMatMul(cRef, 0.5, ad, b)
+	chk.Deep2(tst, "aᵀ⋅b", 1e-15, c.GetDeep2(), cRef.GetDeep2())
+
+	// rows and columns
+	for k := 0; k < 5; k++ {
+		chk.Array(tst, io.Sf("row %d", k), 1e-15, SpGetRow(a, k), ad.GetRow(k))
+		chk.Array(tst, io.Sf("col %d", k), 1e-15, SpGetCol(a, k), ad.GetCol(k))
+	}
+
+	// sub-matrices
+	rows, cols := []int{4, 0, 2}, []int{1, 3}
+	s := SpSubMatrix(a, rows, cols)
+	sRef := make([][]float64, len(rows))
+	for I, i := range rows {
+		sRef[I] = make([]float64, len(cols))
+		for J, j := range cols {
+			sRef[I][J] = ad.Get(i, j)
+		}
+	}
+	chk.Deep2(tst, "a[rows,cols]", 1e-15, s.ToDense().GetDeep2(), sRef)
+	chk.Deep2(tst, "a[:,:]", 1e-15, SpSubMatrix(a, nil, nil).ToDense().GetDeep2(), ad.GetDeep2())
+	chk.Deep2(tst, "a[0:2,:]", 1e-15, SpSubMatrix(a, []int{0, 1}, nil).ToDense().GetDeep2(), ad.GetDeep2()[:2])
+}
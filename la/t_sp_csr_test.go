@@ -0,0 +1,280 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// csrConvDiff2d returns the (non-symmetric) triplet corresponding to a convection-diffusion
+// operator on a nx x nx grid with five-point stencil
+func csrConvDiff2d(nx int) (t *Triplet) {
+	n := nx * nx
+	t = NewTriplet(n, n, 5*n)
+	for i := 0; i < nx; i++ {
+		for j := 0; j < nx; j++ {
+			r := i*nx + j
+			t.Put(r, r, 4)
+			if i > 0 {
+				t.Put(r, r-nx, -1.25)
+			}
+			if i < nx-1 {
+				t.Put(r, r+nx, -0.75)
+			}
+			if j > 0 {
+				t.Put(r, r-1, -1.1)
+			}
+			if j < nx-1 {
+				t.Put(r, r+1, -0.9)
+			}
+		}
+	}
+	return
+}
+
+func TestSpCsr01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpCsr01. conversions")
+
+	// triplet with repeated entries
+	var t Triplet
+	t.Init(3, 4, 10)
+	t.Put(2, 3, 1.0)
+	t.Put(0, 0, 5.0)
+	t.Put(0, 2, 12.0)
+	t.Put(0, 0, 5.0)
+	t.Put(1, 1, 21.0)
+	t.Put(2, 0, 30.0)
+	t.Put(1, 0, 20.0)
+	t.Put(2, 2, 32.0)
+	t.Put(2, 3, 2.0)
+	t.Put(0, 1, 11.0)
+	dense := [][]float64{
+		{10, 11, 12, 0},
+		{20, 21, 0, 0},
+		{30, 0, 32, 3},
+	}
+
+	// from triplet
+	a := t.ToCSR(nil)
+	io.Pf("a = %+v\n", a)
+	chk.Int(tst, "a.nnz", a.nnz, 8)
+	chk.Ints(tst, "a.p", a.p, []int{0, 3, 5, 8})
+	chk.Ints(tst, "a.j", a.j, []int{0, 1, 2, 0, 1, 0, 2, 3})
+	chk.Array(tst, "a.x", 1e-17, a.x, []float64{10, 11, 12, 20, 21, 30, 32, 3})
+	chk.Deep2(tst, "a", 1e-17, a.ToDense().GetDeep2(), dense)
+
+	// again, with the same structure
+	t.Start()
+	t.Put(0, 0, 1)
+	t.Put(0, 1, 1)
+	t.Put(0, 2, 1)
+	t.Put(1, 0, 1)
+	t.Put(1, 1, 1)
+	t.Put(2, 0, 1)
+	t.Put(2, 2, 1)
+	t.Put(2, 3, 1)
+	t.Put(2, 3, 1)
+	a2 := t.ToCSR(a)
+	if a2 != a {
+		tst.Errorf("ToCSR must return the given matrix\n")
+	}
+	chk.Array(tst, "a.x", 1e-17, a.x, []float64{1, 1, 1, 1, 1, 1, 1, 2})
+
+	// from column-compressed matrix and back
+	var s Triplet
+	s.Init(3, 4, 8)
+	for i, row := range dense {
+		for j, v := range row {
+			if v != 0 {
+				s.Put(i, j, v)
+			}
+		}
+	}
+	cc := s.ToMatrix(nil)
+	b := cc.ToCSR(nil)
+	chk.Ints(tst, "b.p", b.p, []int{0, 3, 5, 8})
+	chk.Ints(tst, "b.j", b.j, []int{0, 1, 2, 0, 1, 0, 2, 3})
+	chk.Deep2(tst, "b", 1e-17, b.ToDense().GetDeep2(), dense)
+	c := b.ToCC(nil)
+	chk.Ints(tst, "c.p", c.p, cc.p)
+	chk.Ints(tst, "c.i", c.i, cc.i)
+	chk.Array(tst, "c.x", 1e-17, c.x, cc.x)
+
+	// set directly
+	var d CSRMatrix
+	d.Set(3, 4, []int{0, 3, 5, 8}, []int{0, 1, 2, 0, 1, 0, 2, 3}, []float64{10, 11, 12, 20, 21, 30, 32, 3})
+	chk.Deep2(tst, "d", 1e-17, d.ToDense().GetDeep2(), dense)
+}
+
+func TestSpCsr02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpCsr02. matrix vector multiplication")
+
+	var t Triplet
+	t.Init(3, 5, 15)
+	t.Put(0, 0, 1)
+	t.Put(0, 1, 2)
+	t.Put(0, 2, 3)
+	t.Put(0, 3, 4)
+	t.Put(0, 4, 5)
+	t.Put(1, 0, 0.1)
+	t.Put(1, 1, 0.2)
+	t.Put(1, 2, 0.3)
+	t.Put(1, 3, 0.4)
+	t.Put(1, 4, 0.5)
+	t.Put(2, 0, 10)
+	t.Put(2, 1, 20)
+	t.Put(2, 2, 30)
+	t.Put(2, 3, 40)
+	t.Put(2, 4, 50)
+
+	a := t.ToCSR(nil)
+	u := []float64{0.1, 0.2, 0.3, 0.4, 0.5}
+	w := []float64{10.0, 20.0, 30.0}
+	r := []float64{1000, 1000, 1000, 1000, 1000}
+	s := []float64{1000, 1000, 1000}
+	x := []float64{1000, 2000, 3000}
+	W := []float64{1.0, 2.0, 3.0, 4.0, 5.0}
+
+	p := make([]float64, 3)
+	SpCsrMatVecMul(p, 1, a, u) // p := 1*a*u
+	chk.Array(tst, "p = a*u", 1e-15, p, []float64{5.5, 0.55, 55})
+
+	SpCsrMatVecMulAdd(s, 1, a, u) // s += dot(a, u)
+	chk.Array(tst, "s += a*u", 1e-12, s, []float64{1005.5, 1000.55, 1055})
+
+	SpCsrMatVecMulAddX(x, a, 2, u, 3, W) // x += a * (2*u + 3*W)
+	chk.Array(tst, "x += a * (2*u + 3*W)", 1e-12, x, []float64{1176, 2017.6, 4760})
+
+	q := make([]float64, 5)
+	SpCsrMatTrVecMul(q, 1, a, w) // q = dot(transpose(a), w)
+	chk.Array(tst, "q = trans(a)*w", 1e-17, q, []float64{312, 624, 936, 1248, 1560})
+
+	SpCsrMatTrVecMulAdd(r, 1, a, w) // r += dot(transpose(a), w)
+	chk.Array(tst, "r += trans(a)*w", 1e-17, r, []float64{1312, 1624, 1936, 2248, 2560})
+}
+
+func TestSpCsr03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpCsr03. parallel matrix vector multiplication")
+
+	// matrices
+	t := csrConvDiff2d(60)
+	a := t.ToMatrix(nil)
+	b := t.ToCSR(nil)
+	n := a.m
+	u := NewVectorMapped(n, func(i int) float64 { return float64(i%7) - 3.5 })
+	w := NewVectorMapped(n, func(i int) float64 { return float64(i%5) * 0.1 })
+
+	// run with several numbers of goroutines
+	for _, nthreads := range []int{1, 3, 4, 0} {
+		b.SetNumThreads(nthreads)
+		io.Pforan("nthreads = %d => number of blocks = %d\n", nthreads, len(b.part)-1)
+		if nthreads > 1 && len(b.part)-1 != nthreads {
+			tst.Errorf("number of blocks must be equal to %d\n", nthreads)
+		}
+
+		// a * u
+		vCC, vCSR := NewVector(n), NewVector(n)
+		SpMatVecMul(vCC, 0.5, a, u)
+		SpCsrMatVecMul(vCSR, 0.5, b, u)
+		chk.Array(tst, io.Sf("a*u (%d)", nthreads), 1e-14, vCSR, vCC)
+
+		// v += a * u
+		SpMatVecMulAdd(vCC, 2, a, w)
+		SpCsrMatVecMulAdd(vCSR, 2, b, w)
+		chk.Array(tst, io.Sf("v += a*w (%d)", nthreads), 1e-14, vCSR, vCC)
+
+		// v += a * (α*u + β*w)
+		SpMatVecMulAddX(vCC, a, 2, u, 3, w)
+		SpCsrMatVecMulAddX(vCSR, b, 2, u, 3, w)
+		chk.Array(tst, io.Sf("v += a*(2u+3w) (%d)", nthreads), 1e-13, vCSR, vCC)
+
+		// transp(a) * u
+		SpMatTrVecMul(vCC, 0.5, a, u)
+		SpCsrMatTrVecMul(vCSR, 0.5, b, u)
+		chk.Array(tst, io.Sf("trans(a)*u (%d)", nthreads), 1e-14, vCSR, vCC)
+
+		// v += transp(a) * w
+		SpMatTrVecMulAdd(vCC, 2, a, w)
+		SpCsrMatTrVecMulAdd(vCSR, 2, b, w)
+		chk.Array(tst, io.Sf("v += trans(a)*w (%d)", nthreads), 1e-14, vCSR, vCC)
+	}
+}
+
+func TestSpCsr04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpCsr04. matrix addition and scaling")
+
+	var ta Triplet
+	ta.Init(3, 3, 4)
+	ta.Put(0, 0, 1.0)
+	ta.Put(1, 0, 2.0)
+	ta.Put(2, 1, 3.0)
+	ta.Put(2, 2, 4.0)
+	a := ta.ToCSR(nil)
+	chk.Ints(tst, "a.j", a.j, []int{0, 0, 1, 2})
+	chk.Ints(tst, "a.p", a.p, []int{0, 1, 2, 4})
+
+	var tb Triplet
+	tb.Init(3, 3, 4)
+	tb.Put(1, 0, 3.0)
+	tb.Put(0, 1, 2.0)
+	tb.Put(1, 2, 1.0)
+	tb.Put(2, 2, 1.0)
+	b := tb.ToCSR(nil)
+	chk.Ints(tst, "b.j", b.j, []int{1, 0, 2, 2})
+	chk.Ints(tst, "b.p", b.p, []int{0, 1, 3, 4})
+
+	// c := a + b
+	c, a2c, b2c := SpCsrAllocMatAddMat(a, b)
+	SpCsrMatAddMat(c, 1, a, 1, b, a2c, b2c)
+	io.Pf("c = %+v\n", c)
+	chk.Array(tst, "c.x", 1e-17, c.x, []float64{1, 2, 5, 1, 3, 5})
+	chk.Ints(tst, "c.j", c.j, []int{0, 1, 0, 2, 1, 2})
+	chk.Ints(tst, "c.p", c.p, []int{0, 2, 4, 6})
+	chk.Ints(tst, "a2c", a2c, []int{0, 2, 4, 5})
+	chk.Ints(tst, "b2c", b2c, []int{1, 2, 3, 5})
+	chk.Deep2(tst, "c", 1e-17, c.ToDense().GetDeep2(), [][]float64{{1, 2, 0}, {5, 0, 1}, {0, 3, 5}})
+
+	// c := 2*a - b
+	SpCsrMatAddMat(c, 2, a, -1, b, a2c, b2c)
+	chk.Deep2(tst, "c", 1e-17, c.ToDense().GetDeep2(), [][]float64{{2, -2, 0}, {1, 0, -1}, {0, 6, 7}})
+
+	// diagonal
+	if SpCsrCheckDiag(c) {
+		tst.Errorf("c does not have all diagonal entries\n")
+	}
+	var td Triplet
+	td.Init(3, 3, 6)
+	td.Put(0, 0, 1.0)
+	td.Put(0, 1, 2.0)
+	td.Put(1, 1, 3.0)
+	td.Put(2, 0, 4.0)
+	td.Put(2, 2, 5.0)
+	td.Put(1, 1, 1.0)
+	d := td.ToCSR(nil)
+	if !SpCsrCheckDiag(d) {
+		tst.Errorf("d has all diagonal entries\n")
+	}
+
+	// r := 2*d + 10*I
+	var r CSRMatrix
+	SpCsrInitSimilar(&r, d)
+	SpCsrMatAddI(&r, 2, d, 10)
+	chk.Deep2(tst, "r", 1e-17, r.ToDense().GetDeep2(), [][]float64{{12, 4, 0}, {0, 18, 0}, {8, 0, 20}})
+
+	// r := -r
+	SpCsrMatScale(-1, &r)
+	chk.Deep2(tst, "r", 1e-17, r.ToDense().GetDeep2(), [][]float64{{-12, -4, 0}, {0, -18, 0}, {-8, 0, -20}})
+}
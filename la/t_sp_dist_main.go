@@ -0,0 +1,102 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build ignore
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/mpi"
+)
+
+func main() {
+
+	mpi.Start()
+	defer mpi.Stop()
+
+	comm := mpi.NewCommunicator(nil)
+	if comm.Rank() == 0 {
+		io.Pf("\n------------------------ Test SP Dist 01 ------------------------ \n")
+	}
+
+	// 2D Poisson matrix: all processors hold the full triplet for comparison
+	nx := 10
+	n := nx * nx
+	full := la.NewTriplet(n, n, 5*n)
+	for i := 0; i < nx; i++ {
+		for j := 0; j < nx; j++ {
+			k := i + j*nx
+			full.Put(k, k, 4)
+			if i > 0 {
+				full.Put(k, k-1, -1)
+			}
+			if i < nx-1 {
+				full.Put(k, k+1, -1)
+			}
+			if j > 0 {
+				full.Put(k, k-nx, -1)
+			}
+			if j < nx-1 {
+				full.Put(k, k+nx, -1)
+			}
+		}
+	}
+	A := full.ToDense()
+
+	// local rows
+	part := la.DistPartition(n, comm.Size())
+	lo, hi := part[comm.Rank()], part[comm.Rank()+1]
+	t := la.NewTriplet(n, n, 5*(hi-lo))
+	for i := lo; i < hi; i++ {
+		for j := 0; j < n; j++ {
+			if v := A.Get(i, j); v != 0 {
+				t.Put(i, j, v)
+			}
+		}
+	}
+	a := la.NewDistMatrix(comm, t, part, nil)
+
+	chk.Verbose = true
+	var tst testing.T
+
+	// matrix-vector products
+	x := la.NewVectorMapped(n, func(i int) float64 { return math.Cos(float64(i)) })
+	yRef := la.NewVector(n)
+	la.MatVecMul(yRef, 1, A, x)
+	yl, y := la.NewVector(hi-lo), la.NewVector(n)
+	a.Apply(yl, x[lo:hi])
+	la.DistVecGather(comm, y, yl, part)
+	a.ApplyTranspose(yl, x[lo:hi])
+	la.DistVecGather(comm, yRef, yl, part) // A is symmetric
+	if comm.Rank() == 0 {
+		chk.Array(&tst, "A⋅x", 1e-14, y, yRef)
+	}
+
+	// dot product and norm
+	nrm := la.DistVecNorm(comm, x[lo:hi])
+	if comm.Rank() == 0 {
+		chk.Float64(&tst, "‖x‖", 1e-13, nrm, x.Norm())
+	}
+
+	// Krylov solver with block-Jacobi ILU(0) preconditioner
+	b := la.NewVectorMapped(n, func(i int) float64 { return 1 + math.Sin(float64(i)) })
+	lis := la.NewKrylov("cg")
+	lis.Precond = "ilu0"
+	lis.Tol = 1e-10
+	lis.InitDist(a, false)
+	lis.Fact()
+	xl := la.NewVector(hi - lo)
+	lis.Solve(xl, b[lo:hi], false)
+	la.DistVecGather(comm, x, xl, part)
+	if comm.Rank() == 0 {
+		io.Pf("cg: nit = %d\n", lis.NumIt)
+		la.TestSolverResidual(&tst, A, x, b, 1e-8)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestSpDist01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpDist01. distributed matrix (serial)")
+
+	// partitions
+	chk.Ints(tst, "part(10,3)", DistPartition(10, 3), []int{0, 3, 6, 10})
+	chk.Ints(tst, "part(2,4)", DistPartition(2, 4), []int{0, 0, 1, 1, 2})
+
+	// rectangular matrix
+	t := NewTriplet(4, 6, 10)
+	t.Put(0, 0, 1)
+	t.Put(0, 5, 2)
+	t.Put(1, 1, 3)
+	t.Put(1, 3, -1)
+	t.Put(2, 2, 4)
+	t.Put(2, 4, 5)
+	t.Put(3, 0, -2)
+	t.Put(3, 5, 1)
+	t.Put(3, 5, 1) // repeated
+	a := NewDistMatrix(nil, t, nil, nil)
+	m, n := a.Size()
+	chk.Int(tst, "m", m, 4)
+	chk.Int(tst, "n", n, 6)
+	chk.Int(tst, "nghosts", a.NumGhosts(), 0)
+	chk.Ints(tst, "ColPart", a.ColPart, []int{0, 6})
+	chk.Deep2(tst, "A", 1e-15, OpToMatrix(a).GetDeep2(), t.ToDense().GetDeep2())
+	chk.Deep2(tst, "Aᵀ", 1e-15, OpToMatrix(NewOpTranspose(a)).GetDeep2(), t.ToDense().GetTranspose().GetDeep2())
+	if a.LocalDiag() != nil {
+		tst.Errorf("diagonal block of rectangular matrix must be nil\n")
+	}
+
+	// vectors
+	u := []float64{1, 2, 3}
+	v := []float64{-1, 0, 2}
+	chk.Float64(tst, "u⋅v", 1e-15, DistVecDot(nil, u, v), 5)
+	chk.Float64(tst, "‖u‖", 1e-15, DistVecNorm(nil, u), math.Sqrt(14))
+	w := NewVector(3)
+	DistVecGather(nil, w, u, []int{0, 3})
+	chk.Array(tst, "gather", 1e-15, w, u)
+
+	// wrong rows
+	defer func() {
+		if err := recover(); err != nil {
+			if chk.Verbose {
+				io.Pf("OK, caught the following message:\n\n\t%v\n", err)
+			}
+		} else {
+			tst.Errorf("\n\tTEST FAILED. NewDistMatrix should have panicked\n")
+		}
+	}()
+	NewDistMatrix(nil, t, []int{0, 3}, nil)
+}
+
+func TestSpDist02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpDist02. Krylov solvers with distributed matrix (serial)")
+
+	t := krylovPoisson2d(12)
+	a := NewDistMatrix(nil, t, nil, nil)
+	chk.Deep2(tst, "A", 1e-15, a.LocalDiag().ToDense().GetDeep2(), t.ToDense().GetDeep2())
+	b := NewVectorMapped(t.m, func(i int) float64 { return 1 + math.Sin(float64(i)) })
+	for _, kind := range []string{"cg", "bicgstab", "gmres"} {
+		lis := NewKrylov(kind)
+		lis.Precond = "ilu0"
+		lis.Tol = 1e-10
+		lis.InitDist(a, false)
+		lis.Fact()
+		x := NewVector(t.m)
+		lis.Solve(x, b, false)
+		io.Pforan("%s: nit = %d\n", kind, lis.NumIt)
+		TestSolverResidual(tst, t.ToDense(), x, b, 1e-8)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// spEigenBar returns the stiffness and (consistent) mass matrices of a fixed-fixed bar with
+// unit length discretised with n interior nodes and linear elements
+func spEigenBar(n int) (K, M *CCMatrix) {
+	h := 1.0 / float64(n+1)
+	tk := NewTriplet(n, n, 3*n)
+	tm := NewTriplet(n, n, 3*n)
+	for i := 0; i < n; i++ {
+		tk.Put(i, i, 2.0/h)
+		tm.Put(i, i, 4.0*h/6.0)
+		if i > 0 {
+			tk.Put(i, i-1, -1.0/h)
+			tm.Put(i, i-1, h/6.0)
+		}
+		if i < n-1 {
+			tk.Put(i, i+1, -1.0/h)
+			tm.Put(i, i+1, h/6.0)
+		}
+	}
+	return tk.ToMatrix(nil), tm.ToMatrix(nil)
+}
+
+// spEigenBarλ returns the j-th (j ≥ 1) eigenvalue of the discrete bar problem
+func spEigenBarλ(n, j int) float64 {
+	h := 1.0 / float64(n+1)
+	c := math.Cos(float64(j) * math.Pi * h)
+	return 6.0 / (h * h) * (1.0 - c) / (2.0 + c)
+}
+
+// spEigenCheck checks K⋅x = λ⋅M⋅x and xᵀ⋅M⋅x = 1
+func spEigenCheck(tst *testing.T, λ Vector, X *Matrix, K, M *CCMatrix, tol float64) {
+	n := K.n
+	kx, mx := NewVector(n), NewVector(n)
+	for j := 0; j < len(λ); j++ {
+		x := X.Col(j)
+		SpMatVecMul(kx, 1, K, x)
+		if M == nil {
+			copy(mx, x)
+		} else {
+			SpMatVecMul(mx, 1, M, x)
+		}
+		chk.Float64(tst, io.Sf("xᵀ⋅M⋅x (%d)", j), 1e-12, VecDot(x, mx), 1)
+		mx.Apply(λ[j], mx)
+		chk.Array(tst, io.Sf("K⋅x = λ⋅M⋅x (%d)", j), tol*math.Abs(λ[j]), kx, mx)
+	}
+}
+
+func TestSpEigen01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpEigen01. generalised problem: smallest")
+
+	n := 200
+	K, M := spEigenBar(n)
+	nev := 6
+	λ := NewVector(nev)
+	X := NewMatrix(n, nev)
+	o := NewSparseEigen()
+	o.Solve(λ, X, K, M)
+	io.Pforan("λ = %v\n", λ)
+	io.Pforan("nit = %d  nop = %d\n", o.NumIt, o.NumOp)
+	λcorrect := NewVectorMapped(nev, func(i int) float64 { return spEigenBarλ(n, i+1) })
+	chk.Array(tst, "λ", 1e-8, λ, λcorrect)
+	spEigenCheck(tst, λ, X, K, M, 1e-7)
+}
+
+func TestSpEigen02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpEigen02. generalised problem: largest and nearest")
+
+	n := 100
+	K, M := spEigenBar(n)
+	nev := 4
+
+	// largest
+	λ := NewVector(nev)
+	X := NewMatrix(n, nev)
+	o := NewSparseEigen()
+	o.Which = "largest"
+	o.Ncv = 40
+	o.MaxIt = 1000
+	o.Solve(λ, X, K, M)
+	io.Pforan("λ = %v\n", λ)
+	λcorrect := NewVectorMapped(nev, func(i int) float64 { return spEigenBarλ(n, n-i) })
+	chk.Array(tst, "λ (largest)", 1e-8*λcorrect[0], λ, λcorrect)
+	spEigenCheck(tst, λ, X, K, M, 1e-7)
+
+	// nearest to σ
+	j := 20
+	σ := 0.5 * (spEigenBarλ(n, j) + spEigenBarλ(n, j+1))
+	o = NewSparseEigen()
+	o.Which = "nearest"
+	o.Shift = σ * 1.0001 // λ[j+1] is the closest
+	o.Solve(λ, X, K, M)
+	io.Pforan("λ = %v\n", λ)
+	chk.Array(tst, "λ (nearest)", 1e-8*σ, λ, []float64{
+		spEigenBarλ(n, j+1),
+		spEigenBarλ(n, j),
+		spEigenBarλ(n, j-1),
+		spEigenBarλ(n, j+2),
+	})
+	spEigenCheck(tst, λ, X, K, M, 1e-7)
+}
+
+func TestSpEigen03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpEigen03. standard problem")
+
+	// 2D Laplacian with (nx × nx) grid: λ = 4 sin²(iπ/(2(nx+1))) + 4 sin²(jπ/(2(nx+1)))
+	nx := 15
+	n := nx * nx
+	K := krylovPoisson2d(nx).ToMatrix(nil)
+	s := func(i int) float64 { return math.Pow(2*math.Sin(float64(i)*math.Pi/float64(2*(nx+1))), 2) }
+	λcorrect := []float64{s(1) + s(1), s(1) + s(2), s(2) + s(1), s(2) + s(2)}
+
+	λ := NewVector(4)
+	X := NewMatrix(n, 4)
+	o := NewSparseEigen()
+	o.Solve(λ, X, K, nil)
+	io.Pforan("λ = %v\n", λ)
+	chk.Array(tst, "λ", 1e-10, λ, λcorrect)
+	spEigenCheck(tst, λ, X, K, nil, 1e-8)
+
+	// without eigenvectors
+	λ.Fill(0)
+	o.Solve(λ, nil, K, nil)
+	chk.Array(tst, "λ (no vectors)", 1e-10, λ, λcorrect)
+}
@@ -0,0 +1,268 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func TestMatrixMarket01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatrixMarket01. real: write and read")
+
+	// general (with repeated entries)
+	var t Triplet
+	t.Init(3, 4, 7)
+	t.Put(0, 0, 1.5)
+	t.Put(0, 0, 1.0)
+	t.Put(1, 2, -3.0)
+	t.Put(2, 1, 1.0/3.0)
+	t.Put(2, 3, 4e-20)
+	t.Put(0, 3, 5e+20)
+	t.Put(1, 0, -7)
+	dense := [][]float64{
+		{2.5, 0, 0, 5e+20},
+		{-7, 0, -3, 0},
+		{0, 1.0 / 3.0, 0, 4e-20},
+	}
+	t.WriteMatrixMarket("/tmp/gosl/la", "mm01", "")
+	var r Triplet
+	symmetry := r.ReadMatrixMarket("/tmp/gosl/la/mm01.mtx", true)
+	chk.String(tst, symmetry, "general")
+	chk.Int(tst, "r.Len()", r.Len(), 6)
+	chk.Deep2(tst, "r", 1e-17, r.ToDense().GetDeep2(), dense)
+
+	// column-compressed matrix
+	var a CCMatrix
+	a.ReadMatrixMarket("/tmp/gosl/la/mm01.mtx", false)
+	chk.Deep2(tst, "a", 1e-17, a.ToDense().GetDeep2(), dense)
+
+	// symmetric
+	sym := [][]float64{
+		{4, 1, 0},
+		{1, 5, 2},
+		{0, 2, 6},
+	}
+	var s Triplet
+	s.Init(3, 3, 7)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if sym[i][j] != 0 {
+				s.Put(i, j, sym[i][j])
+			}
+		}
+	}
+	s.WriteMatrixMarket("/tmp/gosl/la", "mm01sym", "symmetric")
+	symmetry = r.ReadMatrixMarket("/tmp/gosl/la/mm01sym.mtx", true)
+	chk.String(tst, symmetry, "symmetric")
+	chk.Int(tst, "r.Len()", r.Len(), 7)
+	chk.Deep2(tst, "r (mirrored)", 1e-17, r.ToDense().GetDeep2(), sym)
+	r.ReadMatrixMarket("/tmp/gosl/la/mm01sym.mtx", false)
+	chk.Int(tst, "r.Len()", r.Len(), 5)
+	chk.Deep2(tst, "r (lower)", 1e-17, r.ToDense().GetDeep2(), [][]float64{
+		{4, 0, 0},
+		{1, 5, 0},
+		{0, 2, 6},
+	})
+
+	// skew-symmetric
+	var k Triplet
+	k.Init(3, 3, 4)
+	k.Put(1, 0, 1)
+	k.Put(0, 1, -1)
+	k.Put(2, 1, 2)
+	k.Put(1, 2, -2)
+	k.WriteMatrixMarket("/tmp/gosl/la", "mm01skew", "skew-symmetric")
+	symmetry = r.ReadMatrixMarket("/tmp/gosl/la/mm01skew.mtx", true)
+	chk.String(tst, symmetry, "skew-symmetric")
+	chk.Deep2(tst, "r (skew)", 1e-17, r.ToDense().GetDeep2(), [][]float64{
+		{0, -1, 0},
+		{1, 0, -2},
+		{0, 2, 0},
+	})
+}
+
+func TestMatrixMarket02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatrixMarket02. complex: write and read")
+
+	// hermitian
+	herm := [][]complex128{
+		{2, 1 - 1i, 0},
+		{1 + 1i, 3, 2i},
+		{0, -2i, 4},
+	}
+	var t TripletC
+	t.Init(3, 3, 9)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if herm[i][j] != 0 {
+				t.Put(i, j, herm[i][j])
+			}
+		}
+	}
+	t.WriteMatrixMarket("/tmp/gosl/la", "mm02herm", "hermitian")
+	var r TripletC
+	symmetry := r.ReadMatrixMarket("/tmp/gosl/la/mm02herm.mtx", true)
+	chk.String(tst, symmetry, "hermitian")
+	chk.Deep2c(tst, "r (mirrored)", 1e-17, r.ToDense().GetDeep2(), herm)
+	r.ReadMatrixMarket("/tmp/gosl/la/mm02herm.mtx", false)
+	chk.Int(tst, "r.Len()", r.Len(), 5)
+
+	// general
+	t.WriteMatrixMarket("/tmp/gosl/la", "mm02", "general")
+	symmetry = r.ReadMatrixMarket("/tmp/gosl/la/mm02.mtx", false)
+	chk.String(tst, symmetry, "general")
+	chk.Deep2c(tst, "r (general)", 1e-17, r.ToDense().GetDeep2(), herm)
+
+	// real file into complex triplet
+	var s Triplet
+	s.Init(2, 2, 2)
+	s.Put(0, 0, 1)
+	s.Put(1, 0, 2)
+	s.WriteMatrixMarket("/tmp/gosl/la", "mm02real", "symmetric")
+	r.ReadMatrixMarket("/tmp/gosl/la/mm02real.mtx", true)
+	chk.Deep2c(tst, "r (real)", 1e-17, r.ToDense().GetDeep2(), [][]complex128{{1, 2}, {2, 0}})
+}
+
+func TestMatrixMarket03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("MatrixMarket03. pattern, integer and comments")
+
+	io.WriteFileD("/tmp/gosl/la", "mm03pattern.mtx", bytes.NewBufferString(`%%MatrixMarket matrix coordinate pattern symmetric
+% this is a comment
+%
+3 3 4
+
+1 1
+2 1
+3 2
+3 3
+`))
+	var r Triplet
+	symmetry := r.ReadMatrixMarket("/tmp/gosl/la/mm03pattern.mtx", true)
+	chk.String(tst, symmetry, "symmetric")
+	chk.Deep2(tst, "r (pattern)", 1e-17, r.ToDense().GetDeep2(), [][]float64{
+		{1, 1, 0},
+		{1, 0, 1},
+		{0, 1, 1},
+	})
+
+	io.WriteFileD("/tmp/gosl/la", "mm03integer.mtx", bytes.NewBufferString(`%%MatrixMarket Matrix Coordinate Integer General
+2 3 3
+1 3 -5
+2 2 7
+1 1 1
+`))
+	symmetry = r.ReadMatrixMarket("/tmp/gosl/la/mm03integer.mtx", false)
+	chk.String(tst, symmetry, "general")
+	chk.Deep2(tst, "r (integer)", 1e-17, r.ToDense().GetDeep2(), [][]float64{
+		{1, 0, -5},
+		{0, 7, 0},
+	})
+}
+
+func TestRutherfordBoeing01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("RutherfordBoeing01. real symmetric")
+
+	// Rutherford-Boeing file (values without separating spaces and with D exponents)
+	io.WriteFileD("/tmp/gosl/la", "rb01.rb", bytes.NewBufferString(`Small symmetric test matrix                                             rb01
+             6             1             1             3
+rsa                        4             4             7             0
+(8I10)          (8I10)          (1P,3D15.8)
+         1         3         5         7         8
+         1         2         2         4         3         4         4
+ 0.40000000D+01-0.10000000D+01 0.50000000D+01
+-0.20000000D+01 0.60000000D+01 0.30000000D-01
+ 0.70000000+101
+`))
+	full := [][]float64{
+		{4, -1, 0, 0},
+		{-1, 5, 0, -2},
+		{0, 0, 6, 0.03},
+		{0, -2, 0.03, 7e100},
+	}
+	var r Triplet
+	symmetry := r.ReadRutherfordBoeing("/tmp/gosl/la/rb01.rb", true)
+	chk.String(tst, symmetry, "symmetric")
+	chk.Int(tst, "r.Len()", r.Len(), 10)
+	chk.Deep2(tst, "r (mirrored)", 1e-15, r.ToDense().GetDeep2(), full)
+	r.ReadRutherfordBoeing("/tmp/gosl/la/rb01.rb", false)
+	chk.Int(tst, "r.Len()", r.Len(), 7)
+	chk.Deep2(tst, "r (lower)", 1e-15, r.ToDense().GetDeep2(), [][]float64{
+		{4, 0, 0, 0},
+		{-1, 5, 0, 0},
+		{0, 0, 6, 0},
+		{0, -2, 0.03, 7e100},
+	})
+}
+
+func TestRutherfordBoeing02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("RutherfordBoeing02. Harwell-Boeing: unsymmetric, complex and pattern")
+
+	// Harwell-Boeing file with right-hand side (ignored)
+	io.WriteFileD("/tmp/gosl/la", "hb02.rua", bytes.NewBufferString(`Unsymmetric test matrix                                                 hb02
+             5             1             1             2             1
+RUA                        2             3             4             0
+(4I5)           (4I5)           (2E20.12)           (2E20.12)
+F                          1             0
+    1    3    4    5
+    1    2    2    1
+  1.000000000000E+00 -2.500000000000E-01
+  3.000000000000E+00  4.000000000000E+00
+  1.000000000000E+00  2.000000000000E+00
+`))
+	var r Triplet
+	symmetry := r.ReadRutherfordBoeing("/tmp/gosl/la/hb02.rua", true)
+	chk.String(tst, symmetry, "general")
+	chk.Deep2(tst, "r", 1e-17, r.ToDense().GetDeep2(), [][]float64{
+		{1, 0, 4},
+		{-0.25, 3, 0},
+	})
+
+	// complex hermitian
+	io.WriteFileD("/tmp/gosl/la", "rb02.rb", bytes.NewBufferString(`Complex hermitian test matrix                                           rb02
+             4             1             1             2
+cha                        2             2             3             0
+(3I8)           (3I8)           (4E15.7)
+       1       3       4
+       1       2       2
+  0.2000000E+01  0.0000000E+00  0.1000000E+01  0.1000000E+01
+  0.3000000E+01  0.0000000E+00
+`))
+	var c TripletC
+	symmetry = c.ReadRutherfordBoeing("/tmp/gosl/la/rb02.rb", true)
+	chk.String(tst, symmetry, "hermitian")
+	chk.Deep2c(tst, "c", 1e-17, c.ToDense().GetDeep2(), [][]complex128{
+		{2, 1 - 1i},
+		{1 + 1i, 3},
+	})
+
+	// pattern
+	io.WriteFileD("/tmp/gosl/la", "rb02p.rb", bytes.NewBufferString(`Pattern test matrix                                                     rb02p
+             2             1             1             0
+pua                        2             2             3             0
+(3I8)           (3I8)
+       1       2       4
+       2       1       2
+`))
+	symmetry = r.ReadRutherfordBoeing("/tmp/gosl/la/rb02p.rb", false)
+	chk.String(tst, symmetry, "general")
+	chk.Deep2(tst, "r (pattern)", 1e-17, r.ToDense().GetDeep2(), [][]float64{
+		{0, 1},
+		{1, 1},
+	})
+}
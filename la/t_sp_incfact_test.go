@@ -0,0 +1,241 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// incFactUnsym returns a non-symmetric (diagonally dominant) matrix
+func incFactUnsym() *Triplet {
+	//   5  1  .  2  .
+	//   1  6  1  .  .
+	//   . -2  7  .  3
+	//   1  .  .  8  1
+	//   .  1  2  .  9
+	t := NewTriplet(5, 5, 15)
+	for i, v := range []float64{5, 6, 7, 8, 9} {
+		t.Put(i, i, v)
+	}
+	t.Put(0, 1, 1)
+	t.Put(0, 3, 2)
+	t.Put(1, 0, 1)
+	t.Put(1, 2, 1)
+	t.Put(2, 1, -2)
+	t.Put(2, 4, 3)
+	t.Put(3, 0, 1)
+	t.Put(3, 4, 1)
+	t.Put(4, 1, 1)
+	t.Put(4, 2, 2)
+	return t
+}
+
+func TestIncFact01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("IncFact01. ILU(k)")
+
+	// ILU(k) with large k is the complete LU factorisation
+	t := incFactUnsym()
+	a := t.ToMatrix(nil)
+	b := []float64{1, 2, 3, 4, 5}
+	xCorrect := NewVector(5)
+	DenSolve(xCorrect, t.ToDense(), b, false)
+	for _, k := range []int{0, 1, 5} {
+		o := &IluK{Level: k}
+		o.Factor(a)
+		x := NewVector(5)
+		o.Solve(x, b)
+		io.Pforan("ILU(%d): nnz = %d  fill = %g  x = %v\n", k, o.Stats.NnzF, o.Stats.Fill, x)
+		if k == 5 {
+			chk.Array(tst, "x", 1e-14, x, xCorrect)
+		}
+		chk.Int(tst, "breakdowns", o.Stats.Breakdowns, 0)
+		chk.Int(tst, "break row", o.Stats.BreakRow, -1)
+	}
+
+	// ILU(0): same pattern as A; exact for tridiagonal matrices
+	o := new(IluK)
+	o.Factor(a)
+	chk.Int(tst, "nnz(A)", o.Stats.NnzA, 15)
+	chk.Int(tst, "nnz(LU)", o.Stats.NnzF, 15)
+	chk.Float64(tst, "fill", 1e-17, o.Stats.Fill, 1)
+	c := krylovConvDiff(20, 0.5).ToMatrix(nil)
+	o.Factor(c)
+	b = NewVectorMapped(20, func(i int) float64 { return float64(i) })
+	x := NewVector(20)
+	o.Solve(x, b)
+	TestSolverResidual(tst, c.ToDense(), x, b, 1e-12)
+
+	// levels of fill on the 2D Poisson matrix: nnz increases with k
+	p := krylovPoisson2d(10).ToMatrix(nil)
+	nnz := 0
+	for k := 0; k < 4; k++ {
+		o = &IluK{Level: k}
+		o.Factor(p)
+		io.Pforan("ILU(%d): nnz = %d  fill = %g\n", k, o.Stats.NnzF, o.Stats.Fill)
+		if o.Stats.NnzF <= nnz {
+			tst.Errorf("number of non-zeros must increase with the level of fill\n")
+		}
+		nnz = o.Stats.NnzF
+	}
+
+	// preconditioner
+	pr := NewPreconditioner("ilu0").(*PrecIlu0)
+	pr.Init(a)
+	chk.Int(tst, "nnz(LU)", pr.Stats.NnzF, 15)
+}
+
+func TestIncFact02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("IncFact02. ILUT")
+
+	// no dropping: complete LU factorisation
+	t := incFactUnsym()
+	a := t.ToMatrix(nil)
+	b := []float64{1, 2, 3, 4, 5}
+	xCorrect := NewVector(5)
+	DenSolve(xCorrect, t.ToDense(), b, false)
+	o := &Ilut{DropTol: 1e-15, MaxFill: 5}
+	o.Factor(a)
+	x := NewVector(5)
+	o.Solve(x, b)
+	chk.Array(tst, "x", 1e-14, x, xCorrect)
+	lu := &IluK{Level: 5}
+	lu.Factor(a)
+	chk.Int(tst, "nnz", o.Stats.NnzF, lu.Stats.NnzF)
+
+	// dropped L entry of a previous row does not hide the fill-in of the following rows
+	t = NewTriplet(3, 3, 8)
+	t.Put(0, 0, 4)
+	t.Put(0, 1, 1)
+	t.Put(1, 0, 1e-8)
+	t.Put(1, 1, 4)
+	t.Put(1, 2, 1)
+	t.Put(2, 0, 1)
+	t.Put(2, 1, 1)
+	t.Put(2, 2, 4)
+	b = []float64{1, 2, 3}
+	xCorrect = NewVector(3)
+	DenSolve(xCorrect, t.ToDense(), b, false)
+	o = &Ilut{DropTol: 1e-4}
+	o.Factor(t.ToMatrix(nil))
+	x = NewVector(3)
+	o.Solve(x, b)
+	chk.Array(tst, "x", 1e-8, x, xCorrect)
+
+	// dropping: fewer entries than the complete factorisation of the Poisson matrix
+	p := krylovPoisson2d(10).ToMatrix(nil)
+	complete := &Ilut{DropTol: 1e-15, MaxFill: 100}
+	complete.Factor(p)
+	o = new(Ilut)
+	o.DropTol = 1e-2
+	o.Factor(p)
+	io.Pforan("ILUT: nnz = %d (complete = %d)  fill = %g\n", o.Stats.NnzF, complete.Stats.NnzF, o.Stats.Fill)
+	if o.Stats.NnzF >= complete.Stats.NnzF/2 {
+		tst.Errorf("ILUT should drop entries: nnz = %d\n", o.Stats.NnzF)
+	}
+	o.MaxFill = 1
+	o.Factor(p)
+	if o.Stats.NnzF > p.p[p.n]+2*p.n {
+		tst.Errorf("ILUT should limit the fill-in: nnz = %d\n", o.Stats.NnzF)
+	}
+
+	// preconditioner: ILUT(1e-3) is better than ILU(0)
+	c := krylovConvDiff(50, 0.5)
+	c.Put(0, 49, 1) // periodic-like entries generate fill-in
+	c.Put(49, 0, 1)
+	nit := make(map[string]int)
+	for _, prec := range []string{"ilu0", "ilut"} {
+		s := NewSparseSolver("gmres").(*Krylov)
+		if prec == "ilut" {
+			s.Prec = &Ilut{DropTol: 1e-3}
+		} else {
+			s.Precond = prec
+		}
+		s.Init(c, false, false, "", "", nil)
+		s.Fact()
+		b := NewVectorMapped(50, func(i int) float64 { return 1 })
+		x := NewVector(50)
+		s.Solve(x, b, false)
+		TestSolverResidual(tst, c.ToDense(), x, b, 1e-8)
+		nit[prec] = s.NumIt
+	}
+	io.Pforan("nit = %v\n", nit)
+	if nit["ilut"] >= nit["ilu0"] {
+		tst.Errorf("ILUT should reduce the number of iterations: %d ≥ %d\n", nit["ilut"], nit["ilu0"])
+	}
+}
+
+func TestIncFact03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("IncFact03. IC(0) and diagonal shifting")
+
+	// preconditioner
+	p := krylovPoisson2d(6).ToMatrix(nil)
+	pr := NewPreconditioner("ic0").(*PrecIc0)
+	pr.Init(p)
+	chk.Int(tst, "nnz(lower(A))", pr.Stats.NnzA, 36+60)
+	chk.Float64(tst, "fill", 1e-17, pr.Stats.Fill, 1)
+
+	// exact for tridiagonal matrices
+	t := NewTriplet(4, 4, 10)
+	for i := 0; i < 4; i++ {
+		t.Put(i, i, 2)
+		if i > 0 {
+			t.Put(i, i-1, -1)
+			t.Put(i-1, i, -1)
+		}
+	}
+	o := new(Ic0)
+	o.Factor(t.ToMatrix(nil))
+	b := []float64{1, 0, 0, 1}
+	x := NewVector(4)
+	o.Solve(x, b)
+	chk.Array(tst, "x", 1e-15, x, []float64{1, 1, 1, 1})
+
+	// indefinite matrix: breakdown and recovery with shifts α = 1e-3 ⋅ 2ᵏ until (1+α)² > 4
+	t = NewTriplet(2, 2, 4)
+	t.Put(0, 0, 1)
+	t.Put(1, 1, 1)
+	t.Put(0, 1, 2)
+	t.Put(1, 0, 2)
+	a := t.ToMatrix(nil)
+	o.Factor(a)
+	io.Pforan("stats = %+v\n", o.Stats)
+	chk.Int(tst, "breakdowns", o.Stats.Breakdowns, 11)
+	chk.Int(tst, "break row", o.Stats.BreakRow, 1)
+	chk.Float64(tst, "shift", 1e-15, o.Stats.Shift, 1.024)
+
+	// zero pivot in ILU(0): recovery with shifts
+	u := NewTriplet(2, 2, 3)
+	u.Put(0, 1, 1)
+	u.Put(1, 0, 1)
+	u.Put(1, 1, 1)
+	l := new(IluK)
+	l.Factor(u.ToMatrix(nil))
+	io.Pforan("stats = %+v\n", l.Stats)
+	chk.Int(tst, "breakdowns", l.Stats.Breakdowns, 1)
+	chk.Int(tst, "break row", l.Stats.BreakRow, 0)
+	chk.Float64(tst, "shift", 1e-17, l.Stats.Shift, 1e-3)
+
+	// no shifts: panic
+	defer func() {
+		if err := recover(); err != nil {
+			if chk.Verbose {
+				io.Pf("OK, caught the following message:\n\n\t%v\n", err)
+			}
+		} else {
+			tst.Errorf("\n\tTEST FAILED. IC(0) should have panicked\n")
+		}
+	}()
+	o.Shift.NoShift = true
+	o.Factor(a)
+}
@@ -121,7 +121,7 @@ func TestSpMatrix01(tst *testing.T) {
 func TestSpMatrix02(tst *testing.T) {
 
 	//verbose()
-	chk.PrintTitle("SpMatrix02. PutMatAndMatT, PutCCMatAndMatT")
+	chk.PrintTitle("SpMatrix02. PutMatAndMatT, PutCCMatAndMatT, PutMatAt")
 
 	var K, L, A Triplet
 	K.Init(6, 6, 36+2*6) // 2*6 == number of nonzeros in A
@@ -153,4 +153,16 @@ func TestSpMatrix02(tst *testing.T) {
 	}
 	chk.Deep2(tst, "Kaug", 1.0e-17, Kaug.GetDeep2(), Cor)
 	chk.Deep2(tst, "Laug", 1.0e-17, Laug.GetDeep2(), Cor)
+
+	// block
+	var B Triplet
+	B.Init(4, 5, 12)
+	B.PutMatAt(0, 0, &A)
+	B.PutMatAt(2, 2, &A)
+	chk.Deep2(tst, "B", 1.0e-17, B.ToMatrix(nil).ToDense().GetDeep2(), [][]float64{
+		{11, 12, 13, 0, 0},
+		{21, 22, 23, 0, 0},
+		{0, 0, 11, 12, 13},
+		{0, 0, 21, 22, 23},
+	})
 }
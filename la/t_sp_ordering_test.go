@@ -0,0 +1,273 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// orderingScrambled returns krylovPoisson2d(nx) with the scrambled numbering k → k⋅7919 mod n
+func orderingScrambled(nx int) (t *Triplet) {
+	a := krylovPoisson2d(nx)
+	n := a.m
+	t = NewTriplet(n, n, a.pos)
+	for k := 0; k < a.pos; k++ {
+		t.Put((a.i[k]*7919)%n, (a.j[k]*7919)%n, a.x[k])
+	}
+	return
+}
+
+// choleskyFill returns the number of non-zeros in the Cholesky factor L (below the diagonal) of
+// P⋅A⋅Pᵀ computed by symbolic elimination
+func choleskyFill(t *Triplet, perm []int) (nnz int) {
+	n := t.m
+	iperm := PermInv(perm)
+	adj := make([]map[int]bool, n)
+	for k := 0; k < n; k++ {
+		adj[k] = make(map[int]bool)
+	}
+	for k := 0; k < t.pos; k++ {
+		i, j := iperm[t.i[k]], iperm[t.j[k]]
+		if i != j {
+			adj[i][j], adj[j][i] = true, true
+		}
+	}
+	for k := 0; k < n; k++ {
+		var higher []int
+		for j := range adj[k] {
+			if j > k {
+				higher = append(higher, j)
+			}
+		}
+		nnz += len(higher)
+		for _, a := range higher {
+			for _, b := range higher {
+				if a != b {
+					adj[a][b] = true
+				}
+			}
+		}
+	}
+	return
+}
+
+// checkPerm checks whether perm is a valid permutation of 0...n-1
+func checkPerm(tst *testing.T, perm []int, n int) {
+	if len(perm) != n {
+		tst.Errorf("len(perm) = %d is incorrect. n = %d\n", len(perm), n)
+		return
+	}
+	found := make([]bool, n)
+	for _, p := range perm {
+		if p < 0 || p >= n || found[p] {
+			tst.Errorf("perm is not a permutation: %v\n", perm)
+			return
+		}
+		found[p] = true
+	}
+}
+
+func TestSpOrdering01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpOrdering01. permutations and bandwidth")
+
+	//   1 2 . . 3
+	//   4 5 . . .
+	//   . . 6 7 .
+	//   . . . 8 .
+	//   9 . . . 10
+	a := NewTriplet(5, 5, 10)
+	a.Put(0, 0, 1)
+	a.Put(0, 1, 2)
+	a.Put(0, 4, 3)
+	a.Put(1, 0, 4)
+	a.Put(1, 1, 5)
+	a.Put(2, 2, 6)
+	a.Put(2, 3, 7)
+	a.Put(3, 3, 8)
+	a.Put(4, 0, 9)
+	a.Put(4, 4, 10)
+	bw, pf := a.Bandwidth(nil)
+	chk.Int(tst, "bandwidth", bw, 4)
+	chk.Int(tst, "profile", pf, 6)
+
+	// permuted triplet
+	perm := []int{3, 2, 1, 0, 4}
+	A := a.ToDense()
+	B := a.Permute(perm).ToDense()
+	for k := 0; k < 5; k++ {
+		for l := 0; l < 5; l++ {
+			chk.Float64(tst, io.Sf("B[%d,%d]", k, l), 1e-17, B.Get(k, l), A.Get(perm[k], perm[l]))
+		}
+	}
+	bw, pf = a.Bandwidth(perm)
+	chk.Int(tst, "bandwidth", bw, 1)
+	chk.Int(tst, "profile", pf, 3)
+
+	// permuted column-compressed matrix
+	c := a.ToMatrix(nil)
+	chk.Deep2(tst, "C", 1e-17, c.Permute(perm).ToDense().GetDeep2(), B.GetDeep2())
+	bwc, pfc := c.Bandwidth(perm)
+	chk.Int(tst, "bandwidth", bwc, bw)
+	chk.Int(tst, "profile", pfc, pf)
+
+	// permuted vectors: (P⋅A⋅Pᵀ)⋅(P⋅x) = P⋅(A⋅x)
+	x := []float64{1, 2, 3, 4, 5}
+	Ax := NewVector(5)
+	MatVecMul(Ax, 1, A, x)
+	px := NewVector(5)
+	VecPermute(px, perm, x)
+	Bpx := NewVector(5)
+	MatVecMul(Bpx, 1, B, px)
+	pAx := NewVector(5)
+	VecPermute(pAx, perm, Ax)
+	chk.Array(tst, "B⋅P⋅x", 1e-17, Bpx, pAx)
+	y := NewVector(5)
+	VecPermuteInv(y, perm, px)
+	chk.Array(tst, "Pᵀ⋅P⋅x", 1e-17, y, x)
+	chk.Ints(tst, "iperm", PermInv(perm), []int{3, 2, 1, 0, 4})
+}
+
+func TestSpOrdering02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpOrdering02. reverse Cuthill-McKee")
+
+	// path with scrambled numbering ⇒ tridiagonal
+	n := 11
+	a := NewTriplet(n, n, 2*n)
+	for k := 0; k < n-1; k++ {
+		a.Put((k*7)%n, ((k+1)*7)%n, 1)
+	}
+	perm := a.Ordering("rcm")
+	checkPerm(tst, perm, n)
+	bw, pf := a.Bandwidth(perm)
+	chk.Int(tst, "bandwidth", bw, 1)
+	chk.Int(tst, "profile", pf, n-1)
+
+	// two components
+	a = NewTriplet(6, 6, 6)
+	a.Put(0, 3, 1)
+	a.Put(3, 5, 1)
+	a.Put(1, 4, 1)
+	a.Put(2, 2, 1)
+	perm = a.Ordering("rcm")
+	checkPerm(tst, perm, 6)
+	bw, _ = a.Bandwidth(perm)
+	chk.Int(tst, "bandwidth", bw, 1)
+
+	// grid: RCM recovers (at least) the bandwidth and profile of the natural numbering
+	nx := 10
+	g := orderingScrambled(nx)
+	bw0, pf0 := g.Bandwidth(nil)
+	bwN, pfN := krylovPoisson2d(nx).Bandwidth(nil)
+	perm = g.Ordering("rcm")
+	checkPerm(tst, perm, nx*nx)
+	bw, pf = g.Bandwidth(perm)
+	io.Pforan("bandwidth: %d → %d (natural = %d)\n", bw0, bw, bwN)
+	io.Pforan("profile:   %d → %d (natural = %d)\n", pf0, pf, pfN)
+	if bw > bwN {
+		tst.Errorf("bandwidth of RCM ordering is too large: %d\n", bw)
+	}
+	if pf > pfN || pf >= pf0/3 {
+		tst.Errorf("profile of RCM ordering is too large: %d\n", pf)
+	}
+
+	// column-compressed matrix
+	chk.Ints(tst, "perm(cc)", g.ToMatrix(nil).Ordering("rcm"), perm)
+}
+
+func TestSpOrdering03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpOrdering03. approximate minimum degree")
+
+	// arrow matrix: no fill-in
+	n := 8
+	a := NewTriplet(n, n, 3*n)
+	for k := 0; k < n; k++ {
+		a.Put(k, k, 1)
+		if k != 2 {
+			a.Put(2, k, 1)
+			a.Put(k, 2, 1)
+		}
+	}
+	perm := a.Ordering("amd")
+	checkPerm(tst, perm, n)
+	chk.Int(tst, "fill", choleskyFill(a, perm), n-1)
+
+	// grid
+	g := krylovPoisson2d(20)
+	natural := make([]int, 400)
+	for k := range natural {
+		natural[k] = k
+	}
+	perm = g.Ordering("amd")
+	checkPerm(tst, perm, 400)
+	fill0, fill := choleskyFill(g, natural), choleskyFill(g, perm)
+	io.Pforan("fill: %d → %d\n", fill0, fill)
+	if fill > fill0/2 {
+		tst.Errorf("fill-in of AMD ordering is too large: %d\n", fill)
+	}
+
+	// dense row and column: ordered last
+	n = 400
+	a = NewTriplet(n, n, 3*n)
+	for k := 0; k < n; k++ {
+		a.Put(17, k, 1)
+		a.Put(k, 17, 1)
+		if k < n-1 {
+			a.Put((k*7)%n, ((k+1)*7)%n, 1)
+		}
+	}
+	perm = a.Ordering("amd")
+	checkPerm(tst, perm, n)
+	chk.Int(tst, "perm[n-1]", perm[n-1], 17)
+
+	// empty matrix
+	chk.Ints(tst, "perm(empty)", NewTriplet(0, 0, 0).Ordering("amd"), []int{})
+}
+
+func TestSpOrdering04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpOrdering04. nested dissection")
+
+	// grid
+	g := orderingScrambled(30)
+	n := 900
+	natural := make([]int, n)
+	for k := range natural {
+		natural[k] = (k * 7919) % n
+	}
+	perm := g.Ordering("nd")
+	checkPerm(tst, perm, n)
+	fill0, fill := choleskyFill(g, natural), choleskyFill(g, perm)
+	io.Pforan("fill: %d → %d\n", fill0, fill)
+	if fill > fill0/2 {
+		tst.Errorf("fill-in of ND ordering is too large: %d\n", fill)
+	}
+
+	// disconnected paths and small graph
+	a := NewTriplet(130, 130, 130)
+	for k := 0; k < 129; k++ {
+		if k != 64 {
+			a.Put(k, k+1, 1)
+		}
+	}
+	perm = a.Ordering("nd")
+	checkPerm(tst, perm, 130)
+	fill = choleskyFill(a, perm)
+	io.Pforan("fill (paths) = %d\n", fill)
+	if fill > 2*130 {
+		tst.Errorf("fill-in of ND ordering is too large: %d\n", fill)
+	}
+	perm = krylovPoisson2d(3).Ordering("nd")
+	checkPerm(tst, perm, 9)
+}
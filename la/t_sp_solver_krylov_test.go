@@ -0,0 +1,172 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package la
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// krylovPoisson2d returns the (SPD) matrix of the 2D Poisson equation discretised with the
+// 5-point stencil on a (nx × nx) grid
+func krylovPoisson2d(nx int) (t *Triplet) {
+	n := nx * nx
+	t = NewTriplet(n, n, 5*n)
+	for i := 0; i < nx; i++ {
+		for j := 0; j < nx; j++ {
+			k := i + j*nx
+			t.Put(k, k, 4)
+			if i > 0 {
+				t.Put(k, k-1, -1)
+			}
+			if i < nx-1 {
+				t.Put(k, k+1, -1)
+			}
+			if j > 0 {
+				t.Put(k, k-nx, -1)
+			}
+			if j < nx-1 {
+				t.Put(k, k+nx, -1)
+			}
+		}
+	}
+	return
+}
+
+// krylovConvDiff returns the (non-symmetric) matrix of the 1D convection-diffusion equation
+// discretised with central differences
+func krylovConvDiff(n int, peclet float64) (t *Triplet) {
+	t = NewTriplet(n, n, 3*n)
+	for i := 0; i < n; i++ {
+		t.Put(i, i, 2)
+		if i > 0 {
+			t.Put(i, i-1, -1-peclet/2)
+		}
+		if i < n-1 {
+			t.Put(i, i+1, -1+peclet/2)
+		}
+	}
+	return
+}
+
+// krylovPrecScale implements a (dummy) user-defined preconditioner: M = 2 I
+type krylovPrecScale struct{}
+
+func (o *krylovPrecScale) Init(a *CCMatrix)  {}
+func (o *krylovPrecScale) Apply(z, r Vector) { z.Apply(0.5, r) }
+
+// krylovCheck solves A⋅x = b with b = A⋅xCorrect and checks the results
+func krylovCheck(tst *testing.T, kind, precond string, t *Triplet, tolX float64) (nit int) {
+	n := t.m
+	xCorrect := NewVectorMapped(n, func(i int) float64 { return 1 + float64(i%7)/7.0 })
+	b := NewVector(n)
+	SpTriMatVecMul(b, t, xCorrect)
+	o := NewSparseSolver(kind).(*Krylov)
+	defer o.Free()
+	o.Precond = precond
+	o.Tol = 1e-10
+	o.Init(t, false, false, "", "", nil)
+	o.Fact()
+	x := NewVector(n)
+	o.Solve(x, b, false)
+	io.Pf("%-9s %-7s nit = %4d  resid = %g\n", kind, precond, o.NumIt, o.Resid)
+	chk.Array(tst, kind+"/"+precond+": x", tolX, x, xCorrect)
+	TestSolverResidual(tst, t.ToDense(), x, b, 1e-8)
+	if len(o.History) < 2 {
+		tst.Errorf("history must have at least two entries\n")
+		return
+	}
+	if o.History[len(o.History)-1] != o.Resid {
+		tst.Errorf("last value in history must be equal to the final residual\n")
+	}
+	return o.NumIt
+}
+
+func TestKrylov01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Krylov01. CG with preconditioners")
+
+	t := krylovPoisson2d(20)
+	nit := make(map[string]int)
+	for _, prec := range []string{"", "jacobi", "ssor", "ic0", "ilu0"} {
+		nit[prec] = krylovCheck(tst, "cg", prec, t, 1e-8)
+	}
+	if nit["ic0"] >= nit[""] {
+		tst.Errorf("IC(0) should reduce the number of iterations: %d ≥ %d\n", nit["ic0"], nit[""])
+	}
+	if nit["ssor"] >= nit[""] {
+		tst.Errorf("SSOR should reduce the number of iterations: %d ≥ %d\n", nit["ssor"], nit[""])
+	}
+}
+
+func TestKrylov02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Krylov02. BiCGStab and GMRES (non-symmetric)")
+
+	t := krylovConvDiff(100, 0.5)
+	for _, kind := range []string{"bicgstab", "gmres"} {
+		nit := make(map[string]int)
+		for _, prec := range []string{"", "jacobi", "ssor", "ilu0"} {
+			nit[prec] = krylovCheck(tst, kind, prec, t, 1e-7)
+		}
+		if nit["ilu0"] >= nit[""] {
+			tst.Errorf("%s: ILU(0) should reduce the number of iterations: %d ≥ %d\n", kind, nit["ilu0"], nit[""])
+		}
+	}
+}
+
+func TestKrylov03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Krylov03. GMRES: small system and restart")
+
+	// same system as in SpSolver01
+	A := new(Triplet)
+	A.Init(5, 5, 13)
+	A.Put(0, 0, +1.0)
+	A.Put(0, 0, +1.0)
+	A.Put(1, 0, +3.0)
+	A.Put(0, 1, +3.0)
+	A.Put(2, 1, -1.0)
+	A.Put(4, 1, +4.0)
+	A.Put(1, 2, +4.0)
+	A.Put(2, 2, -3.0)
+	A.Put(3, 2, +1.0)
+	A.Put(4, 2, +2.0)
+	A.Put(2, 3, +2.0)
+	A.Put(1, 4, +6.0)
+	A.Put(4, 4, +1.0)
+	b := []float64{8.0, 45.0, -3.0, 3.0, 19.0}
+
+	// full GMRES converges in n iterations
+	o := NewSparseSolver("gmres").(*Krylov)
+	defer o.Free()
+	o.Tol = 1e-12
+	o.Init(A, false, false, "", "", nil)
+	o.Fact()
+	x := NewVector(5)
+	o.Solve(x, b, false)
+	chk.Array(tst, "x", 1e-10, x, []float64{1, 2, 3, 4, 5})
+	chk.IntAssertLessThanOrEqualTo(o.NumIt, 5)
+
+	// restarted GMRES with initial guess from previous solution
+	o.Restart = 2
+	o.UseX0 = true
+	o.Solve(x, b, false)
+	chk.Array(tst, "x (restart)", 1e-10, x, []float64{1, 2, 3, 4, 5})
+	chk.Int(tst, "nit with x0 = solution", o.NumIt, 0)
+
+	// user-defined preconditioner
+	o.Prec = new(krylovPrecScale)
+	o.UseX0 = false
+	o.Restart = 5
+	o.Fact()
+	o.Solve(x, b, false)
+	chk.Array(tst, "x (user prec)", 1e-10, x, []float64{1, 2, 3, 4, 5})
+}
@@ -7,9 +7,11 @@
 package la
 
 import (
+	"math"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/mpi"
 )
 
@@ -270,3 +272,100 @@ func TestSpMumps06(tst *testing.T) {
 	bIsDistr := false
 	TestSpSolverC(tst, "mumps", false, &t, b, xCorrect, 1e-3, 1e-12, false, bIsDistr, comm)
 }
+
+func TestSpMumps07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpMumps07. SparseEigen with mumps")
+
+	switchMPI()
+	comm := mpi.NewCommunicator(nil)
+
+	// shift-invert with (K - σ⋅M)
+	n := 100
+	K, M := spEigenBar(n)
+	nev := 4
+	λ := NewVector(nev)
+	X := NewMatrix(n, nev)
+	o := NewSparseEigen()
+	o.Solver = "mumps"
+	o.Comm = comm
+	o.Shift = 1.0
+	o.Solve(λ, X, K, M)
+	λcorrect := NewVectorMapped(nev, func(i int) float64 { return spEigenBarλ(n, i+1) })
+	chk.Array(tst, "λ (smallest)", 1e-8, λ, λcorrect)
+	spEigenCheck(tst, λ, X, K, M, 1e-7)
+
+	// regular mode with M
+	o = NewSparseEigen()
+	o.Solver = "mumps"
+	o.Comm = comm
+	o.Which = "largest"
+	o.Ncv = 40
+	o.MaxIt = 1000
+	o.Solve(λ, X, K, M)
+	λcorrect = NewVectorMapped(nev, func(i int) float64 { return spEigenBarλ(n, n-i) })
+	chk.Array(tst, "λ (largest)", 1e-8*λcorrect[0], λ, λcorrect)
+	spEigenCheck(tst, λ, X, K, M, 1e-7)
+}
+
+func TestSpMumps08(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpMumps08. determinant on demand")
+
+	switchMPI()
+	comm := mpi.NewCommunicator(nil)
+
+	// matrix generator with the same pattern but different values
+	t := new(Triplet)
+	t.Init(5, 5, 13)
+	gen := func(α float64) {
+		t.Start()
+		t.Put(0, 0, +1.0*α)
+		t.Put(0, 0, +1.0*α)
+		t.Put(1, 0, +3.0)
+		t.Put(0, 1, +3.0)
+		t.Put(2, 1, -1.0)
+		t.Put(4, 1, +4.0)
+		t.Put(1, 2, +4.0)
+		t.Put(2, 2, -3.0*α)
+		t.Put(3, 2, +1.0)
+		t.Put(4, 2, +2.0)
+		t.Put(2, 3, +2.0)
+		t.Put(1, 4, +6.0)
+		t.Put(4, 4, +1.0*α)
+	}
+
+	// solver
+	s := NewSparseSolverDirect("mumps")
+	defer s.Free()
+	gen(1)
+	s.Init(t, false, false, "", "", comm)
+	s.Fact()
+
+	// check function: the solution must remain correct after computing the determinant
+	xCorrect := Vector([]float64{1, 2, 3, 4, 5})
+	check := func(msg string) {
+		a := t.ToDense()
+		b := NewVector(5)
+		x := NewVector(5)
+		MatVecMul(b, 1, a, xCorrect)
+		s.Solve(x, b, false)
+		chk.Array(tst, msg+": x (before det)", 1e-13, x, xCorrect)
+		mant, expo := s.Determinant()
+		io.Pforan("%s: det(A) = %g × 10^%g\n", msg, mant, expo)
+		chk.Float64(tst, msg+": det", 1e-12, mant*math.Pow(10, expo), a.Det())
+		mant2, expo2 := s.Determinant() // no refactorisation
+		chk.Float64(tst, msg+": det (again)", 1e-15, mant2*math.Pow(10, expo2), mant*math.Pow(10, expo))
+		x.Fill(0)
+		s.Solve(x, b, false)
+		chk.Array(tst, msg+": x (after det)", 1e-13, x, xCorrect)
+	}
+	check("α=1")
+
+	// refactor with new values: the determinant must be recomputed
+	gen(2)
+	s.RefactorNumeric()
+	check("α=2")
+}
@@ -5,9 +5,11 @@
 package la
 
 import (
+	"math"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
 )
 
 func TestSpUmfpack01a(tst *testing.T) {
@@ -390,3 +392,190 @@ func TestSpUmfpack07b(tst *testing.T) {
 		sol.Solve(x, b, false) // x := inv(A) * b
 	}
 }
+
+func TestSpUmfpack08(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpUmfpack08. refactor, many rhs, transpose and determinant")
+
+	// matrix generator with the same pattern but different values
+	t := new(Triplet)
+	t.Init(5, 5, 13)
+	gen := func(α float64) {
+		t.Start()
+		t.Put(0, 0, +1.0*α)
+		t.Put(0, 0, +1.0*α)
+		t.Put(1, 0, +3.0)
+		t.Put(0, 1, +3.0)
+		t.Put(2, 1, -1.0)
+		t.Put(4, 1, +4.0)
+		t.Put(1, 2, +4.0)
+		t.Put(2, 2, -3.0*α)
+		t.Put(3, 2, +1.0)
+		t.Put(4, 2, +2.0)
+		t.Put(2, 3, +2.0)
+		t.Put(1, 4, +6.0)
+		t.Put(4, 4, +1.0*α)
+	}
+
+	// solver
+	s := NewSparseSolverDirect("umfpack")
+	defer s.Free()
+	gen(1)
+	s.Init(t, false, false, "", "", nil)
+	s.Fact()
+
+	// check function
+	xCorrect := Vector([]float64{1, 2, 3, 4, 5})
+	check := func(msg string) {
+		a := t.ToDense()
+		b := NewVector(5)
+		x := NewVector(5)
+
+		// solve
+		MatVecMul(b, 1, a, xCorrect)
+		s.Solve(x, b, false)
+		chk.Array(tst, msg+": x", 1e-13, x, xCorrect)
+
+		// solve transpose
+		MatTrVecMul(b, 1, a, xCorrect)
+		s.SolveTranspose(x, b, false)
+		chk.Array(tst, msg+": xt", 1e-13, x, xCorrect)
+
+		// many rhs
+		X := NewMatrix(5, 3)
+		B := NewMatrix(5, 3)
+		for j := 0; j < 3; j++ {
+			MatVecMul(B.Col(j), float64(j+1), a, xCorrect)
+		}
+		s.SolveMany(X, B, false)
+		for j := 0; j < 3; j++ {
+			xj := NewVector(5)
+			xj.Apply(float64(j+1), xCorrect)
+			chk.Array(tst, io.Sf("%s: X[:,%d]", msg, j), 1e-13, X.Col(j), xj)
+		}
+
+		// determinant
+		mant, expo := s.Determinant()
+		io.Pforan("%s: det(A) = %g × 10^%g\n", msg, mant, expo)
+		chk.Float64(tst, msg+": det", 1e-12, mant*math.Pow(10, expo), a.Det())
+	}
+	check("α=1")
+
+	// refactor with new values
+	gen(2)
+	s.RefactorNumeric()
+	check("α=2")
+	gen(-3)
+	s.RefactorNumeric()
+	check("α=-3")
+}
+
+func TestSpUmfpack09(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpUmfpack09. inertia and large determinant")
+
+	// symmetric indefinite matrix (diagonally dominant)
+	n := 5
+	d := []float64{4, -5, 6, -7, 8}
+	t := new(Triplet)
+	t.Init(n, n, 3*n)
+	for i := 0; i < n; i++ {
+		t.Put(i, i, d[i])
+		if i > 0 {
+			t.Put(i, i-1, 1)
+			t.Put(i-1, i, 1)
+		}
+	}
+	s := NewSparseSolverDirect("umfpack")
+	defer s.Free()
+	s.Init(t, true, false, "", "", nil)
+	s.Fact()
+	nneg, nzero, npos := s.Inertia()
+	io.Pforan("nneg = %d, nzero = %d, npos = %d\n", nneg, nzero, npos)
+	chk.Int(tst, "nneg", nneg, 2)
+	chk.Int(tst, "nzero", nzero, 0)
+	chk.Int(tst, "npos", npos, 3)
+	mant, expo := s.Determinant()
+	chk.Float64(tst, "det", 1e-11, mant*math.Pow(10, expo), t.ToDense().Det())
+
+	// determinant that overflows: det = 10^500
+	t2 := new(Triplet)
+	t2.Init(n, n, n)
+	for i := 0; i < n; i++ {
+		t2.Put(i, i, -1e100)
+	}
+	s2 := NewSparseSolverDirect("umfpack")
+	defer s2.Free()
+	s2.Init(t2, true, false, "", "", nil)
+	s2.Fact()
+	mant, expo = s2.Determinant()
+	io.Pforan("det(A₂) = %g × 10^%g\n", mant, expo)
+	chk.Float64(tst, "mantissa", 1e-13, mant, -1)
+	chk.Float64(tst, "exponent", 1e-15, expo, 500)
+	nneg, nzero, npos = s2.Inertia()
+	chk.Ints(tst, "inertia", []int{nneg, nzero, npos}, []int{5, 0, 0})
+}
+
+func TestSpUmfpack10(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("SpUmfpack10. refactor, many rhs and transpose (complex)")
+
+	// matrix generator with the same pattern but different values
+	n := 4
+	t := new(TripletC)
+	t.Init(n, n, 3*n)
+	gen := func(α complex128) {
+		t.Start()
+		for i := 0; i < n; i++ {
+			t.Put(i, i, α*complex(float64(i+2), 1))
+			if i > 0 {
+				t.Put(i, i-1, complex(1, -2))
+				t.Put(i-1, i, complex(0, 3))
+			}
+		}
+	}
+
+	// solver
+	s := NewSparseSolverDirectC("umfpack")
+	defer s.Free()
+	gen(1)
+	s.Init(t, false, false, "", "", nil)
+	s.Fact()
+
+	// check function
+	xCorrect := []complex128{1 + 1i, 2, -3i, 4 - 1i}
+	check := func(msg string) {
+		a := t.ToDense()
+		at := a.GetTranspose()
+		b := NewVectorC(n)
+		x := NewVectorC(n)
+
+		// solve
+		MatVecMulC(b, 1, a, xCorrect)
+		s.Solve(x, b, false)
+		chk.ArrayC(tst, msg+": x", 1e-13, x, xCorrect)
+
+		// solve transpose (not conjugate)
+		MatVecMulC(b, 1, at, xCorrect)
+		s.SolveTranspose(x, b, false)
+		chk.ArrayC(tst, msg+": xt", 1e-13, x, xCorrect)
+
+		// many rhs
+		X := NewMatrixC(n, 2)
+		B := NewMatrixC(n, 2)
+		MatVecMulC(B.Col(0), 1, a, xCorrect)
+		MatVecMulC(B.Col(1), 2i, a, xCorrect)
+		s.SolveMany(X, B, false)
+		chk.ArrayC(tst, msg+": X[:,0]", 1e-13, X.Col(0), xCorrect)
+		chk.ArrayC(tst, msg+": X[:,1]", 1e-13, X.Col(1), []complex128{-2 + 2i, 4i, 6, 2 + 8i})
+	}
+	check("α=1")
+
+	// refactor with new values
+	gen(3 - 1i)
+	s.RefactorNumeric()
+	check("α=3-i")
+}
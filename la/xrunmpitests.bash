@@ -7,7 +7,8 @@ t_mumpssol02_main \
 t_mumpssol03_main \
 t_mumpssol04_main \
 t_mumpssol05_main \
-t_sp_mpi_main
+t_sp_mpi_main \
+t_sp_dist_main
 "
 
 for t in $tests; do
@@ -0,0 +1,500 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+	"github.com/cpmech/gosl/utl"
+)
+
+// BDF implements the variable-step, variable-order backward differentiation formulae (BDF) and
+// the numerical differentiation formulae (NDF) with orders 1 to 5
+//
+//   The method is written in terms of the modified divided differences D[j] = ∇ʲy (quasi-constant
+//   stepsize implementation) as in the ode15s code by Shampine and Reichelt [1]. The equations for
+//   y_new are
+//
+//     M ⋅ (y_new - y_pred + ψ) = c ⋅ f(x_new, y_new)    with    c = h / αₖ
+//
+//   where y_pred = Σⱼ D[j] (j = 0..k) is the prediction and ψ = Σⱼ γⱼ⋅D[j] / αₖ (j = 1..k).
+//   These equations are solved by the simplified Newton's method with the matrix (M - c⋅J), where
+//   J = df/dy is kept for several steps (Jacobian reuse) and is only recomputed when the iterations
+//   fail to converge or when it becomes too old (Config.BdfJacAge). The decomposition is only
+//   performed when c changes; i.e. when the stepsize or the order change.
+//
+//   The stepsize is kept constant for k+1 steps after any change. Then, the errors of orders k-1,
+//   k and k+1 are estimated and the order giving the largest stepsize is selected.
+//
+//   The methods available are:
+//     bdf -- backward differentiation formulae (κ = 0)
+//     ndf -- numerical differentiation formulae (κ ≠ 0) [1]; more stable than bdf
+//
+//   Reference:
+//     [1] Shampine LF and Reichelt MW (1997) The MATLAB ODE Suite. SIAM Journal on Scientific
+//         Computing, 18(1):1-22
+//
+type BDF struct {
+
+	// constants
+	Kappa   []float64 // κ coefficients of NDF (zero for BDF)
+	Gamma   []float64 // γₖ = Σ 1/j (j = 1..k)
+	Alpha   []float64 // αₖ = (1 - κₖ)⋅γₖ
+	ErrCte  []float64 // error constants: κₖ⋅γₖ + 1/(k+1)
+	MaxOrd  int       // maximum order
+	MaxJage int       // maximum number of steps before recomputing the Jacobian
+
+	// data
+	ndim  int             // problem dimension
+	conf  *Config         // configurations
+	work  *rkwork         // workspace
+	stat  *Stat           // statistics
+	fcn   Func            // dy/dx := f(x,y)
+	jac   JacF            // Jacobian function: df/dy(x,y)
+	dfdy  *la.Triplet     // df/dy matrix
+	mtri  *la.Triplet     // M matrix in triplet format
+	mmat  *la.CCMatrix    // M matrix in compressed-column format
+	hasM  bool            // has M matrix
+	kmat  *la.Triplet     // matrix of linear system: M - c⋅J
+	ls    la.SparseSolver // linear solver
+	ready bool            // matrices and solver are ready
+	fact  bool            // linear solver has been factorised once
+
+	// state
+	D      []la.Vector // [maxord+3][ndim] modified divided differences
+	order  int         // current order
+	hD     float64     // stepsize corresponding to D
+	nequal int         // number of steps with equal stepsize
+	jage   int         // age of the Jacobian (number of steps); -1 ⇒ no Jacobian yet
+	cfact  float64     // c coefficient of the current factorisation
+	safety float64     // safety factor for stepsize (depends on number of iterations)
+	x0     float64     // x at the beginning of the step
+
+	// workspace
+	ypred la.Vector   // prediction
+	psi   la.Vector   // ψ
+	d     la.Vector   // y_new - y_pred
+	ynew  la.Vector   // y_new
+	scal  la.Vector   // scaling factors: atol + rtol⋅|y|
+	r     la.Vector   // residual
+	dy    la.Vector   // correction
+	w     la.Vector   // workspace
+	tmp   []la.Vector // [maxord+1][ndim] workspace for changing D
+
+	// dense output
+	xd   float64 // x at the end of the last accepted step
+	hd   float64 // stepsize of last accepted step
+	ordd int     // order of last accepted step
+}
+
+// add methods to database
+func init() {
+	rkmDB["bdf"] = func() rkmethod { return newBDF(false) }
+	rkmDB["ndf"] = func() rkmethod { return newBDF(true) }
+}
+
+// Free releases memory
+func (o *BDF) Free() {
+	if o.ls != nil {
+		o.ls.Free()
+	}
+}
+
+// Info returns information about this method
+func (o *BDF) Info() (fixedOnly, implicit bool, nstages int) {
+	return false, true, 1
+}
+
+// Init initialises structure
+func (o *BDF) Init(ndim int, conf *Config, work *rkwork, stat *Stat, fcn Func, jac JacF, M *la.Triplet) {
+
+	// constants
+	o.MaxOrd = utl.Imax(1, utl.Imin(5, conf.BdfMaxOrd))
+	o.MaxJage = conf.BdfJacAge
+
+	// data
+	o.ndim = ndim
+	o.conf = conf
+	o.work = work
+	o.stat = stat
+	o.fcn = fcn
+	o.jac = jac
+	o.dfdy = new(la.Triplet)
+	o.mtri = M
+	if M == nil {
+		o.mtri = new(la.Triplet)
+		la.SpTriSetDiag(o.mtri, ndim, 1)
+	} else {
+		o.hasM = true
+		o.mmat = o.mtri.ToMatrix(nil)
+	}
+	o.kmat = new(la.Triplet)
+	o.ls = la.NewSparseSolver(o.conf.lsKind)
+
+	// state
+	o.D = make([]la.Vector, o.MaxOrd+3)
+	for j := 0; j < len(o.D); j++ {
+		o.D[j] = la.NewVector(ndim)
+	}
+	o.jage = -1
+
+	// workspace
+	o.ypred = la.NewVector(ndim)
+	o.psi = la.NewVector(ndim)
+	o.d = la.NewVector(ndim)
+	o.ynew = la.NewVector(ndim)
+	o.scal = la.NewVector(ndim)
+	o.r = la.NewVector(ndim)
+	o.dy = la.NewVector(ndim)
+	o.w = la.NewVector(ndim)
+	o.tmp = make([]la.Vector, o.MaxOrd+1)
+	for j := 0; j < len(o.tmp); j++ {
+		o.tmp[j] = la.NewVector(ndim)
+	}
+}
+
+// Accept accepts update and computes next stepsize and order
+func (o *BDF) Accept(y0 la.Vector, x0 float64) (dxnew float64) {
+
+	// update differences
+	h, k := o.work.h, o.order
+	for m := 0; m < o.ndim; m++ {
+		o.D[k+2][m] = o.d[m] - o.D[k+1][m]
+		o.D[k+1][m] = o.d[m]
+	}
+	for j := k; j >= 0; j-- {
+		la.VecAdd(o.D[j], 1, o.D[j+1], 1, o.D[j])
+	}
+
+	// data for dense output
+	o.xd, o.hd, o.ordd = o.x0+h, h, k
+
+	// update y and counters
+	y0.Apply(1, o.ynew)
+	o.nequal++
+	o.jage++
+
+	// keep stepsize constant for k+1 steps
+	dxnew = h
+	if o.nequal < k+1 {
+		return
+	}
+
+	// errors of orders k-1 and k+1
+	errm, errp := math.Inf(1), math.Inf(1)
+	if k > 1 {
+		errm = o.rmsNorm(o.ErrCte[k-1], o.D[k])
+	}
+	if k < o.MaxOrd {
+		errp = o.rmsNorm(o.ErrCte[k+1], o.D[k+2])
+	}
+
+	// select order with largest stepsize
+	fm := math.Pow(utl.Max(errm, 1e-10), -1.0/float64(k))
+	fk := math.Pow(o.work.rerr, -1.0/float64(k+1))
+	fp := math.Pow(utl.Max(errp, 1e-10), -1.0/float64(k+2))
+	fac := fk
+	if fm > fac {
+		fac, o.order = fm, k-1
+	}
+	if fp > fac {
+		fac, o.order = fp, k+1
+	}
+
+	// new stepsize
+	fac = utl.Max(o.conf.Mmin, utl.Min(o.conf.Mmax, o.safety*fac))
+	dxnew = h * fac
+	o.nequal = 0
+	return
+}
+
+// Reject processes step rejection and computes next stepsize
+func (o *BDF) Reject() (dxnew float64) {
+	fac := o.safety * math.Pow(o.work.rerr, -1.0/float64(o.order+1))
+	dxnew = o.work.h * utl.Max(o.conf.Mmin, fac)
+	return
+}
+
+// DenseOut produces dense output (after Accept)
+//
+//   y(xout) = D[0] + Σⱼ D[j] ⋅ Πᵢ (xout - x + i⋅h) / ((i+1)⋅h)    (j = 1..k, i = 0..j-1)
+//
+func (o *BDF) DenseOut(yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	yout.Apply(1, o.D[0])
+	p := 1.0
+	for j := 0; j < o.ordd; j++ {
+		p *= (xout - o.xd + float64(j)*o.hd) / (float64(j+1) * o.hd)
+		la.VecAdd(yout, p, o.D[j+1], 1, yout)
+	}
+}
+
+// Step steps update
+func (o *BDF) Step(x0 float64, y0 la.Vector) {
+
+	// auxiliary
+	h := o.work.h
+	o.x0 = x0
+
+	// initialise differences with first order
+	if o.work.first {
+		if o.conf.fixed && o.jac != nil {
+			o.stat.Nfeval++
+			o.fcn(o.work.f0, h, x0, y0)
+		}
+		o.D[0].Apply(1, y0)
+		o.D[1].Apply(h, o.work.f0)
+		o.order = 1
+		o.hD = h
+		o.nequal = 0
+
+		// change stepsize
+	} else if h != o.hD {
+		o.changeD(h / o.hD)
+		o.hD = h
+		o.nequal = 0
+	}
+
+	// prediction and ψ
+	k := o.order
+	o.ypred.Fill(0)
+	o.psi.Fill(0)
+	for j := 0; j <= k; j++ {
+		la.VecAdd(o.ypred, 1, o.D[j], 1, o.ypred)
+		if j > 0 {
+			la.VecAdd(o.psi, o.Gamma[j]/o.Alpha[k], o.D[j], 1, o.psi)
+		}
+	}
+	la.VecScaleAbs(o.scal, o.conf.atol, o.conf.rtol, o.ypred) // scal := atol + rtol * abs(ypred)
+	if o.conf.daeIdx != nil {
+		daeScale(o.scal, o.conf, o.ypred, h) // index-aware scaling of algebraic variables
+	}
+
+	// solve nonlinear system
+	c := h / o.Alpha[k]
+	var nit int
+	converged := false
+	for {
+
+		// Jacobian
+		newJac := false
+		if o.jage < 0 || o.jage >= o.MaxJage {
+			o.calcJac(h, x0, y0)
+			newJac = true
+		}
+
+		// decomposition
+		if newJac || c != o.cfact {
+			o.factorise(c)
+		}
+
+		// iterations
+		converged, nit = o.newton(c, x0+h)
+		if converged || o.jage == 0 {
+			break
+		}
+
+		// try again with a fresh Jacobian
+		o.jage = -1
+	}
+
+	// failed
+	if !converged {
+		o.work.diverg = true
+		o.work.dvfac = 0.5
+		return
+	}
+
+	// safety factor
+	nmax := float64(o.conf.NmaxIt)
+	o.safety = o.conf.Mfac * (2.0*nmax + 1.0) / (2.0*nmax + float64(nit))
+
+	// error estimate
+	la.VecScaleAbs(o.scal, o.conf.atol, o.conf.rtol, o.ynew) // scal := atol + rtol * abs(ynew)
+	if o.conf.daeIdx != nil {
+		daeScale(o.scal, o.conf, o.ynew, h) // index-aware scaling of algebraic variables
+	}
+	o.work.rerr = utl.Max(o.rmsNorm(o.ErrCte[k], o.d), 1.0e-10)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// calcJac computes the Jacobian matrix @ (x0,y0)
+func (o *BDF) calcJac(h, x0 float64, y0 la.Vector) {
+
+	// stat
+	o.stat.Njeval++
+
+	// numerical Jacobian
+	if o.jac == nil {
+		num.Jacobian(o.dfdy, func(fy, yy la.Vector) {
+			o.fcn(fy, h, x0, yy)
+		}, y0, o.work.f0, o.w) // w works here as workspace variable
+
+		// analytical Jacobian
+	} else {
+		o.jac(o.dfdy, h, x0, y0)
+	}
+	o.jage = 0
+}
+
+// factorise assembles and factorises M - c⋅J
+func (o *BDF) factorise(c float64) {
+
+	// initialise matrix
+	if !o.ready {
+		o.kmat.Init(o.ndim, o.ndim, o.mtri.Len()+o.dfdy.Len())
+	}
+
+	// update matrix
+	la.SpTriAdd(o.kmat, 1, o.mtri, -c, o.dfdy) // kmat := M - c⋅dfdy
+
+	// initialise linear solver
+	if !o.ready {
+		o.ls.Init(o.kmat, o.conf.Symmetric, o.conf.LsVerbose, o.conf.Ordering, o.conf.Scaling, o.conf.comm)
+		o.ready = true
+	}
+
+	// perform factorisation
+	o.stat.Ndecomp++
+	lsFact(o.ls, !o.fact)
+	o.fact = true
+	o.cfact = c
+}
+
+// newton solves M ⋅ (y_new - y_pred + ψ) = c ⋅ f(x_new, y_new) by the simplified Newton's method
+func (o *BDF) newton(c, x1 float64) (converged bool, nit int) {
+
+	// trial values
+	o.d.Fill(0)
+	o.ynew.Apply(1, o.ypred)
+
+	// iterations
+	h := o.work.h
+	var Ldy, LdyOld, rate float64
+	for it := 0; it < o.conf.NmaxIt; it++ {
+
+		// max iterations ?
+		nit = it + 1
+		o.work.nit = nit
+		if nit > o.stat.Nitmax {
+			o.stat.Nitmax = nit
+		}
+
+		// residual: r := c⋅f(x1,y_new) - M⋅(ψ + d)
+		o.stat.Nfeval++
+		o.fcn(o.r, h, x1, o.ynew)
+		la.VecAdd(o.w, 1, o.psi, 1, o.d) // w := ψ + d
+		if o.hasM {
+			o.r.Apply(c, o.r)                       // r := c⋅f
+			la.SpMatVecMulAdd(o.r, -1, o.mmat, o.w) // r -= M ⋅ w
+		} else {
+			la.VecAdd(o.r, c, o.r, -1, o.w)
+		}
+		for m := 0; m < o.ndim; m++ {
+			if math.IsNaN(o.r[m]) || math.IsInf(o.r[m], 0) {
+				return
+			}
+		}
+
+		// solve linear system
+		o.stat.Nlinsol++
+		o.ls.Solve(o.dy, o.r, false) // dy := inv(M - c⋅J) ⋅ r
+
+		// check convergence rate
+		Ldy = o.rmsNorm(1, o.dy)
+		if it > 0 {
+			rate = Ldy / LdyOld
+			o.work.theta = rate
+			if rate >= 1 || math.Pow(rate, float64(o.conf.NmaxIt-it))/(1-rate)*Ldy > o.conf.fnewt {
+				return
+			}
+		}
+
+		// update
+		la.VecAdd(o.ynew, 1, o.dy, 1, o.ynew)
+		la.VecAdd(o.d, 1, o.dy, 1, o.d)
+
+		// converged?
+		if Ldy == 0 || (it > 0 && rate/(1-rate)*Ldy < o.conf.fnewt) {
+			converged = true
+			return
+		}
+		LdyOld = Ldy
+	}
+	return
+}
+
+// changeD changes the differences D due to a change of stepsize: h_new = factor ⋅ h_old
+//
+//   D[0..k] := (R⋅U)ᵀ ⋅ D[0..k]   where R = R(factor) and U = R(1)
+//
+func (o *BDF) changeD(factor float64) {
+	k := o.order
+	R := bdfR(k, factor)
+	U := bdfR(k, 1)
+	for i := 0; i <= k; i++ {
+		o.tmp[i].Fill(0)
+		for j := 0; j <= k; j++ {
+			var ru float64 // (R⋅U)[j][i]
+			for l := 0; l <= k; l++ {
+				ru += R[j][l] * U[l][i]
+			}
+			la.VecAdd(o.tmp[i], ru, o.D[j], 1, o.tmp[i])
+		}
+	}
+	for i := 0; i <= k; i++ {
+		o.D[i].Apply(1, o.tmp[i])
+	}
+}
+
+// rmsNorm computes the RMS norm of α⋅v scaled by scal
+func (o *BDF) rmsNorm(α float64, v la.Vector) float64 {
+	var sum, ratio float64
+	for m := 0; m < o.ndim; m++ {
+		ratio = α * v[m] / o.scal[m]
+		sum += ratio * ratio
+	}
+	return math.Sqrt(sum / float64(o.ndim))
+}
+
+// bdfR computes the matrix R used to change the differences
+//
+//   R[i][j] = Πₗ (l - 1 - factor⋅j) / l   (l = 1..i)    with    R[0][j] = 1
+//
+func bdfR(k int, factor float64) (R [][]float64) {
+	R = utl.Alloc(k+1, k+1)
+	for j := 0; j <= k; j++ {
+		R[0][j] = 1
+	}
+	for i := 1; i <= k; i++ {
+		for j := 1; j <= k; j++ {
+			R[i][j] = R[i-1][j] * (float64(i) - 1 - factor*float64(j)) / float64(i)
+		}
+	}
+	return
+}
+
+// newBDF returns a new BDF/NDF method with the coefficients set
+func newBDF(ndf bool) rkmethod {
+	o := new(BDF)
+	n := 6
+	o.Kappa = make([]float64, n)
+	if ndf {
+		o.Kappa = []float64{0, -0.1850, -1.0 / 9.0, -0.0823, -0.0415, 0}
+	}
+	o.Gamma = make([]float64, n)
+	o.Alpha = make([]float64, n)
+	o.ErrCte = make([]float64, n)
+	for k := 0; k < n; k++ {
+		if k > 0 {
+			o.Gamma[k] = o.Gamma[k-1] + 1.0/float64(k)
+		}
+		o.Alpha[k] = (1 - o.Kappa[k]) * o.Gamma[k]
+		o.ErrCte[k] = o.Kappa[k]*o.Gamma[k] + 1.0/float64(k+1)
+	}
+	return o
+}
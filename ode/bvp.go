@@ -0,0 +1,448 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+	"github.com/cpmech/gosl/utl"
+)
+
+// BvpSolver implements a collocation solver for two-point boundary value problems (BVPs)
+//
+//   dy/dx = f(x, y)   for a ≤ x ≤ b   with   g(y(a), y(b)) = 0
+//
+//  The three-stage Lobatto IIIA formula (Simpson's rule) is used as in MATLAB's bvp4c [1]; i.e.
+//  the solution is approximated by a continuous and continuously differentiable cubic S(x) on
+//  each interval [xᵢ, xᵢ₊₁] of the mesh, collocated at both ends and at the midpoint
+//
+//   yᵢ₊₁ - yᵢ - h/6 ⋅ (fᵢ + 4⋅f(xᵢ + h/2, ymᵢ) + fᵢ₊₁) = 0
+//   ymᵢ = (yᵢ + yᵢ₊₁)/2 - h/8 ⋅ (fᵢ₊₁ - fᵢ)
+//
+//  These equations and the boundary conditions are solved by a damped Newton's method with a
+//  sparse Jacobian matrix. The mesh is then adapted based on the residual r(x) = S'(x) - f(x,S(x))
+//  scaled by max(|f|, Atol/Rtol), which is integrated over each interval with the 5-point Lobatto
+//  quadrature. Intervals with residuals larger than Rtol are divided into two or three parts
+//  and consecutive intervals with very small residuals are merged. The process is repeated until
+//  all residuals are smaller than Rtol.
+//
+//   Reference:
+//     [1] Kierzenka J and Shampine LF (2001) A BVP solver based on residual control and the MATLAB
+//         PSE. ACM Transactions on Mathematical Software, 27(3):299-316
+//
+type BvpSolver struct {
+
+	// parameters
+	Atol     float64 // absolute tolerance [default = 1e-6]
+	Rtol     float64 // relative tolerance of residuals [default = 1e-3]
+	NmaxMesh int     // maximum number of mesh points [default = 5000]
+	NmaxIt   int     // maximum number of Newton iterations for each mesh [default = 40]
+
+	// output
+	X    []float64   // mesh
+	Y    []la.Vector // y @ mesh points
+	F    []la.Vector // f(x,y) @ mesh points
+	Res  []float64   // scaled residuals of intervals
+	Nsub int         // number of meshes (after Solve)
+	Stat *Stat       // statistics (Nsteps is the total number of Newton iterations)
+
+	// problem definition
+	ndim int         // size of y
+	fcn  Func        // f(x,y) = dy/dx
+	jac  JacF        // df/dy [may be nil]
+	bc   BvpBcF      // boundary conditions g(ya, yb)
+	bcJ  BvpBcJF     // dg/dya and dg/dyb [may be nil]
+	lsk  string      // kind of linear solver
+	dfdy *la.Triplet // df/dy
+
+	// linear system
+	ls   la.SparseSolver // linear solver
+	jmat *la.Triplet     // Jacobian matrix of collocation equations
+	r    la.Vector       // residual of collocation equations
+	du   la.Vector       // correction
+	neq  int             // number of equations in ls and jmat
+
+	// workspace
+	ym, fm   []la.Vector  // y and f @ midpoints
+	jn, jm   []*la.Matrix // df/dy @ mesh points and midpoints
+	ga, gb   *la.Matrix   // dg/dya and dg/dyb
+	g, w, yt la.Vector    // boundary conditions and workspace
+}
+
+// NewBvpSolver returns a new collocation solver for BVPs
+//   ndim   -- problem dimension
+//   fcn    -- f(x,y) = dy/dx function
+//   jac    -- Jacobian: df/dy function [may be nil ⇒ numerical Jacobian]
+//   bc     -- boundary conditions g(ya, yb) = 0 (ndim equations)
+//   bcJ    -- Jacobian of boundary conditions [may be nil ⇒ numerical Jacobian]
+//   lsKind -- kind of linear solver: "umfpack" or "mumps" [may be empty ⇒ "umfpack"]
+//   NOTE: remember to call Free() to release allocated resources
+func NewBvpSolver(ndim int, fcn Func, jac JacF, bc BvpBcF, bcJ BvpBcJF, lsKind string) (o *BvpSolver) {
+	if lsKind == "" {
+		lsKind = "umfpack"
+	}
+	o = new(BvpSolver)
+	o.Atol = 1e-6
+	o.Rtol = 1e-3
+	o.NmaxMesh = 5000
+	o.NmaxIt = 40
+	o.Stat = NewStat(lsKind, true)
+	o.ndim = ndim
+	o.fcn = fcn
+	o.jac = jac
+	o.bc = bc
+	o.bcJ = bcJ
+	o.lsk = lsKind
+	o.dfdy = new(la.Triplet)
+	o.ga, o.gb = la.NewMatrix(ndim, ndim), la.NewMatrix(ndim, ndim)
+	o.g, o.w, o.yt = la.NewVector(ndim), la.NewVector(ndim), la.NewVector(ndim)
+	return
+}
+
+// Free releases allocated memory
+func (o *BvpSolver) Free() {
+	if o.ls != nil {
+		o.ls.Free()
+	}
+}
+
+// Solve solves the BVP
+//   mesh  -- initial mesh with mesh[0] = a and mesh[len(mesh)-1] = b
+//   guess -- initial guess y(x)
+//   NOTE: the solution is given by X, Y and F or can be evaluated at any x by Eval
+func (o *BvpSolver) Solve(mesh []float64, guess BvpGuessF) {
+
+	// check
+	if len(mesh) < 2 {
+		chk.Panic("the mesh must have at least two points\n")
+	}
+	for i := 1; i < len(mesh); i++ {
+		if mesh[i] <= mesh[i-1] {
+			chk.Panic("the mesh must be strictly increasing. x%d = %g ≤ x%d = %g\n", i, mesh[i], i-1, mesh[i-1])
+		}
+	}
+
+	// initial guess
+	o.Stat.Reset()
+	o.setMesh(mesh)
+	for i, x := range o.X {
+		guess(o.Y[i], x)
+	}
+
+	// meshes
+	for o.Nsub = 1; ; o.Nsub++ {
+
+		// collocation equations
+		o.newton()
+
+		// residuals
+		o.residuals()
+		rmax := 0.0
+		for _, ρ := range o.Res {
+			rmax = math.Max(rmax, ρ)
+		}
+		if rmax <= o.Rtol {
+			return
+		}
+
+		// new mesh
+		var mesh []float64
+		N := len(o.Res)
+		for i := 0; i < N; i++ {
+			x, h := o.X[i], o.X[i+1]-o.X[i]
+			if o.Res[i] > o.Rtol {
+				mesh = append(mesh, x, x+h/2)
+				if o.Res[i] > 100*o.Rtol {
+					mesh = append(mesh[:len(mesh)-1], x+h/3, x+2*h/3)
+				}
+				continue
+			}
+			mesh = append(mesh, x)
+			if i+1 < N && o.Res[i] < o.Rtol/100 && o.Res[i+1] < o.Rtol/100 {
+				i++ // merge intervals i and i+1
+			}
+		}
+		mesh = append(mesh, o.X[N])
+		if len(mesh) > o.NmaxMesh {
+			chk.Panic("the number of mesh points (%d) would exceed the maximum (%d). max residual = %g\n", len(mesh), o.NmaxMesh, rmax)
+		}
+
+		// interpolate solution onto the new mesh
+		Y := make([]la.Vector, len(mesh))
+		for i, x := range mesh {
+			Y[i] = la.NewVector(o.ndim)
+			o.Eval(Y[i], x)
+		}
+		o.setMesh(mesh)
+		for i := range Y {
+			o.Y[i].Apply(1, Y[i])
+		}
+	}
+}
+
+// Eval evaluates the solution y(x) with a ≤ x ≤ b using the cubic interpolant
+func (o *BvpSolver) Eval(y la.Vector, x float64) {
+	N := len(o.X) - 1
+	i := sort.SearchFloat64s(o.X, x) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i > N-1 {
+		i = N - 1
+	}
+	o.hermite(y, nil, i, x)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// setMesh sets the mesh and allocates the arrays
+func (o *BvpSolver) setMesh(mesh []float64) {
+	n, N := o.ndim, len(mesh)-1
+	o.X = make([]float64, N+1)
+	copy(o.X, mesh)
+	o.Y, o.F = make([]la.Vector, N+1), make([]la.Vector, N+1)
+	o.jn = make([]*la.Matrix, N+1)
+	for i := 0; i <= N; i++ {
+		o.Y[i], o.F[i] = la.NewVector(n), la.NewVector(n)
+	}
+	o.ym, o.fm = make([]la.Vector, N), make([]la.Vector, N)
+	o.jm = make([]*la.Matrix, N)
+	for i := 0; i < N; i++ {
+		o.ym[i], o.fm[i] = la.NewVector(n), la.NewVector(n)
+	}
+	o.Res = make([]float64, N)
+
+	// linear system
+	neq := n * (N + 1)
+	if neq != o.neq {
+		if o.ls != nil {
+			o.ls.Free()
+			o.ls = nil
+		}
+		o.neq = neq
+		o.jmat = new(la.Triplet)
+		o.jmat.Init(neq, neq, 2*n*n*(N+1))
+		o.r, o.du = la.NewVector(neq), la.NewVector(neq)
+	}
+}
+
+// newton solves the collocation equations by the damped Newton's method
+func (o *BvpSolver) newton() {
+	n := o.ndim
+	o.collocation(o.r)
+	rnorm := o.r.Largest(1)
+	for it := 0; it < o.NmaxIt; it++ {
+
+		// solve linear system
+		o.assemble()
+		if o.ls == nil {
+			o.ls = la.NewSparseSolver(o.lsk)
+			o.ls.Init(o.jmat, false, false, "", "", nil)
+		}
+		o.ls.Fact()
+		o.ls.Solve(o.du, o.r, false)
+		o.Stat.Ndecomp++
+		o.Stat.Nlinsol++
+		o.Stat.Nsteps++
+		o.Stat.Nitmax = utl.Imax(o.Stat.Nitmax, it+1)
+
+		// scaled norm of correction
+		δ := 0.0
+		for i := range o.Y {
+			for j := 0; j < n; j++ {
+				δ = math.Max(δ, math.Abs(o.du[i*n+j])/(o.Atol+o.Rtol*math.Abs(o.Y[i][j])))
+			}
+		}
+
+		// converged: the (negligible) correction is applied
+		if δ < 0.01 {
+			for i := range o.Y {
+				for j := 0; j < n; j++ {
+					o.Y[i][j] -= o.du[i*n+j]
+				}
+			}
+			o.collocation(o.r) // update F, ym and fm
+			return
+		}
+
+		// damped update: the step is halved until the residual decreases
+		λ := 1.0
+		for {
+			for i := range o.Y {
+				for j := 0; j < n; j++ {
+					o.Y[i][j] -= λ * o.du[i*n+j]
+				}
+			}
+			o.collocation(o.r)
+			rnew := o.r.Largest(1)
+			if rnew < rnorm || λ < 1.0/64.0 {
+				rnorm = rnew
+				break
+			}
+			for i := range o.Y {
+				for j := 0; j < n; j++ {
+					o.Y[i][j] += λ * o.du[i*n+j]
+				}
+			}
+			λ /= 2
+		}
+	}
+	chk.Panic("Newton's method did not converge after %d iterations (mesh with %d points)\n", o.NmaxIt, len(o.X))
+}
+
+// collocation computes the residual of the collocation equations and boundary conditions
+func (o *BvpSolver) collocation(r la.Vector) {
+	n, N := o.ndim, len(o.X)-1
+	for i, x := range o.X {
+		o.fcn(o.F[i], 0, x, o.Y[i])
+	}
+	o.bc(o.g, o.Y[0], o.Y[N])
+	copy(r, o.g)
+	for i := 0; i < N; i++ {
+		h := o.X[i+1] - o.X[i]
+		for j := 0; j < n; j++ {
+			o.ym[i][j] = (o.Y[i][j]+o.Y[i+1][j])/2 - h*(o.F[i+1][j]-o.F[i][j])/8
+		}
+		o.fcn(o.fm[i], 0, o.X[i]+h/2, o.ym[i])
+		for j := 0; j < n; j++ {
+			r[n+i*n+j] = o.Y[i+1][j] - o.Y[i][j] - h*(o.F[i][j]+4*o.fm[i][j]+o.F[i+1][j])/6
+		}
+	}
+	o.Stat.Nfeval += 2*N + 1
+}
+
+// assemble assembles the Jacobian matrix of the collocation equations
+//
+//   ∂Φᵢ/∂yᵢ   = -I - h/6⋅Jᵢ   - 2h/3⋅Jmᵢ⋅(I/2 + h/8⋅Jᵢ)
+//   ∂Φᵢ/∂yᵢ₊₁ =  I - h/6⋅Jᵢ₊₁ - 2h/3⋅Jmᵢ⋅(I/2 - h/8⋅Jᵢ₊₁)
+//
+func (o *BvpSolver) assemble() {
+
+	// Jacobians of f
+	n, N := o.ndim, len(o.X)-1
+	for i, x := range o.X {
+		o.jn[i] = o.calcJac(x, o.Y[i], o.F[i])
+	}
+	for i := 0; i < N; i++ {
+		o.jm[i] = o.calcJac((o.X[i]+o.X[i+1])/2, o.ym[i], o.fm[i])
+	}
+
+	// boundary conditions
+	o.jmat.Start()
+	o.calcBcJac()
+	for k := 0; k < n; k++ {
+		for j := 0; j < n; j++ {
+			o.jmat.Put(k, j, o.ga.Get(k, j))
+			o.jmat.Put(k, N*n+j, o.gb.Get(k, j))
+		}
+	}
+
+	// collocation equations
+	for i := 0; i < N; i++ {
+		h := o.X[i+1] - o.X[i]
+		Ja, Jb, Jm := o.jn[i], o.jn[i+1], o.jm[i]
+		for k := 0; k < n; k++ {
+			for j := 0; j < n; j++ {
+				A := -h * Ja.Get(k, j) / 6
+				B := -h * Jb.Get(k, j) / 6
+				for l := 0; l < n; l++ {
+					A -= 2 * h * Jm.Get(k, l) * h * Ja.Get(l, j) / (3 * 8)
+					B += 2 * h * Jm.Get(k, l) * h * Jb.Get(l, j) / (3 * 8)
+				}
+				A -= 2 * h * Jm.Get(k, j) / (3 * 2)
+				B -= 2 * h * Jm.Get(k, j) / (3 * 2)
+				if k == j {
+					A -= 1
+					B += 1
+				}
+				o.jmat.Put(n+i*n+k, i*n+j, A)
+				o.jmat.Put(n+i*n+k, (i+1)*n+j, B)
+			}
+		}
+	}
+}
+
+// calcJac computes df/dy @ (x,y) as a dense matrix
+func (o *BvpSolver) calcJac(x float64, y, f la.Vector) *la.Matrix {
+	o.Stat.Njeval++
+	if o.jac == nil {
+		num.Jacobian(o.dfdy, func(fy, yy la.Vector) {
+			o.fcn(fy, 0, x, yy)
+		}, y, f, o.w)
+		o.Stat.Nfeval += o.ndim
+	} else {
+		o.jac(o.dfdy, 0, x, y)
+	}
+	return o.dfdy.ToDense()
+}
+
+// calcBcJac computes dg/dya and dg/dyb
+func (o *BvpSolver) calcBcJac() {
+	N := len(o.X) - 1
+	if o.bcJ != nil {
+		o.bcJ(o.ga, o.gb, o.Y[0], o.Y[N])
+		return
+	}
+	o.bc(o.g, o.Y[0], o.Y[N])
+	for _, c := range []struct {
+		y  la.Vector
+		dg *la.Matrix
+	}{{o.Y[0], o.ga}, {o.Y[N], o.gb}} {
+		for j := 0; j < o.ndim; j++ {
+			ysafe := c.y[j]
+			δ := math.Sqrt(num.MACHEPS * math.Max(1e-5, math.Abs(ysafe)))
+			c.y[j] = ysafe + δ
+			o.bc(o.w, o.Y[0], o.Y[N])
+			c.y[j] = ysafe
+			for k := 0; k < o.ndim; k++ {
+				c.dg.Set(k, j, (o.w[k]-o.g[k])/δ)
+			}
+		}
+	}
+}
+
+// residuals computes the scaled residuals of all intervals using the 5-point Lobatto quadrature;
+// the residual vanishes at the end points and at the midpoint (collocation points)
+func (o *BvpSolver) residuals() {
+	n := o.ndim
+	ξ, w := math.Sqrt(3.0/7.0), 49.0/90.0
+	dy, f := la.NewVector(n), la.NewVector(n)
+	for i := range o.Res {
+		xc, h := (o.X[i]+o.X[i+1])/2, o.X[i+1]-o.X[i]
+		sum := 0.0
+		for _, s := range []float64{-ξ, ξ} {
+			x := xc + s*h/2
+			o.hermite(o.yt, dy, i, x)
+			o.fcn(f, 0, x, o.yt)
+			ρ := 0.0
+			for j := 0; j < n; j++ {
+				ρ = math.Max(ρ, math.Abs(dy[j]-f[j])/math.Max(math.Abs(f[j]), o.Atol/o.Rtol))
+			}
+			sum += w * ρ * ρ / 2
+		}
+		o.Res[i] = math.Sqrt(sum)
+	}
+	o.Stat.Nfeval += 2 * len(o.Res)
+}
+
+// hermite evaluates the cubic Hermite interpolant S(x) and its derivative [may be nil] on interval i
+func (o *BvpSolver) hermite(y, dy la.Vector, i int, x float64) {
+	h := o.X[i+1] - o.X[i]
+	t := (x - o.X[i]) / h
+	h00, h10, h01, h11 := (1+2*t)*(1-t)*(1-t), t*(1-t)*(1-t), t*t*(3-2*t), t*t*(t-1)
+	for j := 0; j < o.ndim; j++ {
+		y[j] = h00*o.Y[i][j] + h10*h*o.F[i][j] + h01*o.Y[i+1][j] + h11*h*o.F[i+1][j]
+	}
+	if dy != nil {
+		d00, d10, d01, d11 := 6*t*(t-1), (1-t)*(1-3*t), 6*t*(1-t), t*(3*t-2)
+		for j := 0; j < o.ndim; j++ {
+			dy[j] = (d00*o.Y[i][j]+d01*o.Y[i+1][j])/h + d10*o.F[i][j] + d11*o.F[i+1][j]
+		}
+	}
+}
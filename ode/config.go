@@ -36,6 +36,9 @@ type Config struct {
 	Verbose    bool    // show messages, e.g. during iterations
 	ZeroTrial  bool    // always start iterations with zero trial values (instead of collocation interpolation)
 	StabBeta   float64 // Lund stabilisation coefficient β
+	BdfMaxOrd  int     // maximum order of BDF/NDF methods (1 to 5) [default = 5]
+	BdfJacAge  int     // maximum number of steps before recomputing the Jacobian in BDF/NDF [default = 20]
+	SymEps     float64 // fictitious stepsize ε of adaptive symplectic methods: h ≈ ε/‖f‖ [default = 0 ⇒ ε = Rtol^(1/order)]
 
 	// stiffness detection
 	StiffNstp  int     // number of steps to check stiff situation. 0 ⇒ no check. [default = 1]
@@ -50,6 +53,8 @@ type Config struct {
 	stepOut   bool      // perform output of (variable) steps
 	denseOut  bool      // perform dense output is active
 	denseNstp int       // number of dense steps
+	events    []*event  // event functions
+	keepDense bool      // always prepare data for dense output (e.g. for delay differential equations)
 
 	// linear solver
 	Symmetric bool   // assume symmetric matrix
@@ -78,10 +83,16 @@ type Config struct {
 	fixed       bool    // use fixed steps
 	fixedH      float64 // value of fixed stepsize
 	fixedNsteps int     // number of fixed steps
+
+	// implicit-explicit methods
+	fcnE Func // non-stiff (explicit) part of dy/dx [may be nil]
+
+	// differential-algebraic equations
+	daeIdx []int // index of variables: 0 ⇒ differential, 1 or 2 ⇒ algebraic of index 1 or 2 [may be nil]
 }
 
 // NewConfig returns a new [default] set of configuration parameters
-//   method -- the ODE method: e.g. fweuler, bweuler, radau5, moeuler, dopri5
+//   method -- the ODE method: e.g. fweuler, bweuler, radau5, moeuler, dopri5, ros3p, rodas, ark4, bdf, verlet
 //   comm   -- communicator for the linear solver [may be nil]
 //   lsKind -- kind of linear solver: "umfpack" or "mumps" [may be empty]
 //   NOTE: (1) if comm == nil, the linear solver will be "umfpack" by default
@@ -110,6 +121,8 @@ func NewConfig(method string, lsKind string, comm *mpi.Communicator) (o *Config)
 	o.CteTg = false
 	o.UseRmsNorm = true
 	o.Verbose = false
+	o.BdfMaxOrd = 5
+	o.BdfJacAge = 20
 
 	// stiffness detection
 	o.StiffNstp = 0
@@ -210,3 +223,66 @@ func (o *Config) SetDenseOut(save bool, dxOut, xf float64, out DenseOutF) {
 		o.denseDx = dxOut
 	}
 }
+
+// AddEvent adds an event function g(x,y) such that the event is located where g(x,y) = 0
+//  g         -- event function
+//  direction -- direction of crossing: 0 ⇒ any, +1 ⇒ g increases only, -1 ⇒ g decreases only
+//  terminal  -- stop simulation at the event
+//  NOTE: the root of g is found using the dense output of the method; thus, the method must
+//        provide dense output
+func (o *Config) AddEvent(g EventF, direction int, terminal bool) {
+	o.events = append(o.events, &event{g, direction, terminal})
+}
+
+// SetIMEX sets the non-stiff part fE of the right-hand side for implicit-explicit methods
+// (ark3, ark4, ark5) such that dy/dx = fI(x,y) + fE(x,y), where fI is the (stiff) function given
+// to NewSolver. The Jacobian given to NewSolver (if any) must correspond to fI only.
+func (o *Config) SetIMEX(fE Func) {
+	switch o.method {
+	case "ark3", "ark4", "ark5":
+		o.fcnE = fE
+	default:
+		chk.Panic("method %q cannot handle split (implicit-explicit) functions\n", o.method)
+	}
+}
+
+// SetDAE sets the index of each variable of a (semi-explicit) differential-algebraic system
+// M ⋅ dy/dx = f(x,y), where the rows of the "mass" matrix M corresponding to algebraic equations
+// are zero. The i-th equation is the one "associated" with the i-th variable; thus, the rows of M
+// corresponding to algebraic variables must be zero.
+//   varIndex -- index of each variable: 0 ⇒ differential; 1 ⇒ algebraic variable of index 1;
+//               2 ⇒ algebraic variable of index 2 (Hessenberg form)
+//   NOTE: (1) methods radau5, bdf and ndf can handle index-1 and index-2 systems; methods ros3p
+//             and rodas can handle index-1 systems only
+//         (2) the error of index-2 variables is scaled by the stepsize h (index-aware error control)
+//         (3) the initial values must be consistent; see Solver.ConsistentInit
+func (o *Config) SetDAE(varIndex []int) {
+	maxIdx := 0
+	switch o.method {
+	case "radau5", "bdf", "ndf":
+		maxIdx = 2
+	case "ros3p", "rodas":
+		maxIdx = 1
+	default:
+		chk.Panic("method %q cannot handle differential-algebraic equations\n", o.method)
+	}
+	for i, idx := range varIndex {
+		if idx < 0 || idx > maxIdx {
+			chk.Panic("index of variable %d must be in [0, %d] with method %q. %d is invalid\n", i, maxIdx, o.method, idx)
+		}
+	}
+	o.daeIdx = make([]int, len(varIndex))
+	copy(o.daeIdx, varIndex)
+}
+
+// needDense returns whether the method has to prepare data for dense output or not
+func (o *Config) needDense() bool {
+	return o.denseOut || o.denseF != nil || len(o.events) > 0 || o.keepDense
+}
+
+// event holds data of an event function
+type event struct {
+	g         EventF // event function
+	direction int    // direction of crossing: 0 ⇒ any, +1 ⇒ increasing, -1 ⇒ decreasing
+	terminal  bool   // stop simulation at event
+}
@@ -0,0 +1,168 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+)
+
+// ConsistentInit computes consistent initial values for semi-explicit differential-algebraic
+// equations (DAEs) of index 1 or 2 (Hessenberg form) defined by
+//
+//	 M ⋅ dy/dx = f(x,y)   with   M = [Md 0]   and   y = [yd]  (differential variables)
+//	                                 [0  0]             [ya]  (algebraic variables)
+//
+//	INPUT:
+//	 x -- initial x
+//	 y -- initial values. the differential components must be given and, for index-2 systems,
+//	      must satisfy the constraints; the algebraic components are taken as initial guesses
+//
+//	OUTPUT:
+//	 y    -- the algebraic components are updated
+//	 dydx -- consistent dy/dx (the derivatives of algebraic variables are computed by finite
+//	         differences)
+//
+//	NOTE: (1) conf.SetDAE must be called before NewSolver and M must be given to NewSolver
+//	      (2) the equations associated with index-1 variables, 0 = g(x,yd,ya), are solved
+//	          directly; for the equations associated with index-2 variables, 0 = g(x,yd), the
+//	          hidden constraint dg/dx = ∂g/∂x + ∂g/∂yd ⋅ dyd/dx = 0 is solved instead
+//	      (3) the nonlinear system is solved with num.NlSolver using a numerical Jacobian
+func (o *Solver) ConsistentInit(dydx, y la.Vector, x float64) {
+
+	// check
+	if o.conf.daeIdx == nil {
+		chk.Panic("ConsistentInit requires the indices of variables. call conf.SetDAE first\n")
+	}
+	if o.conf.distr {
+		chk.Panic("ConsistentInit is not available in distributed (MPI) runs\n")
+	}
+
+	// algebraic variables
+	var alg []int
+	hasIdx2 := false
+	for i, idx := range o.conf.daeIdx {
+		if idx > 0 {
+			alg = append(alg, i)
+		}
+		if idx == 2 {
+			hasIdx2 = true
+		}
+	}
+	na := len(alg)
+
+	// matrix to compute dyd/dx: K = [Md 0; 0 I]
+	P := new(la.Triplet)
+	P.Init(o.ndim, o.ndim, na)
+	for _, i := range alg {
+		P.Put(i, i, 1)
+	}
+	K := new(la.Triplet)
+	K.Init(o.ndim, o.ndim, o.mtri.Len()+na)
+	la.SpTriAdd(K, 1, o.mtri, 1, P)
+	ls := la.NewSparseSolver(o.conf.lsKind)
+	defer ls.Free()
+	ls.Init(K, o.conf.Symmetric, o.conf.LsVerbose, o.conf.Ordering, o.conf.Scaling, nil)
+	ls.Fact()
+
+	// workspace
+	f := la.NewVector(o.ndim)
+	fp := la.NewVector(o.ndim)
+	fm := la.NewVector(o.ndim)
+	yp := la.NewVector(o.ndim)
+	ym := la.NewVector(o.ndim)
+	yd := la.NewVector(o.ndim)
+	ε := math.Cbrt(num.MACHEPS) * math.Max(1, math.Abs(x)) // for hidden constraints
+	δ := 1e-4 * math.Max(1, math.Abs(x))                   // for derivatives of algebraic variables
+
+	// derivatives of differential variables @ (xx,yy): K ⋅ dydx = f with f[alg] = 0
+	derivs := func(dydx la.Vector, xx float64, yy la.Vector) {
+		o.fcn(f, 0, xx, yy)
+		for _, i := range alg {
+			f[i] = 0
+		}
+		ls.Solve(dydx, f, false)
+	}
+
+	// residual of algebraic equations @ (xc,y) with y[alg] = ya
+	var xc float64
+	ffcn := func(r, ya la.Vector) {
+		for k, i := range alg {
+			y[i], yp[i], ym[i] = ya[k], ya[k], ya[k]
+		}
+		if hasIdx2 {
+			derivs(yd, xc, y)
+			for i, idx := range o.conf.daeIdx {
+				if idx == 0 {
+					yp[i] = y[i] + ε*yd[i]
+					ym[i] = y[i] - ε*yd[i]
+				}
+			}
+			o.fcn(fp, 0, xc+ε, yp)
+			o.fcn(fm, 0, xc-ε, ym)
+		}
+		o.fcn(f, 0, xc, y)
+		for k, i := range alg {
+			if o.conf.daeIdx[i] == 1 {
+				r[k] = f[i]
+			} else {
+				r[k] = (fp[i] - fm[i]) / (2.0 * ε) // hidden constraint
+			}
+		}
+	}
+
+	// nonlinear solver
+	var nls num.NlSolver
+	defer nls.Free()
+	nls.Init(na, ffcn, nil, nil, false, true, map[string]float64{"atol": 1e-8, "rtol": 1e-8, "ftol": 1e-9})
+
+	// solve for algebraic variables @ x
+	ya := la.NewVector(na)
+	for k, i := range alg {
+		ya[k] = y[i]
+	}
+	xc = x
+	nls.Solve(ya, true)
+
+	// solve for algebraic variables @ x ± δ in order to compute their derivatives
+	y0 := y.GetCopy()
+	for k, i := range alg {
+		y0[i] = ya[k]
+	}
+	derivs(dydx, x, y0)
+	yap, yam := ya.GetCopy(), ya.GetCopy()
+	for _, s := range []float64{+1, -1} {
+		for i, idx := range o.conf.daeIdx {
+			if idx == 0 {
+				y[i] = y0[i] + s*δ*dydx[i]
+			}
+		}
+		xc = x + s*δ
+		if s > 0 {
+			nls.Solve(yap, true)
+		} else {
+			nls.Solve(yam, true)
+		}
+	}
+	for k, i := range alg {
+		dydx[i] = (yap[k] - yam[k]) / (2.0 * δ)
+	}
+
+	// results
+	y.Apply(1, y0)
+}
+
+// daeScale computes the index-aware scaling factors of index-2 algebraic variables. The error of
+// index-2 variables is one order lower; thus, their scaling factors are divided by h [2]
+func daeScale(scal la.Vector, conf *Config, y la.Vector, h float64) {
+	for i, idx := range conf.daeIdx {
+		if idx == 2 {
+			scal[i] = (conf.atol + conf.rtol*math.Abs(y[i])) / h
+		}
+	}
+}
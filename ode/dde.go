@@ -0,0 +1,339 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// DdeSolver implements a solver for delay differential equations (DDEs) with constant and
+// state-dependent delays
+//
+//   dy/dx = f(x, y(x), y(x-τ0), y(x-τ1), ...)   for x ≥ x0   with   y(x) = φ(x)   for x < x0
+//
+//  The equations are solved by an explicit Runge-Kutta method with dense output (dopri5 or
+//  dopri8). The dense output coefficients of all accepted steps are saved in a history buffer,
+//  which is then used to compute the lagged values y(x-τ). The history function φ is used if
+//  x-τ < x0. To avoid x-τ falling within the current step, the integration is carried out in
+//  sub-intervals not longer than the smallest constant delay. Nonetheless, if x-τ(x,y) falls
+//  within the current step (e.g. vanishing state-dependent delays), the dense output of the last
+//  accepted step is extrapolated.
+//
+//  The discontinuities of the derivatives of y (e.g. at x0, where φ'(x0) ≠ f in general) are
+//  propagated by the delays: a discontinuity at ξ produces another discontinuity (of higher
+//  level; i.e. in a higher derivative) at x such that x - τ(x,y) = ξ. The integration is restarted
+//  at each discontinuity with level ≤ MaxLevel. For constant delays, the discontinuities are
+//  known in advance (ξ + τ); for state-dependent delays, they are located as terminal events
+//  (see Config.AddEvent) with g(x,y) = x - τ(x,y) - ξ.
+//
+//  NOTE: (1) fixed steps and dense output are not available; the step output (Config.SetStepOut)
+//            can be used instead
+//        (2) the events located by Sol.Out include the discontinuities of state-dependent delays,
+//            with indices (EventK) after the indices of the events given to Config.AddEvent
+//
+type DdeSolver struct {
+
+	// solver
+	Sol      *Solver // ODE solver (explicit Runge-Kutta method)
+	Stat     *Stat   // statistics (accumulated over all sub-intervals between discontinuities)
+	MaxLevel int     // max level of tracked discontinuities [default = order of the method]
+
+	// discontinuities
+	DiscX []float64 // x of tracked discontinuities (sorted)
+	DiscL []int     // level of tracked discontinuities (order of the derivative with a jump)
+
+	// problem definition
+	ndim   int         // size of y
+	conf   Config      // configuration (copy)
+	fcn    DdeFunc     // dy/dx := f(x, y, ylag)
+	hist   HistoryF    // history φ(x) for x < x0
+	lags   []float64   // constant delays
+	lagMin float64     // smallest constant delay
+	delays []DelayF    // state-dependent delays
+	erk    *ExplicitRK // Runge-Kutta method
+	iniH   float64     // initial stepsize given in conf
+	nev    int         // number of events given to conf
+
+	// history buffer
+	x0   float64       // initial x
+	y0   la.Vector     // initial y
+	bufX []float64     // x at the beginning of accepted steps
+	bufH []float64     // stepsizes of accepted steps
+	bufD [][]la.Vector // dense output coefficients of accepted steps
+	xa   float64       // x at the beginning of the next step
+
+	// auxiliary
+	ylag    []la.Vector // lagged values [ndelays][ndim]
+	xiNext  []float64   // next discontinuity to be crossed by x-τ(x,y) of state-dependent delays
+	xiLevel []int       // level of xiNext
+	stepF   StepOutF    // step output function given to conf [may be nil]
+	segment int         // index of current sub-interval
+	istep   int         // index of step (considering all sub-intervals)
+	stopped bool        // stopped by step output function
+}
+
+// NewDdeSolver returns a new DDE solver
+//
+//  INPUT:
+//    ndim   -- problem dimension
+//    conf   -- configuration parameters; the method must be dopri5 or dopri8
+//    fcn    -- f(x, y, ylag) = dy/dx function
+//    hist   -- history function φ(x) for x < x0
+//    lags   -- constant delays τ > 0 [may be nil]
+//    delays -- state-dependent delays τ(x,y) ≥ 0 [may be nil]
+//
+//  NOTE: (1) ylag in fcn holds the lagged values of the constant delays followed by the lagged
+//            values of the state-dependent delays
+//        (2) remember to call Free() to release allocated resources
+//
+func NewDdeSolver(ndim int, conf *Config, fcn DdeFunc, hist HistoryF, lags []float64, delays []DelayF) (o *DdeSolver) {
+
+	// check
+	if conf.fixed {
+		chk.Panic("fixed steps are not available for delay differential equations\n")
+	}
+	if conf.denseOut || conf.denseF != nil {
+		chk.Panic("dense output is not available for delay differential equations\n")
+	}
+	for k, τ := range lags {
+		if τ <= 0 {
+			chk.Panic("constant delays must be positive. τ%d = %g is invalid\n", k, τ)
+		}
+	}
+
+	// problem definition
+	o = new(DdeSolver)
+	o.ndim = ndim
+	o.fcn = fcn
+	o.hist = hist
+	o.lags = make([]float64, len(lags))
+	copy(o.lags, lags)
+	o.lagMin = math.Inf(1)
+	for _, τ := range lags {
+		o.lagMin = math.Min(o.lagMin, τ)
+	}
+	o.delays = delays
+
+	// configuration: dense output data, step output and events
+	o.conf = *conf
+	o.conf.keepDense = true
+	o.stepF = conf.stepF
+	o.conf.stepF = o.stepOut
+	o.iniH = conf.IniH
+	o.nev = len(conf.events)
+	o.conf.events = make([]*event, o.nev, o.nev+len(delays))
+	copy(o.conf.events, conf.events)
+	o.xiNext = make([]float64, len(delays))
+	o.xiLevel = make([]int, len(delays))
+	for k := range delays {
+		k := k
+		g := func(x float64, y la.Vector) float64 {
+			return x - o.delays[k](x, y) - o.xiNext[k]
+		}
+		o.conf.events = append(o.conf.events, &event{g, 1, true})
+	}
+
+	// solver
+	o.Sol = NewSolver(ndim, &o.conf, o.odeFcn, nil, nil)
+	erk, ok := o.Sol.rkm.(*ExplicitRK)
+	if !ok || erk.dfunB == nil {
+		chk.Panic("method %q cannot be used with delay differential equations because dense output is not available\n", conf.method)
+	}
+	o.erk = erk
+	o.MaxLevel = erk.P
+	o.Stat = NewStat(o.conf.lsKind, false)
+
+	// auxiliary
+	o.y0 = la.NewVector(ndim)
+	o.ylag = make([]la.Vector, len(lags)+len(delays))
+	for k := 0; k < len(o.ylag); k++ {
+		o.ylag[k] = la.NewVector(ndim)
+	}
+	return
+}
+
+// Free releases allocated memory
+func (o *DdeSolver) Free() {
+	o.Sol.Free()
+}
+
+// Solve solves the DDE from x to xf with initial y given in y; i.e. y(x) (which may be different
+// from the history φ(x))
+func (o *DdeSolver) Solve(y la.Vector, x, xf float64) {
+
+	// check
+	if xf < x {
+		chk.Panic("xf=%v must be greater than x=%v\n", xf, x)
+	}
+
+	// initialise
+	o.x0 = x
+	o.y0.Apply(1, y)
+	o.bufX, o.bufH, o.bufD = nil, nil, nil
+	o.DiscX, o.DiscL = nil, nil
+	o.conf.IniH = o.iniH
+	o.segment, o.istep, o.stopped = 0, 0, false
+	o.Stat.Reset()
+	o.addDisc(x, 0, xf)
+
+	// sub-intervals between discontinuities
+	for x < xf {
+
+		// next discontinuity (or x + smallest constant delay)
+		xnext := xf
+		for _, ξ := range o.DiscX {
+			if ξ > x+o.tol(x) {
+				xnext = ξ
+				break
+			}
+		}
+		if x+o.lagMin < xnext-o.tol(xnext) {
+			xnext = x + o.lagMin
+		}
+
+		// next discontinuities to be crossed by x-τ(x,y) of state-dependent delays
+		for k, τ := range o.delays {
+			α := x - τ(x, y)
+			o.xiNext[k], o.xiLevel[k] = math.Inf(1), 0
+			for i, ξ := range o.DiscX {
+				if ξ > α+o.tol(α) {
+					o.xiNext[k], o.xiLevel[k] = ξ, o.DiscL[i]
+					break
+				}
+			}
+		}
+
+		// solve
+		if o.segment > 0 && o.Sol.Out.StepIdx > 0 {
+			o.Sol.Out.StepIdx-- // the last output will be repeated by the next Solve
+		}
+		var evK []int
+		var evX []float64
+		var evY []la.Vector
+		if o.segment > 0 { // keep the events of previous sub-intervals (reset by Sol.Solve)
+			evK, evX, evY = o.Sol.Out.EventK, o.Sol.Out.EventX, o.Sol.Out.EventY
+			o.Sol.Out.EventK, o.Sol.Out.EventX, o.Sol.Out.EventY = nil, nil, nil
+		}
+		o.xa = x
+		o.Sol.Solve(y, x, xnext)
+		o.Sol.Out.EventK = append(evK, o.Sol.Out.EventK...)
+		o.Sol.Out.EventX = append(evX, o.Sol.Out.EventX...)
+		o.Sol.Out.EventY = append(evY, o.Sol.Out.EventY...)
+		o.Stat.add(o.Sol.Stat)
+		o.segment++
+		if o.stopped {
+			return
+		}
+
+		// next x
+		if o.Sol.Out.Stopped {
+			n := len(o.Sol.Out.EventK) - 1
+			k := o.Sol.Out.EventK[n] - o.nev
+			if k < 0 {
+				return // terminal event given to conf
+			}
+			x = o.Sol.Out.EventX[n]
+			o.addDisc(x, o.xiLevel[k]+1, xf)
+		} else {
+			x = xnext
+		}
+
+		// initial stepsize of next sub-interval
+		if len(o.bufH) > 0 {
+			o.conf.IniH = o.bufH[len(o.bufH)-1]
+		}
+	}
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// odeFcn computes f(x,y) after calculating the lagged values
+func (o *DdeSolver) odeFcn(f la.Vector, h, x float64, y la.Vector) {
+	for k, τ := range o.lags {
+		o.lagged(o.ylag[k], x-τ)
+	}
+	n := len(o.lags)
+	for k, τ := range o.delays {
+		o.lagged(o.ylag[n+k], x-τ(x, y))
+	}
+	o.fcn(f, x, y, o.ylag)
+}
+
+// lagged computes y(α) using the history function (α < x0) or the history buffer
+func (o *DdeSolver) lagged(ylag la.Vector, α float64) {
+	if α < o.x0 {
+		o.hist(ylag, α)
+		return
+	}
+	n := len(o.bufX)
+	if n == 0 { // first step with α within the step
+		ylag.Apply(1, o.y0)
+		return
+	}
+	i := sort.Search(n, func(i int) bool { return o.bufX[i] > α }) - 1
+	if i < 0 {
+		i = 0
+	}
+	o.erk.dfunB(ylag, o.bufD[i], (α-o.bufX[i])/o.bufH[i])
+}
+
+// stepOut saves the dense output coefficients of accepted steps in the history buffer and calls
+// the step output function given to conf
+func (o *DdeSolver) stepOut(istep int, h, x float64, y la.Vector) (stop bool) {
+	if istep > 0 {
+		do := make([]la.Vector, len(o.erk.do))
+		for i, d := range o.erk.do {
+			do[i] = d.GetCopy()
+		}
+		o.bufX = append(o.bufX, o.xa)
+		o.bufH = append(o.bufH, h)
+		o.bufD = append(o.bufD, do)
+		o.xa += h
+		o.istep++
+	}
+	if o.stepF != nil && (istep > 0 || o.segment == 0) {
+		stop = o.stepF(o.istep, h, x, y)
+		o.stopped = stop
+	}
+	return
+}
+
+// addDisc adds a discontinuity (or decreases its level) and propagates it by the constant delays
+func (o *DdeSolver) addDisc(ξ float64, level int, xf float64) {
+	if level > o.MaxLevel || (level > 0 && ξ > xf-o.tol(xf)) {
+		return
+	}
+	i := sort.SearchFloat64s(o.DiscX, ξ)
+	found := false
+	for _, j := range []int{i - 1, i} {
+		if j >= 0 && j < len(o.DiscX) && math.Abs(o.DiscX[j]-ξ) <= o.tol(ξ) {
+			if level >= o.DiscL[j] {
+				return
+			}
+			o.DiscL[j] = level
+			found = true
+			break
+		}
+	}
+	if !found {
+		o.DiscX = append(o.DiscX, 0)
+		o.DiscL = append(o.DiscL, 0)
+		copy(o.DiscX[i+1:], o.DiscX[i:])
+		copy(o.DiscL[i+1:], o.DiscL[i:])
+		o.DiscX[i], o.DiscL[i] = ξ, level
+	}
+	for _, τ := range o.lags {
+		o.addDisc(ξ+τ, level+1, xf)
+	}
+}
+
+// tol returns the tolerance to compare x values
+func (o *DdeSolver) tol(x float64) float64 {
+	return 1e-12 * math.Max(1, math.Abs(x))
+}
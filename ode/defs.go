@@ -67,5 +67,94 @@ type StepOutF func(istep int, h, x float64, y la.Vector) (stop bool)
 //
 type DenseOutF func(istep int, h, x float64, y la.Vector, xout float64, yout la.Vector) (stop bool)
 
+// EventF defines an event function g(x,y). An event is located at the x value where g(x,y) = 0
+//
+//   INPUT:
+//     x -- scalar variable
+//     y -- vector variable
+//
+//   OUTPUT:
+//     g -- value of event function
+//
+type EventF func(x float64, y la.Vector) (g float64)
+
 // YanaF defines a function to be used when computing analytical solutions
 type YanaF func(res []float64, x float64)
+
+// JacP defines the Jacobian matrix of Func with respect to the parameters p (e.g. for sensitivity
+// analyses). Note that the parameters are captured by the closures defining Func and JacP
+//
+//   d{f}/d{p} := [Jp](x, {y})   (ndim × np)
+//
+//   INPUT:
+//     x -- current x
+//     y -- current {y}
+//
+//   OUTPUT:
+//     dfdp -- Jacobian matrix d{f}/d{p} (dense)
+//
+type JacP func(dfdp *la.Matrix, x float64, y la.Vector)
+
+// DynForceF defines the external force vector fext(t) of second-order systems
+//
+//   M ⋅ ü + C ⋅ u̇ + fint(u) = fext(t)
+//
+type DynForceF func(fext la.Vector, t float64)
+
+// DynIntForceF defines the internal force vector fint(u) of nonlinear second-order systems
+type DynIntForceF func(fint, u la.Vector)
+
+// DynTangentF defines the tangent stiffness matrix Kt = dfint/du of nonlinear second-order systems
+type DynTangentF func(Kt *la.Triplet, u la.Vector)
+
+// DynStepOutF defines a callback function to be called after each step of second-order systems
+//
+//   INPUT:
+//     istep -- index of step (0 is the initial state)
+//     t     -- current time
+//     u     -- displacements
+//     v     -- velocities
+//     a     -- accelerations
+//
+//   OUTPUT:
+//     stop -- stop simulation (nicely)
+//
+type DynStepOutF func(istep int, t float64, u, v, a la.Vector) (stop bool)
+
+// DdeFunc defines the right-hand side of delay differential equations
+//
+//   dy/dx = f(x, y(x), y(x-τ0), y(x-τ1), ...)
+//
+//   INPUT:
+//     x    -- current x
+//     y    -- current {y}
+//     ylag -- lagged values {y(x-τk)} in the order of the (constant and state-dependent) delays
+//             [ndelays][ndim]
+//
+//   OUTPUT:
+//     f -- {f} @ {x,y,ylag}
+//
+type DdeFunc func(f la.Vector, x float64, y la.Vector, ylag []la.Vector)
+
+// DelayF defines a state-dependent delay τ(x,y) ≥ 0 of delay differential equations
+type DelayF func(x float64, y la.Vector) (τ float64)
+
+// HistoryF defines the history y(x) for x < x0 of delay differential equations
+type HistoryF func(y la.Vector, x float64)
+
+// BvpBcF defines the boundary conditions g(y(a), y(b)) = 0 of boundary value problems
+//
+//   INPUT:
+//     ya -- y @ a (left boundary)
+//     yb -- y @ b (right boundary)
+//
+//   OUTPUT:
+//     g -- residuals of the ndim boundary conditions
+//
+type BvpBcF func(g, ya, yb la.Vector)
+
+// BvpBcJF defines the Jacobian matrices dg/dya and dg/dyb of the boundary conditions
+type BvpBcJF func(dgdya, dgdyb *la.Matrix, ya, yb la.Vector)
+
+// BvpGuessF defines the initial guess y(x) of boundary value problems
+type BvpGuessF func(y la.Vector, x float64)
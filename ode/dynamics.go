@@ -0,0 +1,414 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// DynSolver implements time integrators for second-order systems (e.g. structural dynamics)
+//
+//   M ⋅ ü + C ⋅ u̇ + fint(u) = fext(t)   with   fint(u) = K ⋅ u   (linear systems)
+//
+//  The generalized-α method of Chung and Hulbert [1] is implemented, i.e. equilibrium is enforced
+//  at t_{n+1-αf} with the accelerations taken at t_{n+1-αm}
+//
+//   M ⋅ a_{n+1-αm} + C ⋅ v_{n+1-αf} + fint(u_{n+1-αf}) = fext(t_{n+1-αf})
+//
+//   u_{n+1} = u_n + h⋅v_n + h²⋅[(1/2-β)⋅a_n + β⋅a_{n+1}]
+//   v_{n+1} = v_n + h⋅[(1-γ)⋅a_n + γ⋅a_{n+1}]
+//
+//  where x_{n+1-α} = (1-α)⋅x_{n+1} + α⋅x_n. The Newmark-β (αm = αf = 0) and HHT-α (αm = 0) methods
+//  are particular cases. The displacements u_{n+1} are computed by Newton's method.
+//
+//   Reference:
+//     [1] Chung J and Hulbert GM (1993) A time integration algorithm for structural dynamics with
+//         improved numerical dissipation: the generalized-α method. Journal of Applied Mechanics,
+//         60(2):371-375
+//
+type DynSolver struct {
+
+	// parameters
+	Beta   float64 // Newmark's β
+	Gamma  float64 // Newmark's γ
+	AlphaM float64 // αm: weight of the accelerations at the beginning of the step
+	AlphaF float64 // αf: weight of the forces at the beginning of the step
+	NmaxIt int     // maximum number of Newton iterations (nonlinear systems) [default = 20]
+	Atol   float64 // absolute tolerance of Newton iterations [default = 1e-10]
+	Rtol   float64 // relative tolerance of Newton iterations [default = 1e-10]
+	CteTg  bool    // use constant tangent during the iterations of each step (nonlinear systems)
+
+	// output
+	Out  *DynOutput // output handler
+	Stat *Stat      // statistics
+
+	// problem definition
+	ndim int          // size of u
+	mmat *la.Triplet  // mass matrix
+	cmat *la.Triplet  // damping matrix [may be nil]
+	kmat *la.Triplet  // stiffness matrix (linear systems) or tangent (nonlinear systems)
+	fext DynForceF    // external forces
+	fint DynIntForceF // internal forces (nonlinear systems) [may be nil]
+	ktan DynTangentF  // tangent stiffness (nonlinear systems) [may be nil]
+
+	// linear solver
+	lsKind string          // kind of linear solver
+	ls     la.SparseSolver // linear solver
+	amat   *la.Triplet     // matrix of linear system: c1⋅M + c2⋅C + c3⋅K
+	tmat   *la.Triplet     // temporary matrix: c1⋅M + c2⋅C
+	cfact  [3]float64      // coefficients of the factorised matrix (linear systems)
+
+	// workspace
+	un, vn, an la.Vector // values at the beginning of the step
+	uα, vα, aα la.Vector // values at t_{n+1-α}
+	fe, fi     la.Vector // external and internal forces
+	r, du, w   la.Vector // residual, correction and workspace
+}
+
+// NewDynSolver returns a new solver for second-order systems
+//   scheme -- "newmark" [β=1/4, γ=1/2], "hht" [α=0.05] or "galpha" [ρ∞=0.8]. See SetNewmark,
+//             SetHHT and SetGenAlpha to change the parameters
+//   ndim   -- size of u
+//   M      -- mass matrix
+//   C      -- damping matrix [may be nil]
+//   K      -- stiffness matrix [may be nil for nonlinear systems; see SetNonlinear]
+//   fext   -- external forces [may be nil]
+//   lsKind -- kind of linear solver: "umfpack" or "mumps" [may be empty ⇒ "umfpack"]
+//   NOTE: remember to call Free() to release allocated resources
+func NewDynSolver(scheme string, ndim int, M, C, K *la.Triplet, fext DynForceF, lsKind string) (o *DynSolver) {
+	o = new(DynSolver)
+	switch scheme {
+	case "newmark":
+		o.SetNewmark(0.25, 0.5)
+	case "hht":
+		o.SetHHT(0.05)
+	case "galpha":
+		o.SetGenAlpha(0.8)
+	default:
+		chk.Panic("cannot find second-order scheme named %q\n", scheme)
+	}
+	o.NmaxIt = 20
+	o.Atol = 1e-10
+	o.Rtol = 1e-10
+	if lsKind == "" {
+		lsKind = "umfpack"
+	}
+	o.Out = new(DynOutput)
+	o.Stat = NewStat(lsKind, true)
+	o.ndim = ndim
+	o.mmat, o.cmat, o.kmat = M, C, K
+	o.fext = fext
+	o.lsKind = lsKind
+	o.un, o.vn, o.an = la.NewVector(ndim), la.NewVector(ndim), la.NewVector(ndim)
+	o.uα, o.vα, o.aα = la.NewVector(ndim), la.NewVector(ndim), la.NewVector(ndim)
+	o.fe, o.fi = la.NewVector(ndim), la.NewVector(ndim)
+	o.r, o.du, o.w = la.NewVector(ndim), la.NewVector(ndim), la.NewVector(ndim)
+	return
+}
+
+// Free releases allocated memory
+func (o *DynSolver) Free() {
+	if o.ls != nil {
+		o.ls.Free()
+	}
+}
+
+// SetNewmark sets the parameters of the Newmark-β method (αm = αf = 0)
+//   β = 1/4 and γ = 1/2 ⇒ average acceleration (trapezoidal rule): unconditionally stable and
+//   energy conserving for linear systems
+func (o *DynSolver) SetNewmark(β, γ float64) {
+	o.Beta, o.Gamma, o.AlphaM, o.AlphaF = β, γ, 0, 0
+}
+
+// SetHHT sets the parameters of the Hilber-Hughes-Taylor method with α in [0, 1/3]
+// (αm = 0, αf = α, γ = 1/2 + α and β = (1+α)²/4)
+func (o *DynSolver) SetHHT(α float64) {
+	if α < 0 || α > 1.0/3.0 {
+		chk.Panic("α of the HHT method must be in [0, 1/3]. α = %g is invalid\n", α)
+	}
+	o.Beta, o.Gamma, o.AlphaM, o.AlphaF = (1+α)*(1+α)/4, 0.5+α, 0, α
+}
+
+// SetGenAlpha sets the parameters of the generalized-α method from the spectral radius at
+// infinite frequencies ρ∞ in [0, 1], where ρ∞ = 1 means no numerical dissipation
+func (o *DynSolver) SetGenAlpha(ρ float64) {
+	if ρ < 0 || ρ > 1 {
+		chk.Panic("spectral radius ρ∞ must be in [0, 1]. ρ∞ = %g is invalid\n", ρ)
+	}
+	o.AlphaM = (2*ρ - 1) / (ρ + 1)
+	o.AlphaF = ρ / (ρ + 1)
+	o.Gamma = 0.5 - o.AlphaM + o.AlphaF
+	o.Beta = (1 - o.AlphaM + o.AlphaF) * (1 - o.AlphaM + o.AlphaF) / 4
+}
+
+// SetNonlinear sets the internal forces fint(u) and the tangent stiffness matrix dfint/du
+//   NOTE: the sparsity pattern of the tangent matrix must not change
+func (o *DynSolver) SetNonlinear(fint DynIntForceF, ktan DynTangentF) {
+	o.fint, o.ktan = fint, ktan
+	o.kmat = new(la.Triplet)
+}
+
+// SetStepOut activates output of steps
+//  save -- save all values in Out
+//  out  -- function to be called after each step [may be nil]
+func (o *DynSolver) SetStepOut(save bool, out DynStepOutF) {
+	o.Out.save = save
+	o.Out.stepF = out
+}
+
+// Solve solves the second-order system from t to tf with (fixed) stepsize approximately equal to h
+//   u -- initial displacements (input) and displacements @ tf (output)
+//   v -- initial velocities (input) and velocities @ tf (output)
+//   a -- accelerations @ tf (output). The initial accelerations are computed from equilibrium
+func (o *DynSolver) Solve(u, v, a la.Vector, t, tf, h float64) {
+
+	// check
+	if tf < t {
+		chk.Panic("tf=%v must be greater than t=%v\n", tf, t)
+	}
+	if o.kmat == nil {
+		chk.Panic("the stiffness matrix K must be given for linear systems. call SetNonlinear otherwise\n")
+	}
+
+	// stepsize
+	nsteps := int(math.Ceil((tf - t) / h))
+	h = (tf - t) / float64(nsteps)
+	t0 := t
+
+	// initial accelerations
+	o.Stat.Reset()
+	o.Stat.Hopt = h
+	o.initialAcc(a, u, v, t)
+
+	// output
+	o.Out.init(o.ndim, nsteps)
+	if o.Out.execute(0, t, u, v, a) {
+		return
+	}
+
+	// time loop
+	for n := 0; n < nsteps; n++ {
+		o.step(u, v, a, t, h)
+		o.Stat.Nsteps++
+		o.Stat.Naccepted++
+		t = t0 + float64(n+1)*h
+		if o.Out.execute(n+1, t, u, v, a) {
+			return
+		}
+	}
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// initialAcc computes the initial accelerations: M ⋅ a = fext(t) - C ⋅ v - fint(u)
+func (o *DynSolver) initialAcc(a, u, v la.Vector, t float64) {
+	o.forces(u, v, t)
+	la.VecAdd(o.r, 1, o.fe, -1, o.fi)
+	ls := la.NewSparseSolver(o.lsKind)
+	defer ls.Free()
+	ls.Init(o.mmat, false, false, "", "", nil)
+	ls.Fact()
+	ls.Solve(a, o.r, false)
+	o.Stat.Ndecomp++
+	o.Stat.Nlinsol++
+}
+
+// forces computes fe = fext(t) and fi = C ⋅ v + fint(u)
+func (o *DynSolver) forces(u, v la.Vector, t float64) {
+	o.fe.Fill(0)
+	if o.fext != nil {
+		o.fext(o.fe, t)
+	}
+	if o.fint != nil {
+		o.Stat.Nfeval++
+		o.fint(o.fi, u)
+	} else {
+		la.SpTriMatVecMul(o.fi, o.kmat, u)
+	}
+	if o.cmat != nil {
+		la.SpTriMatVecMul(o.w, o.cmat, v)
+		la.VecAdd(o.fi, 1, o.w, 1, o.fi)
+	}
+}
+
+// step performs one step
+func (o *DynSolver) step(u, v, a la.Vector, t, h float64) {
+
+	// auxiliary
+	β, γ, αm, αf := o.Beta, o.Gamma, o.AlphaM, o.AlphaF
+	o.un.Apply(1, u)
+	o.vn.Apply(1, v)
+	o.an.Apply(1, a)
+
+	// predictor: constant accelerations
+	for i := 0; i < o.ndim; i++ {
+		u[i] = o.un[i] + h*o.vn[i] + h*h*o.an[i]/2
+	}
+
+	// iterations
+	linear := o.fint == nil
+	var it int
+	for it = 0; it < o.NmaxIt; it++ {
+
+		// kinematics
+		for i := 0; i < o.ndim; i++ {
+			a[i] = (u[i]-o.un[i]-h*o.vn[i])/(β*h*h) - (0.5-β)*o.an[i]/β
+			v[i] = o.vn[i] + h*((1-γ)*o.an[i]+γ*a[i])
+			o.uα[i] = (1-αf)*u[i] + αf*o.un[i]
+			o.vα[i] = (1-αf)*v[i] + αf*o.vn[i]
+			o.aα[i] = (1-αm)*a[i] + αm*o.an[i]
+		}
+
+		// residual: r = M ⋅ aα + C ⋅ vα + fint(uα) - fext(tα)
+		o.forces(o.uα, o.vα, t+(1-αf)*h)
+		la.SpTriMatVecMul(o.r, o.mmat, o.aα)
+		for i := 0; i < o.ndim; i++ {
+			o.r[i] += o.fi[i] - o.fe[i]
+		}
+
+		// converged?
+		if it > 0 && o.converged(u) {
+			break
+		}
+
+		// matrix of linear system
+		if linear {
+			if o.coefficients(h) != o.cfact {
+				o.factorise(h)
+			}
+		} else if it == 0 || !o.CteTg {
+			o.Stat.Njeval++
+			o.ktan(o.kmat, o.uα)
+			o.factorise(h)
+		}
+
+		// update u
+		o.Stat.Nlinsol++
+		o.ls.Solve(o.du, o.r, false)
+		la.VecAdd(u, -1, o.du, 1, u) // u -= du
+
+		// linear systems converge in one iteration
+		if linear {
+			it++
+			for i := 0; i < o.ndim; i++ {
+				a[i] = (u[i]-o.un[i]-h*o.vn[i])/(β*h*h) - (0.5-β)*o.an[i]/β
+				v[i] = o.vn[i] + h*((1-γ)*o.an[i]+γ*a[i])
+			}
+			break
+		}
+	}
+
+	// check
+	if it == o.NmaxIt {
+		chk.Panic("Newton's method did not converge after %d iterations @ t = %g\n", it, t)
+	}
+	if it > o.Stat.Nitmax {
+		o.Stat.Nitmax = it
+	}
+}
+
+// converged checks the convergence of iterations using the RMS norm of the last correction
+func (o *DynSolver) converged(u la.Vector) bool {
+	var sum, ratio float64
+	for i := 0; i < o.ndim; i++ {
+		ratio = o.du[i] / (o.Atol + o.Rtol*math.Abs(u[i]))
+		sum += ratio * ratio
+	}
+	return math.Sqrt(sum/float64(o.ndim)) < 1.0
+}
+
+// factorise assembles and factorises the matrix of the linear system
+//
+//   A = (1-αm)/(β⋅h²)⋅M + (1-αf)⋅γ/(β⋅h)⋅C + (1-αf)⋅K
+//
+func (o *DynSolver) factorise(h float64) {
+	c := o.coefficients(h)
+	first := o.ls == nil
+	if first {
+		nnz := 2*o.mmat.Len() + o.kmat.Len() // c1⋅M + 0⋅M + c3⋅K if C is nil
+		if o.cmat != nil {
+			nnz = o.mmat.Len() + o.cmat.Len() + o.kmat.Len()
+		}
+		o.tmat = new(la.Triplet)
+		o.tmat.Init(o.ndim, o.ndim, nnz)
+		o.amat = new(la.Triplet)
+		o.amat.Init(o.ndim, o.ndim, nnz)
+	}
+	if o.cmat != nil {
+		la.SpTriAdd(o.tmat, c[0], o.mmat, c[1], o.cmat)
+	} else {
+		la.SpTriAdd(o.tmat, c[0], o.mmat, 0, o.mmat)
+	}
+	la.SpTriAdd(o.amat, 1, o.tmat, c[2], o.kmat)
+	if first {
+		o.ls = la.NewSparseSolver(o.lsKind)
+		o.ls.Init(o.amat, false, false, "", "", nil)
+	}
+	o.Stat.Ndecomp++
+	lsFact(o.ls, first)
+	o.cfact = c
+}
+
+// coefficients returns the coefficients of M, C and K in the matrix of the linear system
+func (o *DynSolver) coefficients(h float64) [3]float64 {
+	β, γ, αm, αf := o.Beta, o.Gamma, o.AlphaM, o.AlphaF
+	return [3]float64{(1 - αm) / (β * h * h), (1 - αf) * γ / (β * h), 1 - αf}
+}
+
+// DynOutput holds the history of second-order systems (prepared by DynSolver)
+type DynOutput struct {
+
+	// discrete output at steps
+	StepIdx int         // current index in StepT, StepU, StepV and StepA == number of saved steps
+	StepT   []float64   // t values [StepIdx]
+	StepU   []la.Vector // displacements [StepIdx][ndim]
+	StepV   []la.Vector // velocities [StepIdx][ndim]
+	StepA   []la.Vector // accelerations [StepIdx][ndim]
+
+	// control
+	save  bool        // save all steps
+	stepF DynStepOutF // function to process step output [may be nil]
+}
+
+// init initialises the arrays
+func (o *DynOutput) init(ndim, nsteps int) {
+	o.StepIdx = 0
+	if o.save {
+		o.StepT = make([]float64, nsteps+1)
+		o.StepU = make([]la.Vector, nsteps+1)
+		o.StepV = make([]la.Vector, nsteps+1)
+		o.StepA = make([]la.Vector, nsteps+1)
+	}
+}
+
+// execute calls the step output function and saves t, u, v and a values
+func (o *DynOutput) execute(istep int, t float64, u, v, a la.Vector) (stop bool) {
+	if o.stepF != nil {
+		stop = o.stepF(istep, t, u, v, a)
+		if stop {
+			return
+		}
+	}
+	if o.save {
+		o.StepT[o.StepIdx] = t
+		o.StepU[o.StepIdx] = u.GetCopy()
+		o.StepV[o.StepIdx] = v.GetCopy()
+		o.StepA[o.StepIdx] = a.GetCopy()
+		o.StepIdx++
+	}
+	return
+}
+
+// GetStepU returns the history of displacement i
+func (o *DynOutput) GetStepU(i int) (U []float64) {
+	U = make([]float64, o.StepIdx)
+	for k := 0; k < o.StepIdx; k++ {
+		U[k] = o.StepU[k][i]
+	}
+	return
+}
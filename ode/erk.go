@@ -81,8 +81,8 @@ type ExplicitRK struct {
 	yd la.Vector   // y values for dense output (allocated here if len(kd)>0)
 
 	// functions to compute variables for dense output
-	dfunA func(y0 la.Vector, x0 float64)                                // in Accept function
-	dfunB func(yout la.Vector, h, x float64, y la.Vector, xout float64) // DenseOut function
+	dfunA func(y0 la.Vector, x0 float64)                  // in Accept function
+	dfunB func(yout la.Vector, do []la.Vector, θ float64) // DenseOut function with θ = (xout-xold)/h
 }
 
 // Free releases memory
@@ -115,7 +115,7 @@ func (o *ExplicitRK) Init(ndim int, conf *Config, work *rkwork, stat *Stat, fcn
 	o.ndf = float64(ndim)
 
 	// dense output
-	if o.conf.denseOut {
+	if o.conf.needDense() {
 		if o.do == nil {
 			chk.Panic("dense output is not available for %q\n", o.conf.method)
 		}
@@ -135,7 +135,7 @@ func (o *ExplicitRK) Init(ndim int, conf *Config, work *rkwork, stat *Stat, fcn
 func (o *ExplicitRK) Accept(y0 la.Vector, x0 float64) (dxnew float64) {
 
 	// store data for future dense output
-	if o.conf.denseOut {
+	if o.conf.needDense() {
 		if o.dfunA != nil {
 			o.dfunA(y0, x0)
 		}
@@ -173,7 +173,7 @@ func (o *ExplicitRK) Reject() (dxnew float64) {
 
 // DenseOut produces dense output (after Accept)
 func (o *ExplicitRK) DenseOut(yout la.Vector, h, x float64, y la.Vector, xout float64) {
-	o.dfunB(yout, h, x, y, xout)
+	o.dfunB(yout, o.do, (xout-(x-h))/h)
 }
 
 // Step steps update
@@ -438,12 +438,10 @@ func newERK(kind string) rkmethod {
 				o.do[4][m] *= o.work.h
 			}
 		}
-		o.dfunB = func(yout la.Vector, h, x float64, y la.Vector, xout float64) {
-			xold := x - h
-			θ := (xout - xold) / h
+		o.dfunB = func(yout la.Vector, do []la.Vector, θ float64) {
 			uθ := 1.0 - θ
 			for i := 0; i < o.ndim; i++ {
-				yout[i] = o.do[0][i] + θ*(o.do[1][i]+uθ*(o.do[2][i]+θ*(o.do[3][i]+uθ*o.do[4][i])))
+				yout[i] = do[0][i] + θ*(do[1][i]+uθ*(do[2][i]+θ*(do[3][i]+uθ*do[4][i])))
 			}
 		}
 
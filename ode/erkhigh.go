@@ -309,14 +309,12 @@ func newERKhighOrder(kind string) rkmethod {
 		}
 
 		// function for dense output (DenseOut)
-		o.dfunB = func(yout la.Vector, h, x float64, y la.Vector, xout float64) {
-			xold := x - h
-			θ := (xout - xold) / h
+		o.dfunB = func(yout la.Vector, do []la.Vector, θ float64) {
 			uθ := 1.0 - θ
 			var par float64
 			for i := 0; i < o.ndim; i++ {
-				par = o.do[4][i] + θ*(o.do[5][i]+uθ*(o.do[6][i]+θ*o.do[7][i]))
-				yout[i] = o.do[0][i] + θ*(o.do[1][i]+uθ*(o.do[2][i]+θ*(o.do[3][i]+uθ*par)))
+				par = do[4][i] + θ*(do[5][i]+uθ*(do[6][i]+θ*do[7][i]))
+				yout[i] = do[0][i] + θ*(do[1][i]+uθ*(do[2][i]+θ*(do[3][i]+uθ*par)))
 			}
 		}
 
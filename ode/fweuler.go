@@ -36,6 +36,9 @@ func (o *FwEuler) Init(ndim int, conf *Config, work *rkwork, stat *Stat, fcn Fun
 	if M != nil {
 		chk.Panic("Forward-Euler solver cannot handle M matrix yet\n")
 	}
+	if len(conf.events) > 0 {
+		chk.Panic("Forward-Euler solver cannot handle events because dense output is not available\n")
+	}
 	o.ndim = ndim
 	o.conf = conf
 	o.work = work
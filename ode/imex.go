@@ -0,0 +1,452 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+	"github.com/cpmech/gosl/utl"
+)
+
+// ImexRK implements additive implicit-explicit (IMEX) Runge-Kutta methods
+//
+//   The right-hand side is split into a stiff part fI (given to NewSolver) and a non-stiff part fE
+//   (given to Config.SetIMEX) such that
+//
+//     dy/dx = fI(x,y) + fE(x,y)
+//
+//   The stiff part is integrated by an ESDIRK method (explicit first stage and singly diagonally
+//   implicit stages) and the non-stiff part by an explicit Runge-Kutta method sharing the same
+//   nodes cᵢ and weights bᵢ. Each implicit stage is solved by the simplified Newton's method with
+//   the matrix (I - h⋅γ⋅∂fI/∂y), which is factorised once per step (or reused). If fE is not given,
+//   the method reduces to the (L-stable, stiffly accurate) ESDIRK method.
+//
+//   The methods available are (Kennedy and Carpenter [1]):
+//     ark3 -- ARK3(2)4L[2]SA: order 3 with embedded order 2; 4 stages
+//     ark4 -- ARK4(3)6L[2]SA: order 4 with embedded order 3; 6 stages
+//     ark5 -- ARK5(4)8L[2]SA: order 5 with embedded order 4; 8 stages
+//
+//   Reference:
+//     [1] Kennedy CA and Carpenter MH (2003) Additive Runge-Kutta schemes for
+//         convection-diffusion-reaction equations. Applied Numerical Mathematics, 44:139-181
+//
+type ImexRK struct {
+
+	// constants
+	Nstg int         // number of stages
+	P    int         // order of y_new
+	Q    int         // order of the embedded estimator
+	Gam  float64     // γ coefficient (diagonal of Ai)
+	Ae   [][]float64 // explicit coefficients
+	Ai   [][]float64 // implicit coefficients
+	B    []float64   // weights (common to both tables)
+	Bh   []float64   // embedded weights
+	C    []float64   // nodes (common to both tables)
+
+	// data
+	ndim  int             // problem dimension
+	conf  *Config         // configurations
+	work  *rkwork         // workspace
+	stat  *Stat           // statistics
+	fcn   Func            // stiff part: fI(x,y)
+	fcnE  Func            // non-stiff part: fE(x,y) [may be nil]
+	jac   JacF            // Jacobian function: dfI/dy(x,y)
+	dfdy  *la.Triplet     // dfI/dy matrix
+	drdy  *la.Triplet     // linear system matrix: drdy = I - h⋅γ⋅dfdy
+	imat  *la.Triplet     // I matrix in triplet format
+	ls    la.SparseSolver // linear solver
+	ready bool            // matrices and solver are ready
+	fact  bool            // linear solver has been factorised once
+
+	// workspace
+	kE   []la.Vector // [nstg][ndim] explicit stage derivatives
+	kI   []la.Vector // [nstg][ndim] implicit stage derivatives
+	base la.Vector   // explicit part of implicit stage equations
+	z    la.Vector   // stage values
+	r    la.Vector   // residual
+	dz   la.Vector   // correction of stage values
+	ynew la.Vector   // updated y
+	w    la.Vector   // workspace
+	x0   float64     // x at the beginning of the step
+	n    float64     // exponent n = 1/(q+1) of rerrⁿ
+
+	// dense output
+	herm *hermite // Hermite interpolation
+}
+
+// add methods to database
+func init() {
+	rkmDB["ark3"] = func() rkmethod { return newImexRK("ark3") }
+	rkmDB["ark4"] = func() rkmethod { return newImexRK("ark4") }
+	rkmDB["ark5"] = func() rkmethod { return newImexRK("ark5") }
+}
+
+// Free releases memory
+func (o *ImexRK) Free() {
+	if o.ls != nil {
+		o.ls.Free()
+	}
+}
+
+// Info returns information about this method
+func (o *ImexRK) Info() (fixedOnly, implicit bool, nstages int) {
+	return false, true, o.Nstg
+}
+
+// Init initialises structure
+func (o *ImexRK) Init(ndim int, conf *Config, work *rkwork, stat *Stat, fcn Func, jac JacF, M *la.Triplet) {
+
+	// check
+	if M != nil {
+		chk.Panic("IMEX Runge-Kutta solver cannot handle M matrix yet\n")
+	}
+
+	// data
+	o.ndim = ndim
+	o.conf = conf
+	o.work = work
+	o.stat = stat
+	o.fcn = fcn
+	o.fcnE = conf.fcnE
+	o.jac = jac
+	o.dfdy = new(la.Triplet)
+	o.drdy = new(la.Triplet)
+	o.imat = new(la.Triplet)
+	la.SpTriSetDiag(o.imat, ndim, 1)
+	o.ls = la.NewSparseSolver(o.conf.lsKind)
+
+	// workspace
+	o.kE = make([]la.Vector, o.Nstg)
+	o.kI = make([]la.Vector, o.Nstg)
+	for i := 0; i < o.Nstg; i++ {
+		o.kE[i] = la.NewVector(ndim)
+		o.kI[i] = la.NewVector(ndim)
+	}
+	o.base = la.NewVector(ndim)
+	o.z = la.NewVector(ndim)
+	o.r = la.NewVector(ndim)
+	o.dz = la.NewVector(ndim)
+	o.ynew = la.NewVector(ndim)
+	o.w = la.NewVector(ndim)
+	o.n = 1.0 / float64(o.Q+1)
+
+	// dense output
+	if o.conf.needDense() {
+		o.herm = newHermite(ndim)
+	}
+}
+
+// Accept accepts update and computes next stepsize
+func (o *ImexRK) Accept(y0 la.Vector, x0 float64) (dxnew float64) {
+
+	// store data for dense output
+	h := o.work.h
+	if o.herm != nil {
+		o.herm.y0.Apply(1, y0)
+		la.VecAdd(o.herm.f0, 1, o.kI[0], 1, o.kE[0])
+		o.herm.y1.Apply(1, o.ynew)
+		o.stat.Nfeval++
+		o.fcn(o.herm.f1, h, o.x0+h, o.ynew)
+		if o.fcnE != nil {
+			o.stat.Nfeval++
+			o.fcnE(o.w, h, o.x0+h, o.ynew)
+			la.VecAdd(o.herm.f1, 1, o.w, 1, o.herm.f1)
+		}
+	}
+
+	// update y
+	y0.Apply(1, o.ynew)
+
+	// the Jacobian must be computed again (unless reused by the Solver)
+	o.work.jacIsOK = false
+
+	// estimate new stepsize
+	div := utl.Max(o.conf.Mmin, utl.Min(o.conf.Mmax, math.Pow(o.work.rerr, o.n)/o.conf.Mfac))
+	dxnew = h / div
+
+	// predictive controller of Gustafsson
+	if o.conf.PredCtrl {
+		if o.stat.Naccepted > 1 {
+			r2 := o.work.rerr * o.work.rerr
+			fac := (o.work.hPrev / h) * math.Pow(r2/o.work.rerrPrev, o.n) / o.conf.Mfac
+			fac = utl.Max(o.conf.Mmin, utl.Min(o.conf.Mmax, fac))
+			div = utl.Max(div, fac)
+			dxnew = h / div
+		}
+	}
+	return
+}
+
+// Reject processes step rejection and computes next stepsize
+func (o *ImexRK) Reject() (dxnew float64) {
+	div := utl.Max(o.conf.Mmin, utl.Min(o.conf.Mmax, math.Pow(o.work.rerr, o.n)/o.conf.Mfac))
+	dxnew = o.work.h / div
+	return
+}
+
+// DenseOut produces dense output (after Accept)
+func (o *ImexRK) DenseOut(yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	o.herm.eval(yout, h, x-h, xout)
+}
+
+// Step steps update
+func (o *ImexRK) Step(x0 float64, y0 la.Vector) {
+
+	// auxiliary
+	h := o.work.h
+	f0 := o.work.f0
+	hγ := h * o.Gam
+	o.x0 = x0
+
+	// f0 = fI(x0,y0) is computed by Solver, except with fixed steps and analytical Jacobian
+	if o.conf.fixed && o.jac != nil {
+		o.stat.Nfeval++
+		o.fcn(f0, h, x0, y0)
+	}
+
+	// Jacobian and decomposition
+	if o.work.reuseJdec {
+		o.work.reuseJdec = false
+	} else {
+
+		// calculate only first Jacobian for all iterations (simple/modified Newton's method)
+		if o.work.reuseJ {
+			o.work.reuseJ = false
+		} else if !o.work.jacIsOK {
+
+			// stat
+			o.stat.Njeval++
+
+			// numerical Jacobian
+			if o.jac == nil {
+				num.Jacobian(o.dfdy, func(fy, yy la.Vector) {
+					o.fcn(fy, h, x0, yy)
+				}, y0, f0, o.w) // w works here as workspace variable
+
+				// analytical Jacobian
+			} else {
+				o.jac(o.dfdy, h, x0, y0)
+			}
+
+			// set flag
+			o.work.jacIsOK = true
+		}
+
+		// initialise drdy matrix
+		if !o.ready {
+			o.drdy.Init(o.ndim, o.ndim, o.imat.Len()+o.dfdy.Len())
+		}
+
+		// update matrix
+		la.SpTriAdd(o.drdy, 1, o.imat, -hγ, o.dfdy) // drdy := I - h⋅γ⋅dfdy
+
+		// initialise linear solver
+		if !o.ready {
+			o.ls.Init(o.drdy, o.conf.Symmetric, o.conf.LsVerbose, o.conf.Ordering, o.conf.Scaling, o.conf.comm)
+			o.ready = true
+		}
+
+		// perform factorisation
+		o.stat.Ndecomp++
+		lsFact(o.ls, !o.fact)
+		o.fact = true
+	}
+
+	// first stage (explicit)
+	o.kI[0].Apply(1, f0)
+	if o.fcnE == nil {
+		o.kE[0].Fill(0)
+	} else {
+		o.stat.Nfeval++
+		o.fcnE(o.kE[0], h, x0, y0)
+	}
+
+	// implicit stages
+	o.work.nit = 0
+	o.work.eta = math.Pow(utl.Max(o.work.eta, o.conf.Eps), 0.8)
+	o.work.theta = 0
+	o.work.diverg = false
+	var Ldz, LdzOld, θ, ratio float64
+	for i := 1; i < o.Nstg; i++ {
+
+		// stage x
+		xi := x0 + o.C[i]*h
+
+		// base := y0 + h⋅Σⱼ (aEᵢⱼ⋅kEⱼ + aIᵢⱼ⋅kIⱼ)
+		o.base.Apply(1, y0)
+		for j := 0; j < i; j++ {
+			la.VecAdd(o.base, h*o.Ae[i][j], o.kE[j], 1, o.base)
+			la.VecAdd(o.base, h*o.Ai[i][j], o.kI[j], 1, o.base)
+		}
+
+		// trial value: z := base + h⋅γ⋅kI[i-1]
+		la.VecAdd(o.z, 1, o.base, hγ, o.kI[i-1])
+
+		// iterations
+		converged := false
+		for it := 0; it < o.conf.NmaxIt; it++ {
+
+			// max iterations ?
+			o.work.nit++
+			if it+1 > o.stat.Nitmax {
+				o.stat.Nitmax = it + 1
+			}
+
+			// residual: r := base + h⋅γ⋅fI(xi,z) - z
+			o.stat.Nfeval++
+			o.fcn(o.kI[i], h, xi, o.z)
+			for m := 0; m < o.ndim; m++ {
+				o.r[m] = o.base[m] + hγ*o.kI[i][m] - o.z[m]
+			}
+
+			// solve linear system and update z
+			o.stat.Nlinsol++
+			o.ls.Solve(o.dz, o.r, false) // dz := inv(drdy) ⋅ r
+			Ldz = 0.0
+			for m := 0; m < o.ndim; m++ {
+				o.z[m] += o.dz[m]
+				ratio = o.dz[m] / o.work.scal[m]
+				Ldz += ratio * ratio
+			}
+			Ldz = math.Sqrt(Ldz / float64(o.ndim))
+
+			// check convergence
+			if it > 0 {
+				θ = Ldz / LdzOld
+				o.work.theta = utl.Max(o.work.theta, θ)
+				if θ >= 0.99 { // diverging
+					break
+				}
+				o.work.eta = θ / (1.0 - θ)
+			}
+			LdzOld = Ldz
+
+			// converged
+			if o.work.eta*Ldz <= o.conf.fnewt || Ldz <= o.conf.Eps {
+				converged = true
+				break
+			}
+		}
+
+		// diverging
+		if !converged {
+			o.work.dvfac = 0.5
+			o.work.diverg = true
+			return
+		}
+
+		// stage derivatives
+		for m := 0; m < o.ndim; m++ {
+			o.kI[i][m] = (o.z[m] - o.base[m]) / hγ
+		}
+		if o.fcnE == nil {
+			o.kE[i].Fill(0)
+		} else {
+			o.stat.Nfeval++
+			o.fcnE(o.kE[i], h, xi, o.z)
+		}
+	}
+
+	// update and error estimate
+	var lerrm, sk, sum float64
+	for m := 0; m < o.ndim; m++ {
+		o.ynew[m] = y0[m]
+		lerrm = 0.0
+		for i := 0; i < o.Nstg; i++ {
+			o.ynew[m] += h * o.B[i] * (o.kE[i][m] + o.kI[i][m])
+			lerrm += h * (o.B[i] - o.Bh[i]) * (o.kE[i][m] + o.kI[i][m])
+		}
+		sk = o.conf.atol + o.conf.rtol*utl.Max(math.Abs(y0[m]), math.Abs(o.ynew[m]))
+		ratio = lerrm / sk
+		sum += ratio * ratio
+	}
+	o.work.rerr = utl.Max(math.Sqrt(sum/float64(o.ndim)), 1.0e-10)
+}
+
+// newImexRK returns a new IMEX Runge-Kutta method with the coefficients set
+func newImexRK(kind string) rkmethod {
+	o := new(ImexRK)
+	switch kind {
+
+	// ARK3(2)4L[2]SA: Tables 1 and 2 of [1]
+	case "ark3":
+		o.Nstg, o.P, o.Q = 4, 3, 2
+		o.Gam = 1767732205903.0 / 4055673282236.0
+		o.Ae = [][]float64{
+			{0, 0, 0, 0},
+			{1767732205903.0 / 2027836641118.0, 0, 0, 0},
+			{5535828885825.0 / 10492691773637.0, 788022342437.0 / 10882634858940.0, 0, 0},
+			{6485989280629.0 / 16251701735622.0, -4246266847089.0 / 9704473918619.0, 10755448449292.0 / 10357097424841.0, 0},
+		}
+		o.Ai = [][]float64{
+			{0, 0, 0, 0},
+			{1767732205903.0 / 4055673282236.0, 1767732205903.0 / 4055673282236.0, 0, 0},
+			{2746238789719.0 / 10658868560708.0, -640167445237.0 / 6845629431997.0, 1767732205903.0 / 4055673282236.0, 0},
+			{1471266399579.0 / 7840856788654.0, -4482444167858.0 / 7529755066697.0, 11266239266428.0 / 11593286722821.0, 1767732205903.0 / 4055673282236.0},
+		}
+		o.B = []float64{1471266399579.0 / 7840856788654.0, -4482444167858.0 / 7529755066697.0, 11266239266428.0 / 11593286722821.0, 1767732205903.0 / 4055673282236.0}
+		o.Bh = []float64{2756255671327.0 / 12835298489170.0, -10771552573575.0 / 22201958757719.0, 9247589265047.0 / 10645013368117.0, 2193209047091.0 / 5459859503100.0}
+		o.C = []float64{0, 1767732205903.0 / 2027836641118.0, 3.0 / 5.0, 1}
+
+	// ARK4(3)6L[2]SA: Tables 3 and 4 of [1]
+	case "ark4":
+		o.Nstg, o.P, o.Q = 6, 4, 3
+		o.Gam = 1.0 / 4.0
+		o.Ae = [][]float64{
+			{0, 0, 0, 0, 0, 0},
+			{1.0 / 2.0, 0, 0, 0, 0, 0},
+			{13861.0 / 62500.0, 6889.0 / 62500.0, 0, 0, 0, 0},
+			{-116923316275.0 / 2393684061468.0, -2731218467317.0 / 15368042101831.0, 9408046702089.0 / 11113171139209.0, 0, 0, 0},
+			{-451086348788.0 / 2902428689909.0, -2682348792572.0 / 7519795681897.0, 12662868775082.0 / 11960479115383.0, 3355817975965.0 / 11060851509271.0, 0, 0},
+			{647845179188.0 / 3216320057751.0, 73281519250.0 / 8382639484533.0, 552539513391.0 / 3454668386233.0, 3354512671639.0 / 8306763924573.0, 4040.0 / 17871.0, 0},
+		}
+		o.Ai = [][]float64{
+			{0, 0, 0, 0, 0, 0},
+			{1.0 / 4.0, 1.0 / 4.0, 0, 0, 0, 0},
+			{8611.0 / 62500.0, -1743.0 / 31250.0, 1.0 / 4.0, 0, 0, 0},
+			{5012029.0 / 34652500.0, -654441.0 / 2922500.0, 174375.0 / 388108.0, 1.0 / 4.0, 0, 0},
+			{15267082809.0 / 155376265600.0, -71443401.0 / 120774400.0, 730878875.0 / 902184768.0, 2285395.0 / 8070912.0, 1.0 / 4.0, 0},
+			{82889.0 / 524892.0, 0, 15625.0 / 83664.0, 69875.0 / 102672.0, -2260.0 / 8211.0, 1.0 / 4.0},
+		}
+		o.B = []float64{82889.0 / 524892.0, 0, 15625.0 / 83664.0, 69875.0 / 102672.0, -2260.0 / 8211.0, 1.0 / 4.0}
+		o.Bh = []float64{4586570599.0 / 29645900160.0, 0, 178811875.0 / 945068544.0, 814220225.0 / 1159782912.0, -3700637.0 / 11593932.0, 61727.0 / 225920.0}
+		o.C = []float64{0, 1.0 / 2.0, 83.0 / 250.0, 31.0 / 50.0, 17.0 / 20.0, 1}
+
+	// ARK5(4)8L[2]SA: Tables 5 and 6 of [1]
+	case "ark5":
+		o.Nstg, o.P, o.Q = 8, 5, 4
+		o.Gam = 41.0 / 200.0
+		o.Ae = [][]float64{
+			{0, 0, 0, 0, 0, 0, 0, 0},
+			{41.0 / 100.0, 0, 0, 0, 0, 0, 0, 0},
+			{367902744464.0 / 2072280473677.0, 677623207551.0 / 8224143866563.0, 0, 0, 0, 0, 0, 0},
+			{1268023523408.0 / 10340822734521.0, 0, 1029933939417.0 / 13636558850479.0, 0, 0, 0, 0, 0},
+			{14463281900351.0 / 6315353703477.0, 0, 66114435211212.0 / 5879490589093.0, -54053170152839.0 / 4284798021562.0, 0, 0, 0, 0},
+			{14090043504691.0 / 34967701212078.0, 0, 15191511035443.0 / 11219624916014.0, -18461159152457.0 / 12425892160975.0, -281667163811.0 / 9011619295870.0, 0, 0, 0},
+			{19230459214898.0 / 13134317526959.0, 0, 21275331358303.0 / 2942455364971.0, -38145345988419.0 / 4862620318723.0, -1.0 / 8.0, -1.0 / 8.0, 0, 0},
+			{-19977161125411.0 / 11928030595625.0, 0, -40795976796054.0 / 6384907823539.0, 177454434618887.0 / 12078138498510.0, 782672205425.0 / 8267701900261.0, -69563011059811.0 / 9646580694205.0, 7356628210526.0 / 4942186776405.0, 0},
+		}
+		o.Ai = [][]float64{
+			{0, 0, 0, 0, 0, 0, 0, 0},
+			{41.0 / 200.0, 41.0 / 200.0, 0, 0, 0, 0, 0, 0},
+			{41.0 / 400.0, -567603406766.0 / 11931857230679.0, 41.0 / 200.0, 0, 0, 0, 0, 0},
+			{683785636431.0 / 9252920307686.0, 0, -110385047103.0 / 1367015193373.0, 41.0 / 200.0, 0, 0, 0, 0},
+			{3016520224154.0 / 10081342136671.0, 0, 30586259806659.0 / 12414158314087.0, -22760509404356.0 / 11113319521817.0, 41.0 / 200.0, 0, 0, 0},
+			{218866479029.0 / 1489978393911.0, 0, 638256894668.0 / 5436446318841.0, -1179710474555.0 / 5321154724896.0, -60928119172.0 / 8023461067671.0, 41.0 / 200.0, 0, 0},
+			{1020004230633.0 / 5715676835656.0, 0, 25762820946817.0 / 25263940353407.0, -2161375909145.0 / 9755907335909.0, -211217309593.0 / 5846859502534.0, -4269925059573.0 / 7827059040749.0, 41.0 / 200.0, 0},
+			{-872700587467.0 / 9133579230613.0, 0, 0, 22348218063261.0 / 9555858737531.0, -1143369518992.0 / 8141816002931.0, -39379526789629.0 / 19018526304540.0, 32727382324388.0 / 42900044865799.0, 41.0 / 200.0},
+		}
+		o.B = []float64{-872700587467.0 / 9133579230613.0, 0, 0, 22348218063261.0 / 9555858737531.0, -1143369518992.0 / 8141816002931.0, -39379526789629.0 / 19018526304540.0, 32727382324388.0 / 42900044865799.0, 41.0 / 200.0}
+		o.Bh = []float64{-975461918565.0 / 9796059967033.0, 0, 0, 78070527104295.0 / 32432590147079.0, -548382580838.0 / 3424219808633.0, -33438840321285.0 / 15594753105479.0, 3629800801594.0 / 4656183773603.0, 4035322873751.0 / 18575991585200.0}
+		o.C = []float64{0, 41.0 / 100.0, 2935347310677.0 / 11292855782101.0, 1426016391358.0 / 7196633302097.0, 23.0 / 25.0, 6.0 / 25.0, 3.0 / 5.0, 1}
+
+	default:
+		chk.Panic("cannot find IMEX Runge-Kutta method %q\n", kind)
+	}
+	return o
+}
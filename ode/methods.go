@@ -20,6 +20,12 @@ type rkmethod interface {
 	Step(x0 float64, y0 la.Vector)                                                            // step update
 }
 
+// rkmStarter defines an optional function of rkmethods that compute the initial stepsize themselves
+// (variable steps only); e.g. to initialise their stepsize control
+type rkmStarter interface {
+	IniH(x float64, y la.Vector) (h float64) // initial stepsize
+}
+
 // rkmMaker defines a function that makes rkmethods
 type rkmMaker func() rkmethod
 
@@ -34,3 +40,40 @@ func newRKmethod(kind string) rkmethod {
 	chk.Panic("cannot find rkmethod named %q in database\n", kind)
 	return nil
 }
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// lsFact performs the factorisation of the linear system matrix. After the first factorisation,
+// only the numeric factorisation is carried out if the solver is direct (e.g. umfpack or mumps)
+// because the sparsity pattern of the matrix does not change
+func lsFact(ls la.SparseSolver, first bool) {
+	if d, ok := ls.(la.SparseSolverDirect); ok && !first {
+		d.RefactorNumeric()
+		return
+	}
+	ls.Fact()
+}
+
+// hermite implements the cubic Hermite interpolation of y over the last step using y and f=dy/dx
+// at the beginning and at the end of the step (dense output of third order)
+type hermite struct {
+	y0, f0 la.Vector // values at the beginning of the step
+	y1, f1 la.Vector // values at the end of the step
+}
+
+// newHermite returns a new structure
+func newHermite(ndim int) (o *hermite) {
+	return &hermite{la.NewVector(ndim), la.NewVector(ndim), la.NewVector(ndim), la.NewVector(ndim)}
+}
+
+// eval computes yout @ xout = x0 + θ⋅h
+//
+//   y(x0+θh) = (1-θ)⋅y0 + θ⋅y1 + θ⋅(θ-1)⋅[(1-2θ)⋅(y1-y0) + (θ-1)⋅h⋅f0 + θ⋅h⋅f1]
+//
+func (o *hermite) eval(yout la.Vector, h, x0, xout float64) {
+	θ := (xout - x0) / h
+	for i := 0; i < len(yout); i++ {
+		dy := o.y1[i] - o.y0[i]
+		yout[i] = (1-θ)*o.y0[i] + θ*o.y1[i] + θ*(θ-1)*((1-2*θ)*dy+(θ-1)*h*o.f0[i]+θ*h*o.f1[i])
+	}
+}
@@ -32,9 +32,10 @@ type Solver struct {
 	Stat *Stat   // statistics
 
 	// problem definition
-	ndim int  // size of y
-	fcn  Func // dy/dx := f(x,y)
-	jac  JacF // Jacobian: df/dy
+	ndim int         // size of y
+	fcn  Func        // dy/dx := f(x,y)
+	jac  JacF        // Jacobian: df/dy
+	mtri *la.Triplet // "mass" matrix [may be nil]
 
 	// method, info and workspace
 	rkm       rkmethod // Runge-Kutta method
@@ -66,6 +67,17 @@ func NewSolver(ndim int, conf *Config, fcn Func, jac JacF, M *la.Triplet) (o *So
 	o.ndim = ndim
 	o.fcn = fcn
 	o.jac = jac
+	o.mtri = M
+
+	// check differential-algebraic system
+	if o.conf.daeIdx != nil {
+		if len(o.conf.daeIdx) != ndim {
+			chk.Panic("number of variable indices (DAE) must be equal to ndim. %d != %d\n", len(o.conf.daeIdx), ndim)
+		}
+		if M == nil {
+			chk.Panic("the \"mass\" matrix M must be given for differential-algebraic equations\n")
+		}
+	}
 
 	// allocate method
 	o.rkm = newRKmethod(o.conf.method)
@@ -108,16 +120,20 @@ func (o *Solver) Solve(y la.Vector, x, xf float64) {
 		chk.Panic("method %q can only be used with fixed steps. make sure to call conf.SetFixedH > 0\n", o.conf.method)
 	}
 
+	// reset stat
+	o.Stat.Reset()
+
 	// initial step size
 	o.work.h = xf - x
 	if o.conf.fixed {
 		o.work.h = o.conf.fixedH
+	} else if m, ok := o.rkm.(rkmStarter); ok {
+		o.work.h = utl.Min(o.work.h, m.IniH(x, y))
 	} else {
 		o.work.h = utl.Min(o.work.h, o.conf.IniH)
 	}
 
 	// stat and output
-	o.Stat.Reset()
 	o.Stat.Hopt = o.work.h
 	if o.Out != nil {
 		stop := o.Out.execute(0, false, o.work.rs, o.work.h, x, y)
@@ -132,8 +148,16 @@ func (o *Solver) Solve(y la.Vector, x, xf float64) {
 	// first scaling variable
 	la.VecScaleAbs(o.work.scal, o.conf.atol, o.conf.rtol, y) // scal = atol + rtol * abs(y)
 
-	// make sure that final x is equal to xf in the end
+	// make sure that final x is equal to xf in the end (unless stopped by output function or event)
+	var stop bool
 	defer func() {
+		if stop {
+			if o.Out.Stopped { // terminal event
+				n := len(o.Out.EventX) - 1
+				y.Apply(1, o.Out.EventY[n])
+			}
+			return
+		}
 		if math.Abs(x-xf) > 1e-15 {
 			chk.Panic("internal error: x must be equal to xf in the end. x-xf=%v\n", x-xf)
 		}
@@ -157,7 +181,7 @@ func (o *Solver) Solve(y la.Vector, x, xf float64) {
 			x = float64(n+1) * o.work.h
 			o.rkm.Accept(y, x)
 			if o.Out != nil {
-				stop := o.Out.execute(istep, false, o.work.rs, o.work.h, x, y)
+				stop = o.Out.execute(istep, false, o.work.rs, o.work.h, x, y)
 				if stop {
 					return
 				}
@@ -197,7 +221,7 @@ func (o *Solver) Solve(y la.Vector, x, xf float64) {
 	var dxmax, xstep, dxnew, dxratio float64
 	var last, failed bool
 	for x < xf {
-		dxmax, xstep = Δx, x+Δx
+		dxmax, xstep = Δx, xf
 		failed = false
 		for iss := 0; iss < o.conf.NmaxSS+1; iss++ {
 
@@ -256,10 +280,13 @@ func (o *Solver) Solve(y la.Vector, x, xf float64) {
 				// update x and y
 				dxnew = o.rkm.Accept(y, x)
 				x += o.work.h
+				if last {
+					x = xstep // avoid round-off errors (e.g. when x0 ≠ 0)
+				}
 
 				// output
 				if o.Out != nil {
-					stop := o.Out.execute(o.Stat.Naccepted, last, o.work.rs, o.work.h, x, y)
+					stop = o.Out.execute(o.Stat.Naccepted, last, o.work.rs, o.work.h, x, y)
 					if stop {
 						return
 					}
@@ -336,8 +363,9 @@ func (o *Solver) Solve(y la.Vector, x, xf float64) {
 				}
 
 				// last step
-				if x+o.work.h > xstep {
+				if x+o.work.h >= xstep {
 					o.work.h = xstep - x
+					last = true
 				}
 			}
 		}
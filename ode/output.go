@@ -5,7 +5,11 @@
 package ode
 
 import (
+	"math"
+	"sort"
+
 	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
 	"github.com/cpmech/gosl/utl"
 )
 
@@ -33,6 +37,16 @@ type Output struct {
 	xout      float64     // current x of dense output
 	yout      la.Vector   // current y of dense output (used if denseF != nil only)
 
+	// events
+	EventK  []int       // index of event function (in the order given to Config.AddEvent) [nevents]
+	EventX  []float64   // x values where events were located [nevents]
+	EventY  []la.Vector // y values where events were located [nevents][ndim]
+	Stopped bool        // a terminal event has stopped the simulation
+	gPrev   []float64   // values of event functions at the beginning of step [nfcn]
+	gNew    []float64   // values of event functions at the end of step [nfcn]
+	yev     la.Vector   // y values used to find events
+	brent   *num.Brent  // root finder
+
 	// from RK method
 	dout func(yout la.Vector, h, x float64, y la.Vector, xout float64) // function to calculate dense values of y
 }
@@ -64,15 +78,43 @@ func newOutput(ndim int, conf *Config) (o *Output) {
 	if o.conf.denseF != nil {
 		o.yout = la.NewVector(ndim)
 	}
+	if len(o.conf.events) > 0 {
+		o.gPrev = make([]float64, len(o.conf.events))
+		o.gNew = make([]float64, len(o.conf.events))
+		o.yev = la.NewVector(ndim)
+		o.brent = new(num.Brent)
+	}
 	return
 }
 
 // execute executes output; e.g. call Fcn and saves x and y values
+//  NOTE: if a terminal event is found, the output is carried out up to the x of the event and
+//        stop = true is returned
 func (o *Output) execute(istep int, last bool, ρs, h, x float64, y []float64) (stop bool) {
 
+	// events
+	xlim, ylim := x, y
+	if len(o.conf.events) > 0 {
+		if istep == 0 {
+			o.Stopped = false
+			o.EventK = o.EventK[:0]
+			o.EventX = o.EventX[:0]
+			o.EventY = o.EventY[:0]
+			for k, ev := range o.conf.events {
+				o.gPrev[k] = ev.g(x, y)
+			}
+		} else {
+			if o.findEvents(h, x, y) {
+				o.Stopped = true
+				last = true
+				xlim, ylim = o.EventX[len(o.EventX)-1], o.EventY[len(o.EventY)-1]
+			}
+		}
+	}
+
 	// step output using function
 	if o.conf.stepF != nil {
-		stop = o.conf.stepF(istep, h, x, y)
+		stop = o.conf.stepF(istep, h, xlim, ylim)
 		if stop {
 			return
 		}
@@ -82,19 +124,30 @@ func (o *Output) execute(istep int, last bool, ρs, h, x float64, y []float64) (
 	if o.StepIdx < o.stepNmax {
 		o.StepRS[o.StepIdx] = ρs
 		o.StepH[o.StepIdx] = h
-		o.StepX[o.StepIdx] = x
+		o.StepX[o.StepIdx] = xlim
 		o.StepY[o.StepIdx] = la.NewVector(o.ndim)
-		o.StepY[o.StepIdx].Apply(1, y)
+		o.StepY[o.StepIdx].Apply(1, ylim)
 		o.StepIdx++
 	}
 
 	// dense output using function
 	var xo float64
 	if o.conf.denseF != nil {
+		if istep > 0 && o.Stopped { // up to the terminal event
+			xo = o.xout
+			for xlim > xo {
+				o.dout(o.yout, h, x, y, xo)
+				stop = o.conf.denseF(istep, h, x, y, xo, o.yout)
+				if stop {
+					return
+				}
+				xo += o.conf.denseDx
+			}
+		}
 		if istep == 0 || last {
-			xo = x
-			o.yout.Apply(1, y)
-			stop = o.conf.denseF(istep, h, x, y, xo, o.yout)
+			xo = xlim
+			o.yout.Apply(1, ylim)
+			stop = o.conf.denseF(istep, h, xlim, ylim, xo, o.yout)
 			if stop {
 				return
 			}
@@ -114,13 +167,26 @@ func (o *Output) execute(istep int, last bool, ρs, h, x float64, y []float64) (
 
 	// save dense output
 	if o.DenseIdx < o.denseNmax {
+		if istep > 0 && o.Stopped { // up to the terminal event
+			xo = o.xout
+			for xlim > xo && o.DenseIdx < o.denseNmax {
+				o.DenseS[o.DenseIdx] = istep
+				o.DenseX[o.DenseIdx] = xo
+				o.DenseY[o.DenseIdx] = la.NewVector(o.ndim)
+				o.dout(o.DenseY[o.DenseIdx], h, x, y, xo)
+				o.DenseIdx++
+				xo += o.conf.denseDx
+			}
+		}
 		if istep == 0 || last {
-			xo = x
-			o.DenseS[o.DenseIdx] = istep
-			o.DenseX[o.DenseIdx] = xo
-			o.DenseY[o.DenseIdx] = la.NewVector(o.ndim)
-			o.DenseY[o.DenseIdx].Apply(1, y)
-			o.DenseIdx++
+			if o.DenseIdx < o.denseNmax {
+				xo = xlim
+				o.DenseS[o.DenseIdx] = istep
+				o.DenseX[o.DenseIdx] = xo
+				o.DenseY[o.DenseIdx] = la.NewVector(o.ndim)
+				o.DenseY[o.DenseIdx].Apply(1, ylim)
+				o.DenseIdx++
+			}
 			xo = o.conf.denseDx
 		} else {
 			xo = o.xout
@@ -137,6 +203,71 @@ func (o *Output) execute(istep int, last bool, ρs, h, x float64, y []float64) (
 
 	// set xout
 	o.xout = xo
+	stop = o.Stopped
+	return
+}
+
+// findEvents finds the events within the last step [x-h, x] and saves the results. Events are
+// located by using the dense output and the Brent's method. The events are saved in increasing
+// order of x up to the first terminal event (if any)
+func (o *Output) findEvents(h, x float64, y []float64) (terminal bool) {
+
+	// find roots
+	type root struct {
+		k  int     // index of event function
+		xe float64 // x @ event
+	}
+	var roots []root
+	xa := x - h
+	for k, ev := range o.conf.events {
+		ga := o.gPrev[k]
+		gb := ev.g(x, y)
+		o.gNew[k] = gb
+		if ga == 0 || (ga < 0 && gb < 0) || (ga > 0 && gb > 0) {
+			continue
+		}
+		if (ev.direction > 0 && ga > 0) || (ev.direction < 0 && ga < 0) {
+			continue
+		}
+		if gb == 0 {
+			roots = append(roots, root{k, x})
+			continue
+		}
+		scale := utl.Max(math.Abs(ga), math.Abs(gb))
+		o.brent.Init(func(xx float64) float64 {
+			o.dout(o.yev, h, x, y, xx)
+			return ev.g(xx, o.yev) / scale
+		})
+		fa, fb := ga/scale, gb/scale
+		if fa*fb >= -num.MACHEPS { // one value is too small ⇒ take the closest point
+			if math.Abs(fa) < math.Abs(fb) {
+				roots = append(roots, root{k, xa})
+			} else {
+				roots = append(roots, root{k, x})
+			}
+			continue
+		}
+		roots = append(roots, root{k, o.brent.Solve(xa, x, true)})
+	}
+	copy(o.gPrev, o.gNew)
+
+	// save results
+	sort.SliceStable(roots, func(i, j int) bool { return roots[i].xe < roots[j].xe })
+	xt := math.Inf(1)
+	for _, r := range roots {
+		if r.xe > xt {
+			break
+		}
+		ye := la.NewVector(o.ndim)
+		o.dout(ye, h, x, y, r.xe)
+		o.EventK = append(o.EventK, r.k)
+		o.EventX = append(o.EventX, r.xe)
+		o.EventY = append(o.EventY, ye)
+		if o.conf.events[r.k].terminal {
+			terminal = true
+			xt = r.xe
+		}
+	}
 	return
 }
 
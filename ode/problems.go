@@ -362,6 +362,32 @@ func ProbArenstorf() (o *Problem) {
 	return
 }
 
+// ProbKepler returns the Kepler problem (two-body problem) with eccentricity e, starting at the
+// pericentre, as a separable Hamiltonian system with y = {q0, q1, p0, p1} and
+//   H(q,p) = ½⋅pᵀ⋅p - 1/‖q‖
+// The period is 2π (Xf)
+func ProbKepler(e float64) (o *Problem) {
+	o = new(Problem)
+	o.Xf = 2.0 * math.Pi
+	o.Y = la.NewVectorSlice([]float64{
+		1.0 - e,
+		0.0,
+		0.0,
+		math.Sqrt((1.0 + e) / (1.0 - e)),
+	})
+	o.Ndim = len(o.Y)
+	o.Dx = 0.01
+	o.Fcn = func(f la.Vector, dx, x float64, y la.Vector) {
+		r := math.Sqrt(y[0]*y[0] + y[1]*y[1])
+		r3 := r * r * r
+		f[0] = y[2]
+		f[1] = y[3]
+		f[2] = -y[0] / r3
+		f[3] = -y[1] / r3
+	}
+	return
+}
+
 // ProbSimpleNdim2 returns a simple 2-dim problem
 func ProbSimpleNdim2() (o *Problem) {
 	o = new(Problem)
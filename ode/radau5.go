@@ -217,6 +217,11 @@ func (o *Radau5) Step(x0 float64, y0 la.Vector) {
 	β := o.Bet / h
 	γ := o.Gam / h
 
+	// index-aware scaling of algebraic variables (DAEs)
+	if o.conf.daeIdx != nil {
+		daeScale(o.work.scal, o.conf, y0, h)
+	}
+
 	// Jacobian and decomposition
 	if o.work.reuseJdec {
 		o.work.reuseJdec = false
@@ -0,0 +1,392 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Rosenbrock implements (linearly implicit) Rosenbrock methods for stiff problems
+//
+//   The stages require the solution of linear systems with the same matrix; i.e. no Newton
+//   iterations are needed [2, page 111]:
+//
+//     (M/(h⋅γ) - J) ⋅ Uᵢ = f(x + αᵢ⋅h, y + Σⱼ aᵢⱼ⋅Uⱼ) + M ⋅ Σⱼ (cᵢⱼ/h)⋅Uⱼ + γᵢ⋅h⋅∂f/∂x
+//
+//     y_new = y + Σᵢ mᵢ⋅Uᵢ        error = Σᵢ eᵢ⋅Uᵢ
+//
+//   where J = ∂f/∂y is computed at (x,y) in every step and must be accurate (analytical or
+//   numerical). ∂f/∂x is computed by finite differences (one extra call to f per step).
+//
+//   The methods available are:
+//     ros3p -- 3(2) ROS3P by Lang and Verwer [1]. A-stable; designed for parabolic problems
+//     rodas -- 4(3) RODAS by Hairer and Wanner [2, page 452]. L-stable and stiffly accurate;
+//              can be used with a "mass" matrix M (e.g. DAEs of index 1)
+//
+//   References:
+//     [1] Lang J, Verwer JG (2001) ROS3P — An accurate third-order Rosenbrock solver designed for
+//         parabolic problems. BIT Numerical Mathematics, 41(4):731-738
+//     [2] Hairer E, Wanner G (1996). Solving Ordinary Differential Equations II: Stiff and
+//         Differential-Algebraic Problems. Springer Series in Computational Mathematics,
+//         Vol. 14, Berlin, Germany, 614 p.
+//
+type Rosenbrock struct {
+
+	// constants
+	Nstg int         // number of stages
+	P    int         // order of y_new
+	Q    int         // order of the embedded estimator
+	Gam  float64     // γ coefficient (diagonal)
+	A    [][]float64 // aᵢⱼ coefficients (transformed)
+	C    [][]float64 // cᵢⱼ coefficients (transformed)
+	Alp  []float64   // αᵢ coefficients: x of stages
+	Gi   []float64   // γᵢ coefficients: multiply h⋅∂f/∂x
+	B    []float64   // mᵢ coefficients: y_new = y + Σᵢ mᵢ⋅Uᵢ
+	E    []float64   // eᵢ coefficients: error = Σᵢ eᵢ⋅Uᵢ
+	D    [][]float64 // dense output coefficients [2][nstg] [may be nil ⇒ Hermite interpolation]
+
+	// data
+	ndim  int             // problem dimension
+	conf  *Config         // configurations
+	work  *rkwork         // workspace
+	stat  *Stat           // statistics
+	fcn   Func            // dy/dx := f(x,y)
+	jac   JacF            // Jacobian function: df/dy(x,y)
+	dfdy  *la.Triplet     // df/dy matrix
+	dfdx  la.Vector       // df/dx vector
+	mtri  *la.Triplet     // M matrix in triplet format
+	mmat  *la.CCMatrix    // M matrix in compressed-column format
+	hasM  bool            // has M matrix
+	kmat  *la.Triplet     // matrix of linear system: M/(h⋅γ) - J
+	ls    la.SparseSolver // linear solver
+	ready bool            // matrices and solver are ready
+	fact  bool            // linear solver has been factorised once
+
+	// workspace
+	u    []la.Vector // [nstg][ndim] stage values Uᵢ
+	ynew la.Vector   // updated y
+	rhs  la.Vector   // right-hand side of linear system
+	tmp  la.Vector   // Σⱼ (cᵢⱼ/h)⋅Uⱼ
+	w    la.Vector   // workspace
+	x0   float64     // x at the beginning of the step
+	n    float64     // exponent n = 1/(q+1) of rerrⁿ
+
+	// dense output
+	yold  la.Vector // y at the beginning of the step
+	cont2 la.Vector // Σᵢ D[0][i]⋅Uᵢ
+	cont3 la.Vector // Σᵢ D[1][i]⋅Uᵢ
+	herm  *hermite  // Hermite interpolation (if D == nil)
+}
+
+// add methods to database
+func init() {
+	rkmDB["ros3p"] = func() rkmethod { return newRosenbrock("ros3p") }
+	rkmDB["rodas"] = func() rkmethod { return newRosenbrock("rodas") }
+}
+
+// Free releases memory
+func (o *Rosenbrock) Free() {
+	if o.ls != nil {
+		o.ls.Free()
+	}
+}
+
+// Info returns information about this method
+func (o *Rosenbrock) Info() (fixedOnly, implicit bool, nstages int) {
+	return false, true, o.Nstg
+}
+
+// Init initialises structure
+func (o *Rosenbrock) Init(ndim int, conf *Config, work *rkwork, stat *Stat, fcn Func, jac JacF, M *la.Triplet) {
+
+	// data
+	o.ndim = ndim
+	o.conf = conf
+	o.work = work
+	o.stat = stat
+	o.fcn = fcn
+	o.jac = jac
+	o.dfdy = new(la.Triplet)
+	o.dfdx = la.NewVector(ndim)
+	o.mtri = M
+	if M == nil {
+		o.mtri = new(la.Triplet)
+		la.SpTriSetDiag(o.mtri, ndim, 1)
+	} else {
+		o.hasM = true
+	}
+	o.mmat = o.mtri.ToMatrix(nil)
+	o.kmat = new(la.Triplet)
+	o.ls = la.NewSparseSolver(o.conf.lsKind)
+
+	// workspace
+	o.u = make([]la.Vector, o.Nstg)
+	for i := 0; i < o.Nstg; i++ {
+		o.u[i] = la.NewVector(ndim)
+	}
+	o.ynew = la.NewVector(ndim)
+	o.rhs = la.NewVector(ndim)
+	o.tmp = la.NewVector(ndim)
+	o.w = la.NewVector(ndim)
+	o.n = 1.0 / float64(o.Q+1)
+
+	// dense output
+	if o.conf.needDense() {
+		o.yold = la.NewVector(ndim)
+		if o.D == nil {
+			o.herm = newHermite(ndim)
+		} else {
+			o.cont2 = la.NewVector(ndim)
+			o.cont3 = la.NewVector(ndim)
+		}
+	}
+}
+
+// Accept accepts update and computes next stepsize
+func (o *Rosenbrock) Accept(y0 la.Vector, x0 float64) (dxnew float64) {
+
+	// store data for dense output
+	h := o.work.h
+	if o.yold != nil {
+		o.yold.Apply(1, y0)
+		if o.D == nil {
+			o.herm.y0.Apply(1, y0)
+			o.herm.f0.Apply(1, o.work.f0)
+			o.herm.y1.Apply(1, o.ynew)
+			o.stat.Nfeval++
+			o.fcn(o.herm.f1, h, o.x0+h, o.ynew)
+		} else {
+			o.cont2.Fill(0)
+			o.cont3.Fill(0)
+			for i := 0; i < o.Nstg; i++ {
+				la.VecAdd(o.cont2, o.D[0][i], o.u[i], 1, o.cont2)
+				la.VecAdd(o.cont3, o.D[1][i], o.u[i], 1, o.cont3)
+			}
+		}
+	}
+
+	// update y
+	y0.Apply(1, o.ynew)
+
+	// the Jacobian must be computed again in the next step
+	o.work.jacIsOK = false
+
+	// estimate new stepsize
+	div := utl.Max(o.conf.Mmin, utl.Min(o.conf.Mmax, math.Pow(o.work.rerr, o.n)/o.conf.Mfac))
+	dxnew = h / div
+
+	// predictive controller of Gustafsson
+	if o.conf.PredCtrl {
+		if o.stat.Naccepted > 1 {
+			r2 := o.work.rerr * o.work.rerr
+			fac := (o.work.hPrev / h) * math.Pow(r2/o.work.rerrPrev, o.n) / o.conf.Mfac
+			fac = utl.Max(o.conf.Mmin, utl.Min(o.conf.Mmax, fac))
+			div = utl.Max(div, fac)
+			dxnew = h / div
+		}
+	}
+	return
+}
+
+// Reject processes step rejection and computes next stepsize
+func (o *Rosenbrock) Reject() (dxnew float64) {
+	div := utl.Max(o.conf.Mmin, utl.Min(o.conf.Mmax, math.Pow(o.work.rerr, o.n)/o.conf.Mfac))
+	dxnew = o.work.h / div
+	return
+}
+
+// DenseOut produces dense output (after Accept)
+func (o *Rosenbrock) DenseOut(yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	if o.D == nil {
+		o.herm.eval(yout, h, x-h, xout)
+		return
+	}
+	s := (xout - x + h) / h
+	for i := 0; i < len(y); i++ {
+		yout[i] = o.yold[i]*(1-s) + s*(y[i]+(1-s)*(o.cont2[i]+s*o.cont3[i]))
+	}
+}
+
+// Step steps update
+func (o *Rosenbrock) Step(x0 float64, y0 la.Vector) {
+
+	// auxiliary
+	h := o.work.h
+	f0 := o.work.f0
+	o.x0 = x0
+
+	// f0 = f(x0,y0) is computed by Solver, except with fixed steps and analytical Jacobian
+	if o.conf.fixed && o.jac != nil {
+		o.stat.Nfeval++
+		o.fcn(f0, h, x0, y0)
+	}
+
+	// Jacobian matrix and df/dx
+	if !o.work.jacIsOK {
+
+		// stat
+		o.stat.Njeval++
+
+		// numerical Jacobian
+		if o.jac == nil {
+			num.Jacobian(o.dfdy, func(fy, yy la.Vector) {
+				o.fcn(fy, h, x0, yy)
+			}, y0, f0, o.w) // w works here as workspace variable
+
+			// analytical Jacobian
+		} else {
+			o.jac(o.dfdy, h, x0, y0)
+		}
+
+		// df/dx by finite differences
+		δ := math.Sqrt(o.conf.Eps * utl.Max(1e-5, math.Abs(x0)))
+		o.stat.Nfeval++
+		o.fcn(o.w, h, x0+δ, y0)
+		for m := 0; m < o.ndim; m++ {
+			o.dfdx[m] = (o.w[m] - f0[m]) / δ
+		}
+
+		// set flag
+		o.work.jacIsOK = true
+	}
+
+	// initialise matrix
+	if !o.ready {
+		o.kmat.Init(o.ndim, o.ndim, o.mtri.Len()+o.dfdy.Len())
+	}
+
+	// update matrix
+	la.SpTriAdd(o.kmat, 1.0/(h*o.Gam), o.mtri, -1, o.dfdy) // kmat := M/(h⋅γ) - dfdy
+
+	// initialise linear solver
+	if !o.ready {
+		o.ls.Init(o.kmat, o.conf.Symmetric, o.conf.LsVerbose, o.conf.Ordering, o.conf.Scaling, o.conf.comm)
+		o.ready = true
+	}
+
+	// perform factorisation
+	o.stat.Ndecomp++
+	lsFact(o.ls, !o.fact)
+	o.fact = true
+
+	// stages
+	for i := 0; i < o.Nstg; i++ {
+
+		// rhs := f(x + αᵢ⋅h, y + Σⱼ aᵢⱼ⋅Uⱼ)
+		if i == 0 {
+			o.rhs.Apply(1, f0)
+		} else {
+			o.w.Apply(1, y0)
+			for j := 0; j < i; j++ {
+				if o.A[i][j] != 0 {
+					la.VecAdd(o.w, o.A[i][j], o.u[j], 1, o.w) // w += aij ⋅ Uj
+				}
+			}
+			o.stat.Nfeval++
+			o.fcn(o.rhs, h, x0+o.Alp[i]*h, o.w)
+		}
+
+		// rhs += M ⋅ Σⱼ (cᵢⱼ/h)⋅Uⱼ + γᵢ⋅h⋅∂f/∂x
+		if i > 0 {
+			o.tmp.Fill(0)
+			for j := 0; j < i; j++ {
+				la.VecAdd(o.tmp, o.C[i][j]/h, o.u[j], 1, o.tmp) // tmp += cij/h ⋅ Uj
+			}
+			if o.hasM {
+				la.SpMatVecMulAdd(o.rhs, 1, o.mmat, o.tmp) // rhs += M ⋅ tmp
+			} else {
+				la.VecAdd(o.rhs, 1, o.tmp, 1, o.rhs) // rhs += tmp
+			}
+		}
+		if o.Gi[i] != 0 {
+			la.VecAdd(o.rhs, o.Gi[i]*h, o.dfdx, 1, o.rhs) // rhs += γᵢ⋅h⋅∂f/∂x
+		}
+
+		// solve linear system
+		o.stat.Nlinsol++
+		o.ls.Solve(o.u[i], o.rhs, false) // Ui := inv(kmat) ⋅ rhs
+	}
+
+	// update and error estimate
+	var lerrm, sk, ratio, sum float64
+	for m := 0; m < o.ndim; m++ {
+		o.ynew[m] = y0[m]
+		lerrm = 0.0
+		for i := 0; i < o.Nstg; i++ {
+			o.ynew[m] += o.B[i] * o.u[i][m]
+			lerrm += o.E[i] * o.u[i][m]
+		}
+		sk = o.conf.atol + o.conf.rtol*utl.Max(math.Abs(y0[m]), math.Abs(o.ynew[m]))
+		ratio = lerrm / sk
+		sum += ratio * ratio
+	}
+	o.work.rerr = utl.Max(math.Sqrt(sum/float64(o.ndim)), 1.0e-10)
+}
+
+// newRosenbrock returns a new Rosenbrock method with the coefficients set
+func newRosenbrock(kind string) rkmethod {
+	o := new(Rosenbrock)
+	switch kind {
+
+	// ROS3P: Table 1 of [1] (transformed coefficients)
+	case "ros3p":
+		o.Nstg, o.P, o.Q = 3, 3, 2
+		o.Gam = 0.5 + math.Sqrt(3.0)/6.0
+		o.A = [][]float64{
+			{0, 0, 0},
+			{1.267949192431123e+00, 0, 0},
+			{1.267949192431123e+00, 0, 0},
+		}
+		o.C = [][]float64{
+			{0, 0, 0},
+			{-1.607695154586736e+00, 0, 0},
+			{-3.464101615137755e+00, -1.732050807568877e+00, 0},
+		}
+		o.Alp = []float64{0, 1, 1}
+		o.Gi = []float64{7.886751345948129e-01, -2.113248654051871e-01, -1.077350269189626e+00}
+		o.B = []float64{2.0, 5.773502691896258e-01, 4.226497308103742e-01}
+		mh := []float64{2.113248654051871e+00, 1.0, 4.226497308103742e-01} // embedded
+		o.E = []float64{o.B[0] - mh[0], o.B[1] - mh[1], o.B[2] - mh[2]}
+
+	// RODAS: coefficients from Hairer's rodas.f [2]
+	case "rodas":
+		o.Nstg, o.P, o.Q = 6, 4, 3
+		o.Gam = 0.25
+		a51, a52, a53, a54 := 1.221224509226641e+00, 6.019134481288629e+00, 1.253708332932087e+01, -6.878860361058950e-01
+		o.A = [][]float64{
+			{0, 0, 0, 0, 0, 0},
+			{1.544000000000000e+00, 0, 0, 0, 0, 0},
+			{9.466785280815826e-01, 2.557011698983284e-01, 0, 0, 0, 0},
+			{3.314825187068521e+00, 2.896124015972201e+00, 9.986419139977817e-01, 0, 0, 0},
+			{a51, a52, a53, a54, 0, 0},
+			{a51, a52, a53, a54, 1, 0},
+		}
+		o.C = [][]float64{
+			{0, 0, 0, 0, 0, 0},
+			{-5.668800000000000e+00, 0, 0, 0, 0, 0},
+			{-2.430093356833875e+00, -2.063599157091915e-01, 0, 0, 0, 0},
+			{-1.073529058151375e-01, -9.594562251023355e+00, -2.047028614809616e+01, 0, 0, 0},
+			{7.496443313967647e+00, -1.024680431464352e+01, -3.399990352819905e+01, 1.170890893206160e+01, 0, 0},
+			{8.083246795921522e+00, -7.981132988064893e+00, -3.152159432874371e+01, 1.631930543123136e+01, -6.058818238834054e+00, 0},
+		}
+		o.Alp = []float64{0, 0.386, 0.21, 0.63, 1, 1}
+		o.Gi = []float64{0.25, -0.1043, 0.1035, -0.0362, 0, 0}
+		o.B = []float64{a51, a52, a53, a54, 1, 1} // stiffly accurate: y_new = y6
+		o.E = []float64{0, 0, 0, 0, 0, 1}
+		o.D = [][]float64{
+			{1.012623508344586e+01, -7.487995877610167e+00, -3.480091861555747e+01, -7.992771707568823e+00, 1.025137723295662e+00, 0},
+			{-6.762803392801253e-01, 6.087714651680015e+00, 1.643084320892478e+01, 2.476722511418386e+01, -6.594389125716872e+00, 0},
+		}
+
+	default:
+		chk.Panic("cannot find Rosenbrock method %q\n", kind)
+	}
+	return o
+}
@@ -0,0 +1,421 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/rnd"
+)
+
+// SdeSolver implements fixed-step integrators for Itô stochastic differential equations (SDEs)
+// with diagonal noise
+//
+//   dy = f(x,y) dx + g(x,y) ∘ dW   i.e.   dyᵢ = fᵢ(x,y) dx + gᵢ(x,y) dWᵢ
+//
+//  where f is the drift, g is the diffusion and W = {W0, W1, ...} are independent Wiener
+//  processes. Each gᵢ may depend on x and yᵢ only. The following schemes are available:
+//
+//    scheme       strong order   weak order   reference
+//    "em"         0.5            1            Euler-Maruyama [1]
+//    "milstein"   1              1            derivative-free Milstein scheme; Eq. (11.1.5) of [1]
+//    "sri"        1.5            2            stochastic Runge-Kutta method SRIW1 of [2]
+//    "sra"        1.5            2            stochastic Runge-Kutta method SRA1 of [2]
+//
+//  The "sra" scheme is only valid for additive noise; i.e. g = g(x).
+//
+//  The stochastic Runge-Kutta methods are written as follows (I_(1) = ΔW, I_(1,0) = ΔZ, I_(1,1)
+//  and I_(1,1,1) are the iterated Itô integrals; see rnd.Wiener)
+//
+//   H0ᵢ = yₙ + Σ_j A0ᵢⱼ ⋅ f(x+c0ⱼ⋅h, H0ⱼ) ⋅ h + Σ_j B0ᵢⱼ ⋅ g(x+c1ⱼ⋅h, H1ⱼ) ⋅ I_(1,0)/h
+//   H1ᵢ = yₙ + Σ_j A1ᵢⱼ ⋅ f(x+c0ⱼ⋅h, H0ⱼ) ⋅ h + Σ_j B1ᵢⱼ ⋅ g(x+c1ⱼ⋅h, H1ⱼ) ⋅ √h
+//
+//   yₙ₊₁ = yₙ + Σ_i αᵢ ⋅ f(x+c0ᵢ⋅h, H0ᵢ) ⋅ h
+//             + Σ_i (β1ᵢ⋅I_(1) + β2ᵢ⋅I_(1,1)/√h + β3ᵢ⋅I_(1,0)/h + β4ᵢ⋅I_(1,1,1)/h) ⋅ g(x+c1ᵢ⋅h, H1ᵢ)
+//
+//  The Wiener increments are generated by rnd.Wiener; thus the sample paths are reproducible
+//  with the same seed. Many sample paths (an ensemble) can be computed concurrently; see Ensemble.
+//
+//   References:
+//     [1] Kloeden PE and Platen E (1992) Numerical Solution of Stochastic Differential Equations.
+//         Springer. 632p
+//     [2] Rößler A (2010) Runge-Kutta methods for the strong approximation of solutions of
+//         stochastic differential equations. SIAM Journal on Numerical Analysis, 48(3):922-952
+//
+type SdeSolver struct {
+
+	// output
+	Stat *Stat // statistics (Nfeval is the number of calls to the drift function)
+
+	// problem definition
+	method string   // scheme
+	ndim   int      // size of y
+	fcn    Func     // drift f(x,y)
+	gcn    Func     // diffusion g(x,y) (diagonal noise)
+	stepF  StepOutF // step output function [may be nil]
+
+	// stochastic Runge-Kutta methods
+	c0, c1         []float64   // nodes of drift and diffusion stages
+	a0, b0, a1, b1 [][]float64 // coefficients of stages
+	α              []float64   // weights of drift
+	β1, β2, β3, β4 []float64   // weights of diffusion
+	need0, need1   []bool      // drift or diffusion of stage is required
+
+	// workspace
+	work *sdeWork // workspace of Solve
+}
+
+// sdeWork holds the workspace of SdeSolver (one for each goroutine)
+type sdeWork struct {
+	stat   *Stat       // statistics
+	dW, dZ la.Vector   // Wiener increments
+	f, g   la.Vector   // drift and diffusion
+	w, gw  la.Vector   // supporting value and its diffusion (Milstein)
+	H0, H1 []la.Vector // stage values
+	F0, G1 []la.Vector // drift and diffusion at stages
+}
+
+// NewSdeSolver returns a new SDE solver
+//   method -- "em", "milstein", "sri" or "sra"
+//   ndim   -- size of y (and number of Wiener processes)
+//   fcn    -- drift f(x,y)
+//   gcn    -- diffusion g(x,y); the values gᵢ are the diagonal of the diffusion matrix
+func NewSdeSolver(method string, ndim int, fcn, gcn Func) (o *SdeSolver) {
+	o = new(SdeSolver)
+	o.method = method
+	o.ndim = ndim
+	o.fcn = fcn
+	o.gcn = gcn
+	switch method {
+	case "em", "milstein":
+	case "sri":
+		// A1₄₃ = 1/4 of [2] is applied to the first drift stage because H0₃ = H0₁ (and c0₃ = c0₁)
+		o.c0 = []float64{0, 3.0 / 4.0}
+		o.c1 = []float64{0, 1.0 / 4.0, 1, 1.0 / 4.0}
+		o.a0 = [][]float64{{0, 0}, {3.0 / 4.0, 0}}
+		o.b0 = [][]float64{{0, 0, 0, 0}, {3.0 / 2.0, 0, 0, 0}}
+		o.a1 = [][]float64{{0, 0}, {1.0 / 4.0, 0}, {1, 0}, {1.0 / 4.0, 0}}
+		o.b1 = [][]float64{{0, 0, 0, 0}, {1.0 / 2.0, 0, 0, 0}, {-1, 0, 0, 0}, {-5, 3, 1.0 / 2.0, 0}}
+		o.α = []float64{1.0 / 3.0, 2.0 / 3.0}
+		o.β1 = []float64{-1, 4.0 / 3.0, 2.0 / 3.0, 0}
+		o.β2 = []float64{-1, 4.0 / 3.0, -1.0 / 3.0, 0}
+		o.β3 = []float64{2, -4.0 / 3.0, -2.0 / 3.0, 0}
+		o.β4 = []float64{-2, 5.0 / 3.0, -2.0 / 3.0, 1}
+	case "sra":
+		// the weights of I_(1,0)/h are given in β3; the stages H1 are not required (additive noise)
+		o.c0 = []float64{0, 3.0 / 4.0}
+		o.c1 = []float64{1, 0}
+		o.a0 = [][]float64{{0, 0}, {3.0 / 4.0, 0}}
+		o.b0 = [][]float64{{0, 0}, {3.0 / 2.0, 0}}
+		o.α = []float64{1.0 / 3.0, 2.0 / 3.0}
+		o.β1 = []float64{1, 0}
+		o.β2 = []float64{0, 0}
+		o.β3 = []float64{-1, 1}
+		o.β4 = []float64{0, 0}
+	default:
+		chk.Panic("cannot find SDE scheme named %q\n", method)
+	}
+	o.setRequiredStages()
+	o.Stat = NewStat("", false)
+	o.work = o.newWork()
+	return
+}
+
+// SetStepOut sets a function to be called after each step (not used by Ensemble)
+func (o *SdeSolver) SetStepOut(out StepOutF) {
+	o.stepF = out
+}
+
+// Solve solves the SDE from x to xf with (fixed) stepsize approximately equal to h
+//   y -- initial values (input) and values @ xf (output)
+//   w -- generator of Wiener increments
+func (o *SdeSolver) Solve(y la.Vector, x, xf, h float64, w *rnd.Wiener) {
+	if xf < x {
+		chk.Panic("xf=%v must be greater than x=%v\n", xf, x)
+	}
+	nsteps := int(math.Ceil((xf - x) / h))
+	h = (xf - x) / float64(nsteps)
+	o.Stat.Reset()
+	o.work.stat.Reset()
+	o.solve(o.work, y, x, h, nsteps, func(n int, dW, dZ la.Vector) {
+		w.Increments(dW, dZ, h)
+	}, o.stepF)
+	o.Stat.add(o.work.stat)
+}
+
+// SolveIncrements solves the SDE with given Wiener increments (e.g. from a common Brownian path)
+//   y  -- initial values (input) and values @ x + len(dW)⋅h (output)
+//   dW -- [nsteps][ndim] Wiener increments ΔW
+//   dZ -- [nsteps][ndim] integrals ΔZ = I_(1,0) (see rnd.Wiener) [may be nil for "em" and "milstein"]
+func (o *SdeSolver) SolveIncrements(y la.Vector, x, h float64, dW, dZ []la.Vector) {
+	if dZ == nil && o.α != nil {
+		chk.Panic("the integrals ΔZ must be given to the %q scheme\n", o.method)
+	}
+	o.Stat.Reset()
+	o.work.stat.Reset()
+	o.solve(o.work, y, x, h, len(dW), func(n int, ΔW, ΔZ la.Vector) {
+		ΔW.Apply(1, dW[n])
+		if dZ != nil {
+			ΔZ.Apply(1, dZ[n])
+		}
+	}, o.stepF)
+	o.Stat.add(o.work.stat)
+}
+
+// Ensemble computes many sample paths concurrently and returns the values @ xf
+//
+//  INPUT:
+//    y0       -- initial values (of all sample paths)
+//    x        -- initial x
+//    xf       -- final x
+//    h        -- (fixed) stepsize; see Solve
+//    npaths   -- number of sample paths
+//    nworkers -- number of goroutines; use nworkers <= 0 to use runtime.NumCPU()
+//    seed     -- the Wiener increments of sample path k are generated by rnd.NewWiener(seed+k);
+//                thus the results do not depend on nworkers. Use seed <= 0 to use current time
+//
+//  OUTPUT:
+//    Y -- [npaths][ndim] values @ xf
+//
+//  NOTE: fcn and gcn are called concurrently; thus they must not modify shared data
+//
+func (o *SdeSolver) Ensemble(y0 la.Vector, x, xf, h float64, npaths, nworkers, seed int) (Y []la.Vector) {
+	if xf < x {
+		chk.Panic("xf=%v must be greater than x=%v\n", xf, x)
+	}
+	if seed <= 0 {
+		seed = int(time.Now().Unix())
+	}
+	nsteps := int(math.Ceil((xf - x) / h))
+	h = (xf - x) / float64(nsteps)
+	Y = make([]la.Vector, npaths)
+	works := make([]*sdeWork, sdeNworkers(npaths, nworkers))
+	for i := range works {
+		works[i] = o.newWork()
+	}
+	sdeParallel(npaths, len(works), func(worker, k int) {
+		w := rnd.NewWiener(seed + k)
+		Y[k] = y0.GetCopy()
+		o.solve(works[worker], Y[k], x, h, nsteps, func(n int, dW, dZ la.Vector) {
+			w.Increments(dW, dZ, h)
+		}, nil)
+	})
+	o.Stat.Reset()
+	for _, work := range works {
+		o.Stat.add(work.stat)
+	}
+	return
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// setRequiredStages finds the stages whose drift or diffusion is required
+func (o *SdeSolver) setRequiredStages() {
+	used := func(i int, weights []float64, coefs ...[][]float64) bool {
+		if weights[i] != 0 {
+			return true
+		}
+		for _, c := range coefs {
+			for _, row := range c {
+				if row[i] != 0 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	o.need0 = make([]bool, len(o.α))
+	for i := range o.α {
+		o.need0[i] = used(i, o.α, o.a0, o.a1)
+	}
+	o.need1 = make([]bool, len(o.β1))
+	for i := range o.β1 {
+		o.need1[i] = used(i, o.β1, o.b0, o.b1) || o.β2[i] != 0 || o.β3[i] != 0 || o.β4[i] != 0
+	}
+}
+
+// newWork allocates a new workspace
+func (o *SdeSolver) newWork() (w *sdeWork) {
+	n := o.ndim
+	w = new(sdeWork)
+	w.stat = NewStat("", false)
+	w.dW, w.dZ = la.NewVector(n), la.NewVector(n)
+	w.f, w.g = la.NewVector(n), la.NewVector(n)
+	w.w, w.gw = la.NewVector(n), la.NewVector(n)
+	w.H0, w.F0 = make([]la.Vector, len(o.α)), make([]la.Vector, len(o.α))
+	for i := range o.α {
+		w.H0[i], w.F0[i] = la.NewVector(n), la.NewVector(n)
+	}
+	w.H1, w.G1 = make([]la.Vector, len(o.β1)), make([]la.Vector, len(o.β1))
+	for i := range o.β1 {
+		w.H1[i], w.G1[i] = la.NewVector(n), la.NewVector(n)
+	}
+	return
+}
+
+// solve runs nsteps steps with increments given by incr
+func (o *SdeSolver) solve(w *sdeWork, y la.Vector, x, h float64, nsteps int,
+	incr func(n int, dW, dZ la.Vector), stepF StepOutF) {
+
+	// initialise
+	w.stat.Hopt = h
+	if stepF != nil {
+		if stepF(0, h, x, y) {
+			return
+		}
+	}
+
+	// steps
+	x0 := x
+	for n := 0; n < nsteps; n++ {
+		incr(n, w.dW, w.dZ)
+		switch o.method {
+		case "em":
+			o.stepEM(w, y, x, h)
+		case "milstein":
+			o.stepMilstein(w, y, x, h)
+		default:
+			o.stepSRK(w, y, x, h)
+		}
+		w.stat.Nsteps++
+		w.stat.Naccepted++
+		x = x0 + float64(n+1)*h
+		if stepF != nil {
+			if stepF(n+1, h, x, y) {
+				return
+			}
+		}
+	}
+}
+
+// stepEM computes the Euler-Maruyama step: yₙ₊₁ = yₙ + f⋅h + g∘ΔW
+func (o *SdeSolver) stepEM(w *sdeWork, y la.Vector, x, h float64) {
+	o.fcn(w.f, h, x, y)
+	o.gcn(w.g, h, x, y)
+	w.stat.Nfeval++
+	for i := 0; i < o.ndim; i++ {
+		y[i] += w.f[i]*h + w.g[i]*w.dW[i]
+	}
+}
+
+// stepMilstein computes the derivative-free Milstein step
+//
+//   w = yₙ + f⋅h + g⋅√h
+//   yₙ₊₁ = yₙ + f⋅h + g∘ΔW + (g(w) - g)∘(ΔW² - h) / (2⋅√h)
+//
+func (o *SdeSolver) stepMilstein(w *sdeWork, y la.Vector, x, h float64) {
+	sq := math.Sqrt(h)
+	o.fcn(w.f, h, x, y)
+	o.gcn(w.g, h, x, y)
+	w.stat.Nfeval++
+	for i := 0; i < o.ndim; i++ {
+		w.w[i] = y[i] + w.f[i]*h + w.g[i]*sq
+	}
+	o.gcn(w.gw, h, x, w.w)
+	for i := 0; i < o.ndim; i++ {
+		ΔW := w.dW[i]
+		y[i] += w.f[i]*h + w.g[i]*ΔW + (w.gw[i]-w.g[i])*(ΔW*ΔW-h)/(2*sq)
+	}
+}
+
+// stepSRK computes the step of stochastic Runge-Kutta methods
+func (o *SdeSolver) stepSRK(w *sdeWork, y la.Vector, x, h float64) {
+
+	// stages
+	sq := math.Sqrt(h)
+	s0, s1 := len(o.α), len(o.β1)
+	for s := 0; s < s0 || s < s1; s++ {
+		if s < s0 {
+			H := w.H0[s]
+			for i := 0; i < o.ndim; i++ {
+				H[i] = y[i]
+				for j := 0; j < s; j++ {
+					H[i] += o.a0[s][j] * w.F0[j][i] * h
+				}
+				for j := 0; j < s && j < s1; j++ {
+					H[i] += o.b0[s][j] * w.G1[j][i] * w.dZ[i] / h
+				}
+			}
+		}
+		if s < s1 {
+			H := w.H1[s]
+			for i := 0; i < o.ndim; i++ {
+				H[i] = y[i]
+				if o.a1 == nil { // additive noise
+					continue
+				}
+				for j := 0; j < s && j < s0; j++ {
+					H[i] += o.a1[s][j] * w.F0[j][i] * h
+				}
+				for j := 0; j < s; j++ {
+					H[i] += o.b1[s][j] * w.G1[j][i] * sq
+				}
+			}
+		}
+		if s < s0 && o.need0[s] {
+			o.fcn(w.F0[s], h, x+o.c0[s]*h, w.H0[s])
+			w.stat.Nfeval++
+		}
+		if s < s1 && o.need1[s] {
+			o.gcn(w.G1[s], h, x+o.c1[s]*h, w.H1[s])
+		}
+	}
+
+	// update
+	for i := 0; i < o.ndim; i++ {
+		ΔW, ΔZ := w.dW[i], w.dZ[i]
+		I11 := (ΔW*ΔW - h) / 2
+		I111 := (ΔW*ΔW*ΔW - 3*h*ΔW) / 6
+		for s := 0; s < s0; s++ {
+			if o.need0[s] {
+				y[i] += o.α[s] * w.F0[s][i] * h
+			}
+		}
+		for s := 0; s < s1; s++ {
+			if o.need1[s] {
+				y[i] += (o.β1[s]*ΔW + o.β2[s]*I11/sq + o.β3[s]*ΔZ/h + o.β4[s]*I111/h) * w.G1[s][i]
+			}
+		}
+	}
+}
+
+// sdeNworkers returns the number of goroutines to run ntasks
+func sdeNworkers(ntasks, nworkers int) int {
+	if nworkers <= 0 {
+		nworkers = runtime.NumCPU()
+	}
+	if nworkers > ntasks {
+		nworkers = ntasks
+	}
+	if nworkers < 1 {
+		nworkers = 1
+	}
+	return nworkers
+}
+
+// sdeParallel runs ntasks tasks with nworkers goroutines
+func sdeParallel(ntasks, nworkers int, run func(worker, k int)) {
+	tasks := make(chan int, ntasks)
+	for k := 0; k < ntasks; k++ {
+		tasks <- k
+	}
+	close(tasks)
+	var wg sync.WaitGroup
+	wg.Add(nworkers)
+	for worker := 0; worker < nworkers; worker++ {
+		go func(worker int) {
+			defer wg.Done()
+			for k := range tasks {
+				run(worker, k)
+			}
+		}(worker)
+	}
+	wg.Wait()
+}
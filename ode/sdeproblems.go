@@ -0,0 +1,224 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+	"github.com/cpmech/gosl/plt"
+	"github.com/cpmech/gosl/rnd"
+)
+
+// SdeYanaF defines the analytical solution of SDEs as a function of x and W(x), with x0 = 0
+type SdeYanaF func(res la.Vector, x float64, W la.Vector)
+
+// SdeProblem defines the data for SDE problems (e.g. for testing)
+type SdeProblem struct {
+	Yana SdeYanaF  // analytical solution [may be nil]
+	Fcn  Func      // drift f(x,y)
+	Gcn  Func      // diffusion g(x,y) (diagonal noise)
+	Xf   float64   // final x (x0 = 0)
+	Y    la.Vector // initial y vector
+	Ndim int       // dimension == len(Y)
+}
+
+// StrongConvergenceTest runs convergence test of the strong error E[‖y(xf) - yₙ‖∞]
+//   nmin     -- smallest number of steps; the stepsizes are h = Xf / (nmin ⋅ 2ˡ) for l < nlevels
+//   npaths   -- number of sample paths
+//   seed     -- seed of the Wiener increments of the first sample path (see SdeSolver.Ensemble)
+//   NOTE: the reference solution is computed with the same sample paths by Yana or, if Yana is
+//         nil, by the "sri" scheme with 8 times more steps than the finest level
+func (o *SdeProblem) StrongConvergenceTest(tst *testing.T, nmin, nlevels, npaths, seed int,
+	methods []string, orders, tols []float64) {
+	o.convergenceTest(tst, nmin, nlevels, npaths, seed, methods, orders, tols, false)
+}
+
+// WeakConvergenceTest runs convergence test of the weak error max_i |E[yᵢ(xf)] - E[yₙᵢ]|
+//   nmin     -- smallest number of steps; the stepsizes are h = Xf / (nmin ⋅ 2ˡ) for l < nlevels
+//   npaths   -- number of sample paths
+//   seed     -- seed of the Wiener increments of the first sample path (see SdeSolver.Ensemble)
+//   NOTE: the expected values are estimated with the same sample paths for the numerical and the
+//         reference solutions (see StrongConvergenceTest); thus most of the statistical error
+//         cancels out. Nonetheless, npaths must be large enough to capture the weak error
+func (o *SdeProblem) WeakConvergenceTest(tst *testing.T, nmin, nlevels, npaths, seed int,
+	methods []string, orders, tols []float64) {
+	o.convergenceTest(tst, nmin, nlevels, npaths, seed, methods, orders, tols, true)
+}
+
+// convergenceTest runs strong or weak convergence tests
+func (o *SdeProblem) convergenceTest(tst *testing.T, nmin, nlevels, npaths, seed int,
+	methods []string, orders, tols []float64, weak bool) {
+
+	// constants
+	H := make([]float64, nlevels)
+	E := make([]float64, nlevels)
+	lh := make([]float64, nlevels)
+	le := make([]float64, nlevels)
+
+	// try methods
+	for im, method := range methods {
+
+		// run sample paths
+		D := o.sampleErrors(method, nmin, nlevels, npaths, seed)
+
+		// strong or weak errors
+		for l := 0; l < nlevels; l++ {
+			H[l] = o.Xf / float64(nmin<<uint(l))
+			E[l] = 0
+			if weak {
+				for i := 0; i < o.Ndim; i++ {
+					sum := 0.0
+					for k := 0; k < npaths; k++ {
+						sum += D[k][l][i]
+					}
+					E[l] = math.Max(E[l], math.Abs(sum/float64(npaths)))
+				}
+			} else {
+				for k := 0; k < npaths; k++ {
+					E[l] += D[k][l].Largest(1) / float64(npaths)
+				}
+			}
+
+			// log-log values
+			lh[l] = math.Log10(H[l])
+			le[l] = math.Log10(E[l])
+		}
+
+		// calc convergence rate
+		_, m := num.LinFit(lh, le)
+		chk.AnaNum(tst, "slope m", tols[im], m, orders[im], chk.Verbose)
+
+		if chk.Verbose {
+			plt.Plot(H, E, &plt.A{L: method, C: plt.C(im, 0), M: plt.M(im, 0), NoClip: true})
+		}
+	}
+}
+
+// sampleErrors computes the differences between the numerical and reference solutions @ Xf
+//   D -- [npaths][nlevels][ndim] differences
+func (o *SdeProblem) sampleErrors(method string, nmin, nlevels, npaths, seed int) (D [][]la.Vector) {
+
+	// fine grid
+	nfine := nmin << uint(nlevels-1)
+	if o.Yana == nil {
+		nfine *= 8
+	}
+	δ := o.Xf / float64(nfine)
+
+	// solvers (one for each goroutine)
+	nworkers := sdeNworkers(npaths, 0)
+	sols := make([]*SdeSolver, nworkers)
+	refs := make([]*SdeSolver, nworkers)
+	for i := 0; i < nworkers; i++ {
+		sols[i] = NewSdeSolver(method, o.Ndim, o.Fcn, o.Gcn)
+		refs[i] = NewSdeSolver("sri", o.Ndim, o.Fcn, o.Gcn)
+	}
+
+	// run
+	D = make([][]la.Vector, npaths)
+	sdeParallel(npaths, nworkers, func(worker, k int) {
+
+		// Wiener increments on the fine grid
+		w := rnd.NewWiener(seed + k)
+		dW, dZ := make([]la.Vector, nfine), make([]la.Vector, nfine)
+		for n := 0; n < nfine; n++ {
+			dW[n], dZ[n] = la.NewVector(o.Ndim), la.NewVector(o.Ndim)
+			w.Increments(dW[n], dZ[n], δ)
+		}
+
+		// reference solution
+		yRef := o.Y.GetCopy()
+		if o.Yana == nil {
+			refs[worker].SolveIncrements(yRef, 0, δ, dW, dZ)
+		} else {
+			W := la.NewVector(o.Ndim)
+			for n := 0; n < nfine; n++ {
+				la.VecAdd(W, 1, dW[n], 1, W)
+			}
+			o.Yana(yRef, o.Xf, W)
+		}
+
+		// numerical solutions
+		D[k] = make([]la.Vector, nlevels)
+		for l := 0; l < nlevels; l++ {
+			nsteps := nmin << uint(l)
+			DW, DZ := sdeCoarsen(dW, dZ, nfine/nsteps, δ)
+			y := o.Y.GetCopy()
+			sols[worker].SolveIncrements(y, 0, o.Xf/float64(nsteps), DW, DZ)
+			D[k][l] = la.NewVector(o.Ndim)
+			la.VecAdd(D[k][l], 1, y, -1, yRef)
+		}
+	})
+	return
+}
+
+// sdeCoarsen sums groups of m Wiener increments of size δ; i.e. for each group
+//
+//   ΔW = Σ_k δWₖ   and   ΔZ = Σ_k (δZₖ + (W(xₖ) - W(x₀)) ⋅ δ)
+//
+func sdeCoarsen(dW, dZ []la.Vector, m int, δ float64) (DW, DZ []la.Vector) {
+	n := len(dW) / m
+	DW, DZ = make([]la.Vector, n), make([]la.Vector, n)
+	for j := 0; j < n; j++ {
+		DW[j], DZ[j] = la.NewVector(len(dW[0])), la.NewVector(len(dW[0]))
+		for k := j * m; k < (j+1)*m; k++ {
+			for i := range DW[j] {
+				DZ[j][i] += dZ[k][i] + DW[j][i]*δ
+				DW[j][i] += dW[k][i]
+			}
+		}
+	}
+	return
+}
+
+// ProbSdeGbm returns the geometric Brownian motion problem (diagonal noise)
+//
+//   dyᵢ = μᵢ⋅yᵢ dx + σᵢ⋅yᵢ dWᵢ   ⇒   yᵢ(x) = yᵢ(0) ⋅ exp((μᵢ - σᵢ²/2)⋅x + σᵢ⋅Wᵢ(x))
+//
+func ProbSdeGbm(μ, σ []float64) (o *SdeProblem) {
+	o = new(SdeProblem)
+	o.Ndim = len(μ)
+	o.Yana = func(res la.Vector, x float64, W la.Vector) {
+		for i := 0; i < o.Ndim; i++ {
+			res[i] = o.Y[i] * math.Exp((μ[i]-σ[i]*σ[i]/2)*x+σ[i]*W[i])
+		}
+	}
+	o.Fcn = func(f la.Vector, dx, x float64, y la.Vector) {
+		for i := 0; i < o.Ndim; i++ {
+			f[i] = μ[i] * y[i]
+		}
+	}
+	o.Gcn = func(g la.Vector, dx, x float64, y la.Vector) {
+		for i := 0; i < o.Ndim; i++ {
+			g[i] = σ[i] * y[i]
+		}
+	}
+	o.Y = la.NewVector(o.Ndim)
+	o.Y.Fill(1)
+	o.Xf = 1
+	return
+}
+
+// ProbSdeDoubleWell returns the overdamped motion in a double-well potential (additive noise)
+//
+//   dy = (y - y³) dx + σ⋅cos(x) dW
+//
+func ProbSdeDoubleWell(σ float64) (o *SdeProblem) {
+	o = new(SdeProblem)
+	o.Fcn = func(f la.Vector, dx, x float64, y la.Vector) {
+		f[0] = y[0] - y[0]*y[0]*y[0]
+	}
+	o.Gcn = func(g la.Vector, dx, x float64, y la.Vector) {
+		g[0] = σ * math.Cos(x)
+	}
+	o.Y = la.Vector{0.5}
+	o.Ndim = 1
+	o.Xf = 1
+	return
+}
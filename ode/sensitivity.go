@@ -0,0 +1,403 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+)
+
+// SensForward implements the forward sensitivity analysis of the solution y(x;p) with respect to
+// the parameters p by integrating the augmented system
+//
+//   dy/dx = f(x,y)   and   dsⱼ/dx = ∂f/∂y ⋅ sⱼ + ∂f/∂pⱼ   with   sⱼ = ∂y/∂pⱼ
+//
+//  NOTE: (1) the augmented vector is Y = [y, s₀, s₁, ...] (size = ndim⋅(1+np)); thus, the step
+//            and dense outputs of Sol (if requested in conf) hold Y
+//        (2) if jac == nil, the products ∂f/∂y ⋅ sⱼ are computed by directional differences
+//            and the Jacobian of the augmented system is computed numerically (implicit methods);
+//            otherwise, the Jacobian of the augmented system is approximated by the
+//            block-diagonal matrix diag(∂f/∂y, ∂f/∂y, ...)
+type SensForward struct {
+	Sol *Solver // solver of the augmented system
+
+	// problem definition
+	ndim int  // size of y
+	np   int  // number of parameters
+	fcn  Func // dy/dx := f(x,y)
+	jac  JacF // Jacobian: df/dy [may be nil]
+	jacP JacP // Jacobian: df/dp
+
+	// workspace
+	dfdy *la.Triplet // df/dy
+	dfdp *la.Matrix  // df/dp
+	ya   la.Vector   // augmented vector: [y, s₀, s₁, ...]
+	yδ   la.Vector   // y + δ⋅s
+	fδ   la.Vector   // f(x, y + δ⋅s)
+}
+
+// NewSensForward returns a new object to perform forward sensitivity analyses
+//   ndim -- size of y
+//   np   -- number of parameters
+//   conf -- configuration parameters of the solver of the augmented system
+//   fcn  -- f(x,y) = dy/dx function
+//   jac  -- Jacobian: df/dy function [may be nil]
+//   jacP -- Jacobian: df/dp function
+//   NOTE: remember to call Free() to release allocated resources
+func NewSensForward(ndim, np int, conf *Config, fcn Func, jac JacF, jacP JacP) (o *SensForward) {
+	if jacP == nil {
+		chk.Panic("the Jacobian with respect to the parameters (jacP) must be given\n")
+	}
+	o = new(SensForward)
+	o.ndim, o.np = ndim, np
+	o.fcn, o.jac, o.jacP = fcn, jac, jacP
+	o.dfdy = new(la.Triplet)
+	o.dfdp = la.NewMatrix(ndim, np)
+	o.ya = la.NewVector(ndim * (1 + np))
+	o.yδ = la.NewVector(ndim)
+	o.fδ = la.NewVector(ndim)
+	if jac == nil {
+		o.Sol = NewSolver(ndim*(1+np), conf, o.augFcn, nil, nil)
+	} else {
+		o.Sol = NewSolver(ndim*(1+np), conf, o.augFcn, o.augJac, nil)
+	}
+	return
+}
+
+// Free releases allocated memory
+func (o *SensForward) Free() {
+	o.Sol.Free()
+}
+
+// Solve solves the augmented system from x to xf
+//   y -- initial y (input) and y @ xf (output)
+//   S -- initial sensitivities, e.g. zero or dy0/dp, (input) and dy/dp @ xf (output) [ndim × np]
+func (o *SensForward) Solve(y la.Vector, S *la.Matrix, x, xf float64) {
+	copy(o.ya[:o.ndim], y)
+	copy(o.ya[o.ndim:], S.Data) // column-major ⇒ [s₀, s₁, ...]
+	o.Sol.Solve(o.ya, x, xf)
+	copy(y, o.ya[:o.ndim])
+	copy(S.Data, o.ya[o.ndim:])
+}
+
+// augFcn computes the right-hand side of the augmented system
+func (o *SensForward) augFcn(F la.Vector, h, x float64, Y la.Vector) {
+	n := o.ndim
+	y, f := Y[:n], F[:n]
+	o.fcn(f, h, x, y)
+	o.jacP(o.dfdp, x, y)
+	if o.jac != nil {
+		o.jac(o.dfdy, h, x, y)
+	}
+	for j := 0; j < o.np; j++ {
+		s, fs := Y[n*(1+j):n*(2+j)], F[n*(1+j):n*(2+j)]
+		if o.jac == nil {
+			o.jacVec(fs, h, x, y, f, s)
+		} else {
+			la.SpTriMatVecMul(fs, o.dfdy, s)
+		}
+		la.VecAdd(fs, 1, o.dfdp.Col(j), 1, fs) // fs += df/dpⱼ
+	}
+}
+
+// augJac computes the (block-diagonal) Jacobian of the augmented system
+func (o *SensForward) augJac(dfdY *la.Triplet, h, x float64, Y la.Vector) {
+	n := o.ndim
+	o.jac(o.dfdy, h, x, Y[:n])
+	if dfdY.Max() == 0 {
+		dfdY.Init(n*(1+o.np), n*(1+o.np), o.dfdy.Max()*(1+o.np))
+	}
+	dfdY.Start()
+	for k := 0; k <= o.np; k++ {
+		dfdY.PutMatAt(k*n, k*n, o.dfdy)
+	}
+}
+
+// jacVec computes the product ∂f/∂y ⋅ s using the directional difference
+//
+//   ∂f/∂y ⋅ s ≈ [f(x, y + δ⋅s) - f(x, y)] / δ
+//
+func (o *SensForward) jacVec(Js la.Vector, h, x float64, y, f, s la.Vector) {
+	snrm := s.Norm()
+	if snrm == 0 {
+		Js.Fill(0)
+		return
+	}
+	δ := math.Sqrt(num.MACHEPS) * math.Max(1, y.Norm()) / snrm
+	la.VecAdd(o.yδ, 1, y, δ, s)
+	o.fcn(o.fδ, h, x, o.yδ)
+	la.VecAdd(Js, 1/δ, o.fδ, -1/δ, f)
+}
+
+// Functional defines the derivatives of a functional of the solution
+//
+//   G(p) = g(y(xf)) + ∫ q(x,y) dx   (from x0 to xf)
+//
+//  NOTE: g must not depend explicitly on p
+type Functional struct {
+	DgDy func(dgdy, y la.Vector)                      // ∂g/∂y @ xf [may be nil]
+	DqDy func(dqdy la.Vector, x float64, y la.Vector) // ∂q/∂y [may be nil]
+	DqDp func(dqdp la.Vector, x float64, y la.Vector) // ∂q/∂p [may be nil]
+}
+
+// SensAdjoint implements the (checkpointed) adjoint sensitivity analysis to compute the gradient
+// of a functional G(p) = g(y(xf)) + ∫ q(x,y) dx by integrating backwards the adjoint system
+//
+//   dλ/dx = -(∂f/∂y)ᵀ ⋅ λ - (∂q/∂y)ᵀ   with   λ(xf) = (∂g/∂y)ᵀ
+//   dμ/dx = -(∂f/∂p)ᵀ ⋅ λ - (∂q/∂p)ᵀ   with   μ(xf) = 0
+//
+//   such that   dG/dp = μ(x0)   and   dG/dy0 = λ(x0)
+//
+//  The forward solution is saved at checkpoints only (every Nchk accepted steps). During the
+//  backward integration, the forward solution is recomputed between two checkpoints, stored in the
+//  Output of the forward solver, and interpolated by cubic Hermite polynomials.
+type SensAdjoint struct {
+	Nchk int // number of accepted steps between checkpoints [default = 20]
+
+	// problem definition
+	ndim int         // size of y
+	np   int         // number of parameters
+	fcn  Func        // dy/dx := f(x,y)
+	jac  JacF        // Jacobian: df/dy [may be nil]
+	jacP JacP        // Jacobian: df/dp
+	fnl  *Functional // functional
+	xf   float64     // final x
+
+	// solvers
+	solC *Solver // forward solver to compute the checkpoints
+	solF *Solver // forward solver to recompute the solution between checkpoints (with step output)
+	solB *Solver // backward solver of the adjoint system
+
+	// checkpoints
+	chkX []float64   // x at checkpoints
+	chkY []la.Vector // y at checkpoints
+
+	// forward solution between checkpoints
+	nstp int         // number of stored steps
+	fwdF []la.Vector // f(x,y) at stored steps
+	seg  int         // current segment of interpolation
+	herm *hermite    // interpolator
+
+	// workspace
+	dfdy *la.Triplet // df/dy
+	J    *la.Matrix  // df/dy (dense)
+	dfdp *la.Matrix  // df/dp
+	yx   la.Vector   // y(x) interpolated
+	f0   la.Vector   // f(x,y) for the numerical Jacobian
+	w    la.Vector   // workspace for the numerical Jacobian
+	dqdy la.Vector   // ∂q/∂y
+	dqdp la.Vector   // ∂q/∂p
+}
+
+// NewSensAdjoint returns a new object to perform adjoint sensitivity analyses
+//   ndim -- size of y
+//   np   -- number of parameters
+//   conf -- configuration parameters of the forward and backward solvers (variable steps only)
+//   fcn  -- f(x,y) = dy/dx function
+//   jac  -- Jacobian: df/dy function [may be nil ⇒ use numerical Jacobian]
+//   jacP -- Jacobian: df/dp function
+//   NOTE: (1) the output options and events of conf are ignored
+//         (2) remember to call Free() to release allocated resources
+func NewSensAdjoint(ndim, np int, conf *Config, fcn Func, jac JacF, jacP JacP) (o *SensAdjoint) {
+
+	// check
+	if jacP == nil {
+		chk.Panic("the Jacobian with respect to the parameters (jacP) must be given\n")
+	}
+	if conf.fixed {
+		chk.Panic("adjoint sensitivity analysis requires variable steps\n")
+	}
+
+	// data
+	o = new(SensAdjoint)
+	o.Nchk = 20
+	o.ndim, o.np = ndim, np
+	o.fcn, o.jac, o.jacP = fcn, jac, jacP
+
+	// configurations (copies without output)
+	newConf := func() (c *Config) {
+		c = new(Config)
+		*c = *conf
+		c.stepF, c.denseF, c.stepOut, c.denseOut, c.events = nil, nil, false, false, nil
+		return
+	}
+	confC, confF, confB := newConf(), newConf(), newConf()
+	confC.stepF = o.checkpoint
+	confF.stepOut = true
+
+	// solvers
+	o.solC = NewSolver(ndim, confC, fcn, jac, nil)
+	o.solF = NewSolver(ndim, confF, fcn, jac, nil)
+	o.solB = NewSolver(ndim+np, confB, o.adjFcn, o.adjJac, nil)
+
+	// workspace
+	o.herm = new(hermite)
+	o.dfdy = new(la.Triplet)
+	o.dfdp = la.NewMatrix(ndim, np)
+	o.yx = la.NewVector(ndim)
+	o.f0 = la.NewVector(ndim)
+	o.w = la.NewVector(ndim)
+	o.dqdy = la.NewVector(ndim)
+	o.dqdp = la.NewVector(np)
+	return
+}
+
+// Free releases allocated memory
+func (o *SensAdjoint) Free() {
+	o.solC.Free()
+	o.solF.Free()
+	o.solB.Free()
+}
+
+// Gradient computes the gradient of the functional G with respect to p and y0
+//   dGdp  -- dG/dp (output) [np]
+//   dGdy0 -- dG/dy0 (output) [ndim] [may be nil]
+//   y     -- initial y (input) and y @ xf (output)
+//   G     -- the functional
+func (o *SensAdjoint) Gradient(dGdp, dGdy0, y la.Vector, x0, xf float64, G *Functional) {
+
+	// forward solution with checkpoints
+	o.solC.Solve(y, x0, xf)
+	last := len(o.chkX) - 1
+	if xf-o.chkX[last] > 1e-14*math.Max(1, math.Abs(xf)) {
+		o.chkX = append(o.chkX, xf)
+		o.chkY = append(o.chkY, y.GetCopy())
+	} else {
+		o.chkX[last] = xf
+	}
+
+	// terminal values of adjoint variables
+	o.fnl, o.xf = G, xf
+	z := la.NewVector(o.ndim + o.np)
+	if G.DgDy != nil {
+		G.DgDy(z[:o.ndim], y)
+	}
+
+	// backward solution
+	yk := la.NewVector(o.ndim)
+	for k := len(o.chkX) - 2; k >= 0; k-- {
+
+		// recompute forward solution between checkpoints
+		yk.Apply(1, o.chkY[k])
+		o.solF.Out.StepIdx = 0
+		o.solF.Solve(yk, o.chkX[k], o.chkX[k+1])
+		o.nstp = o.solF.Out.StepIdx
+		for len(o.fwdF) < o.nstp {
+			o.fwdF = append(o.fwdF, la.NewVector(o.ndim))
+		}
+		for i := 0; i < o.nstp; i++ {
+			o.fcn(o.fwdF[i], 0, o.solF.Out.StepX[i], o.solF.Out.StepY[i])
+		}
+		o.seg = -1
+
+		// adjoint system with τ = xf - x
+		o.solB.Solve(z, xf-o.chkX[k+1], xf-o.chkX[k])
+	}
+
+	// results
+	copy(dGdp, z[o.ndim:])
+	if dGdy0 != nil {
+		copy(dGdy0, z[:o.ndim])
+	}
+}
+
+// checkpoint saves the forward solution at every Nchk accepted steps
+func (o *SensAdjoint) checkpoint(istep int, h, x float64, y la.Vector) (stop bool) {
+	if istep == 0 {
+		o.chkX, o.chkY = o.chkX[:0], o.chkY[:0]
+	}
+	if istep%o.Nchk == 0 {
+		o.chkX = append(o.chkX, x)
+		o.chkY = append(o.chkY, y.GetCopy())
+	}
+	return
+}
+
+// adjFcn computes the right-hand side of the adjoint system with respect to τ = xf - x
+//
+//   dλ/dτ = (∂f/∂y)ᵀ ⋅ λ + (∂q/∂y)ᵀ
+//   dμ/dτ = (∂f/∂p)ᵀ ⋅ λ + (∂q/∂p)ᵀ
+//
+func (o *SensAdjoint) adjFcn(dzdτ la.Vector, h, τ float64, z la.Vector) {
+	x := o.xf - τ
+	o.jacobians(h, x)
+	λ, dλ, dμ := z[:o.ndim], dzdτ[:o.ndim], dzdτ[o.ndim:]
+	la.MatTrVecMul(dλ, 1, o.J, λ)
+	la.MatTrVecMul(dμ, 1, o.dfdp, λ)
+	if o.fnl.DqDy != nil {
+		o.fnl.DqDy(o.dqdy, x, o.yx)
+		la.VecAdd(dλ, 1, o.dqdy, 1, dλ)
+	}
+	if o.fnl.DqDp != nil {
+		o.fnl.DqDp(o.dqdp, x, o.yx)
+		la.VecAdd(dμ, 1, o.dqdp, 1, dμ)
+	}
+}
+
+// adjJac computes the Jacobian of the adjoint system
+//
+//   d(dz/dτ)/dz = [(∂f/∂y)ᵀ  0]
+//                 [(∂f/∂p)ᵀ  0]
+//
+func (o *SensAdjoint) adjJac(dfdz *la.Triplet, h, τ float64, z la.Vector) {
+	n := o.ndim
+	o.jacobians(h, o.xf-τ)
+	if dfdz.Max() == 0 {
+		dfdz.Init(n+o.np, n+o.np, n*(n+o.np))
+	}
+	dfdz.Start()
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if Jji := o.J.Get(j, i); Jji != 0 {
+				dfdz.Put(i, j, Jji)
+			}
+		}
+	}
+	for k := 0; k < o.np; k++ {
+		for j := 0; j < n; j++ {
+			if val := o.dfdp.Get(j, k); val != 0 {
+				dfdz.Put(n+k, j, val)
+			}
+		}
+	}
+}
+
+// jacobians interpolates y(x) and computes df/dy and df/dp @ (x,y(x))
+func (o *SensAdjoint) jacobians(h, x float64) {
+
+	// interpolate forward solution
+	X, Y := o.solF.Out.StepX[:o.nstp], o.solF.Out.StepY
+	i := sort.SearchFloat64s(X, x) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i > o.nstp-2 {
+		i = o.nstp - 2
+	}
+	if i != o.seg {
+		o.herm.y0, o.herm.f0 = Y[i], o.fwdF[i]
+		o.herm.y1, o.herm.f1 = Y[i+1], o.fwdF[i+1]
+		o.seg = i
+	}
+	o.herm.eval(o.yx, X[i+1]-X[i], X[i], x)
+
+	// df/dy
+	if o.jac == nil {
+		o.fcn(o.f0, h, x, o.yx)
+		num.Jacobian(o.dfdy, func(fy, yy la.Vector) {
+			o.fcn(fy, h, x, yy)
+		}, o.yx, o.f0, o.w)
+	} else {
+		o.jac(o.dfdy, h, x, o.yx)
+	}
+	o.J = o.dfdy.ToDense()
+
+	// df/dp
+	o.jacP(o.dfdp, x, o.yx)
+}
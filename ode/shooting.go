@@ -0,0 +1,270 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+)
+
+// ShootingSolver implements the multiple shooting method for two-point boundary value problems
+//
+//   dy/dx = f(x, y)   for a ≤ x ≤ b   with   g(y(a), y(b)) = 0
+//
+//  The interval [a, b] is divided into m segments by the shooting nodes a = x0 < x1 < ... < xm = b.
+//  The unknowns are the initial values sₖ = y(xₖ) of all segments, which are found by solving
+//
+//   φₖ(xₖ₊₁; sₖ) - sₖ₊₁ = 0   for k = 0 ... m-2   (continuity)
+//   g(s0, φₘ₋₁(b; sₘ₋₁)) = 0                          (boundary conditions)
+//
+//  with num.NlSolver, where φₖ(x; sₖ) is the solution of the initial value problem on segment k
+//  computed by ode.Solver. The derivatives dφₖ/dsₖ are computed by finite differences; thus, the
+//  segments are integrated ndim+1 times for each Jacobian evaluation. Single shooting corresponds
+//  to m = 1; more segments reduce the growth of errors of unstable initial value problems.
+//
+type ShootingSolver struct {
+
+	// parameters
+	Atol      float64 // absolute tolerance of Newton's method [default = 1e-8]
+	Rtol      float64 // relative tolerance of Newton's method [default = 1e-8]
+	Ftol      float64 // tolerance of residuals [default = 1e-9]
+	NmaxIt    int     // maximum number of Newton iterations [default = 50]
+	LinSearch bool    // use line search [default = true]
+
+	// output
+	X    []float64   // shooting nodes
+	Y    []la.Vector // y @ shooting nodes (after Solve)
+	Nit  int         // number of Newton iterations (after Solve)
+	Stat *Stat       // statistics of ODE solutions (accumulated over all segments)
+
+	// problem definition
+	ndim int     // size of y
+	conf *Config // configuration of ODE solver
+	sol  *Solver // ODE solver
+	bc   BvpBcF  // boundary conditions g(ya, yb)
+
+	// workspace
+	phi   []la.Vector  // φₖ(xₖ₊₁; sₖ)
+	dphi  []*la.Matrix // dφₖ/dsₖ
+	sLast la.Vector    // unknowns of the last computation of phi
+	ga    *la.Matrix   // dg/dya
+	gb    *la.Matrix   // dg/dyb
+	g, w  la.Vector    // boundary conditions and workspace
+	yt    la.Vector    // workspace
+}
+
+// NewShootingSolver returns a new multiple shooting solver for BVPs
+//   ndim  -- problem dimension
+//   conf  -- configuration of the ODE solver (e.g. method and tolerances)
+//   fcn   -- f(x,y) = dy/dx function
+//   jac   -- Jacobian: df/dy function [may be nil; see NewSolver]
+//   bc    -- boundary conditions g(ya, yb) = 0 (ndim equations)
+//   nodes -- shooting nodes with nodes[0] = a and nodes[m] = b (m segments)
+//   NOTE: remember to call Free() to release allocated resources
+func NewShootingSolver(ndim int, conf *Config, fcn Func, jac JacF, bc BvpBcF, nodes []float64) (o *ShootingSolver) {
+
+	// check
+	if conf.fixed {
+		chk.Panic("fixed steps are not available for multiple shooting\n")
+	}
+	if len(nodes) < 2 {
+		chk.Panic("at least two shooting nodes are required\n")
+	}
+	for i := 1; i < len(nodes); i++ {
+		if nodes[i] <= nodes[i-1] {
+			chk.Panic("shooting nodes must be strictly increasing. x%d = %g ≤ x%d = %g\n", i, nodes[i], i-1, nodes[i-1])
+		}
+	}
+
+	// data
+	o = new(ShootingSolver)
+	o.Atol = 1e-8
+	o.Rtol = 1e-8
+	o.Ftol = 1e-9
+	o.NmaxIt = 50
+	o.LinSearch = true
+	o.X = make([]float64, len(nodes))
+	copy(o.X, nodes)
+	o.Y = make([]la.Vector, len(nodes))
+	for k := range o.Y {
+		o.Y[k] = la.NewVector(ndim)
+	}
+	o.Stat = NewStat(conf.lsKind, false)
+	o.ndim = ndim
+	o.conf = conf
+	o.sol = NewSolver(ndim, conf, fcn, jac, nil)
+	o.bc = bc
+
+	// workspace
+	m := len(nodes) - 1
+	o.phi = make([]la.Vector, m)
+	o.dphi = make([]*la.Matrix, m)
+	for k := 0; k < m; k++ {
+		o.phi[k] = la.NewVector(ndim)
+		o.dphi[k] = la.NewMatrix(ndim, ndim)
+	}
+	o.ga, o.gb = la.NewMatrix(ndim, ndim), la.NewMatrix(ndim, ndim)
+	o.g, o.w, o.yt = la.NewVector(ndim), la.NewVector(ndim), la.NewVector(ndim)
+	return
+}
+
+// Free releases allocated memory
+func (o *ShootingSolver) Free() {
+	o.sol.Free()
+}
+
+// Solve solves the BVP
+//   guess -- initial guess y(x) used at the shooting nodes
+//   NOTE: the solution is given by Y at the shooting nodes or can be evaluated at any x by Eval
+func (o *ShootingSolver) Solve(guess BvpGuessF) {
+
+	// initial values of segments
+	n, m := o.ndim, len(o.X)-1
+	s := la.NewVector(n * m)
+	for k := 0; k < m; k++ {
+		guess(o.yt, o.X[k])
+		copy(s[k*n:], o.yt)
+	}
+	o.sLast = nil
+	o.Stat.Reset()
+
+	// nonlinear solver
+	linSearch := -1.0
+	if o.LinSearch {
+		linSearch = 1.0
+	}
+	var nls num.NlSolver
+	nls.Init(n*m, o.residual, nil, o.jacobian, true, false, map[string]float64{
+		"atol":      o.Atol,
+		"rtol":      o.Rtol,
+		"ftol":      o.Ftol,
+		"maxIt":     float64(o.NmaxIt),
+		"linSearch": linSearch,
+	})
+	defer nls.Free()
+	nls.Solve(s, true)
+	o.Nit = nls.It
+
+	// solution @ nodes
+	o.shoot(s)
+	for k := 0; k < m; k++ {
+		copy(o.Y[k], s[k*n:(k+1)*n])
+	}
+	o.Y[m].Apply(1, o.phi[m-1])
+}
+
+// Eval evaluates the solution y(x) with a ≤ x ≤ b by integrating from the preceding shooting node
+func (o *ShootingSolver) Eval(y la.Vector, x float64) {
+	k := sort.SearchFloat64s(o.X, x) - 1
+	if k < 0 {
+		k = 0
+	}
+	if k > len(o.X)-2 {
+		k = len(o.X) - 2
+	}
+	y.Apply(1, o.Y[k])
+	if x > o.X[k] {
+		o.sol.Solve(y, o.X[k], x)
+	}
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// shoot computes φₖ(xₖ₊₁; sₖ) for all segments
+func (o *ShootingSolver) shoot(s la.Vector) {
+	if o.sLast != nil && o.sLast.NormDiff(s) == 0 {
+		return
+	}
+	n := o.ndim
+	for k := range o.phi {
+		copy(o.phi[k], s[k*n:(k+1)*n])
+		o.sol.Solve(o.phi[k], o.X[k], o.X[k+1])
+		o.Stat.add(o.sol.Stat)
+	}
+	o.sLast = s.GetCopy()
+}
+
+// residual computes the continuity conditions and the boundary conditions
+func (o *ShootingSolver) residual(r, s la.Vector) {
+	n, m := o.ndim, len(o.X)-1
+	o.shoot(s)
+	for k := 0; k < m-1; k++ {
+		for i := 0; i < n; i++ {
+			r[k*n+i] = o.phi[k][i] - s[(k+1)*n+i]
+		}
+	}
+	o.bc(o.g, s[:n], o.phi[m-1])
+	copy(r[(m-1)*n:], o.g)
+}
+
+// jacobian computes the (dense) Jacobian matrix of the residual
+func (o *ShootingSolver) jacobian(J *la.Matrix, s la.Vector) {
+
+	// derivatives of segments: dφₖ/dsₖ by finite differences
+	n, m := o.ndim, len(o.X)-1
+	o.shoot(s)
+	δtol := math.Sqrt(math.Max(o.conf.rtol, num.MACHEPS))
+	for k := 0; k < m; k++ {
+		for j := 0; j < n; j++ {
+			copy(o.yt, s[k*n:(k+1)*n])
+			δ := δtol * math.Max(1, math.Abs(o.yt[j]))
+			o.yt[j] += δ
+			o.sol.Solve(o.yt, o.X[k], o.X[k+1])
+			o.Stat.add(o.sol.Stat)
+			for i := 0; i < n; i++ {
+				o.dphi[k].Set(i, j, (o.yt[i]-o.phi[k][i])/δ)
+			}
+		}
+	}
+
+	// derivatives of boundary conditions by finite differences
+	ya, yb := s[:n].GetCopy(), o.phi[m-1].GetCopy()
+	o.bc(o.g, ya, yb)
+	for _, c := range []struct {
+		y  la.Vector
+		dg *la.Matrix
+	}{{ya, o.ga}, {yb, o.gb}} {
+		for j := 0; j < n; j++ {
+			ysafe := c.y[j]
+			δ := math.Sqrt(num.MACHEPS * math.Max(1e-5, math.Abs(ysafe)))
+			c.y[j] = ysafe + δ
+			o.bc(o.w, ya, yb)
+			c.y[j] = ysafe
+			for i := 0; i < n; i++ {
+				c.dg.Set(i, j, (o.w[i]-o.g[i])/δ)
+			}
+		}
+	}
+
+	// continuity conditions
+	for i := 0; i < n*m; i++ {
+		for j := 0; j < n*m; j++ {
+			J.Set(i, j, 0)
+		}
+	}
+	for k := 0; k < m-1; k++ {
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				J.Set(k*n+i, k*n+j, o.dphi[k].Get(i, j))
+			}
+			J.Set(k*n+i, (k+1)*n+i, -1)
+		}
+	}
+
+	// boundary conditions: dg/ds0 = dg/dya and dg/dsₘ₋₁ = dg/dyb ⋅ dφₘ₋₁/dsₘ₋₁
+	r := (m - 1) * n
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			J.Add(r+i, j, o.ga.Get(i, j))
+			for l := 0; l < n; l++ {
+				J.Add(r+i, (m-1)*n+j, o.gb.Get(i, l)*o.dphi[m-1].Get(l, j))
+			}
+		}
+	}
+}
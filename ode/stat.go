@@ -41,6 +41,21 @@ func (o *Stat) Reset() {
 	o.Nitmax = 0
 }
 
+// add accumulates the counters of another structure (e.g. from a sequence of solutions)
+func (o *Stat) add(another *Stat) {
+	o.Nfeval += another.Nfeval
+	o.Njeval += another.Njeval
+	o.Nsteps += another.Nsteps
+	o.Naccepted += another.Naccepted
+	o.Nrejected += another.Nrejected
+	o.Ndecomp += another.Ndecomp
+	o.Nlinsol += another.Nlinsol
+	if another.Nitmax > o.Nitmax {
+		o.Nitmax = another.Nitmax
+	}
+	o.Hopt = another.Hopt
+}
+
 // Print prints information about the solution process
 func (o *Stat) Print(extra bool) {
 	io.Pf("number of F evaluations   =%6d\n", o.Nfeval)
@@ -0,0 +1,403 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Symplectic implements symplectic (and symmetric) methods for Hamiltonian systems
+//
+//   dq/dx = ∂H/∂p   and   dp/dx = -∂H/∂q   with   y = {q, p}
+//
+//   The methods available are:
+//     verlet   -- Störmer-Verlet (leapfrog: kick-drift-kick), order 2
+//     yoshida4 -- Yoshida's composition of three Störmer-Verlet steps, order 4 [2]
+//     yoshida6 -- Yoshida's composition of seven Störmer-Verlet steps (solution A), order 6 [2]
+//     gauss4   -- Gauss-Legendre implicit Runge-Kutta with 2 stages, order 4 [3]
+//     gauss6   -- Gauss-Legendre implicit Runge-Kutta with 3 stages, order 6 [3]
+//
+//  The partitioned methods (verlet and yoshida) require separable Hamiltonians H = T(p) + V(q);
+//  i.e. the first half of f (dq/dx) must depend on p only and the second half of f (dp/dx) must
+//  depend on q only. Thus, each sub-step requires two evaluations of f, from which only one half
+//  is used. The Gauss-Legendre methods can be used with any Hamiltonian (indeed, with any ODE);
+//  the stages are computed by fixed-point iterations up to round-off errors.
+//
+//  With fixed steps (Config.SetFixedH), the methods are symplectic and symmetric. With variable
+//  steps, the explicit time-reversible stepsize control of Hairer and Söderlind [1] is used:
+//
+//    z_{n+1/2} = z_{n-1/2} + ε⋅G(y_n)   and   h_{n+1/2} = ε / z_{n+1/2}
+//
+//  where z ≈ 1/σ(y) and σ(y) = 1/‖f(y)‖ is the stepsize function (arc-length in phase space);
+//  thus, G(y) = -∇σ⋅f/σ = fᵀ⋅(∂f/∂y⋅f) / (fᵀ⋅f), with ∂f/∂y⋅f computed by finite differences.
+//  The fictitious stepsize ε is given by Config.SymEps. Since there is no error estimate, all
+//  steps are accepted. The energy error remains bounded in both cases (no drift).
+//
+//  References:
+//    [1] Hairer E, Söderlind G (2005) Explicit, time reversible, adaptive step size control.
+//        SIAM Journal on Scientific Computing, 26(6):1838-1851
+//    [2] Yoshida H (1990) Construction of higher order symplectic integrators. Physics Letters A,
+//        150(5-7):262-268
+//    [3] Hairer E, Lubich C, Wanner G (2006) Geometric Numerical Integration: Structure-Preserving
+//        Algorithms for Ordinary Differential Equations. Second Edition. Springer, 644p
+//
+type Symplectic struct {
+
+	// constants
+	Partitioned bool        // partitioned (Störmer-Verlet based) method
+	W           []float64   // weights of the Störmer-Verlet sub-steps (partitioned methods)
+	A           [][]float64 // A coefficients (Gauss-Legendre methods)
+	B           []float64   // B coefficients (Gauss-Legendre methods)
+	C           []float64   // C coefficients (Gauss-Legendre methods)
+	Nstg        int         // number of stages (Gauss-Legendre methods)
+	P           int         // order
+	NmaxIt      int         // max number of fixed-point iterations (Gauss-Legendre methods)
+
+	// data
+	ndim int     // problem dimension
+	conf *Config // configuration
+	work *rkwork // workspace
+	stat *Stat   // statistics
+	fcn  Func    // dy/dx = f(x,y) function
+
+	// auxiliary
+	nq int         // number of q variables = ndim/2
+	w  la.Vector   // updated y
+	k  la.Vector   // workspace: f(x,y) during sub-steps
+	kp la.Vector   // dp/dx at the current q (partitioned methods) [nq]
+	z  []la.Vector // stage values Zi = Yi - y0 (Gauss-Legendre methods)
+	x0 float64     // x at the beginning of the step
+
+	// time-reversible stepsize control
+	eps float64   // fictitious stepsize ε
+	zh  float64   // z_{n+1/2} ≈ 1/σ
+	fy  la.Vector // f(x,y) at the end of the last step
+	u   la.Vector // perturbed y: u = y + δ⋅f
+	fu  la.Vector // f(x,u)
+
+	// dense output
+	herm *hermite // Hermite interpolation
+}
+
+// add methods to database
+func init() {
+	rkmDB["verlet"] = func() rkmethod { return newSymplectic("verlet") }
+	rkmDB["yoshida4"] = func() rkmethod { return newSymplectic("yoshida4") }
+	rkmDB["yoshida6"] = func() rkmethod { return newSymplectic("yoshida6") }
+	rkmDB["gauss4"] = func() rkmethod { return newSymplectic("gauss4") }
+	rkmDB["gauss6"] = func() rkmethod { return newSymplectic("gauss6") }
+}
+
+// Free releases memory
+func (o *Symplectic) Free() {}
+
+// Info returns information about this method
+func (o *Symplectic) Info() (fixedOnly, implicit bool, nstages int) {
+	return false, false, o.Nstg
+}
+
+// Init initialises structure
+func (o *Symplectic) Init(ndim int, conf *Config, work *rkwork, stat *Stat, fcn Func, jac JacF, M *la.Triplet) {
+
+	// check
+	if M != nil {
+		chk.Panic("symplectic methods cannot handle M matrix\n")
+	}
+	if o.Partitioned && ndim%2 != 0 {
+		chk.Panic("ndim must be even with partitioned method %q because y = {q, p}. ndim = %d is invalid\n", conf.method, ndim)
+	}
+
+	// data
+	o.ndim = ndim
+	o.conf = conf
+	o.work = work
+	o.stat = stat
+	o.fcn = fcn
+
+	// auxiliary
+	o.nq = ndim / 2
+	o.w = la.NewVector(ndim)
+	o.k = la.NewVector(ndim)
+	o.kp = la.NewVector(o.nq)
+	if !o.Partitioned {
+		o.z = make([]la.Vector, o.Nstg)
+		for i := 0; i < o.Nstg; i++ {
+			o.z[i] = la.NewVector(ndim)
+		}
+	}
+
+	// time-reversible stepsize control
+	o.eps = conf.SymEps
+	if o.eps <= 0 {
+		o.eps = math.Pow(conf.rtol, 1.0/float64(o.P))
+	}
+	o.fy = la.NewVector(ndim)
+	o.u = la.NewVector(ndim)
+	o.fu = la.NewVector(ndim)
+
+	// dense output
+	if conf.needDense() {
+		o.herm = newHermite(ndim)
+	}
+}
+
+// IniH computes the initial stepsize h_{1/2} = ε / z_{1/2} with z_{1/2} = z0 + ε⋅G(y0)/2
+func (o *Symplectic) IniH(x float64, y la.Vector) (h float64) {
+	o.stat.Nfeval++
+	o.fcn(o.fy, 0, x, y)
+	z, g := o.density(x, y)
+	o.zh = z + o.eps*g/2.0
+	if o.zh <= 0 {
+		chk.Panic("fictitious stepsize ε = %g is too large for symplectic method %q\n", o.eps, o.conf.method)
+	}
+	return o.eps / o.zh
+}
+
+// Accept accepts update and computes next stepsize
+func (o *Symplectic) Accept(y0 la.Vector, x0 float64) (dxnew float64) {
+
+	// store data for dense output
+	h := o.work.h
+	if o.herm != nil {
+		o.herm.y0.Apply(1, y0)
+		o.herm.f0.Apply(1, o.fy)
+	}
+
+	// update y
+	y0.Apply(1, o.w)
+	if o.herm == nil && o.conf.fixed {
+		return
+	}
+
+	// f(x,y) at the end of the step
+	o.stat.Nfeval++
+	o.fcn(o.fy, h, o.x0+h, y0)
+	if o.herm != nil {
+		o.herm.y1.Apply(1, y0)
+		o.herm.f1.Apply(1, o.fy)
+	}
+	if o.conf.fixed {
+		return
+	}
+
+	// time-reversible stepsize
+	_, g := o.density(o.x0+h, y0)
+	o.zh += o.eps * g
+	if o.zh <= 0 {
+		chk.Panic("fictitious stepsize ε = %g is too large for symplectic method %q\n", o.eps, o.conf.method)
+	}
+	dxnew = o.eps / o.zh
+	return
+}
+
+// Reject processes step rejection and computes next stepsize
+func (o *Symplectic) Reject() (dxnew float64) {
+	return o.work.h / 2.0 // steps are never rejected
+}
+
+// DenseOut produces dense output (after Accept)
+func (o *Symplectic) DenseOut(yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	o.herm.eval(yout, h, x-h, xout)
+}
+
+// Step steps update
+func (o *Symplectic) Step(x0 float64, y0 la.Vector) {
+
+	// f(x,y) at the beginning of the step (for dense output)
+	o.x0 = x0
+	if o.herm != nil && o.work.first {
+		o.stat.Nfeval++
+		o.fcn(o.fy, o.work.h, x0, y0)
+	}
+
+	// compute y1
+	if o.Partitioned {
+		o.stepPartitioned(x0, y0)
+	} else {
+		o.stepGauss(x0, y0)
+	}
+	o.work.rerr = 0 // steps are always accepted
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// stepPartitioned performs the composition of Störmer-Verlet (kick-drift-kick) sub-steps
+func (o *Symplectic) stepPartitioned(x0 float64, y0 la.Vector) {
+
+	// auxiliary
+	h := o.work.h
+	q, p := o.w[:o.nq], o.w[o.nq:]
+	o.w.Apply(1, y0)
+
+	// dp/dx at q0 (otherwise, use kp from the end of the previous step)
+	if o.work.first {
+		o.stat.Nfeval++
+		o.fcn(o.k, h, x0, o.w)
+		copy(o.kp, o.k[o.nq:])
+	}
+
+	// sub-steps
+	x := x0
+	for _, wi := range o.W {
+		hi := wi * h
+		for i := 0; i < o.nq; i++ { // kick
+			p[i] += hi * o.kp[i] / 2.0
+		}
+		o.stat.Nfeval++
+		o.fcn(o.k, h, x, o.w)
+		for i := 0; i < o.nq; i++ { // drift
+			q[i] += hi * o.k[i]
+		}
+		x += hi
+		o.stat.Nfeval++
+		o.fcn(o.k, h, x, o.w)
+		copy(o.kp, o.k[o.nq:])
+		for i := 0; i < o.nq; i++ { // kick
+			p[i] += hi * o.kp[i] / 2.0
+		}
+	}
+}
+
+// stepGauss computes the stages of Gauss-Legendre methods by fixed-point iterations
+//
+//   Zi = h ⋅ Σ_j aij ⋅ f(x0 + cj⋅h, y0 + Zj)   and   y1 = y0 + h ⋅ Σ_j bj ⋅ f(x0 + cj⋅h, y0 + Zj)
+//
+func (o *Symplectic) stepGauss(x0 float64, y0 la.Vector) {
+
+	// auxiliary
+	h := o.work.h
+	v, k := o.work.v, o.work.f
+
+	// iterations
+	for i := 0; i < o.Nstg; i++ {
+		o.z[i].Fill(0)
+	}
+	var δ, δprev float64
+	converged := false
+	for it := 0; it < o.NmaxIt; it++ {
+
+		// stage derivatives
+		for j := 0; j < o.Nstg; j++ {
+			la.VecAdd(v[j], 1, y0, 1, o.z[j]) // vj := y0 + Zj
+			o.stat.Nfeval++
+			o.fcn(k[j], h, x0+o.C[j]*h, v[j])
+		}
+
+		// update stages
+		δ = 0
+		for i := 0; i < o.Nstg; i++ {
+			for m := 0; m < o.ndim; m++ {
+				zim := 0.0
+				for j := 0; j < o.Nstg; j++ {
+					zim += h * o.A[i][j] * k[j][m]
+				}
+				δ = utl.Max(δ, math.Abs(zim-o.z[i][m])/(1.0+math.Abs(y0[m])))
+				o.z[i][m] = zim
+			}
+		}
+		o.stat.Nitmax = utl.Imax(o.stat.Nitmax, it+1)
+
+		// converged? (round-off level or stagnation due to round-off errors)
+		if δ <= 10.0*o.conf.Eps || (it > 0 && δ >= δprev && δ < 1e-10) {
+			converged = true
+			break
+		}
+		δprev = δ
+	}
+	if !converged {
+		chk.Panic("fixed-point iterations of %q did not converge after %d iterations (δ = %g). h = %g may be too large\n", o.conf.method, o.NmaxIt, δ, h)
+	}
+
+	// update
+	for m := 0; m < o.ndim; m++ {
+		o.w[m] = y0[m]
+		for j := 0; j < o.Nstg; j++ {
+			o.w[m] += h * o.B[j] * k[j][m]
+		}
+	}
+}
+
+// density computes z = 1/σ(y) = ‖f‖ and G(y) = fᵀ⋅(∂f/∂y⋅f) / (fᵀ⋅f) with fy = f(x,y) given
+func (o *Symplectic) density(x float64, y la.Vector) (z, g float64) {
+	ff := la.VecDot(o.fy, o.fy)
+	if ff == 0 {
+		return // stationary point
+	}
+	z = math.Sqrt(ff)
+	δ := math.Sqrt(o.conf.Eps) * (1.0 + y.Norm()) / z
+	la.VecAdd(o.u, 1, y, δ, o.fy) // u := y + δ⋅f
+	o.stat.Nfeval++
+	o.fcn(o.fu, o.work.h, x, o.u)
+	for i := 0; i < o.ndim; i++ {
+		g += o.fy[i] * (o.fu[i] - o.fy[i])
+	}
+	g /= δ * ff
+	return
+}
+
+// newSymplectic returns the coefficients of the symplectic method
+func newSymplectic(kind string) rkmethod {
+
+	// new dataset
+	o := new(Symplectic)
+	o.Nstg = 1
+	o.NmaxIt = 100
+
+	// set coefficients
+	switch kind {
+	case "verlet":
+		o.Partitioned = true
+		o.W = []float64{1}
+		o.P = 2
+
+	case "yoshida4":
+		c := math.Cbrt(2.0)
+		w1 := 1.0 / (2.0 - c)
+		w0 := -c / (2.0 - c)
+		o.Partitioned = true
+		o.W = []float64{w1, w0, w1}
+		o.P = 4
+
+	case "yoshida6":
+		w1 := -1.17767998417887
+		w2 := 0.235573213359357
+		w3 := 0.784513610477560
+		w0 := 1.0 - 2.0*(w1+w2+w3)
+		o.Partitioned = true
+		o.W = []float64{w3, w2, w1, w0, w1, w2, w3}
+		o.P = 6
+
+	case "gauss4":
+		s3 := math.Sqrt(3.0)
+		o.A = [][]float64{
+			{1.0 / 4.0, 1.0/4.0 - s3/6.0},
+			{1.0/4.0 + s3/6.0, 1.0 / 4.0},
+		}
+		o.B = []float64{1.0 / 2.0, 1.0 / 2.0}
+		o.C = []float64{1.0/2.0 - s3/6.0, 1.0/2.0 + s3/6.0}
+		o.Nstg = 2
+		o.P = 4
+
+	case "gauss6":
+		s15 := math.Sqrt(15.0)
+		o.A = [][]float64{
+			{5.0 / 36.0, 2.0/9.0 - s15/15.0, 5.0/36.0 - s15/30.0},
+			{5.0/36.0 + s15/24.0, 2.0 / 9.0, 5.0/36.0 - s15/24.0},
+			{5.0/36.0 + s15/30.0, 2.0/9.0 + s15/15.0, 5.0 / 36.0},
+		}
+		o.B = []float64{5.0 / 18.0, 4.0 / 9.0, 5.0 / 18.0}
+		o.C = []float64{1.0/2.0 - s15/10.0, 1.0 / 2.0, 1.0/2.0 + s15/10.0}
+		o.Nstg = 3
+		o.P = 6
+
+	default:
+		chk.Panic("cannot find symplectic method named %q\n", kind)
+	}
+	return o
+}
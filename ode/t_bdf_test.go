@@ -0,0 +1,209 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestBdf01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bdf01. Eq11 (analytical and numerical Jacobian)")
+
+	// problem
+	p := ProbHwEq11()
+
+	// reference solution
+	yExact := la.NewVector(p.Ndim)
+	p.Yana(yExact, p.Xf)
+
+	// run with both methods
+	for _, method := range []string{"bdf", "ndf"} {
+		for _, numJac := range []bool{false, true} {
+			y, stat, _ := p.Solve(method, false, numJac)
+			io.Pf("%s (numJac=%v): nfeval=%d njeval=%d ndecomp=%d naccepted=%d nrejected=%d\n", method, numJac,
+				stat.Nfeval, stat.Njeval, stat.Ndecomp, stat.Naccepted, stat.Nrejected)
+			chk.Float64(tst, "yFin", 1e-4, y[0], yExact[0])
+		}
+	}
+}
+
+func TestBdf02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bdf02. first order with fixed steps equals Backward Euler")
+
+	// problem
+	p := ProbHwEq11()
+
+	// Backward Euler
+	yBE, _, _ := p.Solve("bweuler", true, false)
+
+	// BDF with max order = 1
+	conf := NewConfig("bdf", "", nil)
+	conf.BdfMaxOrd = 1
+	conf.SetFixedH(p.Dx, p.Xf)
+	sol := NewSolver(p.Ndim, conf, p.Fcn, p.Jac, nil)
+	defer sol.Free()
+	y := la.NewVector(p.Ndim)
+	y.Apply(1, p.Y)
+	sol.Solve(y, 0, p.Xf)
+	chk.Array(tst, "y", 1e-12, y, yBE)
+}
+
+func TestBdf03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bdf03. Van der Pol's Equation (dense output)")
+
+	// problem
+	p := ProbVanDerPol(0, false)
+	p.Y[1] = -0.66
+
+	// reference solution with Radau5
+	yRef := la.NewVector(p.Ndim)
+	yRef.Apply(1, p.Y)
+	confR := NewConfig("radau5", "", nil)
+	confR.IniH = 1e-6
+	confR.NmaxSS = 10000
+	confR.SetTols(1e-9, 1e-9)
+	confR.SetDenseOut(true, 0.2, p.Xf, nil)
+	solR := NewSolver(p.Ndim, confR, p.Fcn, p.Jac, nil)
+	defer solR.Free()
+	solR.Solve(yRef, 0, p.Xf)
+
+	// run with both methods
+	for _, method := range []string{"bdf", "ndf"} {
+
+		// configuration
+		conf := NewConfig(method, "", nil)
+		conf.IniH = 1e-6
+		conf.NmaxSS = 10000
+		conf.SetTols(1e-7, 1e-7)
+		conf.SetDenseOut(true, 0.2, p.Xf, nil)
+
+		// solver
+		y := la.NewVector(p.Ndim)
+		y.Apply(1, p.Y)
+		sol := NewSolver(p.Ndim, conf, p.Fcn, p.Jac, nil)
+		defer sol.Free()
+		sol.Solve(y, 0, p.Xf)
+		io.Pf("%s: nfeval=%d njeval=%d ndecomp=%d naccepted=%d nrejected=%d\n", method, sol.Stat.Nfeval,
+			sol.Stat.Njeval, sol.Stat.Ndecomp, sol.Stat.Naccepted, sol.Stat.Nrejected)
+
+		// check final values
+		chk.Array(tst, "y", 1e-4, y, yRef)
+
+		// check dense output
+		chk.Array(tst, "X", 1e-15, sol.Out.GetDenseX(), solR.Out.GetDenseX())
+		chk.Array(tst, "Y0", 1e-4, sol.Out.GetDenseY(0), solR.Out.GetDenseY(0))
+		chk.Array(tst, "Y1", 1e-2, sol.Out.GetDenseY(1), solR.Out.GetDenseY(1))
+	}
+}
+
+func TestBdf04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bdf04. Robertson's Equation")
+
+	// problem
+	p := ProbRobertson()
+
+	// reference solution with Radau5
+	yRef := la.NewVector(p.Ndim)
+	yRef.Apply(1, p.Y)
+	confR := NewConfig("radau5", "", nil)
+	confR.SetTols(1e-10, 1e-10)
+	solR := NewSolver(p.Ndim, confR, p.Fcn, p.Jac, nil)
+	defer solR.Free()
+	solR.Solve(yRef, 0, p.Xf)
+
+	// run with both methods
+	for _, method := range []string{"bdf", "ndf"} {
+		conf := NewConfig(method, "", nil)
+		conf.IniH = 1e-6
+		conf.SetTols(1e-8, 1e-5)
+		y := la.NewVector(p.Ndim)
+		y.Apply(1, p.Y)
+		sol := NewSolver(p.Ndim, conf, p.Fcn, p.Jac, nil)
+		defer sol.Free()
+		sol.Solve(y, 0, p.Xf)
+		io.Pf("%s: nfeval=%d njeval=%d ndecomp=%d naccepted=%d nrejected=%d\n", method, sol.Stat.Nfeval,
+			sol.Stat.Njeval, sol.Stat.Ndecomp, sol.Stat.Naccepted, sol.Stat.Nrejected)
+		for i := 0; i < p.Ndim; i++ {
+			if math.Abs(y[i]-yRef[i]) > 1e-3*math.Abs(yRef[i])+1e-8 {
+				tst.Errorf("%s: y[%d] = %g is different than reference %g\n", method, i, y[i], yRef[i])
+			}
+		}
+	}
+}
+
+func TestBdf05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bdf05. heat equation (large sparse system)")
+
+	// semi-discretisation of ∂u/∂t = ∂²u/∂x² with u(0)=u(1)=0 and u(x,0) = sin(π⋅x)
+	n := 199
+	Δx := 1.0 / float64(n+1)
+	c := 1.0 / (Δx * Δx)
+	fcn := func(f la.Vector, h, t float64, u la.Vector) {
+		for i := 0; i < n; i++ {
+			f[i] = -2 * c * u[i]
+			if i > 0 {
+				f[i] += c * u[i-1]
+			}
+			if i < n-1 {
+				f[i] += c * u[i+1]
+			}
+		}
+	}
+	jac := func(dfdy *la.Triplet, h, t float64, u la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(n, n, 3*n)
+		}
+		dfdy.Start()
+		for i := 0; i < n; i++ {
+			dfdy.Put(i, i, -2*c)
+			if i > 0 {
+				dfdy.Put(i, i-1, c)
+			}
+			if i < n-1 {
+				dfdy.Put(i, i+1, c)
+			}
+		}
+	}
+
+	// initial values and solution of semi-discrete system
+	tf := 0.1
+	u := la.NewVector(n)
+	uana := la.NewVector(n)
+	λ := -4 * c * math.Pow(math.Sin(math.Pi*Δx/2), 2)
+	for i := 0; i < n; i++ {
+		u[i] = math.Sin(math.Pi * float64(i+1) * Δx)
+		uana[i] = u[i] * math.Exp(λ*tf)
+	}
+
+	// solve
+	conf := NewConfig("ndf", "", nil)
+	conf.SetTols(1e-8, 1e-6)
+	sol := NewSolver(n, conf, fcn, jac, nil)
+	defer sol.Free()
+	sol.Solve(u, 0, tf)
+	io.Pf("nfeval=%d njeval=%d ndecomp=%d nlinsol=%d naccepted=%d nrejected=%d\n", sol.Stat.Nfeval,
+		sol.Stat.Njeval, sol.Stat.Ndecomp, sol.Stat.Nlinsol, sol.Stat.Naccepted, sol.Stat.Nrejected)
+	chk.Array(tst, "u", 1e-5, u, uana)
+
+	// the Jacobian must be reused
+	if sol.Stat.Njeval*5 > sol.Stat.Naccepted {
+		tst.Errorf("the Jacobian should have been reused. njeval=%d, naccepted=%d\n", sol.Stat.Njeval, sol.Stat.Naccepted)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// bratuSolution returns the (lower branch) solution of Bratu's problem y'' + λ⋅exp(y) = 0 with
+// y(0) = y(1) = 0; i.e. y = -2⋅ln(cosh((x-½)⋅θ/2) / cosh(θ/4)) with θ = √(2λ)⋅cosh(θ/4)
+func bratuSolution(x, λ float64) float64 {
+	θ := 1.0
+	for it := 0; it < 50; it++ {
+		θ -= (θ - math.Sqrt(2*λ)*math.Cosh(θ/4)) / (1 - math.Sqrt(2*λ)*math.Sinh(θ/4)/4)
+	}
+	return -2 * math.Log(math.Cosh((x-0.5)*θ/2)/math.Cosh(θ/4))
+}
+
+func TestBvp01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bvp01. linear problem with analytical Jacobians")
+
+	// y'' = y with y(0) = 0 and y(1) = 1  ⇒  y = sinh(x) / sinh(1)
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0], f[1] = y[1], y[0]
+	}
+	jac := func(dfdy *la.Triplet, h, x float64, y la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(2, 2, 2)
+		}
+		dfdy.Start()
+		dfdy.Put(0, 1, 1)
+		dfdy.Put(1, 0, 1)
+	}
+	bc := func(g, ya, yb la.Vector) {
+		g[0], g[1] = ya[0], yb[0]-1
+	}
+	bcJ := func(dgdya, dgdyb *la.Matrix, ya, yb la.Vector) {
+		dgdya.Set(0, 0, 1)
+		dgdyb.Set(1, 0, 1)
+	}
+
+	// run
+	sol := NewBvpSolver(2, fcn, jac, bc, bcJ, "")
+	defer sol.Free()
+	sol.Rtol = 1e-6
+	sol.Solve(utl.LinSpace(0, 1, 5), func(y la.Vector, x float64) { y[0], y[1] = x, 1 })
+	io.Pf("npts = %d  nsub = %d  nit = %d  nfeval = %d\n", len(sol.X), sol.Nsub, sol.Stat.Nsteps, sol.Stat.Nfeval)
+
+	// check
+	for _, x := range []float64{0, 0.123, 0.5, 0.77, 1} {
+		y := la.NewVector(2)
+		sol.Eval(y, x)
+		chk.Float64(tst, io.Sf("y0(%g)", x), 1e-7, y[0], math.Sinh(x)/math.Sinh(1))
+		chk.Float64(tst, io.Sf("y1(%g)", x), 1e-7, y[1], math.Cosh(x)/math.Sinh(1))
+	}
+	for _, ρ := range sol.Res {
+		if ρ > sol.Rtol {
+			tst.Errorf("residual %g is greater than Rtol\n", ρ)
+		}
+	}
+}
+
+func TestBvp02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bvp02. Bratu's problem with numerical Jacobians")
+
+	// y'' + λ⋅exp(y) = 0 with y(0) = y(1) = 0
+	λ := 1.0
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0], f[1] = y[1], -λ*math.Exp(y[0])
+	}
+	bc := func(g, ya, yb la.Vector) {
+		g[0], g[1] = ya[0], yb[0]
+	}
+
+	// run with decreasing tolerances
+	for _, tol := range []float64{1e-3, 1e-6} {
+		sol := NewBvpSolver(2, fcn, nil, bc, nil, "")
+		defer sol.Free()
+		sol.Rtol = tol
+		sol.Solve(utl.LinSpace(0, 1, 5), func(y la.Vector, x float64) { y[0], y[1] = 0, 0 })
+		errMax := 0.0
+		for i, x := range sol.X {
+			errMax = math.Max(errMax, math.Abs(sol.Y[i][0]-bratuSolution(x, λ)))
+		}
+		io.Pf("tol = %g: npts = %3d  nsub = %d  nit = %2d  error = %.3e\n", tol, len(sol.X), sol.Nsub, sol.Stat.Nsteps, errMax)
+		chk.Float64(tst, "error", 10*tol, errMax, 0)
+	}
+}
+
+func TestBvp03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bvp03. boundary layer and mesh adaptation")
+
+	// ε⋅y'' + (1+ε)⋅y' + y = 0 with y(0) = 0 and y(1) = 1
+	ε := 1e-3
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0], f[1] = y[1], -((1+ε)*y[1]+y[0])/ε
+	}
+	bc := func(g, ya, yb la.Vector) {
+		g[0], g[1] = ya[0], yb[0]-1
+	}
+	yana := func(x float64) float64 {
+		return (math.Exp(-x) - math.Exp(-x/ε)) / (math.Exp(-1) - math.Exp(-1/ε))
+	}
+
+	// run
+	sol := NewBvpSolver(2, fcn, nil, bc, nil, "")
+	defer sol.Free()
+	sol.Solve(utl.LinSpace(0, 1, 11), func(y la.Vector, x float64) { y[0], y[1] = x, 1 })
+	io.Pf("npts = %d  nsub = %d  nit = %d  nfeval = %d\n", len(sol.X), sol.Nsub, sol.Stat.Nsteps, sol.Stat.Nfeval)
+
+	// check
+	errMax := 0.0
+	y := la.NewVector(2)
+	for _, x := range utl.LinSpace(0, 1, 1001) {
+		sol.Eval(y, x)
+		errMax = math.Max(errMax, math.Abs(y[0]-yana(x)))
+	}
+	io.Pf("max error = %.3e\n", errMax)
+	chk.Float64(tst, "error", 1e-4, errMax, 0)
+
+	// mesh is refined in the boundary layer
+	nlayer := 0
+	for _, x := range sol.X {
+		if x < 10*ε {
+			nlayer++
+		}
+	}
+	io.Pf("number of mesh points in the boundary layer = %d\n", nlayer)
+	if nlayer < len(sol.X)/3 {
+		tst.Errorf("mesh is not refined in the boundary layer\n")
+	}
+}
+
+func TestBvp04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Bvp04. simply supported beam")
+
+	// EI⋅w'''' = q with w = w'' = 0 at both ends; y = {w, w', M, V} with M = -EI⋅w''
+	EI, q, L := 2.0, 3.0, 4.0
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0], f[1], f[2], f[3] = y[1], -y[2]/EI, y[3], -q
+	}
+	bc := func(g, ya, yb la.Vector) {
+		g[0], g[1], g[2], g[3] = ya[0], ya[2], yb[0], yb[2]
+	}
+
+	// run
+	sol := NewBvpSolver(4, fcn, nil, bc, nil, "")
+	defer sol.Free()
+	sol.Solve(utl.LinSpace(0, L, 5), func(y la.Vector, x float64) { y.Fill(0) })
+	io.Pf("npts = %d  nsub = %d  nit = %d\n", len(sol.X), sol.Nsub, sol.Stat.Nsteps)
+
+	// check deflection and bending moment
+	y := la.NewVector(4)
+	for _, x := range []float64{1, L / 2, 3} { // mesh points
+		sol.Eval(y, x)
+		chk.Float64(tst, io.Sf("w(%g)", x), 1e-10, y[0], q*x*(L*L*L-2*L*x*x+x*x*x)/(24*EI))
+		chk.Float64(tst, io.Sf("M(%g)", x), 1e-10, y[2], q*x*(L-x)/2)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// daeMassMatrix returns the "mass" matrix of a semi-explicit DAE with nd differential variables
+func daeMassMatrix(ndim, nd int) (M *la.Triplet) {
+	M = new(la.Triplet)
+	M.Init(ndim, ndim, nd)
+	for i := 0; i < nd; i++ {
+		M.Put(i, i, 1)
+	}
+	return
+}
+
+func TestDae01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dae01. index-1 system")
+
+	// y' = -y + z  and  0 = z - y²  ⇒  y = 1/(1+exp(x))  and  z = y²
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = -y[0] + y[1]
+		f[1] = y[1] - y[0]*y[0]
+	}
+	yana := func(x float64) (y, z float64) {
+		y = 1.0 / (1.0 + math.Exp(x))
+		return y, y * y
+	}
+
+	// run with all DAE methods
+	xf := 2.0
+	for _, method := range []string{"radau5", "bdf", "ndf", "rodas"} {
+
+		// configuration
+		conf := NewConfig(method, "", nil)
+		conf.SetTol(1e-7)
+		conf.SetDAE([]int{0, 1})
+		sol := NewSolver(2, conf, fcn, nil, daeMassMatrix(2, 1))
+		defer sol.Free()
+
+		// consistent initial values
+		y := la.Vector{0.5, 0}
+		dydx := la.NewVector(2)
+		sol.ConsistentInit(dydx, y, 0)
+		chk.Array(tst, "y0   ", 1e-12, y, []float64{0.5, 0.25})
+		chk.Array(tst, "dydx0", 1e-8, dydx, []float64{-0.25, -0.25})
+
+		// solve
+		sol.Solve(y, 0, xf)
+		io.Pf("%-6s: nfeval=%d njeval=%d naccepted=%d nrejected=%d\n", method, sol.Stat.Nfeval,
+			sol.Stat.Njeval, sol.Stat.Naccepted, sol.Stat.Nrejected)
+		ye, ze := yana(xf)
+		chk.Float64(tst, "y", 1e-6, y[0], ye)
+		chk.Float64(tst, "z", 1e-6, y[1], ze)
+	}
+}
+
+func TestDae02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dae02. index-2 system (Hessenberg form)")
+
+	// y0' = z, y1' = -z + 2⋅cos(x)  and  0 = y0 - y1  ⇒  y0 = y1 = sin(x)  and  z = cos(x)
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = y[2]
+		f[1] = -y[2] + 2.0*math.Cos(x)
+		f[2] = y[0] - y[1]
+	}
+
+	// consistent initial values @ x = 1
+	x0 := 1.0
+	conf := NewConfig("radau5", "", nil)
+	conf.SetDAE([]int{0, 0, 2})
+	sol := NewSolver(3, conf, fcn, nil, daeMassMatrix(3, 2))
+	defer sol.Free()
+	y := la.Vector{math.Sin(x0), math.Sin(x0), 0}
+	dydx := la.NewVector(3)
+	sol.ConsistentInit(dydx, y, x0)
+	chk.Array(tst, "y0   ", 1e-10, y, []float64{math.Sin(x0), math.Sin(x0), math.Cos(x0)})
+	chk.Array(tst, "dydx0", 1e-7, dydx, []float64{math.Cos(x0), math.Cos(x0), -math.Sin(x0)})
+
+	// run with index-2 methods
+	xf := 2.0
+	for _, method := range []string{"radau5", "bdf", "ndf"} {
+
+		// configuration
+		conf := NewConfig(method, "", nil)
+		conf.SetTol(1e-7)
+		conf.SetDAE([]int{0, 0, 2})
+		sol := NewSolver(3, conf, fcn, nil, daeMassMatrix(3, 2))
+		defer sol.Free()
+
+		// consistent initial values
+		y := la.Vector{0, 0, 0}
+		dydx := la.NewVector(3)
+		sol.ConsistentInit(dydx, y, 0)
+		chk.Array(tst, "y0   ", 1e-10, y, []float64{0, 0, 1})
+		chk.Array(tst, "dydx0", 1e-7, dydx, []float64{1, 1, 0})
+
+		// solve
+		sol.Solve(y, 0, xf)
+		io.Pf("%-6s: nfeval=%d njeval=%d naccepted=%d nrejected=%d\n", method, sol.Stat.Nfeval,
+			sol.Stat.Njeval, sol.Stat.Naccepted, sol.Stat.Nrejected)
+		chk.Array(tst, "y", 1e-5, y, []float64{math.Sin(xf), math.Sin(xf), math.Cos(xf)})
+	}
+}
+
+func TestDae03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dae03. invalid configurations")
+
+	// method without DAE support
+	func() {
+		defer func() {
+			if err := recover(); err == nil {
+				tst.Errorf("SetDAE with dopri5 should have panicked\n")
+			}
+		}()
+		NewConfig("dopri5", "", nil).SetDAE([]int{0, 1})
+	}()
+
+	// index-2 with Rosenbrock method
+	func() {
+		defer func() {
+			if err := recover(); err == nil {
+				tst.Errorf("SetDAE with index 2 and rodas should have panicked\n")
+			}
+		}()
+		NewConfig("rodas", "", nil).SetDAE([]int{0, 0, 2})
+	}()
+
+	// missing mass matrix
+	func() {
+		defer func() {
+			if err := recover(); err == nil {
+				tst.Errorf("NewSolver without M should have panicked\n")
+			}
+		}()
+		conf := NewConfig("radau5", "", nil)
+		conf.SetDAE([]int{0, 1})
+		NewSolver(2, conf, func(f la.Vector, h, x float64, y la.Vector) {}, nil, nil)
+	}()
+}
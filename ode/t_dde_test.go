@@ -0,0 +1,163 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// ddeLinearSolution returns the solution of y'(x) = -y(x-τ) with y(x) = 1 for x ≤ 0; i.e.
+//   y(x) = Σ_{j=0}^{⌊x/τ⌋+1} (-1)ʲ ⋅ (x - (j-1)⋅τ)ʲ / j!
+func ddeLinearSolution(x, τ float64) (y float64) {
+	fact := 1.0
+	for j := 0; j <= int(math.Floor(x/τ))+1; j++ {
+		if j > 0 {
+			fact *= float64(j)
+		}
+		y += math.Pow(-1, float64(j)) * math.Pow(x-float64(j-1)*τ, float64(j)) / fact
+	}
+	return
+}
+
+func TestDde01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dde01. constant delay")
+
+	// y'(x) = -y(x-τ) with y(x) = 1 for x ≤ 0
+	fcn := func(f la.Vector, x float64, y la.Vector, ylag []la.Vector) {
+		f[0] = -ylag[0][0]
+	}
+	hist := func(y la.Vector, x float64) {
+		y[0] = 1
+	}
+
+	// run with delays larger and smaller than the stepsize
+	xf := 3.0
+	for _, method := range []string{"dopri5", "dopri8"} {
+		for _, τ := range []float64{1, 0.3, 0.05} {
+			conf := NewConfig(method, "", nil)
+			conf.SetTol(1e-10)
+			conf.SetStepOut(true, nil)
+			sol := NewDdeSolver(1, conf, fcn, hist, []float64{τ}, nil)
+			defer sol.Free()
+			y := la.Vector{1}
+			sol.Solve(y, 0, xf)
+			io.Pf("%-6s (τ = %4.2f): nfeval = %4d  naccepted = %4d  ndisc = %2d\n", method, τ,
+				sol.Stat.Nfeval, sol.Stat.Naccepted, len(sol.DiscX))
+			chk.Float64(tst, "y(xf)", 1e-8, y[0], ddeLinearSolution(xf, τ))
+
+			// discontinuities
+			if τ == 1 {
+				chk.Array(tst, "discontinuities", 1e-15, sol.DiscX, []float64{0, 1, 2})
+				chk.Ints(tst, "levels", sol.DiscL, []int{0, 1, 2})
+			}
+
+			// step output
+			out := sol.Sol.Out
+			chk.Float64(tst, "x0", 1e-15, out.StepX[0], 0)
+			chk.Float64(tst, "xf", 1e-15, out.StepX[out.StepIdx-1], xf)
+			for i := 1; i < out.StepIdx; i++ {
+				if out.StepX[i] <= out.StepX[i-1] {
+					tst.Errorf("step output is not increasing: %g ≥ %g\n", out.StepX[i-1], out.StepX[i])
+					return
+				}
+			}
+			for _, i := range []int{out.StepIdx / 3, out.StepIdx / 2} {
+				chk.Float64(tst, io.Sf("y(%.4f)", out.StepX[i]), 1e-8, out.StepY[i][0], ddeLinearSolution(out.StepX[i], τ))
+			}
+		}
+	}
+}
+
+func TestDde02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dde02. state-dependent delay")
+
+	// y'(x) = y(x - y(x)/2) with y(x) = 1 for x ≤ 0  ⇒  y = 1 + x for x ≤ 1 and
+	// y = 2⋅x - 2 + 2⋅exp((1-x)/2) for x ≥ 1; thus, x - τ = 0 @ x = 1
+	fcn := func(f la.Vector, x float64, y la.Vector, ylag []la.Vector) {
+		f[0] = ylag[0][0]
+	}
+	hist := func(y la.Vector, x float64) {
+		y[0] = 1
+	}
+	delay := func(x float64, y la.Vector) float64 {
+		return y[0] / 2.0
+	}
+
+	// run
+	xf := 3.0
+	for _, method := range []string{"dopri5", "dopri8"} {
+		conf := NewConfig(method, "", nil)
+		conf.SetTol(1e-10)
+		sol := NewDdeSolver(1, conf, fcn, hist, nil, []DelayF{delay})
+		defer sol.Free()
+		y := la.Vector{1}
+		sol.Solve(y, 0, xf)
+		io.Pf("%-6s: nfeval = %4d  naccepted = %4d  discontinuities = %v\n", method,
+			sol.Stat.Nfeval, sol.Stat.Naccepted, sol.DiscX)
+		chk.Float64(tst, "y(xf)", 1e-8, y[0], 2*xf-2+2*math.Exp((1-xf)/2))
+		chk.Array(tst, "discontinuities", 1e-8, sol.DiscX, []float64{0, 1})
+		chk.Float64(tst, "y(1)", 1e-8, sol.Sol.Out.EventY[0][0], 2)
+	}
+}
+
+func TestDde03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dde03. system with two delays and step output")
+
+	// control loop with transport delay: y0' = -k⋅y0(x-τ0) + y1, y1' = -y1(x-τ1)
+	// the reference solution is computed with a very small tolerance
+	k, τ0, τ1 := 2.0, 0.5, 0.2
+	fcn := func(f la.Vector, x float64, y la.Vector, ylag []la.Vector) {
+		f[0] = -k*ylag[0][0] + y[1]
+		f[1] = -ylag[1][1]
+	}
+	hist := func(y la.Vector, x float64) {
+		y[0], y[1] = 1+x, math.Cos(x)
+	}
+	xf := 5.0
+	solve := func(tol float64) (y la.Vector, sol *DdeSolver) {
+		conf := NewConfig("dopri5", "", nil)
+		conf.SetTol(tol)
+		conf.NmaxSS = 10000
+		nout := 0
+		conf.SetStepOut(false, func(istep int, h, x float64, y la.Vector) (stop bool) {
+			if istep != nout {
+				tst.Errorf("istep = %d is incorrect. it should be %d\n", istep, nout)
+			}
+			nout++
+			return
+		})
+		sol = NewDdeSolver(2, conf, fcn, hist, []float64{τ0, τ1}, nil)
+		y = la.Vector{1, 1}
+		sol.Solve(y, 0, xf)
+		chk.IntAssert(nout, sol.Stat.Naccepted+1)
+		return
+	}
+	yRef, solRef := solve(1e-13)
+	defer solRef.Free()
+	y, sol := solve(1e-7)
+	defer sol.Free()
+	io.Pf("ndisc = %d  nfeval = %d  naccepted = %d\n", len(sol.DiscX), sol.Stat.Nfeval, sol.Stat.Naccepted)
+	io.Pf("y(xf) = %v\n", y)
+	chk.Array(tst, "y(xf)", 1e-6, y, yRef)
+
+	// discontinuities: combinations of τ0 and τ1 up to level 5
+	for i, ξ := range sol.DiscX {
+		if sol.DiscL[i] < 0 || sol.DiscL[i] > 5 {
+			tst.Errorf("level of discontinuity @ %g is invalid: %d\n", ξ, sol.DiscL[i])
+		}
+	}
+	chk.Float64(tst, "last discontinuity", 1e-14, sol.DiscX[len(sol.DiscX)-1], 5*τ0)
+}
@@ -0,0 +1,197 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// dynDiag returns a diagonal matrix in triplet format
+func dynDiag(vals ...float64) (D *la.Triplet) {
+	D = new(la.Triplet)
+	D.Init(len(vals), len(vals), len(vals))
+	for i, v := range vals {
+		D.Put(i, i, v)
+	}
+	return
+}
+
+// dynReference solves M⋅ü + C⋅u̇ + fint(u) = fext(t) with Dopri5 (first-order form)
+func dynReference(u, v la.Vector, tf float64, M, C []float64, fint func(fi, u la.Vector), fext func(fe la.Vector, t float64)) {
+	n := len(u)
+	fi, fe := la.NewVector(n), la.NewVector(n)
+	conf := NewConfig("dopri5", "", nil)
+	conf.SetTol(1e-12)
+	conf.NmaxSS = 100000
+	sol := NewSolver(2*n, conf, func(f la.Vector, h, t float64, y la.Vector) {
+		fint(fi, y[:n])
+		fe.Fill(0)
+		if fext != nil {
+			fext(fe, t)
+		}
+		for i := 0; i < n; i++ {
+			f[i] = y[n+i]
+			f[n+i] = (fe[i] - C[i]*y[n+i] - fi[i]) / M[i]
+		}
+	}, nil, nil)
+	defer sol.Free()
+	y := la.NewVector(2 * n)
+	copy(y, u)
+	copy(y[n:], v)
+	sol.Solve(y, 0, tf)
+	copy(u, y[:n])
+	copy(v, y[n:])
+}
+
+func TestDyn01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dyn01. single degree of freedom: convergence and energy")
+
+	// undamped oscillator with ω = 2π
+	ω := 2.0 * math.Pi
+	M, K := dynDiag(1), dynDiag(ω*ω)
+	energy := func(u, v la.Vector) float64 { return (v[0]*v[0] + ω*ω*u[0]*u[0]) / 2 }
+
+	// run (tf is not at a peak of u in order to capture the phase error)
+	tf := 1.3
+	for _, scheme := range []string{"newmark", "hht", "galpha"} {
+		var errs []float64
+		for _, h := range []float64{0.01, 0.005} {
+			sol := NewDynSolver(scheme, 1, M, nil, K, nil, "")
+			defer sol.Free()
+			sol.SetStepOut(true, nil)
+			u, v, a := la.Vector{1}, la.Vector{0}, la.NewVector(1)
+			sol.Solve(u, v, a, 0, tf, h)
+			errs = append(errs, math.Abs(u[0]-math.Cos(ω*tf)))
+
+			// energy conservation of average acceleration method
+			if scheme == "newmark" {
+				E0 := energy(sol.Out.StepU[0], sol.Out.StepV[0])
+				for k := 1; k < sol.Out.StepIdx; k++ {
+					chk.Float64(tst, "E/E0", 1e-12, energy(sol.Out.StepU[k], sol.Out.StepV[k])/E0, 1)
+				}
+			}
+		}
+		order := math.Log2(errs[0] / errs[1])
+		io.Pf("%-8s: err(h=0.01) = %.3e  err(h=0.005) = %.3e  order = %.3f\n", scheme, errs[0], errs[1], order)
+		chk.Float64(tst, "order", 0.05, order, 2)
+		if errs[0] > 0.03 {
+			tst.Errorf("error is too large: %g\n", errs[0])
+		}
+	}
+}
+
+func TestDyn02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dyn02. numerical dissipation of high frequencies")
+
+	// stiff oscillator: ω⋅h = 100
+	ω, h := 1e4, 0.01
+	M, K := dynDiag(1), dynDiag(ω*ω)
+	energy := func(u, v la.Vector) float64 { return (v[0]*v[0] + ω*ω*u[0]*u[0]) / 2 }
+
+	// run
+	nsteps := 20
+	for _, scheme := range []string{"newmark", "hht", "galpha"} {
+		sol := NewDynSolver(scheme, 1, M, nil, K, nil, "")
+		defer sol.Free()
+		switch scheme {
+		case "hht":
+			sol.SetHHT(1.0 / 3.0) // ρ∞ = 0.5
+		case "galpha":
+			sol.SetGenAlpha(0.5)
+		}
+		u, v, a := la.Vector{1}, la.Vector{0}, la.NewVector(1)
+		E0 := energy(u, v)
+		sol.Solve(u, v, a, 0, float64(nsteps)*h, h)
+		ratio := energy(u, v) / E0
+		io.Pf("%-8s: E/E0 = %.3e\n", scheme, ratio)
+		if scheme == "newmark" {
+			chk.Float64(tst, "E/E0", 1e-10, ratio, 1)
+		} else if ratio > math.Pow(0.7, 2*float64(nsteps)) { // spectral radius ≈ ρ∞ = 0.5 for ω⋅h ≫ 1
+			tst.Errorf("high frequencies are not damped: E/E0 = %g\n", ratio)
+		}
+	}
+}
+
+func TestDyn03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dyn03. two degrees of freedom with damping and forces")
+
+	// system
+	m, c := []float64{2, 1}, []float64{0.1, 0.2}
+	M, C := dynDiag(m...), dynDiag(c...)
+	K := new(la.Triplet)
+	K.Init(2, 2, 4)
+	K.Put(0, 0, 30)
+	K.Put(0, 1, -10)
+	K.Put(1, 0, -10)
+	K.Put(1, 1, 10)
+	fint := func(fi, u la.Vector) { la.SpTriMatVecMul(fi, K, u) }
+	fext := func(fe la.Vector, t float64) { fe[0], fe[1] = 0, math.Sin(3*t) }
+
+	// reference solution
+	tf := 2.0
+	uRef, vRef := la.Vector{0.1, 0}, la.Vector{0, 0}
+	dynReference(uRef, vRef, tf, m, c, fint, fext)
+
+	// run
+	for _, scheme := range []string{"newmark", "hht", "galpha"} {
+		sol := NewDynSolver(scheme, 2, M, C, K, fext, "")
+		defer sol.Free()
+		u, v, a := la.Vector{0.1, 0}, la.Vector{0, 0}, la.NewVector(2)
+		sol.Solve(u, v, a, 0, tf, 1e-3)
+		io.Pf("%-8s: ndecomp=%d nlinsol=%d\n", scheme, sol.Stat.Ndecomp, sol.Stat.Nlinsol)
+		chk.Array(tst, "u", 1e-5, u, uRef)
+		chk.Array(tst, "v", 1e-4, v, vRef)
+		chk.IntAssert(sol.Stat.Ndecomp, 2) // mass matrix and system matrix
+	}
+}
+
+func TestDyn04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Dyn04. nonlinear system (Duffing oscillator)")
+
+	// m⋅ü + c⋅u̇ + k⋅u + k3⋅u³ = 0
+	m, c, k, k3 := 1.0, 0.05, 4.0, 20.0
+	fint := func(fi, u la.Vector) { fi[0] = k*u[0] + k3*u[0]*u[0]*u[0] }
+	ktan := func(Kt *la.Triplet, u la.Vector) {
+		if Kt.Max() == 0 {
+			Kt.Init(1, 1, 1)
+		}
+		Kt.Start()
+		Kt.Put(0, 0, k+3*k3*u[0]*u[0])
+	}
+
+	// reference solution
+	tf := 3.0
+	uRef, vRef := la.Vector{0.8}, la.Vector{0}
+	dynReference(uRef, vRef, tf, []float64{m}, []float64{c}, fint, nil)
+
+	// run
+	for _, scheme := range []string{"newmark", "hht", "galpha"} {
+		for _, cteTg := range []bool{false, true} {
+			sol := NewDynSolver(scheme, 1, dynDiag(m), dynDiag(c), nil, nil, "")
+			defer sol.Free()
+			sol.SetNonlinear(fint, ktan)
+			sol.CteTg = cteTg
+			u, v, a := la.Vector{0.8}, la.Vector{0}, la.NewVector(1)
+			sol.Solve(u, v, a, 0, tf, 1e-3)
+			io.Pf("%-8s (cteTg=%5v): nit_max=%d njeval=%d nlinsol=%d\n", scheme, cteTg, sol.Stat.Nitmax,
+				sol.Stat.Njeval, sol.Stat.Nlinsol)
+			chk.Array(tst, "u", 1e-4, u, uRef)
+			chk.Array(tst, "v", 1e-3, v, vRef)
+		}
+	}
+}
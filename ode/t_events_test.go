@@ -0,0 +1,143 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func TestEvents01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Events01. free fall: terminal event")
+
+	// problem: y0'' = -g with y = [height, velocity]
+	grav, h0 := 9.81, 10.0
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = y[1]
+		f[1] = -grav
+	}
+	jac := func(dfdy *la.Triplet, h, x float64, y la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(2, 2, 1)
+		}
+		dfdy.Start()
+		dfdy.Put(0, 1, 1)
+	}
+	height := func(x float64, y la.Vector) float64 { return y[0] }
+	xe := math.Sqrt(2 * h0 / grav)
+	ve := -grav * xe
+
+	// run with several methods
+	for _, method := range []string{"dopri5", "dopri8", "radau5", "rodas", "ark4", "bdf"} {
+
+		// configuration
+		conf := NewConfig(method, "", nil)
+		conf.SetTol(1e-8)
+		conf.SetStepOut(true, nil)
+		conf.SetDenseOut(true, 0.1, 5, nil)
+		conf.AddEvent(height, -1, true)
+
+		// solve
+		sol := NewSolver(2, conf, fcn, jac, nil)
+		defer sol.Free()
+		y := la.NewVectorSlice([]float64{h0, 0})
+		sol.Solve(y, 0, 5)
+		io.Pforan("%s: xe = %v\n", method, sol.Out.EventX)
+
+		// check
+		if !sol.Out.Stopped {
+			tst.Errorf("%s: simulation should have been stopped\n", method)
+			return
+		}
+		chk.Ints(tst, "K", sol.Out.EventK, []int{0})
+		chk.Array(tst, "xe", 1e-7, sol.Out.EventX, []float64{xe})
+		chk.Array(tst, "ye", 1e-6, sol.Out.EventY[0], []float64{0, ve})
+		chk.Array(tst, "y", 1e-6, y, []float64{0, ve})
+		X := sol.Out.GetStepX()
+		chk.Float64(tst, "last step x", 1e-15, X[len(X)-1], sol.Out.EventX[0])
+		D := sol.Out.GetDenseX()
+		chk.Float64(tst, "last dense x", 1e-15, D[len(D)-1], sol.Out.EventX[0])
+		chk.Float64(tst, "before last dense x", 1e-15, D[len(D)-2], 1.4)
+	}
+}
+
+func TestEvents02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Events02. harmonic oscillator: non-terminal events and directions")
+
+	// problem: y = [sin(x), cos(x)]
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = y[1]
+		f[1] = -y[0]
+	}
+	g0 := func(x float64, y la.Vector) float64 { return y[0] }
+	g1 := func(x float64, y la.Vector) float64 { return y[1] - 0.5 }
+
+	// run with several methods
+	for _, method := range []string{"dopri5", "radau5", "rodas", "ndf"} {
+
+		// configuration
+		conf := NewConfig(method, "", nil)
+		conf.SetTol(1e-10)
+		conf.AddEvent(g0, 0, false)  // any direction
+		conf.AddEvent(g0, +1, false) // increasing only
+		conf.AddEvent(g1, -1, false) // decreasing only
+
+		// solve
+		sol := NewSolver(2, conf, fcn, nil, nil)
+		defer sol.Free()
+		y := la.NewVectorSlice([]float64{0, 1})
+		sol.Solve(y, 0, 10)
+		io.Pforan("%s: k = %v\n", method, sol.Out.EventK)
+
+		// check
+		π := math.Pi
+		if sol.Out.Stopped {
+			tst.Errorf("%s: simulation should not have been stopped\n", method)
+			return
+		}
+		chk.Ints(tst, "K", sol.Out.EventK, []int{2, 0, 0, 1, 2, 0})
+		chk.Array(tst, "X", 1e-7, sol.Out.EventX, []float64{π / 3, π, 2 * π, 2 * π, 2*π + π/3, 3 * π})
+		chk.Array(tst, "y", 1e-7, y, []float64{math.Sin(10), math.Cos(10)})
+	}
+}
+
+func TestEvents03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Events03. events are reset when Solve is called again")
+
+	// problem: y = [sin(x), cos(x)]
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = y[1]
+		f[1] = -y[0]
+	}
+	g0 := func(x float64, y la.Vector) float64 { return y[0] }
+
+	// configuration
+	conf := NewConfig("dopri5", "", nil)
+	conf.SetTol(1e-10)
+	conf.AddEvent(g0, 0, false)
+
+	// solve twice with the same solver
+	sol := NewSolver(2, conf, fcn, nil, nil)
+	defer sol.Free()
+	π := math.Pi
+	for i := 0; i < 2; i++ {
+		y := la.NewVectorSlice([]float64{0, 1})
+		sol.Solve(y, 0, 7)
+		io.Pforan("run %d: k = %v\n", i, sol.Out.EventK)
+		chk.Ints(tst, io.Sf("K (run %d)", i), sol.Out.EventK, []int{0, 0})
+		chk.Array(tst, io.Sf("X (run %d)", i), 1e-7, sol.Out.EventX, []float64{π, 2 * π})
+		chk.Int(tst, io.Sf("len(Y) (run %d)", i), len(sol.Out.EventY), 2)
+	}
+}
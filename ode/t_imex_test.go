@@ -0,0 +1,153 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+	"github.com/cpmech/gosl/utl"
+)
+
+// imexProblem returns the split problem
+//   dy/dx = λ⋅(y - cos(x)) [stiff] - sin(x) [non-stiff]
+//   with solution y(x) = cos(x) + (y0 - 1)⋅exp(λ⋅x)
+func imexProblem(λ float64) (fI, fE Func, jac JacF, yana func(x, y0 float64) float64) {
+	fI = func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = λ * (y[0] - math.Cos(x))
+	}
+	fE = func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = -math.Sin(x)
+	}
+	jac = func(dfdy *la.Triplet, h, x float64, y la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(1, 1, 1)
+		}
+		dfdy.Start()
+		dfdy.Put(0, 0, λ)
+	}
+	yana = func(x, y0 float64) float64 {
+		return math.Cos(x) + (y0-1)*math.Exp(λ*x)
+	}
+	return
+}
+
+func TestImex01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Imex01. convergence of ARK methods")
+
+	// problem
+	λ := -1.0
+	fI, fE, jac, yana := imexProblem(λ)
+	xf, y0 := 1.0, 2.0
+	yExact := yana(xf, y0)
+
+	// run test
+	dxs := []float64{0.1, 0.05, 0.025, 0.0125}
+	lh := make([]float64, len(dxs))
+	le := make([]float64, len(dxs))
+	for im, method := range []string{"ark3", "ark4", "ark5"} {
+		for idx, dx := range dxs {
+			conf := NewConfig(method, "", nil)
+			conf.SetTols(1e-12, 1e-12)
+			conf.SetFixedH(dx, xf)
+			conf.SetIMEX(fE)
+			sol := NewSolver(1, conf, fI, jac, nil)
+			y := la.NewVectorSlice([]float64{y0})
+			sol.Solve(y, 0, xf)
+			sol.Free()
+			lh[idx] = math.Log10(dx)
+			le[idx] = math.Log10(math.Abs(y[0] - yExact))
+		}
+		_, m := num.LinFit(lh, le)
+		io.Pforan("%s: slope = %v\n", method, m)
+		chk.AnaNum(tst, "slope m", 0.1, m, float64(im+3), chk.Verbose)
+	}
+}
+
+func TestImex02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Imex02. stiff problem with variable steps and dense output")
+
+	// problem
+	λ := -1e4
+	fI, fE, jac, yana := imexProblem(λ)
+	xf, y0 := 2.0, 2.0
+
+	// run test
+	for _, method := range []string{"ark3", "ark4", "ark5"} {
+		for _, numJac := range []bool{false, true} {
+			conf := NewConfig(method, "", nil)
+			conf.SetTols(1e-6, 1e-6)
+			conf.SetIMEX(fE)
+			conf.SetDenseOut(true, 0.25, xf, nil)
+			jacobian := jac
+			if numJac {
+				jacobian = nil
+			}
+			sol := NewSolver(1, conf, fI, jacobian, nil)
+			defer sol.Free()
+			y := la.NewVectorSlice([]float64{y0})
+			sol.Solve(y, 0, xf)
+			io.Pf("%s (numJac=%v): nfeval=%d njeval=%d ndecomp=%d naccepted=%d nrejected=%d nitmax=%d\n",
+				method, numJac, sol.Stat.Nfeval, sol.Stat.Njeval, sol.Stat.Ndecomp, sol.Stat.Naccepted,
+				sol.Stat.Nrejected, sol.Stat.Nitmax)
+			chk.Float64(tst, "yFin", 1e-4, y[0], yana(xf, y0))
+			X := sol.Out.GetDenseX()
+			Y := sol.Out.GetDenseY(0)
+			chk.Array(tst, "Xdense", 1e-15, X, utl.LinSpace(0, xf, 9))
+			for i := 1; i < len(X); i++ {
+				chk.Float64(tst, io.Sf("y(%g)", X[i]), 5e-4, Y[i], yana(X[i], y0))
+			}
+			if sol.Stat.Naccepted > 300 { // an explicit method would need thousands of steps
+				tst.Errorf("%s: too many steps for a stiff problem: %d\n", method, sol.Stat.Naccepted)
+			}
+		}
+	}
+}
+
+func TestImex03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Imex03. Van der Pol's Equation (ESDIRK only)")
+
+	// problem
+	p := ProbVanDerPol(0, false)
+	p.Y[1] = -0.66
+
+	// reference solution with Radau5
+	yRef := la.NewVector(p.Ndim)
+	yRef.Apply(1, p.Y)
+	confR := NewConfig("radau5", "", nil)
+	confR.IniH = 1e-6
+	confR.NmaxSS = 10000
+	confR.SetTols(1e-9, 1e-9)
+	solR := NewSolver(p.Ndim, confR, p.Fcn, p.Jac, nil)
+	defer solR.Free()
+	solR.Solve(yRef, 0, p.Xf)
+
+	// run with all methods (without fE)
+	for _, method := range []string{"ark3", "ark4", "ark5"} {
+		conf := NewConfig(method, "", nil)
+		conf.IniH = 1e-6
+		conf.NmaxSS = 10000
+		conf.SetTols(1e-6, 1e-6)
+		y := la.NewVector(p.Ndim)
+		y.Apply(1, p.Y)
+		sol := NewSolver(p.Ndim, conf, p.Fcn, p.Jac, nil)
+		defer sol.Free()
+		sol.Solve(y, 0, p.Xf)
+		io.Pf("%s: nfeval=%d njeval=%d ndecomp=%d naccepted=%d nrejected=%d nitmax=%d\n",
+			method, sol.Stat.Nfeval, sol.Stat.Njeval, sol.Stat.Ndecomp, sol.Stat.Naccepted,
+			sol.Stat.Nrejected, sol.Stat.Nitmax)
+		chk.Array(tst, "y", 1e-4, y, yRef)
+	}
+}
@@ -5,11 +5,13 @@
 package ode
 
 import (
+	"math"
 	"testing"
 	"time"
 
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
 	"github.com/cpmech/gosl/plt"
 )
 
@@ -275,3 +277,30 @@ func TestOde04(tst *testing.T) {
 		plt.Save("/tmp/gosl/ode", "ode4")
 	}
 }
+
+func TestOde05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("ode05: variable steps end exactly at xf (x0 ≠ 0)")
+
+	// dy/dx = -y
+	fcn := func(f la.Vector, h, x float64, y la.Vector) { f[0] = -y[0] }
+
+	// x0 + (xf - x0) and the sum of step sizes differ from xf due to round-off
+	for _, c := range [][]float64{
+		{0.0009674166204141334, 0.23476087966198025},
+		{0.0007295106896763333, 0.04888115725593329},
+		{0.059937174147015694, 0.42155900772638183},
+	} {
+		x0, xf := c[0], c[1]
+		conf := NewConfig("dopri5", "", nil)
+		conf.SetStepOut(true, nil)
+		sol := NewSolver(1, conf, fcn, nil, nil)
+		y := la.Vector{1}
+		sol.Solve(y, x0, xf)
+		X := sol.Out.GetStepX()
+		io.Pf("x0 = %v  xf = %v  naccepted = %d\n", x0, xf, sol.Stat.Naccepted)
+		chk.Float64(tst, "last x", 1e-17, X[len(X)-1], xf)
+		chk.Float64(tst, "y(xf)", 1e-6, y[0], math.Exp(x0-xf))
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/plt"
+)
+
+func TestRosenbrock01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Rosenbrock01. Eq11 (analytical and numerical Jacobian)")
+
+	// problem
+	p := ProbHwEq11()
+
+	// reference solution
+	yExact := la.NewVector(p.Ndim)
+	p.Yana(yExact, p.Xf)
+
+	// run with both methods
+	for _, method := range []string{"ros3p", "rodas"} {
+		for _, numJac := range []bool{false, true} {
+			y, stat, _ := p.Solve(method, false, numJac)
+			io.Pf("%s (numJac=%v): nfeval=%d njeval=%d naccepted=%d nrejected=%d\n", method, numJac,
+				stat.Nfeval, stat.Njeval, stat.Naccepted, stat.Nrejected)
+			chk.Float64(tst, "yFin", 1e-4, y[0], yExact[0])
+			if stat.Njeval != stat.Ndecomp || stat.Naccepted+stat.Nrejected != stat.Ndecomp {
+				tst.Errorf("there must be one Jacobian and one decomposition per step\n")
+			}
+		}
+	}
+}
+
+func TestRosenbrock02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Rosenbrock02. convergence")
+
+	// problem
+	p := ProbHwEq11()
+
+	// prepare plot
+	if chk.Verbose {
+		plt.Reset(true, nil)
+	}
+
+	// reference solution
+	yExact := la.NewVector(p.Ndim)
+	p.Yana(yExact, p.Xf)
+
+	// run test
+	methods := []string{"ros3p", "rodas"}
+	orders := []float64{3, 4}
+	tols := []float64{0.16, 0.05}
+	p.ConvergenceTest(tst, 1e-3, 1e-2, 3, yExact, methods, orders, tols, chk.Verbose)
+
+	// plot
+	if chk.Verbose {
+		plt.Gll("$nFeval$", "$error$", nil)
+		plt.SetXlog()
+		plt.SetYlog()
+		plt.Save("/tmp/gosl/ode", "rosenbrock02")
+	}
+}
+
+func TestRosenbrock03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Rosenbrock03. Van der Pol's Equation (dense output)")
+
+	// problem
+	p := ProbVanDerPol(0, false)
+	p.Y[1] = -0.66
+
+	// reference solution with Radau5
+	yRef := la.NewVector(p.Ndim)
+	yRef.Apply(1, p.Y)
+	confR := NewConfig("radau5", "", nil)
+	confR.IniH = 1e-6
+	confR.NmaxSS = 10000
+	confR.SetTols(1e-9, 1e-9)
+	confR.SetDenseOut(true, 0.2, p.Xf, nil)
+	solR := NewSolver(p.Ndim, confR, p.Fcn, p.Jac, nil)
+	defer solR.Free()
+	solR.Solve(yRef, 0, p.Xf)
+
+	// run with both methods
+	for _, method := range []string{"ros3p", "rodas"} {
+
+		// configuration
+		conf := NewConfig(method, "", nil)
+		conf.IniH = 1e-6
+		conf.NmaxSS = 10000
+		conf.SetTols(1e-6, 1e-6)
+		conf.SetDenseOut(true, 0.2, p.Xf, nil)
+
+		// solver
+		y := la.NewVector(p.Ndim)
+		y.Apply(1, p.Y)
+		sol := NewSolver(p.Ndim, conf, p.Fcn, p.Jac, nil)
+		defer sol.Free()
+		sol.Solve(y, 0, p.Xf)
+		io.Pf("%s: nfeval=%d naccepted=%d nrejected=%d\n", method, sol.Stat.Nfeval, sol.Stat.Naccepted, sol.Stat.Nrejected)
+
+		// check final values
+		chk.Array(tst, "y", 1e-4, y, yRef)
+
+		// check dense output
+		chk.Array(tst, "X", 1e-15, sol.Out.GetDenseX(), solR.Out.GetDenseX())
+		chk.Array(tst, "Y0", 1e-4, sol.Out.GetDenseY(0), solR.Out.GetDenseY(0))
+		chk.Array(tst, "Y1", 1e-3, sol.Out.GetDenseY(1), solR.Out.GetDenseY(1))
+	}
+}
+
+func TestRosenbrock04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Rosenbrock04. Robertson's Equation")
+
+	// problem
+	p := ProbRobertson()
+
+	// reference solution with Radau5
+	yRef := la.NewVector(p.Ndim)
+	yRef.Apply(1, p.Y)
+	confR := NewConfig("radau5", "", nil)
+	confR.SetTols(1e-10, 1e-10)
+	solR := NewSolver(p.Ndim, confR, p.Fcn, p.Jac, nil)
+	defer solR.Free()
+	solR.Solve(yRef, 0, p.Xf)
+
+	// run with both methods
+	for _, method := range []string{"ros3p", "rodas"} {
+		conf := NewConfig(method, "", nil)
+		conf.IniH = 1e-6
+		conf.SetTols(1e-8, 1e-4)
+		y := la.NewVector(p.Ndim)
+		y.Apply(1, p.Y)
+		sol := NewSolver(p.Ndim, conf, p.Fcn, p.Jac, nil)
+		defer sol.Free()
+		sol.Solve(y, 0, p.Xf)
+		io.Pf("%s: nfeval=%d naccepted=%d nrejected=%d\n", method, sol.Stat.Nfeval, sol.Stat.Naccepted, sol.Stat.Nrejected)
+		for i := 0; i < p.Ndim; i++ {
+			if math.Abs(y[i]-yRef[i]) > 1e-3*math.Abs(yRef[i])+1e-8 {
+				tst.Errorf("%s: y[%d] = %g is different than reference %g\n", method, i, y[i], yRef[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/rnd"
+)
+
+func TestSde01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Sde01. strong convergence (geometric Brownian motion)")
+
+	// problem
+	p := ProbSdeGbm([]float64{1.5, -0.5}, []float64{0.5, 1.0})
+
+	// run
+	methods := []string{"em", "milstein", "sri"}
+	orders := []float64{0.5, 1.0, 1.5}
+	tols := []float64{0.15, 0.1, 0.1}
+	p.StrongConvergenceTest(tst, 16, 5, 400, 1234, methods, orders, tols)
+}
+
+func TestSde02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Sde02. strong convergence with additive noise (double-well potential)")
+
+	// problem
+	p := ProbSdeDoubleWell(0.8)
+
+	// run
+	methods := []string{"em", "milstein", "sri", "sra"}
+	orders := []float64{1.0, 1.0, 1.5, 1.5}
+	tols := []float64{0.1, 0.1, 0.2, 0.15}
+	p.StrongConvergenceTest(tst, 8, 5, 400, 1234, methods, orders, tols)
+}
+
+func TestSde03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Sde03. weak convergence (geometric Brownian motion)")
+
+	// problem
+	p := ProbSdeGbm([]float64{1.5}, []float64{0.2})
+
+	// run
+	methods := []string{"em", "milstein", "sri"}
+	orders := []float64{1.0, 1.0, 2.0}
+	tols := []float64{0.1, 0.1, 0.2}
+	p.WeakConvergenceTest(tst, 8, 5, 4000, 1234, methods, orders, tols)
+}
+
+func TestSde04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Sde04. reproducible ensembles")
+
+	// Ornstein-Uhlenbeck process: dy = θ⋅(m - y) dx + σ dW  ⇒  E[y] and Var[y] are known
+	θ, m, σ := 2.0, 1.0, 0.5
+	fcn := func(f la.Vector, h, x float64, y la.Vector) { f[0] = θ * (m - y[0]) }
+	gcn := func(g la.Vector, h, x float64, y la.Vector) { g[0] = σ }
+	y0, xf := la.Vector{3}, 1.0
+	sol := NewSdeSolver("sra", 1, fcn, gcn)
+
+	// results do not depend on the number of goroutines
+	npaths := 20000
+	Y1 := sol.Ensemble(y0, 0, xf, 0.05, npaths, 1, 4321)
+	chk.IntAssert(sol.Stat.Nsteps, npaths*20)
+	Y4 := sol.Ensemble(y0, 0, xf, 0.05, npaths, 4, 4321)
+	for k := 0; k < npaths; k++ {
+		if Y1[k][0] != Y4[k][0] {
+			tst.Errorf("sample path %d is not reproducible: %g != %g\n", k, Y1[k][0], Y4[k][0])
+			return
+		}
+	}
+
+	// the same as sequential runs with the same seed
+	for _, k := range []int{0, npaths / 2, npaths - 1} {
+		y := y0.GetCopy()
+		sol.Solve(y, 0, xf, 0.05, rnd.NewWiener(4321+k))
+		chk.Float64(tst, io.Sf("y%d", k), 1e-15, y[0], Y1[k][0])
+	}
+
+	// statistics
+	mean, vari := 0.0, 0.0
+	for k := 0; k < npaths; k++ {
+		mean += Y1[k][0] / float64(npaths)
+	}
+	for k := 0; k < npaths; k++ {
+		vari += (Y1[k][0] - mean) * (Y1[k][0] - mean) / float64(npaths-1)
+	}
+	meanAna := m + (y0[0]-m)*math.Exp(-θ*xf)
+	variAna := σ * σ * (1 - math.Exp(-2*θ*xf)) / (2 * θ)
+	io.Pf("mean = %.5f (%.5f)  var = %.5f (%.5f)\n", mean, meanAna, vari, variAna)
+	chk.Float64(tst, "mean", 0.01, mean, meanAna)
+	chk.Float64(tst, "var", 0.003, vari, variAna)
+}
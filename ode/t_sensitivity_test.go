@@ -0,0 +1,154 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+)
+
+// kinetics implements the first order reactions A → B → C with rates k₀ and k₁
+type kinetics struct {
+	k    []float64 // rates
+	fcn  Func      // dy/dx
+	jac  JacF      // df/dy
+	jacP JacP      // df/dp
+}
+
+// newKinetics returns a new kinetic model
+func newKinetics(k0, k1 float64) (o *kinetics) {
+	o = &kinetics{k: []float64{k0, k1}}
+	o.fcn = func(f la.Vector, h, x float64, y la.Vector) {
+		f[0] = -o.k[0] * y[0]
+		f[1] = o.k[0]*y[0] - o.k[1]*y[1]
+	}
+	o.jac = func(dfdy *la.Triplet, h, x float64, y la.Vector) {
+		if dfdy.Max() == 0 {
+			dfdy.Init(2, 2, 3)
+		}
+		dfdy.Start()
+		dfdy.Put(0, 0, -o.k[0])
+		dfdy.Put(1, 0, o.k[0])
+		dfdy.Put(1, 1, -o.k[1])
+	}
+	o.jacP = func(dfdp *la.Matrix, x float64, y la.Vector) {
+		dfdp.Set(0, 0, -y[0])
+		dfdp.Set(0, 1, 0)
+		dfdp.Set(1, 0, y[0])
+		dfdp.Set(1, 1, -y[1])
+	}
+	return
+}
+
+// yana returns the analytical solution with y(0) = {1, 0}
+func (o *kinetics) yana(x, k0, k1 float64) (y0, y1 float64) {
+	y0 = math.Exp(-k0 * x)
+	y1 = k0 / (k1 - k0) * (math.Exp(-k0*x) - math.Exp(-k1*x))
+	return
+}
+
+func TestSens01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Sens01. forward sensitivities")
+
+	// problem and analytical sensitivities
+	p := newKinetics(1.5, 0.5)
+	xf := 2.0
+	Sana := la.NewMatrix(2, 2)
+	for i := 0; i < 2; i++ {
+		Sana.Set(i, 0, num.DerivCen5(p.k[0], 1e-3, func(k0 float64) float64 {
+			y0, y1 := p.yana(xf, k0, p.k[1])
+			return []float64{y0, y1}[i]
+		}))
+		Sana.Set(i, 1, num.DerivCen5(p.k[1], 1e-3, func(k1 float64) float64 {
+			y0, y1 := p.yana(xf, p.k[0], k1)
+			return []float64{y0, y1}[i]
+		}))
+	}
+
+	// run with explicit and implicit methods, with and without Jacobian
+	for _, method := range []string{"dopri5", "radau5", "bdf"} {
+		for _, withJac := range []bool{false, true} {
+			conf := NewConfig(method, "", nil)
+			conf.SetTol(1e-8)
+			jac := p.jac
+			if !withJac {
+				jac = nil
+			}
+			sens := NewSensForward(2, 2, conf, p.fcn, jac, p.jacP)
+			defer sens.Free()
+			y := la.Vector{1, 0}
+			S := la.NewMatrix(2, 2)
+			sens.Solve(y, S, 0, xf)
+			io.Pf("%-6s (jac=%5v): nfeval=%d naccepted=%d\n", method, withJac, sens.Sol.Stat.Nfeval, sens.Sol.Stat.Naccepted)
+			y0, y1 := p.yana(xf, p.k[0], p.k[1])
+			chk.Array(tst, "y   ", 1e-6, y, []float64{y0, y1})
+			chk.Deep2(tst, "dydp", 1e-5, S.GetDeep2(), Sana.GetDeep2())
+		}
+	}
+}
+
+func TestSens02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Sens02. adjoint sensitivities")
+
+	// problem
+	p := newKinetics(1.5, 0.5)
+	xf := 2.0
+	k0, k1 := p.k[0], p.k[1]
+
+	// G = y1(xf)
+	G1 := &Functional{DgDy: func(dgdy, y la.Vector) { dgdy[0], dgdy[1] = 0, 1 }}
+	dG1dp := []float64{
+		num.DerivCen5(k0, 1e-3, func(k float64) float64 { _, y1 := p.yana(xf, k, k1); return y1 }),
+		num.DerivCen5(k1, 1e-3, func(k float64) float64 { _, y1 := p.yana(xf, k0, k); return y1 }),
+	}
+	dG1dy0 := []float64{k0 / (k1 - k0) * (math.Exp(-k0*xf) - math.Exp(-k1*xf)), math.Exp(-k1 * xf)}
+
+	// G = ∫ y0 dx
+	G2 := &Functional{DqDy: func(dqdy la.Vector, x float64, y la.Vector) { dqdy[0], dqdy[1] = 1, 0 }}
+	intY0 := func(k float64) float64 { return (1 - math.Exp(-k*xf)) / k }
+	dG2dp := []float64{num.DerivCen5(k0, 1e-3, intY0), 0}
+	dG2dy0 := []float64{intY0(k0), 0}
+
+	// run with explicit and implicit methods, with and without Jacobian
+	for _, method := range []string{"dopri5", "radau5", "bdf"} {
+		for _, withJac := range []bool{false, true} {
+			conf := NewConfig(method, "", nil)
+			conf.SetTol(1e-8)
+			jac := p.jac
+			if !withJac {
+				jac = nil
+			}
+			sens := NewSensAdjoint(2, 2, conf, p.fcn, jac, p.jacP)
+			defer sens.Free()
+			sens.Nchk = 5
+			dGdp, dGdy0 := la.NewVector(2), la.NewVector(2)
+			io.Pf("%-6s (jac=%5v)\n", method, withJac)
+
+			// terminal functional
+			y := la.Vector{1, 0}
+			sens.Gradient(dGdp, dGdy0, y, 0, xf, G1)
+			io.Pf("  number of checkpoints = %d\n", len(sens.chkX))
+			y0, y1 := p.yana(xf, k0, k1)
+			chk.Array(tst, "  y        ", 1e-6, y, []float64{y0, y1})
+			chk.Array(tst, "  dG1/dp   ", 1e-6, dGdp, dG1dp)
+			chk.Array(tst, "  dG1/dy0  ", 1e-6, dGdy0, dG1dy0)
+
+			// integral functional
+			y = la.Vector{1, 0}
+			sens.Gradient(dGdp, dGdy0, y, 0, xf, G2)
+			chk.Array(tst, "  dG2/dp   ", 1e-6, dGdp, dG2dp)
+			chk.Array(tst, "  dG2/dy0  ", 1e-6, dGdy0, dG2dy0)
+		}
+	}
+}
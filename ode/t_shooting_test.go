@@ -0,0 +1,125 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+func TestShooting01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Shooting01. Bratu's problem with single and multiple shooting")
+
+	// y'' + λ⋅exp(y) = 0 with y(0) = y(1) = 0
+	λ := 1.0
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0], f[1] = y[1], -λ*math.Exp(y[0])
+	}
+	bc := func(g, ya, yb la.Vector) {
+		g[0], g[1] = ya[0], yb[0]
+	}
+
+	// run
+	for _, m := range []int{1, 4} {
+		conf := NewConfig("dopri8", "", nil)
+		conf.SetTol(1e-10)
+		sol := NewShootingSolver(2, conf, fcn, nil, bc, utl.LinSpace(0, 1, m+1))
+		defer sol.Free()
+		sol.Solve(func(y la.Vector, x float64) { y[0], y[1] = 0, 0 })
+		io.Pf("m = %d: nit = %d  nfeval = %d\n", m, sol.Nit, sol.Stat.Nfeval)
+		for k, x := range sol.X {
+			chk.Float64(tst, io.Sf("y(%g)", x), 1e-9, sol.Y[k][0], bratuSolution(x, λ))
+		}
+		y := la.NewVector(2)
+		for _, x := range []float64{0.1, 0.6} {
+			sol.Eval(y, x)
+			chk.Float64(tst, io.Sf("y(%g)", x), 1e-9, y[0], bratuSolution(x, λ))
+		}
+	}
+}
+
+func TestShooting02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Shooting02. unstable initial value problem")
+
+	// y'' = μ²⋅y with y(0) = y(1) = 1  ⇒  y = (sinh(μ⋅(1-x)) + sinh(μ⋅x)) / sinh(μ)
+	μ := 25.0
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0], f[1] = y[1], μ*μ*y[0]
+	}
+	bc := func(g, ya, yb la.Vector) {
+		g[0], g[1] = ya[0]-1, yb[0]-1
+	}
+	yana := func(x float64) float64 {
+		return (math.Sinh(μ*(1-x)) + math.Sinh(μ*x)) / math.Sinh(μ)
+	}
+
+	// run
+	errs := make([]float64, 2)
+	for i, m := range []int{1, 10} {
+		conf := NewConfig("dopri8", "", nil)
+		conf.SetTol(1e-10)
+		sol := NewShootingSolver(2, conf, fcn, nil, bc, utl.LinSpace(0, 1, m+1))
+		defer sol.Free()
+		sol.Solve(func(y la.Vector, x float64) { y[0], y[1] = 1, 0 })
+		y := la.NewVector(2)
+		for _, x := range utl.LinSpace(0, 1, 21) {
+			sol.Eval(y, x)
+			errs[i] = math.Max(errs[i], math.Abs(y[0]-yana(x)))
+		}
+		io.Pf("m = %2d: nit = %d  error = %.3e\n", m, sol.Nit, errs[i])
+	}
+	chk.Float64(tst, "error of multiple shooting", 1e-8, errs[1], 0)
+	if errs[1] > errs[0]/10 {
+		tst.Errorf("multiple shooting should be more accurate than single shooting\n")
+	}
+}
+
+func TestShooting03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Shooting03. nonlinear boundary conditions (shooting and collocation)")
+
+	// y'' = -y with y(0)² + y'(0)² = 1 and y(π/2) = ½  ⇒  y = ½⋅sin(x) + √¾⋅cos(x)
+	fcn := func(f la.Vector, h, x float64, y la.Vector) {
+		f[0], f[1] = y[1], -y[0]
+	}
+	bc := func(g, ya, yb la.Vector) {
+		g[0], g[1] = ya[0]*ya[0]+ya[1]*ya[1]-1, yb[0]-0.5
+	}
+	guess := func(y la.Vector, x float64) { y[0], y[1] = 1, 0 }
+	yana := func(x float64) float64 { return 0.5*math.Sin(x) + math.Sqrt(0.75)*math.Cos(x) }
+	xb := math.Pi / 2
+
+	// multiple shooting
+	conf := NewConfig("dopri5", "", nil)
+	conf.SetTol(1e-10)
+	shoot := NewShootingSolver(2, conf, fcn, nil, bc, utl.LinSpace(0, xb, 3))
+	defer shoot.Free()
+	shoot.Solve(guess)
+
+	// collocation
+	coll := NewBvpSolver(2, fcn, nil, bc, nil, "")
+	defer coll.Free()
+	coll.Rtol = 1e-6
+	coll.Solve(utl.LinSpace(0, xb, 5), guess)
+
+	// check
+	y := la.NewVector(2)
+	for _, x := range []float64{0, 0.4, 1.1, xb} {
+		shoot.Eval(y, x)
+		chk.Float64(tst, io.Sf("shooting: y(%.2f)", x), 1e-8, y[0], yana(x))
+		coll.Eval(y, x)
+		chk.Float64(tst, io.Sf("collocation: y(%.2f)", x), 1e-7, y[0], yana(x))
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// keplerEnergy returns the Hamiltonian H = ½⋅pᵀ⋅p - 1/‖q‖ of the Kepler problem
+func keplerEnergy(y la.Vector) float64 {
+	return (y[2]*y[2]+y[3]*y[3])/2.0 - 1.0/math.Sqrt(y[0]*y[0]+y[1]*y[1])
+}
+
+// keplerDrift solves the Kepler problem over nper periods and returns the maximum energy error
+// during the first and the last tenth of the simulation
+func keplerDrift(p *Problem, conf *Config, nper int) (errFirst, errLast float64, stat *Stat) {
+	H0 := keplerEnergy(p.Y)
+	xf := float64(nper) * p.Xf
+	conf.SetStepOut(false, func(istep int, h, x float64, y la.Vector) (stop bool) {
+		err := math.Abs(keplerEnergy(y) - H0)
+		if x <= xf/10.0 {
+			errFirst = math.Max(errFirst, err)
+		}
+		if x >= 0.9*xf {
+			errLast = math.Max(errLast, err)
+		}
+		return
+	})
+	sol := NewSolver(p.Ndim, conf, p.Fcn, nil, nil)
+	defer sol.Free()
+	y := p.Y.GetCopy()
+	sol.Solve(y, 0, xf)
+	return errFirst, errLast, sol.Stat
+}
+
+func TestSymplectic01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Symplectic01. convergence with fixed steps (Kepler problem)")
+
+	// problem: one period ⇒ y(xf) = y(0)
+	p := ProbKepler(0.5)
+
+	// run
+	methods := []string{"verlet", "yoshida4", "yoshida6", "gauss4", "gauss6"}
+	orders := []float64{2, 4, 6, 4, 6}
+	nsteps := [][]int{{1000, 2000}, {400, 800}, {200, 400}, {100, 200}, {50, 100}}
+	for k, method := range methods {
+		var errs []float64
+		for _, n := range nsteps[k] {
+			conf := NewConfig(method, "", nil)
+			conf.SetFixedH(p.Xf/float64(n), p.Xf)
+			sol := NewSolver(p.Ndim, conf, p.Fcn, nil, nil)
+			defer sol.Free()
+			y := p.Y.GetCopy()
+			sol.Solve(y, 0, p.Xf)
+			errs = append(errs, y.NormDiff(p.Y))
+		}
+		order := math.Log2(errs[0] / errs[1])
+		io.Pf("%-8s: err = %.3e, %.3e  order = %.3f\n", method, errs[0], errs[1], order)
+		chk.Float64(tst, "order", 0.2, order, orders[k])
+	}
+}
+
+func TestSymplectic02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Symplectic02. long-time energy conservation with fixed steps")
+
+	// problem
+	p := ProbKepler(0.6)
+	nper := 100
+
+	// symplectic methods: bounded energy error
+	for _, method := range []string{"verlet", "yoshida4", "gauss4"} {
+		conf := NewConfig(method, "", nil)
+		conf.SetFixedH(p.Xf/500.0, float64(nper)*p.Xf)
+		errFirst, errLast, _ := keplerDrift(p, conf, nper)
+		io.Pf("%-8s: max|H-H0|: first = %.3e  last = %.3e\n", method, errFirst, errLast)
+		if errLast > 1.1*errFirst || errLast > 1e-2 {
+			tst.Errorf("energy error of %q is not bounded\n", method)
+		}
+	}
+
+	// explicit Runge-Kutta method: energy drift
+	conf := NewConfig("rk4", "", nil)
+	conf.SetFixedH(p.Xf/500.0, float64(nper)*p.Xf)
+	errFirst, errLast, _ := keplerDrift(p, conf, nper)
+	io.Pf("%-8s: max|H-H0|: first = %.3e  last = %.3e\n", "rk4", errFirst, errLast)
+	if errLast < 5*errFirst {
+		tst.Errorf("energy error of rk4 should drift\n")
+	}
+}
+
+func TestSymplectic03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("Symplectic03. time-reversible adaptive steps")
+
+	// problem with high eccentricity
+	p := ProbKepler(0.9)
+	nper := 50
+
+	// run
+	for _, method := range []string{"verlet", "yoshida4", "gauss6"} {
+		conf := NewConfig(method, "", nil)
+		conf.SymEps = 0.05
+		conf.NmaxSS = 100000
+		errFirst, errLast, stat := keplerDrift(p, conf, nper)
+		io.Pf("%-8s: naccepted = %6d  max|H-H0|: first = %.3e  last = %.3e\n", method, stat.Naccepted, errFirst, errLast)
+		if errLast > 1.1*errFirst || errLast > 1e-2 {
+			tst.Errorf("energy error of %q is not bounded\n", method)
+		}
+
+		// fixed steps with the same number of steps
+		conf = NewConfig(method, "", nil)
+		conf.SetFixedH(float64(nper)*p.Xf/float64(stat.Naccepted), float64(nper)*p.Xf)
+		_, errFixed, _ := keplerDrift(p, conf, nper)
+		io.Pf("%-8s: max|H-H0| with fixed steps = %.3e\n", "", errFixed)
+		if errFixed < 10*errLast {
+			tst.Errorf("adaptive steps of %q should be more accurate than fixed steps\n", method)
+		}
+	}
+}
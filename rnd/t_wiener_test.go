@@ -0,0 +1,44 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rnd
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_wiener01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("wiener01. Wiener increments")
+
+	// generate
+	n, h := 100000, 0.25
+	w := NewWiener(1234)
+	dW, dZ := make([]float64, n), make([]float64, n)
+	w.Increments(dW, dZ, h)
+
+	// moments: Var[ΔW] = h, Var[ΔZ] = h³/3 and Cov[ΔW,ΔZ] = h²/2
+	aveW, devW := StatAveDev(dW, true)
+	aveZ, devZ := StatAveDev(dZ, true)
+	cov := 0.0
+	for i := 0; i < n; i++ {
+		cov += (dW[i] - aveW) * (dZ[i] - aveZ) / float64(n-1)
+	}
+	io.Pforan("E[ΔW] = %v  Var[ΔW] = %v  E[ΔZ] = %v  Var[ΔZ] = %v  Cov = %v\n", aveW, devW*devW, aveZ, devZ*devZ, cov)
+	chk.Float64(tst, "E[ΔW]", 0.005, aveW, 0)
+	chk.Float64(tst, "Var[ΔW]", 0.005, devW*devW, h)
+	chk.Float64(tst, "E[ΔZ]", 0.005, aveZ, 0)
+	chk.Float64(tst, "Var[ΔZ]", 0.005, devZ*devZ, h*h*h/3)
+	chk.Float64(tst, "Cov[ΔW,ΔZ]", 0.005, cov, h*h/2)
+
+	// reproducible with the same seed, regardless of dZ
+	w = NewWiener(1234)
+	dWb := make([]float64, n)
+	w.Increments(dWb, nil, h)
+	chk.Array(tst, "ΔW", 1e-17, dWb, dW)
+}
@@ -0,0 +1,60 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rnd
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Wiener generates increments of independent Wiener processes (standard Brownian motions) W(t)
+//
+//  NOTE: each Wiener holds its own generator of random numbers; thus, the sequence of increments
+//        is reproducible (with the same seed) and independent of the global generator (see Init)
+//        and of the generators used by other goroutines
+//
+type Wiener struct {
+	rng *rand.Rand // generator of random numbers
+}
+
+// NewWiener returns a new generator of Wiener increments
+//  Input:
+//   seed -- seed value; use seed <= 0 to use current time
+func NewWiener(seed int) (o *Wiener) {
+	if seed <= 0 {
+		seed = int(time.Now().UnixNano())
+	}
+	o = new(Wiener)
+	o.rng = rand.New(rand.NewSource(int64(seed)))
+	return
+}
+
+// Increments generates the increments of len(dW) independent Wiener processes over [t, t+h]
+//
+//   ΔW = W(t+h) - W(t) = √h ⋅ ξ1                            ~ N(0, h)
+//   ΔZ = ∫_t^{t+h} (W(s) - W(t)) ds = ½ ⋅ h^{3/2} ⋅ (ξ1 + ξ2/√3)   ~ N(0, h³/3)
+//
+//  where ξ1 and ξ2 are independent standard normal variables. ΔZ is the iterated integral I_(1,0)
+//  needed by higher order schemes for stochastic differential equations.
+//
+//  Input:
+//   h -- time increment
+//  Output:
+//   dW -- increments ΔW
+//   dZ -- [may be nil] integrals ΔZ; len(dZ) == len(dW)
+//
+//  NOTE: ξ2 is always generated; thus, the sequence of ΔW does not depend on whether dZ is nil
+//
+func (o *Wiener) Increments(dW, dZ []float64, h float64) {
+	sq := math.Sqrt(h)
+	for i := 0; i < len(dW); i++ {
+		ξ1, ξ2 := o.rng.NormFloat64(), o.rng.NormFloat64()
+		dW[i] = sq * ξ1
+		if dZ != nil {
+			dZ[i] = 0.5 * h * sq * (ξ1 + ξ2/math.Sqrt(3))
+		}
+	}
+}